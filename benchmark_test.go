@@ -4,6 +4,7 @@ import (
 	// "fmt"
 	// "github.com/stretchr/testify/assert"
 	"bytes"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -21,19 +22,37 @@ func BenchmarkPutField_1(b *testing.B) {
 		builder.PutString("username", "username")
 		builder.PutUint8("age", uint8(25))
 		builder.Build()
-		builder.reset()
+		builder.Reset()
 	}
 }
 
+func BenchmarkBuilderPool(b *testing.B) {
+	User := createTestTupleType()
+	pool := NewBuilderPool(User, 1024, 0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			builder := pool.Get()
+			builder.PutString("uuid", "0123456789abcdef")
+			builder.PutString("username", "username")
+			builder.PutUint8("age", uint8(25))
+			builder.Build()
+			pool.Put(builder)
+		}
+	})
+}
+
 func BenchmarkSmallTuple(b *testing.B) {
 
 	Image := New("testing", "Image")
 	Image.AddVersion(
-		Field{"url", true, StringField},
-		Field{"title", true, StringField},
-		Field{"width", true, Uint32Field},
-		Field{"height", true, Uint32Field},
-		Field{"size", true, Uint8Field},
+		Field{Name: "url", Required: true, Type: StringField},
+		Field{Name: "title", Required: true, Type: StringField},
+		Field{Name: "width", Required: true, Type: Uint32Field},
+		Field{Name: "height", Required: true, Type: Uint32Field},
+		Field{Name: "size", Required: true, Type: Uint8Field},
 	)
 
 	// create builder
@@ -48,7 +67,7 @@ func BenchmarkSmallTuple(b *testing.B) {
 		builder.PutUint32("height", uint32(2))
 		builder.PutUint8("size", uint8(0))
 		builder.Build()
-		builder.reset()
+		builder.Reset()
 	}
 }
 
@@ -67,12 +86,12 @@ func BenchmarkBuild(b *testing.B) {
 
 	// Version 1
 	AType.AddVersion(
-		Field{"Name", true, StringField},
-		Field{"BirthDay", true, TimestampField},
-		Field{"Phone", true, StringField},
-		Field{"Siblings", true, Uint8Field},
+		Field{Name: "Name", Required: true, Type: StringField},
+		Field{Name: "BirthDay", Required: true, Type: TimestampField},
+		Field{Name: "Phone", Required: true, Type: StringField},
+		Field{Name: "Siblings", Required: true, Type: Uint8Field},
 		// Field{"Spouse", true, BooleanField},
-		Field{"Money", true, Float32Field},
+		Field{Name: "Money", Required: true, Type: Float32Field},
 	)
 
 	// create builder
@@ -89,7 +108,54 @@ func BenchmarkBuild(b *testing.B) {
 		// builder.PutBoolean("Spouse", false)
 		builder.PutFloat32("Money", 999.99)
 		builder.Build()
-		builder.reset()
+		builder.Reset()
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	// Benchmark type
+	AType := New("testing", "A")
+
+	// Version 1
+	AType.AddVersion(
+		Field{Name: "Name", Required: true, Type: StringField},
+		Field{Name: "BirthDay", Required: true, Type: TimestampField},
+		Field{Name: "Phone", Required: true, Type: StringField},
+		Field{Name: "Siblings", Required: true, Type: Uint8Field},
+		// Field{"Spouse", true, BooleanField},
+		Field{Name: "Money", Required: true, Type: Float32Field},
+	)
+
+	buffer := make([]byte, 1024)
+	a := A{"Bugs Bunny", time.Now(), "555-555-5555", 0, false, 999.99}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Marshal(&a, AType, buffer)
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	// Benchmark type
+	AType := New("testing", "A")
+
+	// Version 1
+	AType.AddVersion(
+		Field{Name: "Name", Required: true, Type: StringField},
+		Field{Name: "BirthDay", Required: true, Type: TimestampField},
+		Field{Name: "Phone", Required: true, Type: StringField},
+		Field{Name: "Siblings", Required: true, Type: Uint8Field},
+		// Field{"Spouse", true, BooleanField},
+		Field{Name: "Money", Required: true, Type: Float32Field},
+	)
+
+	buffer := make([]byte, 1024)
+	tuple, _ := Marshal(A{"Bugs Bunny", time.Now(), "555-555-5555", 0, false, 999.99}, AType, buffer)
+
+	var a A
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Unmarshal(tuple, &a)
 	}
 }
 
@@ -99,12 +165,12 @@ func BenchmarkEncode(b *testing.B) {
 
 	// Version 1
 	AType.AddVersion(
-		Field{"Name", true, StringField},
-		Field{"BirthDay", true, TimestampField},
-		Field{"Phone", true, StringField},
-		Field{"Siblings", true, Uint8Field},
-		Field{"Spouse", true, BooleanField},
-		Field{"Money", true, Float32Field},
+		Field{Name: "Name", Required: true, Type: StringField},
+		Field{Name: "BirthDay", Required: true, Type: TimestampField},
+		Field{Name: "Phone", Required: true, Type: StringField},
+		Field{Name: "Siblings", Required: true, Type: Uint8Field},
+		Field{Name: "Spouse", Required: true, Type: BooleanField},
+		Field{Name: "Money", Required: true, Type: Float32Field},
 	)
 
 	var buf []byte
@@ -120,7 +186,7 @@ func BenchmarkEncode(b *testing.B) {
 	builder.PutString("Phone", "555-555-5555")
 	builder.PutUint8("Siblings", uint8(0))
 	builder.PutTimestamp("BirthDay", now)
-	// builder.PutBoolean("Spouse", false)
+	builder.PutBool("Spouse", false)
 	builder.PutFloat32("Money", 999.99)
 	a, _ := builder.Build()
 
@@ -142,12 +208,12 @@ func BenchmarkDecode(b *testing.B) {
 
 	// Version 1
 	AType.AddVersion(
-		Field{"Name", true, StringField},
-		Field{"BirthDay", true, TimestampField},
-		Field{"Phone", true, StringField},
-		Field{"Siblings", true, Uint8Field},
-		Field{"Spouse", true, BooleanField},
-		Field{"Money", true, Float32Field},
+		Field{Name: "Name", Required: true, Type: StringField},
+		Field{Name: "BirthDay", Required: true, Type: TimestampField},
+		Field{Name: "Phone", Required: true, Type: StringField},
+		Field{Name: "Siblings", Required: true, Type: Uint8Field},
+		Field{Name: "Spouse", Required: true, Type: BooleanField},
+		Field{Name: "Money", Required: true, Type: Float32Field},
 	)
 
 	// Create registry
@@ -167,7 +233,7 @@ func BenchmarkDecode(b *testing.B) {
 	builder.PutString("Phone", "555-555-5555")
 	builder.PutUint8("Siblings", uint8(0))
 	builder.PutTimestamp("BirthDay", now)
-	// builder.PutBoolean("Spouse", false)
+	builder.PutBool("Spouse", false)
 	builder.PutFloat32("Money", 999.99)
 	a, _ := builder.Build()
 
@@ -181,3 +247,142 @@ func BenchmarkDecode(b *testing.B) {
 		out.Reset()
 	}
 }
+
+func BenchmarkDecodeBytes(b *testing.B) {
+	// Benchmark type
+	AType := New("testing", "A")
+
+	// Version 1
+	AType.AddVersion(
+		Field{Name: "Name", Required: true, Type: StringField},
+		Field{Name: "BirthDay", Required: true, Type: TimestampField},
+		Field{Name: "Phone", Required: true, Type: StringField},
+		Field{Name: "Siblings", Required: true, Type: Uint8Field},
+		Field{Name: "Spouse", Required: true, Type: BooleanField},
+		Field{Name: "Money", Required: true, Type: Float32Field},
+	)
+
+	// Create registry
+	reg := NewRegistry()
+	reg.Register(AType)
+
+	var buf []byte
+	out := bytes.NewBuffer(buf)
+	encoder := NewEncoder(out)
+
+	// create builder
+	buffer := make([]byte, 1024)
+	builder := NewBuilder(AType, buffer)
+
+	now := time.Now()
+	builder.PutString("Name", "Bugs Bunny")
+	builder.PutString("Phone", "555-555-5555")
+	builder.PutUint8("Siblings", uint8(0))
+	builder.PutTimestamp("BirthDay", now)
+	builder.PutBool("Spouse", false)
+	builder.PutFloat32("Money", 999.99)
+	a, _ := builder.Build()
+
+	encoder.Encode(a)
+	data := out.Bytes()
+	b.SetBytes(int64(len(data)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DecodeBytes(&reg, data)
+	}
+}
+
+// benchmarkIntegers are typical counters and identifiers. Most values are small, a few use the full width of the field.
+var benchmarkIntegers = []int64{0, 1, -1, 42, -42, 300, 65535, -70000, 1 << 20, 1 << 31, -(1 << 40), 1 << 62}
+
+// createBenchmarkIntegerType creates a tuple type with a field of the given type for each benchmark integer as well as an array field.
+func createBenchmarkIntegerType(fieldType, arrayType FieldType) (TupleType, []string) {
+	Numbers := New("testing", "numbers")
+	names := make([]string, len(benchmarkIntegers))
+	fields := make([]Field, len(benchmarkIntegers))
+	for i := range fields {
+		names[i] = "n" + strconv.Itoa(i)
+		fields[i] = Field{Name: names[i], Type: fieldType}
+	}
+	Numbers.AddVersion(append(fields, Field{Name: "array", Type: arrayType})...)
+	return Numbers, names
+}
+
+// benchmarkPutUint32 writes the benchmark integers which fit into 32 bits and reports the number of bytes written.
+func benchmarkPutUint32(b *testing.B, encoding IntEncoding) {
+	Numbers, names := createBenchmarkIntegerType(Uint32Field, Uint32ArrayField)
+	builder := NewBuilder(Numbers, make([]byte, 1024))
+	builder.SetIntEncoding(encoding)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Reset()
+		for j, value := range benchmarkIntegers {
+			if value >= 0 && value <= 1<<32-1 {
+				builder.PutUint32(names[j], uint32(value))
+			}
+		}
+	}
+	b.ReportMetric(float64(builder.pos), "bytes")
+}
+
+// benchmarkPutInt64 writes the benchmark integers and reports the number of bytes written.
+func benchmarkPutInt64(b *testing.B, encoding IntEncoding) {
+	Numbers, names := createBenchmarkIntegerType(Int64Field, Int64ArrayField)
+	builder := NewBuilder(Numbers, make([]byte, 1024))
+	builder.SetIntEncoding(encoding)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Reset()
+		for j, value := range benchmarkIntegers {
+			builder.PutInt64(names[j], value)
+		}
+	}
+	b.ReportMetric(float64(builder.pos), "bytes")
+}
+
+// benchmarkPutInt64Array writes the benchmark integers as an array and reports the number of bytes written.
+func benchmarkPutInt64Array(b *testing.B, encoding IntEncoding) {
+	Numbers, _ := createBenchmarkIntegerType(Int64Field, Int64ArrayField)
+	builder := NewBuilder(Numbers, make([]byte, 1024))
+	builder.SetIntEncoding(encoding)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Reset()
+		builder.PutInt64Array("array", benchmarkIntegers)
+	}
+	b.ReportMetric(float64(builder.pos), "bytes")
+}
+
+// benchmarkGetInt64 reads the benchmark integers from a tuple written with the given encoding.
+func benchmarkGetInt64(b *testing.B, encoding IntEncoding) {
+	Numbers, names := createBenchmarkIntegerType(Int64Field, Int64ArrayField)
+	builder := NewBuilder(Numbers, make([]byte, 1024))
+	builder.SetIntEncoding(encoding)
+	for j, value := range benchmarkIntegers {
+		builder.PutInt64(names[j], value)
+	}
+	numbers, err := builder.Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			numbers.GetInt64(name)
+		}
+	}
+}
+
+func BenchmarkPutUint32Fixed(b *testing.B)      { benchmarkPutUint32(b, FixedIntEncoding) }
+func BenchmarkPutUint32VarInt(b *testing.B)     { benchmarkPutUint32(b, VarIntEncoding) }
+func BenchmarkPutInt64Fixed(b *testing.B)       { benchmarkPutInt64(b, FixedIntEncoding) }
+func BenchmarkPutInt64VarInt(b *testing.B)      { benchmarkPutInt64(b, VarIntEncoding) }
+func BenchmarkPutInt64ArrayFixed(b *testing.B)  { benchmarkPutInt64Array(b, FixedIntEncoding) }
+func BenchmarkPutInt64ArrayVarInt(b *testing.B) { benchmarkPutInt64Array(b, VarIntEncoding) }
+func BenchmarkGetInt64Fixed(b *testing.B)       { benchmarkGetInt64(b, FixedIntEncoding) }
+func BenchmarkGetInt64VarInt(b *testing.B)      { benchmarkGetInt64(b, VarIntEncoding) }
@@ -0,0 +1,181 @@
+package namedtuple
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// ErrUnexpectedBlock is returned when a compressed block is read where a single tuple is expected. Blocks are only decompressed by a `Decoder`.
+var ErrUnexpectedBlock = errors.New("Compressed blocks can only be read with a Decoder")
+
+// DefaultBlockTuples is the default number of tuples in a compressed block.
+const DefaultBlockTuples = 128
+
+// MaxBlockSize is the maximum uncompressed size of a compressed block. The block encoder writes a block before it would exceed the size, and decoders reject larger blocks before allocating them.
+const MaxBlockSize = 64 << 20
+
+// minFrameSize is the size of the smallest framed tuple in a block, the protocol header and a single length byte.
+const minFrameSize = 2
+
+// BlockEncoder encodes tuples into compressed blocks. The tuples are buffered until the block is full, so `Flush` must be called after the last tuple.
+type BlockEncoder interface {
+	Encoder
+
+	// Flush compresses and writes the buffered tuples as a block, even if the block is not full.
+	Flush() error
+}
+
+// NewBlockEncoder creates an encoder which batches the given number of tuples into a block compressed with the compressor. Each block is written as a `ProtocolVersionThree` frame, which contains the compressor code, the number of tuples, the uncompressed length, the compressed tuples and a CRC32C checksum. The tuples in the block are framed with the given protocol version, so version 2 keeps the fingerprint of each tuple. Decoders decompress blocks transparently, but the compressed block must not exceed the maximum size of the decoder. Blocks are written early if the uncompressed tuples would exceed `MaxBlockSize`. If the number of tuples is not greater than 0, `DefaultBlockTuples` is used. `ErrInvalidProtocolVersion` is returned for protocol versions other than 1 and 2.
+func NewBlockEncoder(w io.Writer, version uint8, c Compressor, tuples int) (BlockEncoder, error) {
+	block := bytes.NewBuffer(make([]byte, 0, 4096))
+	inner, err := NewEncoderVersion(block, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if tuples <= 0 {
+		tuples = DefaultBlockTuples
+	}
+	frame := encoder{w, make([]byte, 9), bytes.NewBuffer(make([]byte, 0, 4096)), ProtocolVersionThree, false, 0}
+	return &blockEncoder{frame: frame, encoder: inner, block: block, compressor: c, tuples: tuples}, nil
+}
+
+type blockEncoder struct {
+	frame      encoder
+	encoder    Encoder
+	block      *bytes.Buffer
+	compressed []byte
+	compressor Compressor
+	tuples     int
+	count      int
+}
+
+func (e *blockEncoder) Encode(t Tuple) error {
+	previous := e.block.Len()
+	if err := e.encoder.Encode(t); err != nil {
+		return err
+	}
+
+	// write the buffered tuples first if the block would exceed the maximum size
+	if e.block.Len() > MaxBlockSize {
+		e.block.Truncate(previous)
+		if e.count == 0 {
+			return ErrTupleExceedsMaxSize
+		} else if err := e.Flush(); err != nil {
+			return err
+		}
+		return e.Encode(t)
+	}
+
+	e.count++
+	if e.count < e.tuples {
+		return nil
+	}
+	return e.Flush()
+}
+
+func (e *blockEncoder) Flush() error {
+	if e.count == 0 {
+		return nil
+	}
+	defer e.reset()
+
+	compressed, err := e.compressor.Compress(e.compressed[:0], e.block.Bytes())
+	if err != nil {
+		return err
+	}
+	e.compressed = compressed
+
+	// Write compressor code, tuple count and uncompressed length
+	var header [BlockHeaderSize]byte
+	header[0] = e.compressor.Code()
+	binary.LittleEndian.PutUint32(header[1:], uint32(e.count))
+	binary.LittleEndian.PutUint64(header[5:], uint64(e.block.Len()))
+	e.frame.buffer.Write(header[:])
+	e.frame.buffer.Write(compressed)
+
+	// Write checksum of the block header and the compressed tuples
+	var checksum [ChecksumSize]byte
+	binary.LittleEndian.PutUint32(checksum[:], crc32.Checksum(e.frame.buffer.Bytes(), castagnoli))
+	e.frame.buffer.Write(checksum[:])
+
+	if err := e.frame.writeProtocolHeader(e.frame.buffer.Len()); err != nil {
+		return err
+	}
+	_, err = e.frame.w.Write(e.frame.buffer.Bytes())
+	return err
+}
+
+// reset discards the buffered tuples after a block was written
+func (e *blockEncoder) reset() {
+	e.frame.buffer.Reset()
+	e.block.Reset()
+	e.count = 0
+}
+
+// decodeBlock verifies and decompresses the content of a compressed block. The framed tuples of the block are returned in a new slice. The uncompressed length may not exceed `MaxBlockSize` or the maximum size for each tuple in the block, and each tuple needs at least `minFrameSize` bytes. After decompression, the block must contain the number of frames in the block header and each frame is checked against the maximum size.
+func decodeBlock(content []byte, maxSize uint64) ([]byte, error) {
+
+	// The content needs to include the block header and the checksum
+	if len(content) < BlockHeaderSize+ChecksumSize {
+		return nil, ErrTupleLengthTooSmall
+	}
+
+	// Verify checksum
+	data := content[:len(content)-ChecksumSize]
+	if crc32.Checksum(data, castagnoli) != binary.LittleEndian.Uint32(content[len(data):]) {
+		return nil, ErrChecksumMismatch
+	}
+
+	compressor, exists := lookupCompressor(data[0])
+	if !exists {
+		return nil, ErrUnknownCompressor
+	}
+
+	// Verify uncompressed length before allocating it
+	count := uint64(binary.LittleEndian.Uint32(data[1:]))
+	length := binary.LittleEndian.Uint64(data[5:])
+	if count == 0 || length < count*minFrameSize {
+		return nil, ErrInvalidBlock
+	} else if length > MaxBlockSize || length/count > maxSize {
+		return nil, ErrTupleExceedsMaxSize
+	}
+
+	tuples := make([]byte, length)
+	if err := compressor.Decompress(tuples, data[BlockHeaderSize:]); err != nil {
+		return nil, err
+	} else if err := checkFrames(tuples, count, maxSize); err != nil {
+		return nil, err
+	}
+	return tuples, nil
+}
+
+// checkFrames verifies that the tuples of a block are exactly the given number of complete frames and that no frame exceeds the maximum size.
+func checkFrames(tuples []byte, count, maxSize uint64) error {
+	var frames uint64
+	for pos := 0; pos < len(tuples); frames++ {
+		byteCount, _ := ParseProtocolHeader(tuples[pos])
+		start := pos + 1 + int(byteCount)
+		if frames == count || start > len(tuples) {
+			return ErrInvalidBlock
+		}
+
+		length, err := parseLength(byteCount, tuples[pos+1:start])
+		if err != nil {
+			return ErrInvalidBlock
+		} else if length > maxSize {
+			return ErrTupleExceedsMaxSize
+		} else if length > uint64(len(tuples)-start) {
+			return ErrInvalidBlock
+		}
+		pos = start + int(length)
+	}
+
+	if frames != count {
+		return ErrInvalidBlock
+	}
+	return nil
+}
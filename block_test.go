@@ -0,0 +1,263 @@
+package namedtuple
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encodeTestBlocks encodes a location tuple for each longitude into compressed blocks
+func encodeTestBlocks(t *testing.T, c Compressor, tuples int, lons ...float32) []byte {
+	var out bytes.Buffer
+	encoder, err := NewBlockEncoder(&out, ProtocolVersionTwo, c, tuples)
+	assert.Nil(t, err)
+
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	for _, lon := range lons {
+		builder.PutFloat32("lon", lon)
+		builder.PutFloat32("lat", 50.5)
+		loc, err := builder.Build()
+		assert.Nil(t, err)
+		assert.Nil(t, encoder.Encode(loc))
+	}
+	assert.Nil(t, encoder.Flush())
+	return out.Bytes()
+}
+
+func TestBlockEncoder(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	lons := make([]float32, 100)
+	for i := range lons {
+		lons[i] = float32(i)
+	}
+	flateCompressor, _ := NewFlateCompressor(flate.DefaultCompression)
+	gzipCompressor, _ := NewGzipCompressor(flate.DefaultCompression)
+
+	uncompressed := encodeTestLocations(t, ProtocolVersionTwo, lons...)
+	for _, c := range []Compressor{flateCompressor, gzipCompressor} {
+		b := encodeTestBlocks(t, c, 32, lons...)
+		assert.True(t, len(b) < len(uncompressed)/2)
+
+		// 3 full blocks and 1 flushed block
+		_, version := ParseProtocolHeader(b[0])
+		assert.Equal(t, uint8(ProtocolVersionThree), version)
+
+		decoder := NewDecoder(reg, bytes.NewReader(b))
+		var tuples []Tuple
+		for {
+			loc, err := decoder.Decode()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			tuples = append(tuples, loc)
+		}
+		assert.Equal(t, len(lons), len(tuples))
+
+		// tuples of earlier blocks are not modified
+		for i, loc := range tuples {
+			lon, err := loc.GetFloat32("lon")
+			assert.Nil(t, err)
+			assert.Equal(t, lons[i], lon)
+		}
+	}
+
+	// blocks and single tuples can be mixed in a stream
+	b := append(encodeTestLocations(t, ProtocolVersionOne, 1), encodeTestBlocks(t, flateCompressor, 0, 2, 3)...)
+	decoder := NewDecoder(reg, bytes.NewReader(b))
+	for _, expected := range []float32{1, 2, 3} {
+		loc, err := decoder.Decode()
+		assert.Nil(t, err)
+		lon, _ := loc.GetFloat32("lon")
+		assert.Equal(t, expected, lon)
+	}
+
+	// flushing an empty block does not write anything
+	var out bytes.Buffer
+	encoder, err := NewBlockEncoder(&out, ProtocolVersionOne, flateCompressor, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Flush())
+	assert.Equal(t, 0, out.Len())
+
+	_, err = NewBlockEncoder(&out, ProtocolVersionThree, flateCompressor, 0)
+	assert.Equal(t, ErrInvalidProtocolVersion, err)
+}
+
+func TestBlockEncoderCustomCompressor(t *testing.T) {
+	defer func() {
+		compressorsMutex.Lock()
+		delete(compressors, identityCompressor{}.Code())
+		compressorsMutex.Unlock()
+	}()
+
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+	b := encodeTestBlocks(t, identityCompressor{}, 0, 1)
+
+	// the compressor is not registered
+	_, err := NewDecoder(reg, bytes.NewReader(b)).Decode()
+	assert.Equal(t, ErrUnknownCompressor, err)
+
+	RegisterCompressor(identityCompressor{})
+	loc, err := NewDecoder(reg, bytes.NewReader(b)).Decode()
+	assert.Nil(t, err)
+	lon, _ := loc.GetFloat32("lon")
+	assert.Equal(t, float32(1), lon)
+}
+
+func TestDecodeBlockFail(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	flateCompressor, _ := NewFlateCompressor(flate.DefaultCompression)
+	b := encodeTestBlocks(t, flateCompressor, 0, 1, 2, 3)
+	content := b[2:]
+
+	frames, err := decodeBlock(content, DefaultMaxSize)
+	assert.Nil(t, err)
+	assert.Equal(t, encodeTestLocations(t, ProtocolVersionTwo, 1, 2, 3), frames)
+
+	// checksum mismatch
+	corrupt := append([]byte{}, content...)
+	corrupt[BlockHeaderSize]++
+	_, err = decodeBlock(corrupt, DefaultMaxSize)
+	assert.Equal(t, ErrChecksumMismatch, err)
+
+	// the uncompressed tuples are larger than the maximum size
+	_, err = decodeBlock(content, 8)
+	assert.Equal(t, ErrTupleExceedsMaxSize, err)
+
+	_, err = decodeBlock(content[:BlockHeaderSize], DefaultMaxSize)
+	assert.Equal(t, ErrTupleLengthTooSmall, err)
+
+	// the compressed block must not exceed the maximum size of the decoder
+	_, err = NewDecoderSize(reg, uint64(len(content)-1), bytes.NewReader(b)).Decode()
+	assert.Equal(t, ErrTupleExceedsMaxSize, err)
+
+	// blocks can not be read without a decoder
+	reader := NewTupleReader(&reg, bytes.NewReader(b), uint64(len(b)))
+	_, err = reader.Next()
+	assert.Equal(t, ErrUnexpectedBlock, err)
+}
+
+// encodeTestBlockHeader frames a block header with the given tuple count and uncompressed length, but without compressed data
+func encodeTestBlockHeader(count uint32, length uint64) []byte {
+	content := make([]byte, BlockHeaderSize+ChecksumSize)
+	content[0] = FlateCompression
+	binary.LittleEndian.PutUint32(content[1:], count)
+	binary.LittleEndian.PutUint64(content[5:], length)
+	binary.LittleEndian.PutUint32(content[BlockHeaderSize:], crc32.Checksum(content[:BlockHeaderSize], castagnoli))
+	return append([]byte{ProtocolVersionThree, byte(len(content))}, content...)
+}
+
+func TestDecodeBlockSize(t *testing.T) {
+
+	// huge blocks are rejected before they are allocated
+	b := encodeTestBlockHeader(math.MaxUint32, math.MaxUint32*4096)
+	assert.Equal(t, 19, len(b))
+	_, err := NewDecoder(NewRegistry(), bytes.NewReader(b)).Decode()
+	assert.Equal(t, ErrTupleExceedsMaxSize, err)
+
+	_, err = decodeBlock(encodeTestBlockHeader(1, MaxBlockSize+1)[2:], math.MaxUint64)
+	assert.Equal(t, ErrTupleExceedsMaxSize, err)
+
+	// each tuple needs at least a protocol header and a length
+	_, err = decodeBlock(encodeTestBlockHeader(math.MaxUint32, 4096)[2:], DefaultMaxSize)
+	assert.Equal(t, ErrInvalidBlock, err)
+	_, err = decodeBlock(encodeTestBlockHeader(2, 3)[2:], DefaultMaxSize)
+	assert.Equal(t, ErrInvalidBlock, err)
+}
+
+// encodeTestBlock compresses the framed tuples into a block with the given tuple count
+func encodeTestBlock(t *testing.T, count uint32, tuples []byte) []byte {
+	c, _ := NewFlateCompressor(flate.DefaultCompression)
+	content := make([]byte, BlockHeaderSize)
+	content[0] = c.Code()
+	binary.LittleEndian.PutUint32(content[1:], count)
+	binary.LittleEndian.PutUint64(content[5:], uint64(len(tuples)))
+
+	content, err := c.Compress(content, tuples)
+	assert.Nil(t, err)
+	var checksum [ChecksumSize]byte
+	binary.LittleEndian.PutUint32(checksum[:], crc32.Checksum(content, castagnoli))
+	return append(content, checksum[:]...)
+}
+
+func TestDecodeBlockFrames(t *testing.T) {
+	tuples := encodeTestLocations(t, ProtocolVersionTwo, 1, 2, 3)
+	frames, err := decodeBlock(encodeTestBlock(t, 3, tuples), DefaultMaxSize)
+	assert.Nil(t, err)
+	assert.Equal(t, tuples, frames)
+
+	// the number of frames does not match the block header
+	_, err = decodeBlock(encodeTestBlock(t, 2, tuples), DefaultMaxSize)
+	assert.Equal(t, ErrInvalidBlock, err)
+	_, err = decodeBlock(encodeTestBlock(t, 4, tuples), DefaultMaxSize)
+	assert.Equal(t, ErrInvalidBlock, err)
+
+	// the last frame is incomplete
+	_, err = decodeBlock(encodeTestBlock(t, 3, tuples[:len(tuples)-1]), DefaultMaxSize)
+	assert.Equal(t, ErrInvalidBlock, err)
+
+	// a single frame exceeds the maximum size, although the average does not
+	large := append([]byte{ProtocolVersionOne | 1<<6, 200, 0}, make([]byte, 200)...)
+	small := []byte{ProtocolVersionOne, 0}
+	block := append(append(append([]byte{}, large...), small...), small...)
+	_, err = decodeBlock(encodeTestBlock(t, 3, block), 100)
+	assert.Equal(t, ErrTupleExceedsMaxSize, err)
+}
+
+func TestBlockEncoderMaxBlockSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("encodes more than MaxBlockSize bytes")
+	}
+
+	Data := New("testing", "data")
+	Data.AddVersion(Field{Name: "data", Required: true, Type: Uint8ArrayField})
+	reg := NewRegistry()
+	reg.Register(Data)
+
+	var out bytes.Buffer
+	flateCompressor, _ := NewFlateCompressor(flate.BestSpeed)
+	encoder, err := NewBlockEncoder(&out, ProtocolVersionOne, flateCompressor, 0)
+	assert.Nil(t, err)
+
+	// the blocks are written before they exceed the maximum size
+	data := make([]byte, MaxBlockSize/3)
+	builder := NewGrowableBuilder(Data, nil, 0)
+	for i := 0; i < 4; i++ {
+		data[0] = byte(i)
+		builder.PutUint8Array("data", data)
+		tuple, err := builder.Build()
+		assert.Nil(t, err)
+		assert.Nil(t, encoder.Encode(tuple))
+	}
+	assert.Nil(t, encoder.Flush())
+
+	decoder := NewDecoderSize(reg, MaxBlockSize, &out)
+	for i := 0; i < 4; i++ {
+		tuple, err := decoder.Decode()
+		assert.Nil(t, err)
+		decoded, _ := tuple.GetUint8Array("data")
+		assert.Equal(t, len(data), len(decoded))
+		assert.Equal(t, byte(i), decoded[0])
+	}
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+
+	// tuples larger than a block
+	builder.PutUint8Array("data", make([]byte, MaxBlockSize))
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, ErrTupleExceedsMaxSize, encoder.Encode(tuple))
+	assert.Nil(t, encoder.Flush())
+}
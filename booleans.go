@@ -0,0 +1,157 @@
+package namedtuple
+
+import (
+	"math"
+
+	"github.com/blacklabeldata/xbinary"
+)
+
+// PutBool writes a boolean value for the given field. The field type must be a `BooleanField`, otherwise an error is returned. The value is stored in the type code, either `TrueCode` or `FalseCode`, so only a single byte is written. If the buffer is full, an `xbinary.ErrOutOfRange` error is returned.
+func (b *TupleBuilder) PutBool(field string, value bool) (wrote uint64, err error) {
+
+	// field type should be
+	if err = b.typeCheck(field, BooleanField); err != nil {
+		return 0, err
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(1); err != nil {
+		return 0, err
+	}
+
+	// length check
+	if b.available() < 1 {
+		return 0, xbinary.ErrOutOfRange
+	}
+
+	// write type code
+	if value {
+		b.buffer[b.pos] = byte(TrueCode.OpCode)
+	} else {
+		b.buffer[b.pos] = byte(FalseCode.OpCode)
+	}
+
+	// set field offset
+	b.setOffset(field)
+
+	// incr pos
+	b.pos++
+
+	return 1, nil
+}
+
+// PutBoolArray writes an array of booleans for the given field. The field type must be a `BooleanArrayField`, otherwise an error is returned. The type code is written first, then the number of booleans and finally the values packed into bits, 8 values per byte with the first value in the least significant bit. The number of bytes used for the length depends on the number of values. If the buffer is not large enough, an `xbinary.ErrOutOfRange` error is returned. Upon success, the number of bytes written is returned along with a nil error.
+func (b *TupleBuilder) PutBoolArray(field string, value []bool) (wrote int, err error) {
+
+	// field type should be
+	if err = b.typeCheck(field, BooleanArrayField); err != nil {
+		return 0, err
+	}
+
+	// write type code and length
+	size := (len(value) + 7) / 8
+	wrote, err = b.putArrayHeader(BooleanArray8Code, len(value), size)
+	if err != nil {
+		return 0, err
+	}
+
+	// pack values
+	data := b.buffer[b.pos+wrote : b.pos+wrote+size]
+	for i := range data {
+		data[i] = 0
+	}
+	for i, v := range value {
+		if v {
+			data[i/8] |= 1 << uint(i%8)
+		}
+	}
+	wrote += size
+
+	b.setOffset(field)
+	b.pos += wrote
+	return
+}
+
+// putArrayHeader writes the type code and the length for an array at the current position. The type codes for the 8, 16, 32 and 64-bit lengths must be sequential, starting with the given type code. The size is the number of bytes needed for the array elements and is used to verify that the buffer is large enough for the entire array. The number of bytes written for the header is returned.
+func (b *TupleBuilder) putArrayHeader(first TypeCode, length int, size int) (wrote int, err error) {
+	opcode := first.OpCode
+	if length < math.MaxUint8 {
+		wrote = 2
+	} else if length < math.MaxUint16 {
+		opcode++
+		wrote = 3
+	} else if length < math.MaxUint32 {
+		opcode += 2
+		wrote = 5
+	} else {
+		opcode += 3
+		wrote = 9
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(wrote + size); err != nil {
+		return 0, err
+	}
+
+	// length check
+	if b.available() < wrote+size {
+		return 0, xbinary.ErrOutOfRange
+	}
+
+	// write type code
+	b.buffer[b.pos] = byte(opcode)
+
+	// write length
+	switch wrote {
+	case 2:
+		b.buffer[b.pos+1] = byte(length)
+	case 3:
+		xbinary.LittleEndian.PutUint16(b.buffer, b.pos+1, uint16(length))
+	case 5:
+		xbinary.LittleEndian.PutUint32(b.buffer, b.pos+1, uint32(length))
+	default:
+		xbinary.LittleEndian.PutUint64(b.buffer, b.pos+1, uint64(length))
+	}
+	return
+}
+
+// GetBool returns the boolean value for the given field. The field type must be a `BooleanField`, otherwise an error will be returned. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetBool(field string) (bool, error) {
+	pos, err := t.fieldOffset(field, BooleanField)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(bool); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// the value is stored in the type code
+	switch t.data[pos] {
+	case TrueCode.OpCode:
+		return true, nil
+	case FalseCode.OpCode:
+		return false, nil
+	}
+	return false, ErrInvalidTypeCode
+}
+
+// GetBoolArray returns the array of booleans for the given field. The field type must be a `BooleanArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetBoolArray(field string) ([]bool, error) {
+	pos, length, err := t.readArrayLength(field, BooleanArrayField, BooleanArray8Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// verify the tuple data contains all the bits
+	if length > uint64(len(t.data)-pos)*8 {
+		return nil, xbinary.ErrOutOfRange
+	}
+
+	value := make([]bool, length)
+	for i := range value {
+		value[i] = t.data[pos+i/8]&(1<<uint(i%8)) != 0
+	}
+	return value, nil
+}
@@ -0,0 +1,175 @@
+package namedtuple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createBooleanTestType() TupleType {
+	TestType := New("testing", "bool")
+	TestType.AddVersion(
+		Field{Name: "true", Required: true, Type: BooleanField},
+		Field{Name: "false", Required: true, Type: BooleanField},
+		Field{Name: "array", Required: true, Type: BooleanArrayField},
+		Field{Name: "string", Required: false, Type: StringField},
+	)
+	return TestType
+}
+
+func TestPutBoolFail(t *testing.T) {
+	TestType := createBooleanTestType()
+
+	// create builder
+	buffer := make([]byte, 0)
+	builder := NewBuilder(TestType, buffer)
+
+	// fails type check
+	wrote, err := builder.PutBool("string", true)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(0), wrote)
+
+	// fails length check
+	wrote, err = builder.PutBool("true", true)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(0), wrote)
+}
+
+func TestPutBoolPass(t *testing.T) {
+	TestType := createBooleanTestType()
+
+	// create builder
+	buffer := make([]byte, 2)
+	builder := NewBuilder(TestType, buffer)
+
+	// successful writes
+	wrote, err := builder.PutBool("true", true)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), wrote)
+
+	wrote, err = builder.PutBool("false", false)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), wrote)
+
+	// test data validity
+	assert.Equal(t, TrueCode.OpCode, buffer[0])
+	assert.Equal(t, FalseCode.OpCode, buffer[1])
+
+	// validate field offsets
+	assert.Equal(t, 0, builder.offset("true"))
+	assert.Equal(t, 1, builder.offset("false"))
+}
+
+func TestPutBoolArrayFail(t *testing.T) {
+	TestType := createBooleanTestType()
+
+	// create builder
+	buffer := make([]byte, 3)
+	builder := NewBuilder(TestType, buffer)
+
+	// fails type check
+	wrote, err := builder.PutBoolArray("true", []bool{true})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, wrote)
+
+	// fails length check
+	wrote, err = builder.PutBoolArray("array", make([]bool, 9))
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, wrote)
+}
+
+func TestPutBoolArrayPass(t *testing.T) {
+	TestType := createBooleanTestType()
+
+	// create builder
+	buffer := make([]byte, 4)
+	builder := NewBuilder(TestType, buffer)
+
+	// successful write
+	wrote, err := builder.PutBoolArray("array", []bool{true, false, true, false, false, false, false, false, true})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, wrote)
+
+	// test data validity
+	assert.Equal(t, BooleanArray8Code.OpCode, buffer[0])
+	assert.Equal(t, uint8(9), buffer[1])
+	assert.Equal(t, uint8(5), buffer[2])
+	assert.Equal(t, uint8(1), buffer[3])
+
+	// validate field offset
+	assert.Equal(t, 0, builder.offset("array"))
+}
+
+func TestPutBoolArrayPass_16(t *testing.T) {
+	TestType := createBooleanTestType()
+
+	// create builder
+	buffer := make([]byte, 3+38)
+	builder := NewBuilder(TestType, buffer)
+
+	// successful write
+	value := make([]bool, 300)
+	value[299] = true
+	wrote, err := builder.PutBoolArray("array", value)
+	assert.Nil(t, err)
+	assert.Equal(t, 41, wrote)
+
+	// test data validity
+	assert.Equal(t, BooleanArray16Code.OpCode, buffer[0])
+	assert.Equal(t, uint8(44), buffer[1])
+	assert.Equal(t, uint8(1), buffer[2])
+	assert.Equal(t, uint8(8), buffer[40])
+}
+
+func TestTupleGetBool(t *testing.T) {
+	TestType := createBooleanTestType()
+
+	// create builder
+	buffer := make([]byte, 1024)
+	builder := NewBuilder(TestType, buffer)
+	builder.PutBool("true", true)
+	builder.PutBool("false", false)
+	builder.PutBoolArray("array", []bool{true, false, true, true, false, false, true, false, true, true})
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	value, err := tuple.GetBool("true")
+	assert.Nil(t, err)
+	assert.True(t, value)
+
+	value, err = tuple.GetBool("false")
+	assert.Nil(t, err)
+	assert.False(t, value)
+
+	array, err := tuple.GetBoolArray("array")
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{true, false, true, true, false, false, true, false, true, true}, array)
+
+	// incorrect field type
+	_, err = tuple.GetBool("array")
+	assert.Equal(t, ErrIncorrectFieldType, err)
+	_, err = tuple.GetBoolArray("true")
+	assert.Equal(t, ErrIncorrectFieldType, err)
+
+	// field not present
+	_, err = tuple.GetString("string")
+	assert.Equal(t, ErrFieldNotPresent, err)
+}
+
+func TestTupleGetBoolArrayOutOfRange(t *testing.T) {
+	TestType := createBooleanTestType()
+
+	// create builder
+	buffer := make([]byte, 1024)
+	builder := NewBuilder(TestType, buffer)
+	builder.PutBool("true", true)
+	builder.PutBool("false", false)
+	builder.PutBoolArray("array", make([]bool, 16))
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	// truncate the packed values
+	tuple.data = tuple.data[:len(tuple.data)-1]
+	_, err = tuple.GetBoolArray("array")
+	assert.NotNil(t, err)
+}
@@ -6,41 +6,54 @@ import (
 )
 
 type TupleBuilder struct {
-	fields    map[string]Field
-	offsets   map[string]int
+	offsets   []int
 	tupleType TupleType
 	buffer    []byte
 	pos       int
+	encoding  IntEncoding
+	growable  bool
+	limit     int
 }
 
-func NewBuilder(t TupleType, buffer []byte) TupleBuilder {
-
-	// init instance variables
-	fields := make(map[string]Field)
-	offsets := make(map[string]int)
+// notWritten is the offset of fields which have not been written by the builder
+const notWritten = -1
 
-	// populate instance fields for builder
-	for _, version := range t.Versions() {
-		for _, field := range version.Fields {
-			fields[field.Name] = field
-			offsets[field.Name] = 0
-		}
-	}
+func NewBuilder(t TupleType, buffer []byte) TupleBuilder {
 
-	// create new builder
-	return TupleBuilder{fields: fields, offsets: offsets, tupleType: t, buffer: buffer, pos: 0}
+	// fields are looked up in the field table of the type
+	// offsets are only populated as fields are written
+	b := TupleBuilder{offsets: make([]int, len(t.table)), tupleType: t, buffer: buffer, pos: 0}
+	b.Reset()
+	return b
 }
 
 func (b TupleBuilder) available() int {
 	return len(b.buffer) - b.pos
 }
 
-func (b TupleBuilder) reset() {
+// Reset discards the fields written by the builder so it can be used for a new tuple of the same type. The buffer is kept and will be overwritten, so tuples built before the reset must no longer be used. `Build` resets the builder as well if the tuple was built. The integer encoding and the limit of a growable builder are not changed.
+func (b *TupleBuilder) Reset() {
 	b.pos = 0
+	for i := range b.offsets {
+		b.offsets[i] = notWritten
+	}
+}
+
+// offset returns the position of the field in the buffer or `notWritten` if the field has not been written.
+func (b *TupleBuilder) offset(field string) int {
+	if index, exists := b.tupleType.fields[field]; exists {
+		return b.offsets[index]
+	}
+	return notWritten
+}
+
+// setOffset records the current position as the offset of the field.
+func (b *TupleBuilder) setOffset(field string) {
+	b.offsets[b.tupleType.fields[field]] = b.pos
 }
 
 func (t *TupleBuilder) typeCheck(fieldName string, fieldType FieldType) error {
-	field, exists := t.fields[fieldName]
+	field, _, exists := t.tupleType.field(fieldName)
 	if !exists {
 		return errors.New("Field does not exist: " + fieldName)
 	}
@@ -49,16 +62,28 @@ func (t *TupleBuilder) typeCheck(fieldName string, fieldType FieldType) error {
 		return errors.New("Incorrect field type: " + fieldName)
 	}
 
+	// only one field of a oneof group can be written
+	if field.OneOf != "" {
+		return t.checkOneOf(field)
+	}
+
 	return nil
 }
 
+// Build creates the tuple from the written fields and resets the builder. If a required field is missing or a constraint is violated, the error is returned and the builder keeps the fields, so the missing fields can be added and `Build` can be called again.
 func (b *TupleBuilder) Build() (Tuple, error) {
-	defer b.reset()
 	header, err := b.newTupleHeader()
 	if err != nil {
 		return NIL, err
 	}
-	return Tuple{data: b.buffer[:b.pos], Header: header}, nil
+	tuple := Tuple{data: b.buffer[:b.pos], Header: header}
+
+	// check field constraints
+	if err := tuple.validate(); err != nil {
+		return NIL, err
+	}
+	b.Reset()
+	return tuple, nil
 }
 
 func (b *TupleBuilder) newTupleHeader() (TupleHeader, error) {
@@ -69,62 +94,58 @@ func (b *TupleBuilder) newTupleHeader() (TupleHeader, error) {
 	var fieldSize uint8
 	var fieldCount int
 
-	totalFieldCount := uint32(len(b.tupleType.fields))
+	totalFieldCount := uint32(len(b.tupleType.table))
 	offsets := make([]uint64, totalFieldCount)
 
 	// iterate over all the versions
-	for _, version := range b.tupleType.Versions() {
-	OUTER:
+OUTER:
+	for _, version := range b.tupleType.versions {
+
+		// if a required field in this version has not been written,
+		// exit the loop and save the missing field name
+		for i, field := range version {
+			if field.Required && b.offsets[fieldCount+i] == notWritten {
+				missingField = field.Name
+				break OUTER
+			}
+		}
 
 		// iterate over all the fields for the current version
-		for _, field := range version.Fields {
+		for range version {
 
 			// get offset for field
-			offset, exists := b.offsets[field.Name]
-
-			// if the field is required, determine if it has been added to the builder
-			if field.Required {
+			offset := b.offsets[fieldCount]
 
-				// if the field has not been written
-				// exit the loop and save the missing field name
-				if !exists {
-					missingField = field.Name
-					break OUTER
-				}
+			// if the optional fields was not written, encode a maximum offset
+			if offset == notWritten {
 
 				// set byte offset of field in tuple data
-				offsets[fieldCount] = uint64(offset)
+				offsets[fieldCount] = uint64(math.MaxUint64)
 			} else {
 
-				// if the optional fields was not written, encode a maximum offset
-				if !exists {
-
-					// set byte offset of field in tuple data
-					offsets[fieldCount] = uint64(math.MaxUint64)
-
-				} else {
-					// if the optional field does exist
-					// set byte offset of field in tuple data
-					offsets[fieldCount] = uint64(offset)
-				}
+				// set byte offset of field in tuple data
+				offsets[fieldCount] = uint64(offset)
 			}
 			fieldCount++
 		}
 
 		// increment the version number after all required fields have been satisfied
+		// the version must fit in the lower bits of the tuple version byte
+		if tupleVersion == TupleVersionMask {
+			return TupleHeader{}, ErrTooManyVersions
+		}
 		tupleVersion++
 	}
 
 	// If the first version is missing a field, return an error
 	// At least one version must contain all the required fields.
 	// The version number will increment for each version which
-	// contains all the required fields.
+	// contains all the required fields. Only the fields of the
+	// satisfied versions are included in the header.
 	if tupleVersion < 1 {
 		return TupleHeader{}, errors.New("Missing required field: " + missingField)
 	}
 
-	// TODO: Add Field level validation
-
 	// Calculate minimum offset for accessing all fields in data
 	// If the total data size is < 256 bytes, all field offsets
 	if b.pos < math.MaxUint8-1 {
@@ -142,10 +163,10 @@ func (b *TupleBuilder) newTupleHeader() (TupleHeader, error) {
 		TupleVersion:    tupleVersion,
 		NamespaceHash:   b.tupleType.NamespaceHash,
 		Hash:            b.tupleType.Hash,
-		FieldCount:      totalFieldCount,
+		FieldCount:      uint32(fieldCount),
 		FieldSize:       fieldSize,
 		ContentLength:   uint64(b.pos),
-		Offsets:         offsets,
+		Offsets:         offsets[:fieldCount],
 		Type:            b.tupleType,
 	}, nil
 }
@@ -1,7 +1,8 @@
 package namedtuple
 
 import (
-	// "fmt"
+	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,11 +55,11 @@ func TestNewBuilder(t *testing.T) {
 	assert.Equal(t, User, builder.tupleType)
 
 	// verify type fields
-	assert.Equal(t, len(User.fields), len(builder.fields))
-	for name := range builder.fields {
+	assert.Equal(t, len(User.fields), len(builder.offsets))
+	for name := range User.fields {
 
-		// make sure the type has the same fields as the builder
-		assert.True(t, User.Contains(name))
+		// make sure none of the fields have been written
+		assert.Equal(t, notWritten, builder.offset(name))
 	}
 }
 
@@ -134,10 +135,10 @@ func TestTupleTypeNew(t *testing.T) {
 func TestTupleTypeAddVersion(t *testing.T) {
 
 	// fields
-	uuid := Field{"uuid", true, StringField}
-	username := Field{"username", true, StringField}
-	age := Field{"age", false, Uint8Field}
-	location := Field{"location", false, TupleField}
+	uuid := Field{Name: "uuid", Required: true, Type: StringField}
+	username := Field{Name: "username", Required: true, Type: StringField}
+	age := Field{Name: "age", Required: false, Type: Uint8Field}
+	location := Field{Name: "location", Required: false, Type: TupleField}
 
 	// create tuple type
 	User := New("testing", "user")
@@ -164,10 +165,10 @@ func TestTupleTypeAddVersion(t *testing.T) {
 func TestTupleTypeFieldOffset(t *testing.T) {
 
 	// fields
-	uuid := Field{"uuid", true, StringField}
-	username := Field{"username", true, StringField}
-	age := Field{"age", false, Uint8Field}
-	location := Field{"location", false, TupleField}
+	uuid := Field{Name: "uuid", Required: true, Type: StringField}
+	username := Field{Name: "username", Required: true, Type: StringField}
+	age := Field{Name: "age", Required: false, Type: Uint8Field}
+	location := Field{Name: "location", Required: false, Type: TupleField}
 
 	// create tuple type
 	User := New("testing", "user")
@@ -199,3 +200,97 @@ func TestTupleTypeFieldOffset(t *testing.T) {
 	assert.Equal(t, 0, offset)
 	assert.Equal(t, false, exists)
 }
+
+func TestBuilderReset(t *testing.T) {
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutString("username", "username")
+	builder.PutUint8("age", 25)
+	user, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, 30, user.Size())
+
+	// Build resets the builder
+	assert.Equal(t, 0, builder.pos)
+	assert.Equal(t, notWritten, builder.offset("age"))
+
+	// fields of the previous tuple are not included
+	builder.PutString("uuid", "fedcba9876543210")
+	builder.PutString("username", "other")
+	user, err = builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, 25, user.Size())
+
+	_, err = user.GetUint8("age")
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	// required fields have to be written again
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.Reset()
+	builder.PutString("username", "username")
+	_, err = builder.Build()
+	assert.NotNil(t, err)
+}
+
+func TestBuilderRetry(t *testing.T) {
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutUint8("age", 25)
+
+	// the fields are kept if a required field is missing
+	_, err := builder.Build()
+	assert.NotNil(t, err)
+	builder.PutString("username", "username")
+	user, err := builder.Build()
+	assert.Nil(t, err)
+	age, err := user.GetUint8("age")
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(25), age)
+
+	// invalid values can be fixed
+	Constrained := createTestConstrainedType()
+	builder = NewBuilder(Constrained, make([]byte, 1024))
+	builder.PutString("username", "username")
+	builder.PutUint8("age", 200)
+	_, err = builder.Build()
+	assert.IsType(t, ValidationError{}, err)
+	builder.PutUint8("age", 20)
+	constrained, err := builder.Build()
+	assert.Nil(t, err)
+	username, err := constrained.GetString("username")
+	assert.Nil(t, err)
+	assert.Equal(t, "username", username)
+}
+
+func TestBuilderMaxVersions(t *testing.T) {
+	for _, versions := range []int{TupleVersionMask, TupleVersionMask + 1} {
+		Versions := New("testing", "versions")
+		for i := 0; i < versions; i++ {
+			Versions.AddVersion(Field{Name: fmt.Sprintf("field%d", i), Required: false, Type: Uint8Field})
+		}
+
+		builder := NewBuilder(Versions, make([]byte, 1024))
+		builder.PutUint8("field0", 1)
+		tuple, err := builder.Build()
+		if versions > TupleVersionMask {
+			assert.Equal(t, ErrTooManyVersions, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, uint8(TupleVersionMask), tuple.Header.TupleVersion)
+
+		// the highest version can be decoded
+		reg := NewRegistry()
+		reg.Register(Versions)
+		var out bytes.Buffer
+		assert.Nil(t, NewEncoder(&out).Encode(tuple))
+		decoded, _, err := DecodeBytes(&reg, out.Bytes())
+		assert.Nil(t, err)
+		assert.Equal(t, uint8(TupleVersionMask), decoded.Header.TupleVersion)
+		value, err := decoded.GetUint8("field0")
+		assert.Nil(t, err)
+		assert.Equal(t, uint8(1), value)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blacklabeldata/namedtuple/schema"
+)
+
+// check compares the old and new schema revisions and prints each breaking change. The number of breaking changes is returned.
+func check(oldPath, newPath string, w io.Writer) (int, error) {
+	oldList, oldPkg, err := load(oldPath)
+	if err != nil {
+		return 0, err
+	}
+	newList, newPkg, err := load(newPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// single files are compared directly so renamed packages are reported
+	var changes []schema.Incompatibility
+	if oldPkg != nil && newPkg != nil {
+		changes = schema.CheckCompatibility(*oldPkg, *newPkg)
+	} else {
+		changes = schema.CheckAllCompatibility(oldList, newList)
+	}
+
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(w, change); err != nil {
+			return 0, err
+		}
+	}
+	return len(changes), nil
+}
+
+// load parses a schema directory or a single schema file. The package is only returned for a single file.
+func load(path string) (schema.PackageList, *schema.Package, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkgList := schema.NewPackageList()
+	parser := schema.NewParser(pkgList)
+	if fi.IsDir() {
+		return pkgList, nil, schema.LoadDirectory(path, parser)
+	}
+
+	pkg, err := schema.LoadFile(path, parser)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkgList, &pkg, nil
+}
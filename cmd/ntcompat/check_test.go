@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const oldSchema = `package users
+
+type User {
+    version 1 {
+        required string uuid
+        optional uint8 age
+    }
+}
+`
+
+const newSchema = `package users
+
+type User {
+    version 1 {
+        required string uuid
+        optional string age
+    }
+}
+`
+
+func writeSchema(t *testing.T, dir, name, text string) string {
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(text), 0644))
+	return path
+}
+
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ntcompat")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	oldDir := filepath.Join(dir, "old")
+	newDir := filepath.Join(dir, "new")
+	oldFile := writeSchema(t, oldDir, "users.ent", oldSchema)
+	newFile := writeSchema(t, newDir, "users.ent", newSchema)
+
+	// directories
+	var out bytes.Buffer
+	count, err := check(oldDir, newDir, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, newFile+":6: field 'age' of type 'User' was changed from 'uint8' to 'string'\n", out.String())
+
+	// files
+	out.Reset()
+	count, err = check(oldFile, oldFile, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, "", out.String())
+
+	// missing file
+	_, err = check(filepath.Join(dir, "missing.ent"), newFile, &out)
+	assert.NotNil(t, err)
+}
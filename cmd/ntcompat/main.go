@@ -0,0 +1,40 @@
+// Command ntcompat reports breaking changes between two revisions of .ent schema files.
+//
+// Usage:
+//
+//	ntcompat -old ./schemas-master -new ./schemas
+//	ntcompat -old users.ent.orig -new users.ent
+//
+// Both revisions are either directories, in which case the packages are
+// matched by name, or single files which are compared even if the package
+// was renamed. Each breaking change is printed with the file and line number
+// of the offending declaration and the exit status is 1 if there are any,
+// so the command can be used in pre-merge checks.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	oldPath := flag.String("old", "", "old schema directory or file")
+	newPath := flag.String("new", "", "new schema directory or file")
+	flag.Parse()
+
+	if *oldPath == "" || *newPath == "" {
+		fmt.Fprintln(os.Stderr, "ntcompat: -old and -new are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	count, err := check(*oldPath, *newPath, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ntcompat:", err)
+		os.Exit(2)
+	} else if count > 0 {
+		fmt.Fprintf(os.Stderr, "ntcompat: %d breaking changes\n", count)
+		os.Exit(1)
+	}
+}
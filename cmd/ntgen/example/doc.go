@@ -0,0 +1,4 @@
+// Package example is generated by ntgen from people.ent. The tests of ntgen verify that the generated code is up to date and that the tuples written by the generated types are read back unchanged.
+package example
+
+//go:generate go run .. -package example -file people.ent -out people.go
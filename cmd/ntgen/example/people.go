@@ -0,0 +1,180 @@
+// Code generated by ntgen. DO NOT EDIT.
+
+package example
+
+import (
+	"errors"
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// ErrIncorrectTupleType is returned from FromTuple if the tuple is of a different type.
+var ErrIncorrectTupleType = errors.New("Incorrect tuple type")
+
+// Register adds all the generated types to the registry. The first error returned by the registry is returned.
+func Register(reg *namedtuple.Registry) error {
+	if err := reg.Register(AddressType); err != nil {
+		return err
+	}
+	if err := reg.Register(PersonType); err != nil {
+		return err
+	}
+	return nil
+}
+
+// registry resolves the nested tuples read by FromTuple.
+var registry = newRegistry()
+
+func newRegistry() *namedtuple.Registry {
+	reg := namedtuple.NewRegistry()
+	Register(&reg)
+	return &reg
+}
+
+// AddressType is the tuple type for people.Address
+var AddressType = NewAddressType()
+
+// NewAddressType creates the tuple type for people.Address with all of its versions.
+func NewAddressType() namedtuple.TupleType {
+	t := namedtuple.New("people", "Address")
+	t.AddVersion(
+		namedtuple.Field{Name: "street", Required: true, Type: namedtuple.StringField},
+		namedtuple.Field{Name: "city", Required: true, Type: namedtuple.StringField},
+		namedtuple.Field{Name: "zipcode", Required: false, Type: namedtuple.Uint16Field},
+	)
+	return t
+}
+
+// Address is the Go representation of people.Address. Optional fields and nested tuples are pointers and are not written if they are nil.
+type Address struct {
+	Street  string
+	City    string
+	Zipcode *uint16
+}
+
+// ToTuple writes the fields into the builder and builds the tuple.
+func (v *Address) ToTuple(b *namedtuple.TupleBuilder) (namedtuple.Tuple, error) {
+	if _, err := b.PutString("street", v.Street); err != nil {
+		return namedtuple.NIL, err
+	}
+	if _, err := b.PutString("city", v.City); err != nil {
+		return namedtuple.NIL, err
+	}
+	if v.Zipcode != nil {
+		if _, err := b.PutUint16("zipcode", *v.Zipcode); err != nil {
+			return namedtuple.NIL, err
+		}
+	}
+	return b.Build()
+}
+
+// FromTuple reads the fields from the tuple. Fields which are not present in the tuple are set to their default value or to their zero value if the field does not have a default value.
+func (v *Address) FromTuple(t namedtuple.Tuple) (err error) {
+	if !t.Is(AddressType) {
+		return ErrIncorrectTupleType
+	}
+
+	if v.Street, err = t.GetString("street"); err != nil && err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+	if v.City, err = t.GetString("city"); err != nil && err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+	v.Zipcode = nil
+	if value, err := t.GetUint16("zipcode"); err == nil {
+		v.Zipcode = &value
+	} else if err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+	return nil
+}
+
+// PersonType is the tuple type for people.Person
+var PersonType = NewPersonType()
+
+// NewPersonType creates the tuple type for people.Person with all of its versions.
+func NewPersonType() namedtuple.TupleType {
+	t := namedtuple.New("people", "Person")
+	t.AddVersion(
+		namedtuple.Field{Name: "name", Required: true, Type: namedtuple.StringField},
+		namedtuple.Field{Name: "address", Required: false, Type: namedtuple.TupleField, TupleNamespace: "people", TupleName: "Address"},
+	)
+	t.AddVersion(
+		namedtuple.Field{Name: "previous", Required: false, Type: namedtuple.TupleArrayField, TupleNamespace: "people", TupleName: "Address"},
+	)
+	return t
+}
+
+// Person is the Go representation of people.Person. Optional fields and nested tuples are pointers and are not written if they are nil.
+type Person struct {
+	Name     string
+	Address  *Address
+	Previous []Address
+}
+
+// ToTuple writes the fields into the builder and builds the tuple.
+func (v *Person) ToTuple(b *namedtuple.TupleBuilder) (namedtuple.Tuple, error) {
+	if _, err := b.PutString("name", v.Name); err != nil {
+		return namedtuple.NIL, err
+	}
+	if v.Address != nil {
+		nested := namedtuple.NewGrowableBuilder(AddressType, nil, 0)
+		tuple, err := v.Address.ToTuple(&nested)
+		if err != nil {
+			return namedtuple.NIL, err
+		}
+		if _, err := b.PutTuple("address", tuple); err != nil {
+			return namedtuple.NIL, err
+		}
+	}
+	if v.Previous != nil {
+		tuples := make([]namedtuple.Tuple, len(v.Previous))
+		for i := range v.Previous {
+			nested := namedtuple.NewGrowableBuilder(AddressType, nil, 0)
+			tuple, err := v.Previous[i].ToTuple(&nested)
+			if err != nil {
+				return namedtuple.NIL, err
+			}
+			tuples[i] = tuple
+		}
+		if _, err := b.PutTupleArray("previous", tuples); err != nil {
+			return namedtuple.NIL, err
+		}
+	}
+	return b.Build()
+}
+
+// FromTuple reads the fields from the tuple. Fields which are not present in the tuple are set to their default value or to their zero value if the field does not have a default value.
+func (v *Person) FromTuple(t namedtuple.Tuple) (err error) {
+	if !t.Is(PersonType) {
+		return ErrIncorrectTupleType
+	}
+
+	if v.Name, err = t.GetString("name"); err != nil && err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+	v.Address = nil
+	if nested, err := t.GetTuple("address", registry); err == nil {
+		v.Address = new(Address)
+		if err := v.Address.FromTuple(nested); err != nil {
+			return err
+		}
+	} else if err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+	v.Previous = nil
+	if it, err := t.GetTupleArray("previous", registry); err == nil {
+		v.Previous = make([]Address, it.Len())
+		for i := 0; it.Next(); i++ {
+			if err := v.Previous[i].FromTuple(it.Tuple()); err != nil {
+				return err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	} else if err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+	return nil
+}
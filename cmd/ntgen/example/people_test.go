@@ -0,0 +1,41 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedRoundTrip(t *testing.T) {
+	zipcode := uint16(10001)
+	person := Person{
+		Name:    "Jane",
+		Address: &Address{Street: "1 Main St", City: "New York", Zipcode: &zipcode},
+		Previous: []Address{
+			{Street: "2 Elm St", City: "Boston"},
+			{Street: "3 Oak St", City: "Chicago"},
+		},
+	}
+
+	b := namedtuple.NewGrowableBuilder(PersonType, nil, 0)
+	tuple, err := person.ToTuple(&b)
+	assert.Nil(t, err)
+
+	var decoded Person
+	assert.Nil(t, decoded.FromTuple(tuple))
+	assert.Equal(t, person, decoded)
+
+	// missing nested tuples are nil
+	b = namedtuple.NewGrowableBuilder(PersonType, nil, 0)
+	tuple, err = (&Person{Name: "John"}).ToTuple(&b)
+	assert.Nil(t, err)
+	assert.Nil(t, decoded.FromTuple(tuple))
+	assert.Equal(t, Person{Name: "John"}, decoded)
+
+	// tuples of other types are rejected
+	b = namedtuple.NewGrowableBuilder(AddressType, nil, 0)
+	tuple, err = person.Address.ToTuple(&b)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrIncorrectTupleType, decoded.FromTuple(tuple))
+}
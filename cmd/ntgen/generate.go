@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// goTypes maps the supported field types to their Go types
+var goTypes = map[namedtuple.FieldType]string{
+	namedtuple.Uint8Field:          "uint8",
+	namedtuple.Uint8ArrayField:     "[]uint8",
+	namedtuple.Int8Field:           "int8",
+	namedtuple.Int8ArrayField:      "[]int8",
+	namedtuple.Uint16Field:         "uint16",
+	namedtuple.Uint16ArrayField:    "[]uint16",
+	namedtuple.Int16Field:          "int16",
+	namedtuple.Int16ArrayField:     "[]int16",
+	namedtuple.Uint32Field:         "uint32",
+	namedtuple.Uint32ArrayField:    "[]uint32",
+	namedtuple.Int32Field:          "int32",
+	namedtuple.Int32ArrayField:     "[]int32",
+	namedtuple.Uint64Field:         "uint64",
+	namedtuple.Uint64ArrayField:    "[]uint64",
+	namedtuple.Int64Field:          "int64",
+	namedtuple.Int64ArrayField:     "[]int64",
+	namedtuple.Float32Field:        "float32",
+	namedtuple.Float32ArrayField:   "[]float32",
+	namedtuple.Float64Field:        "float64",
+	namedtuple.Float64ArrayField:   "[]float64",
+	namedtuple.TimestampField:      "time.Time",
+	namedtuple.TimestampArrayField: "[]time.Time",
+	namedtuple.StringField:         "string",
+	namedtuple.StringArrayField:    "[]string",
+	namedtuple.BooleanField:        "bool",
+	namedtuple.BooleanArrayField:   "[]bool",
+	namedtuple.EnumField:           "string",
+}
+
+// methods maps the field types whose builder and getter methods are not named after the field type
+var methods = map[namedtuple.FieldType]string{
+	namedtuple.BooleanField:      "Bool",
+	namedtuple.BooleanArrayField: "BoolArray",
+}
+
+// typeData is the template data for a single tuple type
+type typeData struct {
+	GoName    string
+	Namespace string
+	Name      string
+	Versions  [][]fieldData
+	Fields    []fieldData
+}
+
+// enumData is the template data for a single enum
+type enumData struct {
+	GoName    string
+	Namespace string
+	Name      string
+	Open      bool
+	Values    []namedtuple.EnumValue
+}
+
+// fieldData is the template data for a single field
+type fieldData struct {
+	GoName         string
+	Name           string
+	Required       bool
+	Array          bool
+	Map            bool
+	Type           string
+	KeyType        string
+	ValueType      string
+	GoType         string
+	KeyGoType      string
+	ValueGoType    string
+	Method         string
+	Constraints    string
+	Default        string
+	Enum           string
+	OneOf          string
+	Tuple          string
+	TupleNamespace string
+	TupleName      string
+}
+
+// generate writes the Go source for the given tuple types into the writer. All the types are generated into a single Go package.
+func generate(w io.Writer, pkgName string, types []namedtuple.TupleType) error {
+	var data struct {
+		Package     string
+		Time        bool
+		Regexp      bool
+		Constraints bool
+		Nested      bool
+		Enums       []enumData
+		Types       []typeData
+	}
+	data.Package = pkgName
+
+	// nested tuples reference the Go types generated for their tuple types
+	goNames := make(map[string]string)
+	for _, tupleType := range types {
+		goNames[tupleType.Namespace+"."+tupleType.Name] = goName(tupleType.Name)
+	}
+
+	names := make(map[string]string)
+	enums := make(map[string]string)
+	for _, tupleType := range types {
+		typ := typeData{
+			GoName:    goName(tupleType.Name),
+			Namespace: tupleType.Namespace,
+			Name:      tupleType.Name,
+		}
+
+		// Go type names must be unique across namespaces
+		if namespace, exists := names[typ.GoName]; exists {
+			return fmt.Errorf("type '%s' is declared in both '%s' and '%s'", typ.GoName, namespace, typ.Namespace)
+		}
+		names[typ.GoName] = typ.Namespace
+
+		for _, version := range tupleType.Versions() {
+			fields := make([]fieldData, len(version.Fields))
+			for i, field := range version.Fields {
+				goType, ok := goTypes[field.Type]
+				keyType, valueType := goTypes[field.KeyType], goTypes[field.ValueType]
+				if field.Type == namedtuple.MapField && namedtuple.ValidMapTypes(field.KeyType, field.ValueType) {
+					goType, ok = "map["+keyType+"]"+valueType, true
+				}
+				var nested string
+				if field.Type == namedtuple.TupleField || field.Type == namedtuple.TupleArrayField {
+					var generated bool
+					if nested, generated = goNames[field.TupleNamespace+"."+field.TupleName]; !generated {
+						return fmt.Errorf("field '%s' of type '%s' references '%s.%s' which is not generated", field.Name, tupleType.Name, field.TupleNamespace, field.TupleName)
+					} else if field.Type == namedtuple.TupleField {
+						goType, ok = "*"+nested, true
+					} else {
+						goType, ok = "[]"+nested, true
+					}
+					data.Nested = true
+				}
+				if !ok {
+					return fmt.Errorf("field '%s' of type '%s' is a %s which is not supported", field.Name, tupleType.Name, field.Type)
+				}
+				data.Time = data.Time || strings.HasSuffix(goType, "time.Time")
+
+				method, ok := methods[field.Type]
+				if !ok {
+					method = strings.TrimSuffix(field.Type.String(), "Field")
+				}
+
+				fields[i] = fieldData{
+					GoName:         goName(field.Name),
+					Name:           field.Name,
+					Required:       field.Required,
+					Array:          strings.HasPrefix(goType, "[]"),
+					Map:            field.Type == namedtuple.MapField,
+					Type:           field.Type.String(),
+					KeyType:        field.KeyType.String(),
+					ValueType:      field.ValueType.String(),
+					GoType:         goType,
+					KeyGoType:      keyType,
+					ValueGoType:    valueType,
+					Method:         method,
+					Constraints:    constraintsLiteral(field.Constraints),
+					Default:        defaultLiteral(field.Default),
+					OneOf:          field.OneOf,
+					Tuple:          nested,
+					TupleNamespace: field.TupleNamespace,
+					TupleName:      field.TupleName,
+				}
+
+				// enums are generated once and shared by all the fields
+				if e := field.Enum; e != nil {
+					key := e.Namespace + "." + e.Name
+					if _, exists := enums[key]; !exists {
+						enum := enumData{goName(e.Name), e.Namespace, e.Name, e.Open, e.Values()}
+						for existing, name := range enums {
+							if name == enum.GoName {
+								return fmt.Errorf("enum '%s' is declared in both '%s' and '%s'", enum.GoName, strings.TrimSuffix(existing, "."+e.Name), e.Namespace)
+							}
+						}
+						enums[key] = enum.GoName
+						data.Enums = append(data.Enums, enum)
+					}
+					fields[i].Enum = enums[key] + "Enum"
+				}
+
+				c := field.Constraints
+				data.Constraints = data.Constraints || !c.IsZero()
+				data.Regexp = data.Regexp || c.Pattern != nil
+				data.Time = data.Time || c.MinTime != nil || c.MaxTime != nil
+			}
+			typ.Versions = append(typ.Versions, fields)
+			typ.Fields = append(typ.Fields, fields...)
+		}
+		data.Types = append(data.Types, typ)
+	}
+
+	// execute template
+	var buf bytes.Buffer
+	if err := sourceTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	// format source
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// constraintsLiteral returns the Go expression for the field constraints or an empty string if there are none
+func constraintsLiteral(c namedtuple.Constraints) string {
+	if c.IsZero() {
+		return ""
+	}
+
+	var values []string
+	add := func(name, value string) {
+		values = append(values, name+": "+value)
+	}
+	if c.Min != nil {
+		add("Min", fmt.Sprintf("float64Ptr(%s)", strconv.FormatFloat(*c.Min, 'g', -1, 64)))
+	}
+	if c.Max != nil {
+		add("Max", fmt.Sprintf("float64Ptr(%s)", strconv.FormatFloat(*c.Max, 'g', -1, 64)))
+	}
+	if c.MinTime != nil {
+		add("MinTime", fmt.Sprintf("timePtr(time.Unix(%d, %d).UTC())", c.MinTime.Unix(), c.MinTime.Nanosecond()))
+	}
+	if c.MaxTime != nil {
+		add("MaxTime", fmt.Sprintf("timePtr(time.Unix(%d, %d).UTC())", c.MaxTime.Unix(), c.MaxTime.Nanosecond()))
+	}
+	if c.MinLength != nil {
+		add("MinLength", fmt.Sprintf("intPtr(%d)", *c.MinLength))
+	}
+	if c.MaxLength != nil {
+		add("MaxLength", fmt.Sprintf("intPtr(%d)", *c.MaxLength))
+	}
+	if c.Pattern != nil {
+		add("Pattern", fmt.Sprintf("regexp.MustCompile(%q)", c.Pattern.String()))
+	}
+	if c.MinItems != nil {
+		add("MinItems", fmt.Sprintf("intPtr(%d)", *c.MinItems))
+	}
+	if c.MaxItems != nil {
+		add("MaxItems", fmt.Sprintf("intPtr(%d)", *c.MaxItems))
+	}
+	return "namedtuple.Constraints{" + strings.Join(values, ", ") + "}"
+}
+
+// defaultLiteral returns the Go expression for the default value of a field or an empty string if there is none
+func defaultLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float32:
+		return "float32(" + strconv.FormatFloat(float64(v), 'g', -1, 32) + ")"
+	case float64:
+		return "float64(" + strconv.FormatFloat(v, 'g', -1, 64) + ")"
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return fmt.Sprintf("time.Unix(%d, %d).UTC()", v.Unix(), v.Nanosecond())
+	}
+	return fmt.Sprintf("%T(%v)", value, value)
+}
+
+// goName converts a schema name into an exported Go identifier
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+var sourceTemplate = template.Must(template.New("source").Parse(`// Code generated by ntgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"errors"
+{{- if .Regexp}}
+	"regexp"
+{{- end}}
+{{- if .Time}}
+	"time"
+{{- end}}
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// ErrIncorrectTupleType is returned from FromTuple if the tuple is of a different type.
+var ErrIncorrectTupleType = errors.New("Incorrect tuple type")
+
+// Register adds all the generated types to the registry. The first error returned by the registry is returned.
+func Register(reg *namedtuple.Registry) error {
+{{- range .Types}}
+	if err := reg.Register({{.GoName}}Type); err != nil {
+		return err
+	}
+{{- end}}
+	return nil
+}
+{{- if .Nested}}
+
+// registry resolves the nested tuples read by FromTuple.
+var registry = newRegistry()
+
+func newRegistry() *namedtuple.Registry {
+	reg := namedtuple.NewRegistry()
+	Register(&reg)
+	return &reg
+}
+{{- end}}
+{{- if .Constraints}}
+
+func float64Ptr(v float64) *float64 { return &v }
+func intPtr(v int) *int { return &v }
+{{- if .Time}}
+func timePtr(v time.Time) *time.Time { return &v }
+{{- end}}
+{{- end}}
+{{range .Enums}}
+// {{.GoName}}Enum is the enum {{.Namespace}}.{{.Name}}
+var {{.GoName}}Enum = namedtuple.NewEnum("{{.Namespace}}", "{{.Name}}", {{.Open}},
+{{- range .Values}}
+	namedtuple.EnumValue{Name: "{{.Name}}", Value: {{.Value}}},
+{{- end}}
+)
+{{end}}
+{{- range .Types}}
+// {{.GoName}}Type is the tuple type for {{.Namespace}}.{{.Name}}
+var {{.GoName}}Type = New{{.GoName}}Type()
+
+// New{{.GoName}}Type creates the tuple type for {{.Namespace}}.{{.Name}} with all of its versions.
+func New{{.GoName}}Type() namedtuple.TupleType {
+	t := namedtuple.New("{{.Namespace}}", "{{.Name}}")
+{{- range .Versions}}
+	t.AddVersion(
+{{- range .}}
+		namedtuple.Field{Name: "{{.Name}}", Required: {{.Required}}, Type: namedtuple.{{.Type}}{{if .Constraints}}, Constraints: {{.Constraints}}{{end}}{{if .Map}}, KeyType: namedtuple.{{.KeyType}}, ValueType: namedtuple.{{.ValueType}}{{end}}{{if .Enum}}, Enum: {{.Enum}}{{end}}{{if .Default}}, Default: {{.Default}}{{end}}{{if .Tuple}}, TupleNamespace: "{{.TupleNamespace}}", TupleName: "{{.TupleName}}"{{end}}{{if .OneOf}}, OneOf: "{{.OneOf}}"{{end}}},
+{{- end}}
+	)
+{{- end}}
+	return t
+}
+
+// {{.GoName}} is the Go representation of {{.Namespace}}.{{.Name}}. Optional fields and nested tuples are pointers and are not written if they are nil.
+type {{.GoName}} struct {
+{{- range .Fields}}
+	{{.GoName}} {{if and (not .Required) (not .Array) (not .Map) (not .Tuple)}}*{{end}}{{.GoType}}
+{{- end}}
+}
+
+// ToTuple writes the fields into the builder and builds the tuple.
+func (v *{{.GoName}}) ToTuple(b *namedtuple.TupleBuilder) (namedtuple.Tuple, error) {
+{{- range .Fields}}
+{{- if and .Tuple .Array}}
+	if v.{{.GoName}} != nil {
+		tuples := make([]namedtuple.Tuple, len(v.{{.GoName}}))
+		for i := range v.{{.GoName}} {
+			nested := namedtuple.NewGrowableBuilder({{.Tuple}}Type, nil, 0)
+			tuple, err := v.{{.GoName}}[i].ToTuple(&nested)
+			if err != nil {
+				return namedtuple.NIL, err
+			}
+			tuples[i] = tuple
+		}
+		if _, err := b.PutTupleArray("{{.Name}}", tuples); err != nil {
+			return namedtuple.NIL, err
+		}
+	}
+{{- else if .Tuple}}
+	if v.{{.GoName}} != nil {
+		nested := namedtuple.NewGrowableBuilder({{.Tuple}}Type, nil, 0)
+		tuple, err := v.{{.GoName}}.ToTuple(&nested)
+		if err != nil {
+			return namedtuple.NIL, err
+		}
+		if _, err := b.PutTuple("{{.Name}}", tuple); err != nil {
+			return namedtuple.NIL, err
+		}
+	}
+{{- else if .Required}}
+	if _, err := b.Put{{.Method}}("{{.Name}}", v.{{.GoName}}); err != nil {
+		return namedtuple.NIL, err
+	}
+{{- else if or .Array .Map}}
+	if v.{{.GoName}} != nil {
+		if _, err := b.Put{{.Method}}("{{.Name}}", v.{{.GoName}}); err != nil {
+			return namedtuple.NIL, err
+		}
+	}
+{{- else}}
+	if v.{{.GoName}} != nil {
+		if _, err := b.Put{{.Method}}("{{.Name}}", *v.{{.GoName}}); err != nil {
+			return namedtuple.NIL, err
+		}
+	}
+{{- end}}
+{{- end}}
+	return b.Build()
+}
+
+// FromTuple reads the fields from the tuple. Fields which are not present in the tuple are set to their default value or to their zero value if the field does not have a default value.
+func (v *{{.GoName}}) FromTuple(t namedtuple.Tuple) (err error) {
+	if !t.Is({{.GoName}}Type) {
+		return ErrIncorrectTupleType
+	}
+{{range .Fields}}
+{{- if and .Tuple .Array}}
+	v.{{.GoName}} = nil
+	if it, err := t.GetTupleArray("{{.Name}}", registry); err == nil {
+		v.{{.GoName}} = make({{.GoType}}, it.Len())
+		for i := 0; it.Next(); i++ {
+			if err := v.{{.GoName}}[i].FromTuple(it.Tuple()); err != nil {
+				return err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	} else if err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+{{- else if .Tuple}}
+	v.{{.GoName}} = nil
+	if nested, err := t.GetTuple("{{.Name}}", registry); err == nil {
+		v.{{.GoName}} = new({{.Tuple}})
+		if err := v.{{.GoName}}.FromTuple(nested); err != nil {
+			return err
+		}
+	} else if err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+{{- else if .Map}}
+	v.{{.GoName}} = nil
+	if it, err := t.GetMapIterator("{{.Name}}"); err == nil {
+		v.{{.GoName}} = make({{.GoType}}, it.Len())
+		for it.Next() {
+			v.{{.GoName}}[it.Key().({{.KeyGoType}})] = it.Value().({{.ValueGoType}})
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	} else if err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+{{- else if or .Required .Array}}
+	if v.{{.GoName}}, err = t.Get{{.Method}}("{{.Name}}"); err != nil && err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+{{- else}}
+	v.{{.GoName}} = nil
+	if value, err := t.Get{{.Method}}("{{.Name}}"); err == nil {
+		v.{{.GoName}} = &value
+	} else if err != namedtuple.ErrFieldNotPresent {
+		return err
+	}
+{{- end}}
+{{- end}}
+	return nil
+}
+{{end}}`))
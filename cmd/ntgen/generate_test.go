@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/blacklabeldata/namedtuple/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func compileTestSchema(t *testing.T, text string) []namedtuple.TupleType {
+	pkgList := schema.NewPackageList()
+	_, err := schema.NewParser(pkgList).Parse("test.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	types, err := schema.CompileAll(pkgList, &reg)
+	assert.Nil(t, err)
+	return types
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "Uuid", goName("uuid"))
+	assert.Equal(t, "FirstName", goName("first_name"))
+	assert.Equal(t, "User", goName("User"))
+	assert.Equal(t, "Zip5", goName("zip5"))
+}
+
+func TestGenerate(t *testing.T) {
+	types := compileTestSchema(t, `
+    package users
+
+    type User {
+        version 1 {
+            required string uuid, username
+            optional uint8 age
+            optional []uint32 scores
+            optional bool active
+            optional []string emails
+        }
+
+        version 2 {
+            optional timestamp created
+        }
+    }
+    `)
+
+	var buf bytes.Buffer
+	err := generate(&buf, "users", types)
+	assert.Nil(t, err)
+	src := buf.String()
+
+	// package and imports
+	assert.True(t, strings.HasPrefix(src, "// Code generated by ntgen. DO NOT EDIT.\n\npackage users\n"))
+	assert.Contains(t, src, "\t\"time\"\n")
+
+	// tuple type
+	assert.Contains(t, src, "var UserType = NewUserType()")
+	assert.Contains(t, src, "t := namedtuple.New(\"users\", \"User\")")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"uuid\", Required: true, Type: namedtuple.StringField},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"created\", Required: false, Type: namedtuple.TimestampField},")
+	assert.Equal(t, 2, strings.Count(src, "t.AddVersion("))
+	assert.Contains(t, src, "if err := reg.Register(UserType); err != nil {")
+
+	// struct
+	assert.Contains(t, src, "type User struct {")
+	assert.Contains(t, src, "Username string")
+	assert.Contains(t, src, "Age      *uint8")
+	assert.Contains(t, src, "Scores   []uint32")
+	assert.Contains(t, src, "Created  *time.Time")
+
+	// methods
+	assert.Contains(t, src, "b.PutString(\"uuid\", v.Uuid)")
+	assert.Contains(t, src, "b.PutUint8(\"age\", *v.Age)")
+	assert.Contains(t, src, "t.GetUint32Array(\"scores\")")
+	assert.Contains(t, src, "t.GetTimestamp(\"created\")")
+	assert.Contains(t, src, "b.PutBool(\"active\", *v.Active)")
+	assert.Contains(t, src, "t.GetBool(\"active\")")
+	assert.Contains(t, src, "b.PutStringArray(\"emails\", v.Emails)")
+}
+
+func TestGenerateConstraints(t *testing.T) {
+	types := compileTestSchema(t, `
+    package users
+
+    type User {
+        version 1 {
+            required string username (minlen 3, pattern "^[a-z]+$")
+            optional uint8 age (min 13, max 150)
+            optional []timestamp logins (min "2000-01-01T00:00:00Z", maxitems 10)
+        }
+    }
+    `)
+
+	var buf bytes.Buffer
+	err := generate(&buf, "users", types)
+	assert.Nil(t, err)
+	src := buf.String()
+
+	// imports and helpers
+	assert.Contains(t, src, "\t\"regexp\"\n")
+	assert.Contains(t, src, "func float64Ptr(v float64) *float64")
+	assert.Contains(t, src, "func timePtr(v time.Time) *time.Time")
+
+	// constraints
+	assert.Contains(t, src, "namedtuple.Field{Name: \"username\", Required: true, Type: namedtuple.StringField, Constraints: namedtuple.Constraints{MinLength: intPtr(3), Pattern: regexp.MustCompile(\"^[a-z]+$\")}},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"age\", Required: false, Type: namedtuple.Uint8Field, Constraints: namedtuple.Constraints{Min: float64Ptr(13), Max: float64Ptr(150)}},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"logins\", Required: false, Type: namedtuple.TimestampArrayField, Constraints: namedtuple.Constraints{MinTime: timePtr(time.Unix(946684800, 0).UTC()), MaxItems: intPtr(10)}},")
+}
+
+func TestGenerateDefaults(t *testing.T) {
+	types := compileTestSchema(t, `
+    package users
+
+    type User {
+        version 1 {
+            optional uint8 age = 18
+            optional float64 score = 0.5
+            optional string nickname = "anonymous"
+            optional bool active = true
+            optional timestamp created = "2015-01-01T00:00:00Z"
+        }
+    }
+    `)
+
+	var buf bytes.Buffer
+	err := generate(&buf, "users", types)
+	assert.Nil(t, err)
+	src := buf.String()
+
+	assert.Contains(t, src, "namedtuple.Field{Name: \"age\", Required: false, Type: namedtuple.Uint8Field, Default: uint8(18)},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"score\", Required: false, Type: namedtuple.Float64Field, Default: float64(0.5)},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"nickname\", Required: false, Type: namedtuple.StringField, Default: \"anonymous\"},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"active\", Required: false, Type: namedtuple.BooleanField, Default: true},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"created\", Required: false, Type: namedtuple.TimestampField, Default: time.Unix(1420070400, 0).UTC()},")
+}
+
+func TestGenerateNested(t *testing.T) {
+	text, err := ioutil.ReadFile("example/people.ent")
+	assert.Nil(t, err)
+	types := compileTestSchema(t, string(text))
+
+	var buf bytes.Buffer
+	err = generate(&buf, "example", types)
+	assert.Nil(t, err)
+	src := buf.String()
+
+	assert.Contains(t, src, "namedtuple.Field{Name: \"address\", Required: false, Type: namedtuple.TupleField, TupleNamespace: \"people\", TupleName: \"Address\"},")
+	assert.Contains(t, src, "\tAddress  *Address\n")
+	assert.Contains(t, src, "\tPrevious []Address\n")
+	assert.Contains(t, src, "v.Address.ToTuple(&nested)")
+	assert.Contains(t, src, "t.GetTupleArray(\"previous\", registry)")
+
+	// the example package is generated from the same schema and tests the round trip
+	generated, err := ioutil.ReadFile("example/people.go")
+	assert.Nil(t, err)
+	assert.Equal(t, string(generated), src, "example/people.go is out of date, run go generate")
+
+	// nested types must be generated as well
+	buf.Reset()
+	err = generate(&buf, "example", types[1:])
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestGenerateEnums(t *testing.T) {
+	types := compileTestSchema(t, `
+    package users
+
+    open enum Status {
+        ACTIVE = 1
+        DISABLED = 2
+    }
+
+    type User {
+        version 1 {
+            required Status status
+            optional Status previous = "ACTIVE"
+        }
+    }
+    `)
+
+	var buf bytes.Buffer
+	err := generate(&buf, "users", types)
+	assert.Nil(t, err)
+	src := buf.String()
+
+	// enums are declared once and shared by the fields
+	assert.Equal(t, 1, strings.Count(src, "var StatusEnum = "))
+	assert.Contains(t, src, "var StatusEnum = namedtuple.NewEnum(\"users\", \"Status\", true,\n\tnamedtuple.EnumValue{Name: \"ACTIVE\", Value: 1},\n\tnamedtuple.EnumValue{Name: \"DISABLED\", Value: 2},\n)")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"status\", Required: true, Type: namedtuple.EnumField, Enum: StatusEnum},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"previous\", Required: false, Type: namedtuple.EnumField, Enum: StatusEnum, Default: \"ACTIVE\"},")
+	assert.Contains(t, src, "\tStatus   string\n")
+	assert.Contains(t, src, "b.PutEnum(\"status\", v.Status)")
+}
+
+func TestGenerateMaps(t *testing.T) {
+	types := compileTestSchema(t, `
+    package users
+
+    type User {
+        version 1 {
+            optional map<string, uint32> counts
+        }
+    }
+    `)
+
+	var buf bytes.Buffer
+	err := generate(&buf, "users", types)
+	assert.Nil(t, err)
+	src := buf.String()
+
+	assert.Contains(t, src, "namedtuple.Field{Name: \"counts\", Required: false, Type: namedtuple.MapField, KeyType: namedtuple.StringField, ValueType: namedtuple.Uint32Field},")
+	assert.Contains(t, src, "\tCounts map[string]uint32\n")
+	assert.Contains(t, src, "b.PutMap(\"counts\", v.Counts)")
+	assert.Contains(t, src, "t.GetMapIterator(\"counts\")")
+	assert.Contains(t, src, "it.Key().(string)")
+	assert.Contains(t, src, "it.Value().(uint32)")
+}
+
+func TestGenerateOneOf(t *testing.T) {
+	types := compileTestSchema(t, `
+    package users
+
+    type User {
+        version 1 {
+            oneof contact {
+                optional string email
+                optional uint64 phone
+            }
+        }
+    }
+    `)
+
+	var buf bytes.Buffer
+	err := generate(&buf, "users", types)
+	assert.Nil(t, err)
+	src := buf.String()
+
+	assert.Contains(t, src, "namedtuple.Field{Name: \"email\", Required: false, Type: namedtuple.StringField, OneOf: \"contact\"},")
+	assert.Contains(t, src, "namedtuple.Field{Name: \"phone\", Required: false, Type: namedtuple.Uint64Field, OneOf: \"contact\"},")
+}
@@ -0,0 +1,75 @@
+// Command ntgen generates Go structs and tuple types from .ent schema files.
+//
+// Usage:
+//
+//	ntgen -package users -dir ./schemas -out users.go
+//	ntgen -package users -file users.ent
+//
+// Each type in the schema becomes a Go struct with ToTuple and FromTuple
+// methods, a TupleType constructor containing all the versions and a
+// Register function which adds every type to a namedtuple.Registry.
+// Nested tuples become pointers and slices of the generated structs, so
+// the nested types must be generated into the same package.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/blacklabeldata/namedtuple/schema"
+)
+
+func main() {
+	pkgName := flag.String("package", "", "Go package name of the generated file")
+	dir := flag.String("dir", "", "directory containing .ent schema files")
+	file := flag.String("file", "", "single .ent schema file")
+	out := flag.String("out", "", "output file (defaults to stdout)")
+	flag.Parse()
+
+	if *pkgName == "" || (*dir == "") == (*file == "") {
+		fmt.Fprintln(os.Stderr, "ntgen: -package and one of -dir or -file are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*pkgName, *dir, *file, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "ntgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkgName, dir, file, out string) error {
+
+	// load schema files
+	pkgList := schema.NewPackageList()
+	parser := schema.NewParser(pkgList)
+	if dir != "" {
+		if err := schema.LoadDirectory(dir, parser); err != nil {
+			return err
+		}
+	} else if _, err := schema.LoadFile(file, parser); err != nil {
+		return err
+	}
+
+	// compile types
+	reg := namedtuple.NewRegistry()
+	types, err := schema.CompileAll(pkgList, &reg)
+	if err != nil {
+		return err
+	}
+
+	// write output
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return generate(w, pkgName, types)
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// options are the command line options
+type options struct {
+	SchemaDir string
+	Registry  string
+	MaxSize   uint64
+	JSON      bool
+	Hex       bool
+	TypeName  string
+}
+
+// tupleInfo is a decoded tuple along with its location in the stream
+type tupleInfo struct {
+	Index       int
+	Offset      int
+	Raw         []byte
+	LengthBytes uint8
+	Length      uint64
+	Tuple       namedtuple.Tuple
+}
+
+// inspect decodes each tuple in the stream and prints it in the format selected by the options. If the registry is nil, the stream is read as a self-describing stream and the tuple types are taken from its schema frames.
+func inspect(w io.Writer, data []byte, reg *namedtuple.Registry, opts options) error {
+	var dec namedtuple.Decoder
+	if reg == nil {
+		schemaDecoder := namedtuple.NewSchemaDecoder(opts.MaxSize, bytes.NewReader(data))
+		dec, reg = schemaDecoder, schemaDecoder.Registry()
+	} else {
+		dec = namedtuple.NewDecoderSize(*reg, opts.MaxSize, bytes.NewReader(data))
+	}
+
+	var offset int
+	for index := 0; ; index++ {
+
+		// schema frames are read by the decoder along with the next tuple and are not printed
+		for offset < len(data) {
+			size, schema := schemaFrameSize(data[offset:])
+			if !schema {
+				break
+			}
+			offset += size
+		}
+
+		// the tuples of a compressed block have no raw bytes in the stream
+		if offset < len(data) && data[offset]&namedtuple.ProtocolVersionMask == namedtuple.ProtocolVersionThree {
+			return fmt.Errorf("tuple %d at offset %d: compressed blocks can not be inspected", index, offset)
+		}
+
+		t, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("tuple %d at offset %d: %s", index, offset, err)
+		}
+
+		// locate the raw bytes of the tuple in the stream
+		lengthBytes, _ := namedtuple.ParseProtocolHeader(data[offset])
+		length := readLength(data[offset+1:], lengthBytes)
+		size := 1 + int(lengthBytes) + int(length)
+		info := tupleInfo{index, offset, data[offset : offset+size], lengthBytes, length, t}
+		offset += size
+
+		if !matches(t.Header.Type, opts.TypeName) {
+			continue
+		}
+
+		switch {
+		case opts.JSON:
+			err = printJSON(w, info, reg)
+		case opts.Hex:
+			err = printHex(w, info)
+		default:
+			err = printText(w, info, reg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readLength reads the little endian content length following the protocol header
+func readLength(buffer []byte, size uint8) uint64 {
+	switch size {
+	case 1:
+		return uint64(buffer[0])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(buffer))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(buffer))
+	}
+	return binary.LittleEndian.Uint64(buffer)
+}
+
+// schemaFrameSize returns the size of the frame at the start of the data and true if it is a complete schema frame of a self-describing stream
+func schemaFrameSize(data []byte) (int, bool) {
+	lengthBytes, version := namedtuple.ParseProtocolHeader(data[0])
+	start := 1 + int(lengthBytes)
+	if version != namedtuple.ProtocolVersionTwo || start >= len(data) || data[start] != namedtuple.SchemaFlag {
+		return 0, false
+	}
+
+	length := readLength(data[1:], lengthBytes)
+	if length > uint64(len(data)-start) {
+		return 0, false
+	}
+	return start + int(length), true
+}
+
+// matches returns true if the filter is empty or matches the name or the qualified name of the tuple type
+func matches(t namedtuple.TupleType, filter string) bool {
+	return filter == "" || filter == t.Name || filter == t.Namespace+"."+t.Name
+}
+
+// fields returns the fields of the versions the tuple was written with
+func fields(t namedtuple.Tuple) []namedtuple.Field {
+	var fields []namedtuple.Field
+	for _, version := range t.Header.Type.Versions() {
+		if version.Num > t.Header.TupleVersion {
+			break
+		}
+		fields = append(fields, version.Fields...)
+	}
+	return fields
+}
+
+// formatOffsets formats the field offsets. Fields which were not written are printed as "-".
+func formatOffsets(offsets []uint64) string {
+	values := make([]string, len(offsets))
+	for i, offset := range offsets {
+		if offset == math.MaxUint64 {
+			values[i] = "-"
+		} else {
+			values[i] = fmt.Sprint(offset)
+		}
+	}
+	return "[" + strings.Join(values, " ") + "]"
+}
+
+func printText(w io.Writer, info tupleInfo, reg *namedtuple.Registry) error {
+	t := info.Tuple
+	h := t.Header
+
+	// decode the field values
+	b, err := namedtuple.ToJSONWithRegistry(t, reg)
+	if err != nil {
+		return err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "tuple %d at offset %d: %s.%s\n", info.Index, info.Offset, h.Type.Namespace, h.Type.Name)
+	fmt.Fprintf(w, "  protocol: version %d, length %d (%d byte)\n", h.ProtocolVersion, info.Length, info.LengthBytes)
+	fmt.Fprintf(w, "  version:  %d\n", h.TupleVersion)
+	fmt.Fprintf(w, "  hashes:   namespace 0x%08x, type 0x%08x\n", h.NamespaceHash, h.Hash)
+	if h.TypeID != 0 {
+		fmt.Fprintf(w, "  type id:  0x%016x\n", h.TypeID)
+	}
+	fmt.Fprintf(w, "  offsets:  %s (field size %d)\n", formatOffsets(h.Offsets), h.FieldSize)
+	fmt.Fprintln(w, "  fields:")
+	for _, field := range fields(t) {
+		if value, ok := values[field.Name]; ok {
+			fmt.Fprintf(w, "    %s (%s) = %s\n", field.Name, field.Type, value)
+		} else {
+			fmt.Fprintf(w, "    %s (%s) not present\n", field.Name, field.Type)
+		}
+	}
+	return nil
+}
+
+// jsonTuple is the JSON representation of a tuple printed with -json
+type jsonTuple struct {
+	Offset        int             `json:"offset"`
+	Protocol      uint8           `json:"protocol"`
+	Length        uint64          `json:"length"`
+	Namespace     string          `json:"namespace"`
+	Type          string          `json:"type"`
+	Version       uint8           `json:"version"`
+	NamespaceHash uint32          `json:"namespaceHash"`
+	Hash          uint32          `json:"hash"`
+	TypeID        uint64          `json:"typeId,omitempty"`
+	Offsets       []*uint64       `json:"offsets"`
+	Fields        json.RawMessage `json:"fields"`
+}
+
+func printJSON(w io.Writer, info tupleInfo, reg *namedtuple.Registry) error {
+	t := info.Tuple
+	h := t.Header
+
+	b, err := namedtuple.ToJSONWithRegistry(t, reg)
+	if err != nil {
+		return err
+	}
+
+	// fields which were not written have a null offset
+	offsets := make([]*uint64, len(h.Offsets))
+	for i := range h.Offsets {
+		if h.Offsets[i] != math.MaxUint64 {
+			offsets[i] = &h.Offsets[i]
+		}
+	}
+
+	return json.NewEncoder(w).Encode(jsonTuple{
+		Offset:        info.Offset,
+		Protocol:      h.ProtocolVersion,
+		Length:        info.Length,
+		Namespace:     h.Type.Namespace,
+		Type:          h.Type.Name,
+		Version:       h.TupleVersion,
+		NamespaceHash: h.NamespaceHash,
+		Hash:          h.Hash,
+		TypeID:        h.TypeID,
+		Offsets:       offsets,
+		Fields:        b,
+	})
+}
+
+// segment is a range of bytes in the hexdump with an annotation
+type segment struct {
+	Offset int
+	Data   []byte
+	Note   string
+}
+
+// fieldOffset is the offset of a field in the tuple payload
+type fieldOffset struct {
+	Name   string
+	Offset int
+}
+
+// segments splits the raw bytes of a tuple into annotated segments for the protocol header, the tuple header and each field
+func segments(info tupleInfo) []segment {
+	t := info.Tuple
+	h := t.Header
+	raw := info.Raw
+
+	var segs []segment
+	var pos int
+	add := func(n int, note string) {
+		if pos+n > len(raw) {
+			n = len(raw) - pos
+		}
+		segs = append(segs, segment{info.Offset + pos, raw[pos : pos+n], note})
+		pos += n
+	}
+
+	// protocol header
+	add(1, fmt.Sprintf("protocol version %d, %d byte length", h.ProtocolVersion, info.LengthBytes))
+	add(int(info.LengthBytes), fmt.Sprintf("content length %d", info.Length))
+	if h.ProtocolVersion == namedtuple.ProtocolVersionTwo {
+		add(1, "protocol flags")
+		add(8, fmt.Sprintf("schema fingerprint 0x%016x", binary.LittleEndian.Uint64(raw[pos:])))
+	}
+
+	// tuple header
+	note := fmt.Sprintf("tuple version %d, field size %d", h.TupleVersion, h.FieldSize)
+	if h.TypeID != 0 {
+		note += ", type id"
+	}
+	add(1, note)
+	add(4, fmt.Sprintf("namespace hash 0x%08x", h.NamespaceHash))
+	add(4, fmt.Sprintf("type hash 0x%08x", h.Hash))
+	add(4, fmt.Sprintf("field count %d", h.FieldCount))
+	if h.TypeID != 0 {
+		add(namedtuple.TypeIDSize, fmt.Sprintf("type id 0x%016x", h.TypeID))
+	}
+	add(int(h.FieldSize)*int(h.FieldCount), "field offsets "+formatOffsets(h.Offsets))
+
+	// fields ordered by their position in the payload
+	var offsets []fieldOffset
+	for i, field := range fields(t) {
+		if i < len(h.Offsets) && h.Offsets[i] != math.MaxUint64 {
+			offsets = append(offsets, fieldOffset{field.Name, int(h.Offsets[i])})
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i].Offset < offsets[j].Offset })
+
+	start := pos
+	payload := t.Payload()
+	for i, field := range offsets {
+		end := len(payload)
+		if i+1 < len(offsets) {
+			end = offsets[i+1].Offset
+		}
+		if start+field.Offset > pos {
+			add(start+field.Offset-pos, "payload")
+		}
+
+		note := "field " + field.Name
+		if code, ok := namedtuple.LookupTypeCode(raw[pos]); ok {
+			note += ": " + code.String()
+		} else {
+			note += fmt.Sprintf(": unknown opcode %d", raw[pos])
+		}
+		add(end-field.Offset, note)
+	}
+	if start+len(payload) > pos {
+		add(start+len(payload)-pos, "payload")
+	}
+
+	// checksum
+	if pos < len(raw) {
+		add(len(raw)-pos, "checksum")
+	}
+	return segs
+}
+
+func printHex(w io.Writer, info tupleInfo) error {
+	h := info.Tuple.Header
+	if _, err := fmt.Fprintf(w, "tuple %d at offset %d: %s.%s\n", info.Index, info.Offset, h.Type.Namespace, h.Type.Name); err != nil {
+		return err
+	}
+
+	// print 16 bytes per line, the annotation is on the first line of each segment
+	for _, seg := range segments(info) {
+		for i := 0; i < len(seg.Data); i += 16 {
+			end := i + 16
+			if end > len(seg.Data) {
+				end = len(seg.Data)
+			}
+
+			var note string
+			if i == 0 {
+				note = seg.Note
+			}
+			line := fmt.Sprintf("  %08x  %-47s  %s", seg.Offset+i, fmt.Sprintf("% x", seg.Data[i:end]), note)
+			if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
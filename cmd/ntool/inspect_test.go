@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/blacklabeldata/namedtuple/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `
+package users
+
+type User {
+    version 1 {
+        required string uuid
+        optional uint8 age
+    }
+}
+
+type Group {
+    version 1 {
+        required string name
+    }
+}
+`
+
+func compileTestSchema(t *testing.T) namedtuple.Registry {
+	pkgList := schema.NewPackageList()
+	_, err := schema.NewParser(pkgList).Parse("test.ent", testSchema)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	_, err = schema.CompileAll(pkgList, &reg)
+	assert.Nil(t, err)
+	return reg
+}
+
+// encodeTestStream encodes a user and a group using the given protocol version
+func encodeTestStream(t *testing.T, reg namedtuple.Registry, version uint8) []byte {
+	var out bytes.Buffer
+	encoder, err := namedtuple.NewEncoderVersion(&out, version)
+	assert.Nil(t, err)
+
+	User, _ := reg.Get("users", "User")
+	user := User.Builder(make([]byte, 256))
+	user.PutString("uuid", "abc")
+	u, err := user.Build()
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(u))
+
+	Group, _ := reg.Get("users", "Group")
+	group := Group.Builder(make([]byte, 256))
+	group.PutString("name", "admins")
+	g, err := group.Build()
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(g))
+	return out.Bytes()
+}
+
+func TestInspectText(t *testing.T) {
+	reg := compileTestSchema(t)
+	data := encodeTestStream(t, reg, namedtuple.ProtocolVersionOne)
+
+	var out bytes.Buffer
+	err := inspect(&out, data, &reg, options{MaxSize: 1024})
+	assert.Nil(t, err)
+
+	text := out.String()
+	assert.Contains(t, text, "tuple 0 at offset 0: users.User\n")
+	assert.Contains(t, text, "  protocol: version 1, length 20 (1 byte)\n")
+	assert.Contains(t, text, "  offsets:  [0 -] (field size 1)\n")
+	assert.Contains(t, text, `    uuid (StringField) = "abc"`)
+	assert.Contains(t, text, "    age (Uint8Field) not present\n")
+	assert.Contains(t, text, "tuple 1 at offset 22: users.Group\n")
+	assert.Contains(t, text, `    name (StringField) = "admins"`)
+}
+
+func TestInspectJSON(t *testing.T) {
+	reg := compileTestSchema(t)
+	data := encodeTestStream(t, reg, namedtuple.ProtocolVersionOne)
+
+	var out bytes.Buffer
+	err := inspect(&out, data, &reg, options{MaxSize: 1024, JSON: true, TypeName: "users.Group"})
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 1, len(lines))
+
+	var tuple jsonTuple
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &tuple))
+	assert.Equal(t, 22, tuple.Offset)
+	assert.Equal(t, "Group", tuple.Type)
+	assert.Equal(t, uint8(1), tuple.Version)
+	assert.Equal(t, `{"name":"admins"}`, string(tuple.Fields))
+}
+
+func TestInspectHex(t *testing.T) {
+	reg := compileTestSchema(t)
+	data := encodeTestStream(t, reg, namedtuple.ProtocolVersionTwo)
+
+	var out bytes.Buffer
+	err := inspect(&out, data, &reg, options{MaxSize: 1024, Hex: true, TypeName: "User"})
+	assert.Nil(t, err)
+
+	text := out.String()
+	assert.Contains(t, text, "  00000000  02                                               protocol version 2, 1 byte length\n")
+	assert.Contains(t, text, "  00000002  00                                               protocol flags\n")
+	assert.Contains(t, text, "field offsets [0 -]")
+	assert.Contains(t, text, "  0000001a  45 03 61 62 63                                   field uuid: String8Code\n")
+	assert.Contains(t, text, "checksum")
+	assert.NotContains(t, text, "Group")
+}
+
+func TestInspectUnknownType(t *testing.T) {
+	reg := compileTestSchema(t)
+	data := encodeTestStream(t, reg, namedtuple.ProtocolVersionOne)
+
+	empty := namedtuple.NewRegistry()
+	err := inspect(ioutil.Discard, data, &empty, options{MaxSize: 1024})
+	assert.EqualError(t, err, "tuple 0 at offset 0: Unknown tuple type")
+}
+
+func TestInspectSchemaStream(t *testing.T) {
+	reg := compileTestSchema(t)
+	data := encodeTestStream(t, reg, namedtuple.ProtocolVersionOne)
+
+	// the same tuples written to a self-describing stream, each after the schema frame of its type
+	var out bytes.Buffer
+	encoder, err := namedtuple.NewSchemaEncoder(&out, namedtuple.ProtocolVersionOne, nil)
+	assert.Nil(t, err)
+	dec := namedtuple.NewDecoder(reg, bytes.NewReader(data))
+	for i := 0; i < 2; i++ {
+		tuple, err := dec.Decode()
+		assert.Nil(t, err)
+		assert.Nil(t, encoder.Encode(tuple))
+	}
+	stream := append([]byte{}, out.Bytes()...)
+	userOffset, _ := schemaFrameSize(stream)
+	groupSchema := userOffset + 22
+	groupSize, schema := schemaFrameSize(stream[groupSchema:])
+	assert.True(t, schema)
+
+	out.Reset()
+	assert.Nil(t, inspect(&out, stream, nil, options{MaxSize: 1024, Hex: true}))
+	text := out.String()
+	assert.Contains(t, text, fmt.Sprintf("tuple 0 at offset %d: users.User\n", userOffset))
+	assert.Contains(t, text, fmt.Sprintf("tuple 1 at offset %d: users.Group\n", groupSchema+groupSize))
+	assert.Contains(t, text, "field uuid: String8Code")
+	assert.NotContains(t, text, "namedtuple.TupleType")
+
+	// the schema frames are skipped with a registry as well
+	out.Reset()
+	assert.Nil(t, inspect(&out, stream, &reg, options{MaxSize: 1024}))
+	assert.Contains(t, out.String(), `    name (StringField) = "admins"`)
+
+	// tuples without a schema frame
+	err = inspect(ioutil.Discard, data, nil, options{MaxSize: 1024})
+	assert.EqualError(t, err, "tuple 0 at offset 0: Unknown tuple type")
+}
+
+func TestInspectBlock(t *testing.T) {
+	reg := compileTestSchema(t)
+	data := encodeTestStream(t, reg, namedtuple.ProtocolVersionOne)
+
+	// a block of the same tuples following the stream
+	var out bytes.Buffer
+	compressor, _ := namedtuple.NewFlateCompressor(1)
+	encoder, err := namedtuple.NewBlockEncoder(&out, namedtuple.ProtocolVersionOne, compressor, 0)
+	assert.Nil(t, err)
+	dec := namedtuple.NewDecoder(reg, bytes.NewReader(data))
+	for i := 0; i < 2; i++ {
+		tuple, err := dec.Decode()
+		assert.Nil(t, err)
+		assert.Nil(t, encoder.Encode(tuple))
+	}
+	assert.Nil(t, encoder.Flush())
+	data = append(data, out.Bytes()...)
+
+	err = inspect(ioutil.Discard, data, &reg, options{MaxSize: 1024})
+	assert.EqualError(t, err, "tuple 2 at offset 46: compressed blocks can not be inspected")
+}
+
+func TestRun(t *testing.T) {
+	reg := compileTestSchema(t)
+
+	dir, err := ioutil.TempDir("", "ntool")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	schemaDir := filepath.Join(dir, "schema")
+	assert.Nil(t, os.Mkdir(schemaDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(schemaDir, "users.ent"), []byte(testSchema), 0644))
+
+	stream := filepath.Join(dir, "stream.bin")
+	assert.Nil(t, ioutil.WriteFile(stream, encodeTestStream(t, reg, namedtuple.ProtocolVersionOne), 0644))
+
+	var out bytes.Buffer
+	err = run(options{SchemaDir: schemaDir, MaxSize: 1024}, stream, &out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "users.Group")
+
+	// missing stream
+	err = run(options{SchemaDir: schemaDir, MaxSize: 1024}, filepath.Join(dir, "missing.bin"), &out)
+	assert.NotNil(t, err)
+
+	// registry snapshots
+	var snapshot bytes.Buffer
+	_, err = reg.WriteTo(&snapshot)
+	assert.Nil(t, err)
+	registry := filepath.Join(dir, "registry.bin")
+	assert.Nil(t, ioutil.WriteFile(registry, snapshot.Bytes(), 0644))
+
+	snapshot.Reset()
+	assert.Nil(t, reg.WriteJSON(&snapshot))
+	registryJSON := filepath.Join(dir, "registry.json")
+	assert.Nil(t, ioutil.WriteFile(registryJSON, snapshot.Bytes(), 0644))
+
+	for _, file := range []string{registry, registryJSON} {
+		out.Reset()
+		err = run(options{Registry: file, MaxSize: 1024}, stream, &out)
+		assert.Nil(t, err)
+		assert.Contains(t, out.String(), "users.Group")
+	}
+
+	// self-describing streams need neither
+	var schemaStream bytes.Buffer
+	encoder, err := namedtuple.NewSchemaEncoder(&schemaStream, namedtuple.ProtocolVersionTwo, nil)
+	assert.Nil(t, err)
+	User, _ := reg.Get("users", "User")
+	user := User.Builder(make([]byte, 256))
+	user.PutString("uuid", "abc")
+	u, err := user.Build()
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(u))
+	assert.Nil(t, ioutil.WriteFile(stream, schemaStream.Bytes(), 0644))
+
+	out.Reset()
+	err = run(options{MaxSize: 1024}, stream, &out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `    uuid (StringField) = "abc"`)
+}
@@ -0,0 +1,111 @@
+// Command ntool inspects streams of encoded tuples.
+//
+// Usage:
+//
+//	ntool -schema ./schemas stream.bin
+//	ntool -schema ./schemas -json -type users.User stream.bin
+//	ntool -schema ./schemas -hex stream.bin
+//	ntool -registry registry.json stream.bin
+//	ntool self-describing.bin
+//
+// The tuple types are compiled from the .ent files in the schema directory
+// or loaded from a registry snapshot written by Registry.WriteTo, or by
+// Registry.WriteJSON if the file name ends with .json. Without -schema and
+// -registry, the stream is read as a self-describing stream written by a
+// schema encoder and the types are read from its schema frames, which are
+// not printed themselves. Each tuple in the
+// stream is decoded using those types. For every tuple the protocol header,
+// the tuple version, the hashes, the field offsets and the decoded field
+// values are printed. With -json each tuple is printed as
+// a single line JSON object and with -hex the raw bytes are printed with
+// annotations for the headers and the field type codes. Use - to read the
+// stream from stdin.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/blacklabeldata/namedtuple/schema"
+)
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.SchemaDir, "schema", "", "directory containing .ent schema files")
+	flag.StringVar(&opts.Registry, "registry", "", "registry snapshot file (binary or .json)")
+	flag.Uint64Var(&opts.MaxSize, "max", 1<<20, "maximum size of a tuple in bytes")
+	flag.BoolVar(&opts.JSON, "json", false, "print each tuple as a JSON object")
+	flag.BoolVar(&opts.Hex, "hex", false, "print an annotated hexdump of each tuple")
+	flag.StringVar(&opts.TypeName, "type", "", "only print tuples of this type (name or namespace.name)")
+	flag.Parse()
+
+	if (opts.SchemaDir != "" && opts.Registry != "") || flag.NArg() != 1 || (opts.JSON && opts.Hex) {
+		fmt.Fprintln(os.Stderr, "ntool: a stream file is required, -schema and -registry as well as -json and -hex are exclusive")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(opts, flag.Arg(0), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ntool:", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options, file string, w io.Writer) error {
+	reg, err := loadRegistry(opts)
+	if err != nil {
+		return err
+	}
+
+	// read stream
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return inspect(w, data, reg, opts)
+}
+
+// loadRegistry reads the registry snapshot or compiles the schema files. If neither is given, the registry is nil and the types are read from the stream.
+func loadRegistry(opts options) (*namedtuple.Registry, error) {
+	if opts.SchemaDir == "" && opts.Registry == "" {
+		return nil, nil
+	} else if opts.Registry != "" {
+		f, err := os.Open(opts.Registry)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if strings.HasSuffix(opts.Registry, ".json") {
+			return namedtuple.ReadRegistryJSON(f)
+		}
+		return namedtuple.ReadRegistry(f)
+	}
+
+	// load and compile schema files
+	pkgList := schema.NewPackageList()
+	if err := schema.LoadDirectory(opts.SchemaDir, schema.NewParser(pkgList)); err != nil {
+		return nil, err
+	}
+
+	reg := namedtuple.NewRegistry()
+	if _, err := schema.CompileAll(pkgList, &reg); err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
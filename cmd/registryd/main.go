@@ -0,0 +1,48 @@
+// Command registryd serves a schema registry over HTTP.
+//
+// Usage:
+//
+//	registryd -schema ./schemas
+//	registryd -snapshot registry.bin -addr :8080
+//	registryd -snapshot registry.json
+//
+// The tuple types are compiled from the .ent files in the schema directory
+// or loaded from a registry snapshot written by Registry.WriteTo, or by
+// Registry.WriteJSON if the file name ends with .json. A missing snapshot
+// file starts an empty registry. Clients list the namespaces, get types by
+// name or by their hashes and register new versions of types, see the
+// registry package for the endpoints. New versions must keep the existing
+// versions of a type. With -snapshot the file is rewritten after each
+// registration, types registered with -schema are only kept in memory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+)
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.SchemaDir, "schema", "", "directory containing .ent schema files")
+	flag.StringVar(&opts.Snapshot, "snapshot", "", "registry snapshot file (binary or .json)")
+	flag.StringVar(&opts.Addr, "addr", ":7420", "address to listen on")
+	flag.Parse()
+
+	if (opts.SchemaDir == "") == (opts.Snapshot == "") || flag.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "registryd: either -schema or -snapshot is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	server, err := newServer(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "registryd:", err)
+		os.Exit(1)
+	}
+
+	log.Printf("registryd: listening on %s", opts.Addr)
+	log.Fatal(http.ListenAndServe(opts.Addr, server))
+}
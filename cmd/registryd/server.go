@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/blacklabeldata/namedtuple/registry"
+	"github.com/blacklabeldata/namedtuple/schema"
+)
+
+// options are the command line flags of registryd
+type options struct {
+	SchemaDir string
+	Snapshot  string
+	Addr      string
+}
+
+// newServer loads the registry and creates the server. Registrations are saved to the snapshot file if there is one.
+func newServer(opts options) (*registry.Server, error) {
+	reg, err := loadRegistry(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	server := registry.NewServer(reg)
+	if opts.Snapshot != "" {
+		server.Save = func(reg *namedtuple.Registry) error {
+			return saveSnapshot(opts.Snapshot, reg)
+		}
+	}
+	return server, nil
+}
+
+// loadRegistry reads the registry snapshot or compiles the schema files
+func loadRegistry(opts options) (*namedtuple.Registry, error) {
+	if opts.Snapshot != "" {
+		f, err := os.Open(opts.Snapshot)
+		if os.IsNotExist(err) {
+			reg := namedtuple.NewRegistry()
+			return &reg, nil
+		} else if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if strings.HasSuffix(opts.Snapshot, ".json") {
+			return namedtuple.ReadRegistryJSON(f)
+		}
+		return namedtuple.ReadRegistry(f)
+	}
+
+	// load and compile schema files
+	pkgList := schema.NewPackageList()
+	if err := schema.LoadDirectory(opts.SchemaDir, schema.NewParser(pkgList)); err != nil {
+		return nil, err
+	}
+
+	reg := namedtuple.NewRegistry()
+	if _, err := schema.CompileAll(pkgList, &reg); err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+// saveSnapshot writes the registry to a temporary file which replaces the snapshot, so the snapshot is never partially written
+func saveSnapshot(path string, reg *namedtuple.Registry) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := writeSnapshot(f, path, reg); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// writeSnapshot writes the registry as JSON if the snapshot file name ends with .json
+func writeSnapshot(w io.Writer, path string, reg *namedtuple.Registry) error {
+	if strings.HasSuffix(path, ".json") {
+		return reg.WriteJSON(w)
+	}
+	_, err := reg.WriteTo(w)
+	return err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/blacklabeldata/namedtuple/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `
+package users
+
+type User {
+    version 1 {
+        required string uuid
+        optional uint8 age
+    }
+}
+
+type Group {
+    version 1 {
+        required string name
+    }
+}
+`
+
+func TestSchemaServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registryd")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "users.ent"), []byte(testSchema), 0644))
+
+	server, err := newServer(options{SchemaDir: dir})
+	assert.Nil(t, err)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := registry.NewClient(ts.URL, nil)
+	namespaces, err := client.Namespaces()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"users"}, namespaces)
+
+	Group, err := client.Get("users", "Group")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, Group.NumVersions())
+
+	// invalid schema files
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "invalid.ent"), []byte("package"), 0644))
+	_, err = newServer(options{SchemaDir: dir})
+	assert.NotNil(t, err)
+}
+
+func TestSnapshotServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registryd")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"registry.bin", "registry.json"} {
+		snapshot := filepath.Join(dir, name)
+
+		// a missing snapshot starts an empty registry
+		server, err := newServer(options{Snapshot: snapshot})
+		assert.Nil(t, err)
+		ts := httptest.NewServer(server)
+
+		Message := namedtuple.New("chat", "Message")
+		Message.AddVersion(namedtuple.Field{Name: "text", Required: true, Type: namedtuple.StringField})
+		_, err = registry.NewClient(ts.URL, nil).Register(Message)
+		assert.Nil(t, err)
+		ts.Close()
+
+		// registrations are saved
+		reg, err := loadRegistry(options{Snapshot: snapshot})
+		assert.Nil(t, err)
+		assert.True(t, reg.ContainsName("chat", "Message"))
+
+		// temporary files are removed
+		files, _ := ioutil.ReadDir(dir)
+		assert.Equal(t, 1, len(files))
+		os.Remove(snapshot)
+	}
+
+	// invalid snapshots
+	snapshot := filepath.Join(dir, "registry.json")
+	assert.Nil(t, ioutil.WriteFile(snapshot, []byte("{"), 0644))
+	_, err = newServer(options{Snapshot: snapshot})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,158 @@
+package namedtuple
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sync"
+)
+
+var (
+	// ErrUnknownCompressor is returned when decoding a compressed block with a compressor code which is not registered.
+	ErrUnknownCompressor = errors.New("Unknown block compressor")
+
+	// ErrCompressorExists is returned from `RegisterCompressor` if a compressor with the same code is already registered.
+	ErrCompressorExists = errors.New("Block compressor is already registered")
+
+	// ErrInvalidBlock is returned if a compressed block does not decompress to the length stored in the block header.
+	ErrInvalidBlock = errors.New("Invalid compressed block")
+)
+
+const (
+	// FlateCompression is the compressor code of the `compress/flate` compressor.
+	FlateCompression uint8 = 1
+
+	// GzipCompression is the compressor code of the `compress/gzip` compressor.
+	GzipCompression uint8 = 2
+)
+
+// Compressor compresses the blocks written by a block encoder. The code of the compressor is stored in each block, so decoders can find the compressor in the registered compressors. Compressors must be safe for concurrent use.
+type Compressor interface {
+
+	// Code identifies the compressor. Codes up to 31 are reserved for the compressors of this package.
+	Code() uint8
+
+	// Compress appends the compressed data to dst and returns the extended buffer.
+	Compress(dst, src []byte) ([]byte, error)
+
+	// Decompress decompresses the data into dst, which has the length of the uncompressed data. `ErrInvalidBlock` is returned if the data does not decompress to the length of dst.
+	Decompress(dst, src []byte) error
+}
+
+var (
+	// compressors maps the compressor codes to the compressors used by decoders
+	compressors = map[uint8]Compressor{
+		FlateCompression: &flateCompressor{level: flate.DefaultCompression},
+		GzipCompression:  &flateCompressor{level: gzip.DefaultCompression, gzip: true},
+	}
+
+	// compressorsMutex guards the registered compressors
+	compressorsMutex sync.RWMutex
+)
+
+// RegisterCompressor registers a compressor for decoding blocks with its code. The flate and gzip compressors are always registered. `ErrCompressorExists` is returned if the code is already used.
+func RegisterCompressor(c Compressor) error {
+	compressorsMutex.Lock()
+	defer compressorsMutex.Unlock()
+
+	if _, exists := compressors[c.Code()]; exists {
+		return ErrCompressorExists
+	}
+	compressors[c.Code()] = c
+	return nil
+}
+
+// lookupCompressor returns the registered compressor with the given code.
+func lookupCompressor(code uint8) (Compressor, bool) {
+	compressorsMutex.RLock()
+	defer compressorsMutex.RUnlock()
+
+	c, exists := compressors[code]
+	return c, exists
+}
+
+// NewFlateCompressor creates a compressor using `compress/flate` with the given compression level. An error is returned for invalid levels.
+func NewFlateCompressor(level int) (Compressor, error) {
+	if _, err := flate.NewWriter(nil, level); err != nil {
+		return nil, err
+	}
+	return &flateCompressor{level: level}, nil
+}
+
+// NewGzipCompressor creates a compressor using `compress/gzip` with the given compression level. Gzip adds a header and a CRC32 checksum to the flate data of each block. An error is returned for invalid levels.
+func NewGzipCompressor(level int) (Compressor, error) {
+	if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+		return nil, err
+	}
+	return &flateCompressor{level: level, gzip: true}, nil
+}
+
+// flateCompressor compresses blocks with flate or gzip. The writers are pooled as they allocate large tables.
+type flateCompressor struct {
+	level   int
+	gzip    bool
+	writers sync.Pool
+}
+
+// resetWriter is implemented by the flate and gzip writers
+type resetWriter interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+// Code returns `GzipCompression` or `FlateCompression`.
+func (c *flateCompressor) Code() uint8 {
+	if c.gzip {
+		return GzipCompression
+	}
+	return FlateCompression
+}
+
+// Compress appends the compressed data to dst.
+func (c *flateCompressor) Compress(dst, src []byte) ([]byte, error) {
+	buffer := bytes.NewBuffer(dst)
+
+	var w resetWriter
+	if pooled := c.writers.Get(); pooled != nil {
+		w = pooled.(resetWriter)
+		w.Reset(buffer)
+	} else if c.gzip {
+		w, _ = gzip.NewWriterLevel(buffer, c.level)
+	} else {
+		w, _ = flate.NewWriter(buffer, c.level)
+	}
+	defer c.writers.Put(w)
+
+	if _, err := w.Write(src); err != nil {
+		return dst, err
+	}
+	if err := w.Close(); err != nil {
+		return dst, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decompress decompresses the data into dst.
+func (c *flateCompressor) Decompress(dst, src []byte) error {
+	var r io.ReadCloser
+	if c.gzip {
+		var err error
+		if r, err = gzip.NewReader(bytes.NewReader(src)); err != nil {
+			return ErrInvalidBlock
+		}
+	} else {
+		r = flate.NewReader(bytes.NewReader(src))
+	}
+	defer r.Close()
+
+	// the data must end after the uncompressed length
+	var extra [1]byte
+	if _, err := io.ReadFull(r, dst); err != nil {
+		return ErrInvalidBlock
+	} else if n, err := r.Read(extra[:]); n != 0 || err != io.EOF {
+		return ErrInvalidBlock
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package namedtuple
+
+import (
+	"bytes"
+	"compress/flate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// identityCompressor stores blocks without compression
+type identityCompressor struct{}
+
+func (identityCompressor) Code() uint8 { return 32 }
+
+func (identityCompressor) Compress(dst, src []byte) ([]byte, error) {
+	return append(dst, src...), nil
+}
+
+func (identityCompressor) Decompress(dst, src []byte) error {
+	if len(dst) != len(src) {
+		return ErrInvalidBlock
+	}
+	copy(dst, src)
+	return nil
+}
+
+func TestCompressors(t *testing.T) {
+	flateCompressor, err := NewFlateCompressor(flate.BestCompression)
+	assert.Nil(t, err)
+	assert.Equal(t, FlateCompression, flateCompressor.Code())
+
+	gzipCompressor, err := NewGzipCompressor(flate.BestSpeed)
+	assert.Nil(t, err)
+	assert.Equal(t, GzipCompression, gzipCompressor.Code())
+
+	data := bytes.Repeat([]byte("namedtuple"), 100)
+	for _, c := range []Compressor{flateCompressor, gzipCompressor} {
+
+		// compressed data is appended
+		compressed, err := c.Compress([]byte{1, 2}, data)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte{1, 2}, compressed[:2])
+		assert.True(t, len(compressed) < len(data))
+
+		// pooled writers are reset
+		again, err := c.Compress(nil, data)
+		assert.Nil(t, err)
+		assert.Equal(t, compressed[2:], again)
+
+		decompressed := make([]byte, len(data))
+		assert.Nil(t, c.Decompress(decompressed, again))
+		assert.Equal(t, data, decompressed)
+
+		// the data must decompress to the given length
+		assert.Equal(t, ErrInvalidBlock, c.Decompress(make([]byte, len(data)-1), again))
+		assert.Equal(t, ErrInvalidBlock, c.Decompress(make([]byte, len(data)+1), again))
+		assert.Equal(t, ErrInvalidBlock, c.Decompress(decompressed, again[:len(again)/2]))
+	}
+
+	_, err = NewFlateCompressor(42)
+	assert.NotNil(t, err)
+	_, err = NewGzipCompressor(42)
+	assert.NotNil(t, err)
+}
+
+func TestRegisterCompressor(t *testing.T) {
+	defer func() {
+		compressorsMutex.Lock()
+		delete(compressors, identityCompressor{}.Code())
+		compressorsMutex.Unlock()
+	}()
+
+	assert.Nil(t, RegisterCompressor(identityCompressor{}))
+	assert.Equal(t, ErrCompressorExists, RegisterCompressor(identityCompressor{}))
+
+	c, exists := lookupCompressor(32)
+	assert.True(t, exists)
+	assert.Equal(t, identityCompressor{}, c)
+
+	gzipCompressor, _ := NewGzipCompressor(flate.BestSpeed)
+	assert.Equal(t, ErrCompressorExists, RegisterCompressor(gzipCompressor))
+}
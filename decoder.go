@@ -2,9 +2,11 @@ package namedtuple
 
 import (
 	"bufio"
-	"bytes"
+	"encoding/binary"
 	"errors"
+	"hash/crc32"
 	"io"
+	"math"
 
 	"github.com/blacklabeldata/xbinary"
 )
@@ -26,6 +28,15 @@ var (
 	// ErrInvalidLength is returned if the byte count for the length is not 1, 2, 4 or 8.
 	ErrInvalidLength = errors.New("Invalid Tuple Size: tuple length must be encoded as 1,2,4 or 8 bytes")
 
+	// ErrChecksumMismatch is returned from Decode() if the CRC32C checksum of a version 2 tuple does not match its content.
+	ErrChecksumMismatch = errors.New("Tuple checksum does not match")
+
+	// ErrSchemaMismatch is returned from Decode() if the schema fingerprint of a version 2 tuple does not match the registered tuple type.
+	ErrSchemaMismatch = errors.New("Tuple schema fingerprint does not match the registered tuple type")
+
+	// ErrUnsupportedProtocolFlags is returned from Decode() if a version 2 tuple uses protocol flags which are not supported.
+	ErrUnsupportedProtocolFlags = errors.New("Unsupported protocol flags in Tuple header")
+
 	// EmptyTuple is returned along with an error from the Decode() method.
 	EmptyTuple = Tuple{}
 )
@@ -41,8 +52,8 @@ const (
 
 // Create a reader which reads the first byte and the content length.
 // If the length exceeds the maxSize, return an error
-// Create a bytes.Buffer and io.CopyN(contentLength) into the buffer
-// Based on the protocol version, decode(buffer.Bytes()) into (Tuple, error)
+// Read contentLength bytes into a new slice
+// Based on the protocol version, decode the content into (Tuple, error)
 
 // decoder := NewDecoder(reg, 65536)
 // for _, tup, err := decoder.Decode(reader); err != nil {
@@ -55,71 +66,200 @@ type Decoder interface {
 
 // NewDecoder creates a new Decoder using a type Registry and an io.Reader.
 func NewDecoder(reg Registry, r io.Reader) Decoder {
-	var buf []byte
-	return decoder{reg, DefaultMaxSize, bytes.NewBuffer(buf), bufio.NewReader(r)}
+	return decoder{reg, DefaultMaxSize, &streamReader{Reader: bufio.NewReader(r)}}
 }
 
 // NewDecoderSize creates a new Decoder using a type Registry, a max size and an io.Reader.
 func NewDecoderSize(reg Registry, maxSize uint64, r io.Reader) Decoder {
-	var buf []byte
-	return decoder{reg, maxSize, bytes.NewBuffer(buf), bufio.NewReader(r)}
+	return decoder{reg, maxSize, &streamReader{Reader: bufio.NewReader(r)}}
+}
+
+// SchemaDecoder decodes self-describing streams written by a schema encoder. See `NewSchemaEncoder`.
+type SchemaDecoder interface {
+	Decoder
+
+	// Registry returns the private registry of the decoder, which contains the tuple types read from the stream so far. The registry is needed to read nested tuples.
+	Registry() *Registry
+}
+
+// NewSchemaDecoder creates a decoder for self-describing streams. The tuple types are registered from the schema frames in the stream into a private registry, so the stream can be read without the code which wrote it. If a schema frame contains a type which was already read with different versions, the type is replaced. Tuples of types without a schema frame before them return `ErrUnknownTupleType`.
+func NewSchemaDecoder(maxSize uint64, r io.Reader) SchemaDecoder {
+	reg := NewRegistry()
+	return decoder{maxSize: maxSize, reader: &streamReader{Reader: bufio.NewReader(r), schemas: &reg}}
 }
 
 type decoder struct {
 	reg     Registry
 	maxSize uint64
-	buffer  *bytes.Buffer
-	reader  *bufio.Reader
+	reader  *streamReader
+}
+
+// streamReader reads from the input and keeps the frames remaining in the last compressed block. The registry of a schema decoder is kept as well, as the decoder is copied on each call.
+type streamReader struct {
+	*bufio.Reader
+	block   []byte
+	schemas *Registry
 }
 
+// Decode reads the next tuple from the reader. Each tuple is read into a new slice, so tuples returned from earlier calls are not modified. Compressed blocks written by a `BlockEncoder` are decompressed and their tuples are returned one at a time. Schema frames are only read by schema decoders and skipped by other decoders.
 func (d decoder) Decode() (Tuple, error) {
+	for {
+		version, content, inBlock, err := d.nextFrame()
+		if err != nil {
+			return EmptyTuple, err
+		}
+
+		switch {
+		case version == ProtocolVersionThree && !inBlock:
+			d.reader.block, err = decodeBlock(content, d.maxSize)
+		case isSchemaFrame(version, content):
+			err = d.registerSchema(content)
+		default:
+			return decodeContent(d.registry(), version, content)
+		}
+
+		if err != nil {
+			return EmptyTuple, err
+		}
+	}
+}
+
+// Registry returns the registry used to resolve tuple types. For schema decoders, this is the registry with the types read from the stream.
+func (d decoder) Registry() *Registry {
+	return d.registry()
+}
+
+func (d decoder) registry() *Registry {
+	if d.reader.schemas != nil {
+		return d.reader.schemas
+	}
+	return &d.reg
+}
+
+// registerSchema registers the tuple type of a schema frame in the registry of a schema decoder.
+func (d decoder) registerSchema(content []byte) error {
+	if d.reader.schemas == nil {
+		return nil
+	}
+
+	schema, err := parseVersionTwoFrame(&schemaRegistry, ProtocolVersionTwo, content, SchemaFlag)
+	if err != nil {
+		return err
+	}
+	t, err := decodeType(schema)
+	if err != nil {
+		return err
+	}
+
+	// Replace types with different versions
+	if existing, exists := d.reader.schemas.Get(t.Namespace, t.Name); exists {
+		if existing.Fingerprint(existing.NumVersions()) == t.Fingerprint(t.NumVersions()) {
+			return nil
+		}
+		return d.reader.schemas.Replace(existing, t)
+	}
+	return d.reader.schemas.Register(t)
+}
+
+// nextFrame returns the next frame of the last compressed block or reads the next frame from the input.
+func (d decoder) nextFrame() (version uint8, content []byte, inBlock bool, err error) {
+	if len(d.reader.block) == 0 {
+		version, content, err = d.readFrame()
+		return version, content, false, err
+	}
+
+	if version, content, d.reader.block, err = sliceFrame(d.reader.block); err != nil {
+		d.reader.block = nil
+	}
+	return version, content, true, err
+}
+
+// readFrame reads the protocol header and the content of the next frame.
+func (d decoder) readFrame() (version uint8, content []byte, err error) {
 
 	// Reads the protocol header
 	pH, err := d.reader.ReadByte()
 	if err != nil {
-		return EmptyTuple, err
+		return 0, nil, err
 	}
 
 	// Parse nuber of length bytes and version
 	byteCount, version := ParseProtocolHeader(pH)
 
 	// Read bytes for content length
-	b := make([]byte, byteCount)
-	n, err := d.reader.Read(b)
-	if err != nil {
-		return EmptyTuple, err
-	} else if n != int(byteCount) {
-		return EmptyTuple, io.ErrUnexpectedEOF
+	var b [8]byte
+	if _, err := io.ReadFull(d.reader, b[:byteCount]); err != nil {
+		return 0, nil, err
 	}
 
 	// Parse content length based on number of bytes
-	length, err := d.parseLength(byteCount, b)
+	length, err := d.parseLength(byteCount, b[:byteCount])
 	if err != nil {
 		// This should not happen as the
 		// Read call above also checks for length.
-		return EmptyTuple, err
+		return 0, nil, err
 	}
 
 	// Verify length against maxSize
 	if length > d.maxSize {
-		return EmptyTuple, ErrTupleExceedsMaxSize
+		return 0, nil, ErrTupleExceedsMaxSize
 	}
 
-	// Copy Length bytes into buffer
-	if _, err := io.CopyN(d.buffer, d.reader, int64(length)); err != nil {
-		return EmptyTuple, err
+	// Read content
+	content = make([]byte, length)
+	if _, err := io.ReadFull(d.reader, content); err != nil {
+		return 0, nil, err
 	}
+	return version, content, nil
+}
 
-	// Depending on the protocol version, parse the tuple
-	switch version {
-	case 1:
-		return d.parseVersionOneTuple(byteCount, version, length)
-	default:
-		return EmptyTuple, ErrInvalidProtocolVersion
+func (d decoder) parseLength(byteCount uint8, buf []byte) (l uint64, err error) {
+	return parseLength(byteCount, buf)
+}
+
+// DecodeBytes decodes the first tuple in the buffer. The tuple data points into the buffer, so no data is copied. The remaining bytes after the tuple are returned so consecutive tuples can be decoded, for example from a memory mapped file. If the buffer is empty, `io.EOF` is returned. If the buffer ends before the tuple, `io.ErrUnexpectedEOF` is returned. Schema frames written by `NewSchemaEncoder` are skipped, the types must be registered in the registry. Compressed blocks are only supported by `Decoder`, for blocks `ErrUnexpectedBlock` is returned.
+func DecodeBytes(reg *Registry, buffer []byte) (t Tuple, rest []byte, err error) {
+	version, content, rest, err := sliceFrame(buffer)
+	for err == nil && isSchemaFrame(version, content) {
+		version, content, rest, err = sliceFrame(rest)
+	}
+	if err != nil {
+		return EmptyTuple, buffer, err
 	}
+
+	t, err = decodeContent(reg, version, content)
+	if err != nil {
+		return EmptyTuple, buffer, err
+	}
+	return t, rest, nil
 }
 
-func (d decoder) parseLength(byteCount uint8, buf []byte) (l uint64, err error) {
+// sliceFrame returns the protocol version and the content of the first frame in the buffer as well as the remaining bytes. If the buffer is empty, `io.EOF` is returned. If the buffer ends before the frame, `io.ErrUnexpectedEOF` is returned.
+func sliceFrame(buffer []byte) (version uint8, content, rest []byte, err error) {
+	if len(buffer) == 0 {
+		return 0, nil, buffer, io.EOF
+	}
+
+	// Parse protocol header and content length
+	byteCount, version := ParseProtocolHeader(buffer[0])
+	if len(buffer) < 1+int(byteCount) {
+		return 0, nil, buffer, io.ErrUnexpectedEOF
+	}
+	length, err := parseLength(byteCount, buffer[1:1+byteCount])
+	if err != nil {
+		return 0, nil, buffer, err
+	}
+
+	// Slice content
+	start := 1 + uint64(byteCount)
+	if length > uint64(len(buffer))-start {
+		return 0, nil, buffer, io.ErrUnexpectedEOF
+	}
+	end := start + length
+	return version, buffer[start:end:end], buffer[end:], nil
+}
+
+func parseLength(byteCount uint8, buf []byte) (l uint64, err error) {
 	switch byteCount {
 	case 1:
 		if len(buf) == 1 {
@@ -152,89 +292,133 @@ func (d decoder) parseLength(byteCount uint8, buf []byte) (l uint64, err error)
 	return
 }
 
-func (d decoder) parseVersionOneTuple(offsetSize uint8, protocolVersion uint8, length uint64) (t Tuple, err error) {
-	buffer := d.buffer.Bytes()
-	var namespaceHash, typeHash, fieldCount uint32
-	var version uint8
+// decodeContent parses the content following the protocol header. Depending on the protocol version, the content is parsed into a tuple which points into the given buffer.
+func decodeContent(reg *Registry, version uint8, buffer []byte) (Tuple, error) {
+	switch version {
+	case ProtocolVersionOne:
+		return parseVersionOneTuple(reg, version, buffer)
+	case ProtocolVersionTwo:
+		return parseVersionTwoTuple(reg, version, buffer)
+	case ProtocolVersionThree:
+		return EmptyTuple, ErrUnexpectedBlock
+	default:
+		return EmptyTuple, ErrInvalidProtocolVersion
+	}
+}
+
+func parseVersionOneTuple(reg *Registry, protocolVersion uint8, buffer []byte) (t Tuple, err error) {
+	return resolveTuple(reg, protocolVersion, buffer)
+}
 
-	// The buffer needs to be at least 13 bytes. This includes the uint8 tuple version, the uint32 namespace and type hashes and the field count
-	if len(buffer) < VersionOneTupleHeaderSize {
+func parseVersionTwoTuple(reg *Registry, protocolVersion uint8, buffer []byte) (t Tuple, err error) {
+	return parseVersionTwoFrame(reg, protocolVersion, buffer, 0)
+}
+
+// isSchemaFrame returns true if the content is a version 2 frame with the schema flag.
+func isSchemaFrame(version uint8, content []byte) bool {
+	return version == ProtocolVersionTwo && len(content) > 0 && content[0] == SchemaFlag
+}
+
+// parseVersionTwoFrame parses a version 2 frame with the given protocol flags. Frames with other flags return `ErrUnsupportedProtocolFlags`.
+func parseVersionTwoFrame(reg *Registry, protocolVersion uint8, buffer []byte, flags uint8) (t Tuple, err error) {
+
+	// The buffer needs to include the flags, the fingerprint and the checksum
+	if len(buffer) < VersionTwoPrefixSize+ChecksumSize {
 		return EmptyTuple, ErrTupleLengthTooSmall
 	}
 
-	// Read Tuple version
-	version = buffer[0]
+	// Verify checksum
+	content := buffer[:len(buffer)-ChecksumSize]
+	if crc32.Checksum(content, castagnoli) != binary.LittleEndian.Uint32(buffer[len(content):]) {
+		return EmptyTuple, ErrChecksumMismatch
+	}
 
-	// Read namespace hash
-	namespaceHash, err = xbinary.LittleEndian.Uint32(buffer, 1)
-	if err != nil {
-		// Should not occur as buffer length has already been validated
-		return EmptyTuple, err
+	// Schema frames are only read by schema decoders
+	if content[0] != flags {
+		return EmptyTuple, ErrUnsupportedProtocolFlags
 	}
+	fingerprint := binary.LittleEndian.Uint64(content[1:])
 
-	// Read type hash
-	typeHash, err = xbinary.LittleEndian.Uint32(buffer, 5)
+	// Parse tuple
+	t, err = resolveTuple(reg, protocolVersion, content[VersionTwoPrefixSize:])
 	if err != nil {
-		// Should not occur as buffer length has already been validated
 		return EmptyTuple, err
 	}
 
-	// Check if known tuple type
-	tupleType, exists := d.reg.GetWithHash(namespaceHash, typeHash)
-	if !exists {
-		return EmptyTuple, ErrUnknownTupleType
+	// Verify the registered type has the same versions the tuple was written with
+	version := int(t.Header.TupleVersion)
+	if version > t.Header.Type.NumVersions() || t.Header.Type.Fingerprint(version) != fingerprint {
+		return EmptyTuple, ErrSchemaMismatch
 	}
+	return t, nil
+}
+
+// resolveTuple parses the tuple header and resolves the tuple type using the registry.
+func resolveTuple(reg *Registry, protocolVersion uint8, buffer []byte) (t Tuple, err error) {
 
-	// Read field count
-	fieldCount, err = xbinary.LittleEndian.Uint32(buffer, 9)
+	// Parse tuple header
+	header, pos, err := parseTupleHeader(buffer)
 	if err != nil {
-		// Should not occur as buffer length has already been validated
 		return EmptyTuple, err
 	}
 
-	// Read field offsets
-	offsets, err := readFieldOffsets(offsetSize, fieldCount, buffer)
-	if err != nil {
-		return EmptyTuple, err
+	// Check if known tuple type. The type ID is used if it is present
+	// in the header, otherwise the 32-bit hashes are used.
+	var tupleType TupleType
+	var exists bool
+	if header.TypeID != 0 {
+		tupleType, exists = reg.GetWithID(header.TypeID)
+		exists = exists && tupleType.NamespaceHash == header.NamespaceHash && tupleType.Hash == header.Hash
+	} else {
+		tupleType, exists = reg.GetWithHash(header.NamespaceHash, header.Hash)
+	}
+	if !exists {
+		return EmptyTuple, ErrUnknownTupleType
 	}
 
 	// Slice tuple data
-	pos := VersionOneTupleHeaderSize + int(fieldCount)*int(offsetSize)
 	t.data = buffer[pos:]
 
-	// Create TupleHeader
-	t.Header = TupleHeader{
-		ProtocolVersion: protocolVersion,
-		TupleVersion:    version,
-		NamespaceHash:   namespaceHash,
-		Hash:            typeHash,
-		FieldCount:      fieldCount,
-		FieldSize:       offsetSize,
-		ContentLength:   uint64(len(t.data)),
-		Offsets:         offsets,
-		Type:            tupleType,
+	// Set the protocol version and tuple type
+	header.ProtocolVersion = protocolVersion
+	header.Type = tupleType
+	t.Header = header
+
+	// Reject values which are not declared by closed enums
+	if err = t.checkEnums(); err != nil {
+		return EmptyTuple, err
+	}
+
+	// Reject tuples with more than one field of a oneof group
+	if err = t.checkOneOfs(); err != nil {
+		return EmptyTuple, err
 	}
 	return
 }
 
 func readFieldOffsets(byteCount uint8, fieldCount uint32, buffer []byte) ([]uint64, error) {
+	return readFieldOffsetsAt(byteCount, fieldCount, buffer, VersionOneTupleHeaderSize)
+}
+
+// readFieldOffsetsAt reads the field offsets starting at the given position in the buffer.
+func readFieldOffsetsAt(byteCount uint8, fieldCount uint32, buffer []byte, pos int) ([]uint64, error) {
 	offsets := make([]uint64, int(fieldCount))
 	var err error
 	switch byteCount {
 	case 1:
 		// Check buffer length
-		if len(buffer) < int(fieldCount)+VersionOneTupleHeaderSize {
+		if len(buffer) < int(fieldCount)+pos {
 			err = ErrTupleLengthTooSmall
 		} else {
 
 			// Process offsets
-			for i := VersionOneTupleHeaderSize; i < int(fieldCount)+VersionOneTupleHeaderSize; i++ {
-				offsets[i-VersionOneTupleHeaderSize] = uint64(buffer[i])
+			for i := pos; i < int(fieldCount)+pos; i++ {
+				offsets[i-pos] = uint64(buffer[i])
 			}
 		}
 	case 2:
 		o := make([]uint16, int(fieldCount))
-		err = xbinary.LittleEndian.Uint16Array(buffer, VersionOneTupleHeaderSize, &o)
+		err = xbinary.LittleEndian.Uint16Array(buffer, pos, &o)
 		if err == nil {
 			for i, offset := range o {
 				offsets[i] = uint64(offset)
@@ -242,7 +426,7 @@ func readFieldOffsets(byteCount uint8, fieldCount uint32, buffer []byte) ([]uint
 		}
 	case 4:
 		o := make([]uint32, int(fieldCount))
-		err = xbinary.LittleEndian.Uint32Array(buffer, VersionOneTupleHeaderSize, &o)
+		err = xbinary.LittleEndian.Uint32Array(buffer, pos, &o)
 		if err == nil {
 			for i, offset := range o {
 				offsets[i] = uint64(offset)
@@ -250,7 +434,7 @@ func readFieldOffsets(byteCount uint8, fieldCount uint32, buffer []byte) ([]uint
 		}
 	case 8:
 		o := make([]uint64, int(fieldCount))
-		err = xbinary.LittleEndian.Uint64Array(buffer, VersionOneTupleHeaderSize, &o)
+		err = xbinary.LittleEndian.Uint64Array(buffer, pos, &o)
 		if err == nil {
 			for i, offset := range o {
 				offsets[i] = uint64(offset)
@@ -259,5 +443,16 @@ func readFieldOffsets(byteCount uint8, fieldCount uint32, buffer []byte) ([]uint
 	default:
 		err = ErrInvalidLength
 	}
+
+	// Optional fields which were not written are encoded with the
+	// maximum value for the offset size. Widen them to math.MaxUint64.
+	if err == nil {
+		missing := uint64(1)<<(8*uint(byteCount)) - 1
+		for i, offset := range offsets {
+			if offset == missing {
+				offsets[i] = math.MaxUint64
+			}
+		}
+	}
 	return offsets, err
 }
@@ -2,6 +2,7 @@ package namedtuple
 
 import (
 	"bytes"
+	"hash/crc32"
 	"io"
 	"testing"
 
@@ -32,7 +33,7 @@ func TestNewDecoderSize(t *testing.T) {
 }
 
 func TestDecoderParseLength8(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	var buf = []byte{5}
@@ -44,7 +45,7 @@ func TestDecoderParseLength8(t *testing.T) {
 }
 
 func TestDecoderParseLength8Fail(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	var buf []byte
@@ -56,7 +57,7 @@ func TestDecoderParseLength8Fail(t *testing.T) {
 }
 
 func TestDecoderParseLength16(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	buf := make([]byte, 2)
@@ -69,7 +70,7 @@ func TestDecoderParseLength16(t *testing.T) {
 }
 
 func TestDecoderParseLength16Fail(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	var buf []byte
@@ -81,7 +82,7 @@ func TestDecoderParseLength16Fail(t *testing.T) {
 }
 
 func TestDecoderParseLength32(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	buf := make([]byte, 4)
@@ -94,7 +95,7 @@ func TestDecoderParseLength32(t *testing.T) {
 }
 
 func TestDecoderParseLength32Fail(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	var buf []byte
@@ -106,7 +107,7 @@ func TestDecoderParseLength32Fail(t *testing.T) {
 }
 
 func TestDecoderParseLength64(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	buf := make([]byte, 8)
@@ -119,7 +120,7 @@ func TestDecoderParseLength64(t *testing.T) {
 }
 
 func TestDecoderParseLength64Fail(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	var buf []byte
@@ -131,7 +132,7 @@ func TestDecoderParseLength64Fail(t *testing.T) {
 }
 
 func TestDecoderParseLengthFail(t *testing.T) {
-	d := decoder{DefaultRegistry, 512, nil, nil}
+	d := decoder{DefaultRegistry, 512, nil}
 
 	// Create buffer
 	var buf []byte
@@ -244,7 +245,7 @@ func TestDecoder_ParseVersionOneTuple(t *testing.T) {
 	assert.True(t, ok)
 
 	// Parse tuple
-	tup, err := d.parseVersionOneTuple(0, 0, 0)
+	tup, err := parseVersionOneTuple(&d.reg, 0, nil)
 	assert.Equal(t, EmptyTuple, tup)
 	assert.NotNil(t, err)
 	assert.Equal(t, ErrTupleLengthTooSmall, err)
@@ -412,3 +413,350 @@ func TestDecode(t *testing.T) {
 	assert.True(t, bytes.Equal(msg.data, message.data))
 	assert.Equal(t, msg.Header, message.Header)
 }
+
+func TestDecodeTypeID(t *testing.T) {
+	// Create encoder
+	var buf []byte
+	out := bytes.NewBuffer(buf)
+	encoder := NewTypeIDEncoder(out)
+
+	// Create location tuple
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 150.5)
+	builder.PutFloat32("lat", 50.5)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+
+	// Encode location twice
+	assert.Nil(t, encoder.Encode(loc))
+	assert.Nil(t, encoder.Encode(loc))
+
+	// Create Registry
+	reg := NewRegistry()
+	reg.Register(Location)
+
+	// Decode with type ID
+	dec := NewDecoder(reg, bytes.NewReader(out.Bytes()))
+	location, err := dec.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, Location.ID, location.Header.TypeID)
+	assert.True(t, location.Is(Location))
+
+	lat, err := location.GetFloat32("lat")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(50.5), lat)
+
+	// Unknown type ID
+	dec = NewDecoder(NewRegistry(), bytes.NewReader(out.Bytes()))
+	_, err = dec.Decode()
+	assert.Equal(t, ErrUnknownTupleType, err)
+}
+
+func encodeVersionTwo(t *testing.T, tuple Tuple) []byte {
+	var buf []byte
+	out := bytes.NewBuffer(buf)
+	encoder, err := NewEncoderVersion(out, ProtocolVersionTwo)
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(tuple))
+	return out.Bytes()
+}
+
+func TestDecodeVersionTwo(t *testing.T) {
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 150.5)
+	builder.PutFloat32("lat", 50.5)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+	b := encodeVersionTwo(t, loc)
+
+	// Create Registry
+	reg := NewRegistry()
+	reg.Register(Location)
+
+	// Decode
+	dec := NewDecoder(reg, bytes.NewReader(b))
+	location, err := dec.Decode()
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(loc.data, location.data))
+	assert.Equal(t, uint8(ProtocolVersionTwo), location.Header.ProtocolVersion)
+	assert.Equal(t, loc.Header.Offsets, location.Header.Offsets)
+
+	lon, err := location.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(150.5), lon)
+}
+
+func TestDecodeVersionTwoFailChecksum(t *testing.T) {
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 150.5)
+	builder.PutFloat32("lat", 50.5)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+
+	reg := NewRegistry()
+	reg.Register(Location)
+
+	// Corrupt each byte of the content
+	b := encodeVersionTwo(t, loc)
+	for i := 2; i < len(b); i++ {
+		corrupt := append([]byte{}, b...)
+		corrupt[i] ^= 0x10
+
+		dec := NewDecoder(reg, bytes.NewReader(corrupt))
+		_, err := dec.Decode()
+		assert.Equal(t, ErrChecksumMismatch, err)
+	}
+}
+
+func TestDecodeVersionTwoFailFlags(t *testing.T) {
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 150.5)
+	builder.PutFloat32("lat", 50.5)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+
+	reg := NewRegistry()
+	reg.Register(Location)
+
+	// Set a flag which is not defined and recalculate the checksum
+	b := encodeVersionTwo(t, loc)
+	b[2] = 128
+	checksum := crc32.Checksum(b[2:len(b)-ChecksumSize], crc32.MakeTable(crc32.Castagnoli))
+	xbinary.LittleEndian.PutUint32(b, len(b)-ChecksumSize, checksum)
+
+	dec := NewDecoder(reg, bytes.NewReader(b))
+	_, err = dec.Decode()
+	assert.Equal(t, ErrUnsupportedProtocolFlags, err)
+}
+
+func TestDecodeVersionTwoFailSchema(t *testing.T) {
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 150.5)
+	builder.PutFloat32("lat", 50.5)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+	b := encodeVersionTwo(t, loc)
+
+	// The reader's type has the same name but different fields
+	Other := New("testing", "Location")
+	Other.AddVersion(
+		Field{Name: "lat", Required: true, Type: Float32Field},
+		Field{Name: "lon", Required: true, Type: Float32Field},
+		Field{Name: "alt", Required: false, Type: Float32Field},
+	)
+	reg := NewRegistry()
+	reg.Register(Other)
+
+	dec := NewDecoder(reg, bytes.NewReader(b))
+	_, err = dec.Decode()
+	assert.Equal(t, ErrSchemaMismatch, err)
+
+	// The reader's type may have newer versions
+	Newer := createTestLocationType()
+	Newer.AddVersion(Field{Name: "name", Required: false, Type: StringField})
+	reg = NewRegistry()
+	reg.Register(Newer)
+
+	dec = NewDecoder(reg, bytes.NewReader(b))
+	_, err = dec.Decode()
+	assert.Nil(t, err)
+}
+
+func TestDecodeVersionTwoFailLength(t *testing.T) {
+	dec := NewDecoder(DefaultRegistry, bytes.NewReader([]byte{ProtocolVersionTwo, 4, 0, 0, 0, 0}))
+	_, err := dec.Decode()
+	assert.Equal(t, ErrTupleLengthTooSmall, err)
+}
+
+func TestDecodeBytes(t *testing.T) {
+	b := encodeTestLocations(t, ProtocolVersionOne, 1, 2)
+
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	// first tuple
+	loc, rest, err := DecodeBytes(&reg, b)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b)/2, len(rest))
+	lon, err := loc.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1), lon)
+
+	// the tuple points into the buffer
+	payload := loc.Payload()
+	assert.True(t, &payload[0] == &b[len(b)/2-len(payload)])
+
+	// second tuple
+	loc, rest, err = DecodeBytes(&reg, rest)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(rest))
+	lon, err = loc.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(2), lon)
+
+	// end of input
+	_, _, err = DecodeBytes(&reg, rest)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDecodeBytesFail(t *testing.T) {
+	b := encodeTestLocations(t, ProtocolVersionTwo, 1)
+
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	// truncated length
+	_, rest, err := DecodeBytes(&reg, []byte{128, 1, 2})
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Equal(t, 3, len(rest))
+
+	// truncated content
+	_, _, err = DecodeBytes(&reg, b[:len(b)-1])
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	// unknown protocol version
+	_, _, err = DecodeBytes(&reg, []byte{4, 0})
+	assert.Equal(t, ErrInvalidProtocolVersion, err)
+
+	// compressed blocks
+	_, _, err = DecodeBytes(&reg, []byte{ProtocolVersionThree, 0})
+	assert.Equal(t, ErrUnexpectedBlock, err)
+
+	// unknown type
+	empty := NewRegistry()
+	_, _, err = DecodeBytes(&empty, b)
+	assert.Equal(t, ErrUnknownTupleType, err)
+}
+
+func TestDecodeMultiple(t *testing.T) {
+	b := encodeTestLocations(t, ProtocolVersionOne, 1, 2)
+
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	// tuples from earlier calls are not modified by later calls
+	dec := NewDecoder(reg, bytes.NewReader(b))
+	first, err := dec.Decode()
+	assert.Nil(t, err)
+	second, err := dec.Decode()
+	assert.Nil(t, err)
+
+	lon, err := first.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1), lon)
+
+	lon, err = second.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(2), lon)
+
+	_, err = dec.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSchemaDecoder(t *testing.T) {
+	reg := createTestOneOfRegistry()
+	b := encodeTestPlaces(t, &reg, "home", "work")
+
+	// the types are read from the stream
+	dec := NewSchemaDecoder(DefaultMaxSize, bytes.NewReader(b))
+	for _, expected := range []string{"home", "work"} {
+		place, err := dec.Decode()
+		assert.Nil(t, err)
+
+		name, err := place.GetString("name")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, name)
+
+		address, err := place.GetTuple("address", dec.Registry())
+		assert.Nil(t, err)
+		street, err := address.GetString("street")
+		assert.Nil(t, err)
+		assert.Equal(t, "221B Baker Street", street)
+	}
+	_, err := dec.Decode()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 3, dec.Registry().Size())
+
+	// other decoders skip the schema frames
+	dec2 := NewDecoder(reg, bytes.NewReader(b))
+	place, err := dec2.Decode()
+	assert.Nil(t, err)
+	name, _ := place.GetString("name")
+	assert.Equal(t, "home", name)
+
+	// tuples without a schema frame
+	dec = NewSchemaDecoder(DefaultMaxSize, bytes.NewReader(encodeTestLocations(t, ProtocolVersionOne, 1)))
+	_, err = dec.Decode()
+	assert.Equal(t, ErrUnknownTupleType, err)
+}
+
+func TestDecodeBytesSchemaFrames(t *testing.T) {
+	reg := createTestOneOfRegistry()
+	b := encodeTestPlaces(t, &reg, "home", "work")
+	version, content, rest, err := sliceFrame(b)
+	assert.Nil(t, err)
+	assert.True(t, isSchemaFrame(version, content))
+	schemaFrame := b[:len(b)-len(rest)]
+
+	// schema frames are skipped
+	for _, expected := range []string{"home", "work"} {
+		place, rest, err := DecodeBytes(&reg, b)
+		assert.Nil(t, err)
+		name, _ := place.GetString("name")
+		assert.Equal(t, expected, name)
+		b = rest
+	}
+	assert.Equal(t, 0, len(b))
+
+	// buffers which only contain schema frames
+	_, rest, err = DecodeBytes(&reg, schemaFrame)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, schemaFrame, rest)
+}
+
+func TestSchemaDecoderNewVersion(t *testing.T) {
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 1)
+	builder.PutFloat32("lat", 2)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+
+	var out bytes.Buffer
+	encoder, err := NewSchemaEncoder(&out, ProtocolVersionTwo, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(loc))
+
+	// a newer version of the type replaces the type read before
+	Location.AddVersion(Field{Name: "name", Type: StringField})
+	builder = Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 1)
+	builder.PutFloat32("lat", 2)
+	builder.PutString("name", "home")
+	loc, err = builder.Build()
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(loc))
+
+	dec := NewSchemaDecoder(DefaultMaxSize, bytes.NewReader(out.Bytes()))
+	_, err = dec.Decode()
+	assert.Nil(t, err)
+	loc, err = dec.Decode()
+	assert.Nil(t, err)
+	name, err := loc.GetString("name")
+	assert.Nil(t, err)
+	assert.Equal(t, "home", name)
+
+	registered, _ := dec.Registry().Get("testing", "Location")
+	assert.Equal(t, 2, registered.NumVersions())
+
+	// schema frames must match their checksum
+	b := out.Bytes()
+	b[10]++
+	_, err = NewSchemaDecoder(DefaultMaxSize, bytes.NewReader(b)).Decode()
+	assert.Equal(t, ErrChecksumMismatch, err)
+}
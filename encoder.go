@@ -2,7 +2,9 @@ package namedtuple
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
+	"hash/crc32"
 	"io"
 	"math"
 
@@ -19,25 +21,130 @@ type Encoder interface {
 	Encode(Tuple) error
 }
 
-// NewEncoder creates a new encoder with the given io.Writer
+// NewEncoder creates a new encoder with the given io.Writer. Tuples are encoded using protocol version 1.
 func NewEncoder(w io.Writer) Encoder {
-	return versionOneEncoder{w, make([]byte, 9), bytes.NewBuffer(make([]byte, 0, 4096))}
+	return encoder{w, make([]byte, 9), bytes.NewBuffer(make([]byte, 0, 4096)), ProtocolVersionOne, false, 0}
 }
 
-type versionOneEncoder struct {
+// NewEncoderVersion creates a new encoder for the given protocol version. Version 1 encodes the tuple header and payload. Version 2 adds the protocol flags, the schema fingerprint and a CRC32C checksum to each tuple. `ErrInvalidProtocolVersion` is returned for unknown versions.
+func NewEncoderVersion(w io.Writer, version uint8) (Encoder, error) {
+	if version != ProtocolVersionOne && version != ProtocolVersionTwo {
+		return nil, ErrInvalidProtocolVersion
+	}
+	return encoder{w, make([]byte, 9), bytes.NewBuffer(make([]byte, 0, 4096)), version, false, 0}, nil
+}
+
+// NewTypeIDEncoder creates a new encoder which includes the 64-bit type ID in each tuple header. Decoders use the type ID to look up the tuple type instead of the 32-bit hashes.
+func NewTypeIDEncoder(w io.Writer) Encoder {
+	return encoder{w, make([]byte, 9), bytes.NewBuffer(make([]byte, 0, 4096)), ProtocolVersionOne, true, 0}
+}
+
+// NewSchemaEncoder creates an encoder for self-describing streams. The first time a tuple type appears in the stream, a schema frame with the versions and fields of the type is written before the tuple, so the stream can be read with a schema decoder without the code which wrote it. If the registry is not nil, the types of nested tuple fields are looked up in the registry and written as well. Tuples are encoded with the given protocol version, schema frames always use protocol version 2 with the `SchemaFlag`. Decoders which are not schema decoders skip the schema frames. `ErrInvalidProtocolVersion` is returned for protocol versions other than 1 and 2.
+func NewSchemaEncoder(w io.Writer, version uint8, reg *Registry) (Encoder, error) {
+	tuples, err := NewEncoderVersion(w, version)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := encoder{w, make([]byte, 9), bytes.NewBuffer(make([]byte, 0, 4096)), ProtocolVersionTwo, false, SchemaFlag}
+	return &schemaEncoder{tuples, schemas, reg, make(map[uint64]uint64)}, nil
+}
+
+type schemaEncoder struct {
+	tuples  Encoder
+	schemas encoder
+	reg     *Registry
+	written map[uint64]uint64
+}
+
+func (e *schemaEncoder) Encode(t Tuple) error {
+	if err := e.writeSchema(t.Header.Type); err != nil {
+		return err
+	}
+	return e.tuples.Encode(t)
+}
+
+// writeSchema writes the schema frames of the type and the types of its nested tuple fields. The fingerprint of each type written is kept, so the schema is written again if the type gets new versions.
+func (e *schemaEncoder) writeSchema(t TupleType) error {
+	fingerprint := t.Fingerprint(t.NumVersions())
+	if written, exists := e.written[t.ID]; exists && written == fingerprint {
+		return nil
+	}
+
+	// The type is marked before its nested types to stop at recursive types
+	previous, exists := e.written[t.ID]
+	e.written[t.ID] = fingerprint
+	err := e.writeNestedSchemas(t)
+	if err == nil {
+		var schema Tuple
+		if schema, err = encodeType(t); err == nil {
+			err = e.schemas.Encode(schema)
+		}
+	}
+
+	if err != nil {
+		if exists {
+			e.written[t.ID] = previous
+		} else {
+			delete(e.written, t.ID)
+		}
+	}
+	return err
+}
+
+// writeNestedSchemas writes the schema frames of the registered types of the tuple fields.
+func (e *schemaEncoder) writeNestedSchemas(t TupleType) error {
+	if e.reg == nil {
+		return nil
+	}
+
+	for _, version := range t.versions {
+		for _, field := range version {
+			if field.TupleName == "" {
+				continue
+			}
+
+			nested, exists := e.reg.Get(field.TupleNamespace, field.TupleName)
+			if !exists {
+				continue
+			}
+			if err := e.writeSchema(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type encoder struct {
 	w              io.Writer
 	protocolHeader []byte
 	buffer         *bytes.Buffer
+	version        uint8
+	typeID         bool
+	flags          uint8
 }
 
-func (e versionOneEncoder) Encode(t Tuple) error {
+func (e encoder) Encode(t Tuple) error {
 	defer e.buffer.Reset()
 
+	// Write flags and schema fingerprint to buffer
+	if e.version == ProtocolVersionTwo {
+		e.writeVersionTwoPrefix(t)
+	}
+
 	// Write tuple header and payload to buffer
 	if _, err := e.writeTuple(t); err != nil {
 		return err
 	}
 
+	// Write checksum of the flags, fingerprint, header and payload
+	if e.version == ProtocolVersionTwo {
+		var checksum [ChecksumSize]byte
+		binary.LittleEndian.PutUint32(checksum[:], crc32.Checksum(e.buffer.Bytes(), castagnoli))
+		e.buffer.Write(checksum[:])
+	}
+
 	// Write protocol header to underlying writer
 	size := e.buffer.Len()
 	if err := e.writeProtocolHeader(size); err != nil {
@@ -49,10 +156,21 @@ func (e versionOneEncoder) Encode(t Tuple) error {
 	return err
 }
 
-func (e versionOneEncoder) writeProtocolHeader(size int) (err error) {
+func (e encoder) writeVersionTwoPrefix(t Tuple) {
+	var prefix [VersionTwoPrefixSize]byte
 
-	// Set protocol version to 1
-	e.protocolHeader[0] = 1
+	// Only schema frames set protocol flags
+	prefix[0] = e.flags
+
+	// The fingerprint covers the versions the tuple was written with
+	binary.LittleEndian.PutUint64(prefix[1:], t.Header.Type.Fingerprint(int(t.Header.TupleVersion)))
+	e.buffer.Write(prefix[:])
+}
+
+func (e encoder) writeProtocolHeader(size int) (err error) {
+
+	// Set protocol version
+	e.protocolHeader[0] = e.version
 
 	// Write protocol version, size enum and content length
 	if size < math.MaxUint8 {
@@ -103,21 +221,24 @@ func (e versionOneEncoder) writeProtocolHeader(size int) (err error) {
 	return nil
 }
 
-func (e versionOneEncoder) writeTupleHeader(t TupleHeader) (int64, error) {
+func (e encoder) writeTupleHeader(t TupleHeader) (int64, error) {
+	if e.typeID {
+		t.TypeID = t.Type.ID
+	}
 	return t.WriteTo(e.buffer)
 }
 
-func (e versionOneEncoder) writeTuple(t Tuple) (int64, error) {
+func (e encoder) writeTuple(t Tuple) (int64, error) {
 
 	// write header
-	var wrote int
-	if wrote, err := e.writeTupleHeader(t.Header); err != nil {
-		return wrote, nil
+	wrote, err := e.writeTupleHeader(t.Header)
+	if err != nil {
+		return wrote, err
 	}
 
 	n, err := e.buffer.Write(t.data)
 	if err != nil {
-		return int64(n), err
+		return wrote + int64(n), err
 	}
-	return int64(wrote + n), nil
+	return wrote + int64(n), nil
 }
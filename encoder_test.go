@@ -2,17 +2,18 @@ package namedtuple
 
 import (
 	"bytes"
+	"hash/crc32"
 	"testing"
 
+	"github.com/blacklabeldata/xbinary"
 	"github.com/stretchr/testify/assert"
-	// "github.com/blacklabeldata/xbinary"
 )
 
 func createTestLocationType() TupleType {
 	// fields
-	lon := Field{"lon", true, Float32Field}
-	lat := Field{"lat", true, Float32Field}
-	alt := Field{"alt", false, Float32Field}
+	lon := Field{Name: "lon", Required: true, Type: Float32Field}
+	lat := Field{Name: "lat", Required: true, Type: Float32Field}
+	alt := Field{Name: "alt", Required: false, Type: Float32Field}
 
 	// create tuple type
 	Location := New("testing", "Location")
@@ -22,9 +23,9 @@ func createTestLocationType() TupleType {
 
 func createTestMessageType() TupleType {
 	// fields
-	userid := Field{"userid", true, StringField}
-	payload := Field{"payload", true, StringField}
-	loc := Field{"loc", false, TupleField}
+	userid := Field{Name: "userid", Required: true, Type: StringField}
+	payload := Field{Name: "payload", Required: true, Type: StringField}
+	loc := Field{Name: "loc", Required: false, Type: TupleField}
 
 	// create tuple type
 	Message := New("testing", "Message")
@@ -72,3 +73,143 @@ func TestEncode(t *testing.T) {
 	// b := out.Bytes()
 	// t.Logf("Output: %d", len(b), b)
 }
+
+func TestNewEncoderVersion(t *testing.T) {
+	var buf []byte
+	out := bytes.NewBuffer(buf)
+
+	_, err := NewEncoderVersion(out, ProtocolVersionOne)
+	assert.Nil(t, err)
+
+	_, err = NewEncoderVersion(out, ProtocolVersionTwo)
+	assert.Nil(t, err)
+
+	encoder, err := NewEncoderVersion(out, 3)
+	assert.Nil(t, encoder)
+	assert.Equal(t, ErrInvalidProtocolVersion, err)
+}
+
+func TestEncodeVersionTwo(t *testing.T) {
+	var buf []byte
+	out := bytes.NewBuffer(buf)
+	encoder, err := NewEncoderVersion(out, ProtocolVersionTwo)
+	assert.Nil(t, err)
+
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 150.5)
+	builder.PutFloat32("lat", 50.5)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+
+	err = encoder.Encode(loc)
+	assert.Nil(t, err)
+
+	// protocol header, flags, fingerprint, tuple header, payload and checksum
+	b := out.Bytes()
+	size := VersionTwoPrefixSize + loc.Header.Size() + loc.Size() + ChecksumSize
+	assert.Equal(t, 2+size, len(b))
+	assert.Equal(t, uint8(ProtocolVersionTwo), b[0])
+	assert.Equal(t, uint8(size), b[1])
+	assert.Equal(t, uint8(0), b[2])
+
+	fingerprint, err := xbinary.LittleEndian.Uint64(b, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, Location.Fingerprint(1), fingerprint)
+
+	checksum, err := xbinary.LittleEndian.Uint32(b, len(b)-ChecksumSize)
+	assert.Nil(t, err)
+	assert.Equal(t, crc32.Checksum(b[2:len(b)-ChecksumSize], crc32.MakeTable(crc32.Castagnoli)), checksum)
+}
+
+func TestFingerprintCache(t *testing.T) {
+	User := createTestTupleType()
+	User.AddVersion(Field{Name: "created", Required: false, Type: TimestampField})
+
+	// the cached fingerprints match the field layout of each version
+	for versions := 0; versions <= User.NumVersions()+1; versions++ {
+		assert.Equal(t, User.fingerprint(versions), User.Fingerprint(versions))
+	}
+	assert.NotEqual(t, User.Fingerprint(1), User.Fingerprint(2))
+	assert.Equal(t, User.Fingerprint(User.NumVersions()), User.Fingerprint(User.NumVersions()+1))
+}
+
+// encodeTestPlaces encodes places with an address using a schema encoder
+func encodeTestPlaces(t *testing.T, reg *Registry, names ...string) []byte {
+	var out bytes.Buffer
+	encoder, err := NewSchemaEncoder(&out, ProtocolVersionTwo, reg)
+	assert.Nil(t, err)
+
+	Place, Address, _ := createTestOneOfTypes()
+	for _, name := range names {
+		builder := NewBuilder(Address, make([]byte, 64))
+		builder.PutString("street", "221B Baker Street")
+		address, err := builder.Build()
+		assert.Nil(t, err)
+
+		builder = NewBuilder(Place, make([]byte, 256))
+		builder.PutString("name", name)
+		builder.PutTuple("address", address)
+		place, err := builder.Build()
+		assert.Nil(t, err)
+		assert.Nil(t, encoder.Encode(place))
+	}
+	return out.Bytes()
+}
+
+func TestSchemaEncoder(t *testing.T) {
+	reg := createTestOneOfRegistry()
+	b := encodeTestPlaces(t, &reg, "home", "work")
+
+	// the schemas of the nested types are written before the place
+	var frames []uint8
+	var names []string
+	for rest := b; len(rest) > 0; {
+		version, content, next, err := sliceFrame(rest)
+		assert.Nil(t, err)
+		assert.Equal(t, uint8(ProtocolVersionTwo), version)
+
+		frames = append(frames, content[0])
+		if isSchemaFrame(version, content) {
+			schema, err := parseVersionTwoFrame(&schemaRegistry, version, content, SchemaFlag)
+			assert.Nil(t, err)
+			name, _ := schema.GetString("name")
+			names = append(names, name)
+		}
+		rest = next
+	}
+	assert.Equal(t, []uint8{SchemaFlag, SchemaFlag, SchemaFlag, 0, 0}, frames)
+	assert.Equal(t, []string{"address", "coordinates", "place"}, names)
+
+	// only the types of the tuples are written without a registry
+	b = encodeTestPlaces(t, nil, "home")
+	_, _, rest, err := sliceFrame(b)
+	assert.Nil(t, err)
+	_, content, _, err := sliceFrame(rest)
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(0), content[0])
+
+	// the schema is written again for new versions of a type
+	var out bytes.Buffer
+	encoder, err := NewSchemaEncoder(&out, ProtocolVersionOne, nil)
+	assert.Nil(t, err)
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 1)
+	builder.PutFloat32("lat", 2)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(loc))
+	assert.Nil(t, encoder.Encode(loc))
+	size := out.Len()
+
+	Location.AddVersion(Field{Name: "name", Type: StringField})
+	loc.Header.Type = Location
+	assert.Nil(t, encoder.Encode(loc))
+	_, _, rest, err = sliceFrame(out.Bytes()[size:])
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(ProtocolVersionOne), rest[0])
+
+	_, err = NewSchemaEncoder(&out, ProtocolVersionThree, nil)
+	assert.Equal(t, ErrInvalidProtocolVersion, err)
+}
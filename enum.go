@@ -0,0 +1,186 @@
+package namedtuple
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrUnknownEnumValue is returned when a name or value is not declared by a closed enum.
+	ErrUnknownEnumValue = errors.New("Unknown enum value")
+
+	// ErrMissingEnum is returned when an `EnumField` does not reference an enum.
+	ErrMissingEnum = errors.New("Enum field does not reference an enum")
+)
+
+// EnumValue is a symbolic name and the numeric value it represents.
+type EnumValue struct {
+	Name  string
+	Value uint32
+}
+
+// Enum is a set of symbolic names for the values of an `EnumField`. Values are written with the compact unsigned integer type codes, so the names are never encoded. Closed enums only accept the declared values and tuples with other values are rejected when they are decoded. Open enums accept any value, which allows values to be added without breaking readers with an older schema.
+type Enum struct {
+	Namespace string
+	Name      string
+	Open      bool
+	values    []EnumValue
+	names     map[uint32]string
+	numbers   map[string]uint32
+}
+
+// NewEnum creates an enum with the given values. Names and values should be unique, if they are not the last declaration wins.
+func NewEnum(namespace, name string, open bool, values ...EnumValue) *Enum {
+	e := &Enum{namespace, name, open, values, make(map[uint32]string), make(map[string]uint32)}
+	for _, v := range values {
+		e.names[v.Value] = v.Name
+		e.numbers[v.Name] = v.Value
+	}
+	return e
+}
+
+// Values returns the declared values in declaration order.
+func (e *Enum) Values() []EnumValue {
+	return e.values
+}
+
+// Value returns the numeric value for the given name.
+func (e *Enum) Value(name string) (uint32, bool) {
+	value, ok := e.numbers[name]
+	return value, ok
+}
+
+// ValueName returns the name of the given numeric value.
+func (e *Enum) ValueName(value uint32) (string, bool) {
+	name, ok := e.names[value]
+	return name, ok
+}
+
+// Contains returns true if the value is declared or the enum is open.
+func (e *Enum) Contains(value uint32) bool {
+	_, ok := e.names[value]
+	return ok || e.Open
+}
+
+// PutEnum writes the value of the given name for an enum field. The field type must be an `EnumField`, otherwise an error will be returned. If the name is not declared by the enum, `ErrUnknownEnumValue` will be returned. The value is written the same way as `PutUint32`.
+func (b *TupleBuilder) PutEnum(field string, name string) (wrote uint64, err error) {
+	e, err := b.enum(field)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := e.Value(name)
+	if !ok {
+		return 0, ErrUnknownEnumValue
+	}
+	return b.putUint32(field, value)
+}
+
+// PutEnumValue writes a numeric value for an enum field. The field type must be an `EnumField`, otherwise an error will be returned. Open enums accept any value, closed enums return `ErrUnknownEnumValue` if the value is not declared. The value is written the same way as `PutUint32`.
+func (b *TupleBuilder) PutEnumValue(field string, value uint32) (wrote uint64, err error) {
+	e, err := b.enum(field)
+	if err != nil {
+		return 0, err
+	}
+
+	if !e.Contains(value) {
+		return 0, ErrUnknownEnumValue
+	}
+	return b.putUint32(field, value)
+}
+
+// enum verifies the field type and returns the enum of the field
+func (b *TupleBuilder) enum(field string) (*Enum, error) {
+	if err := b.typeCheck(field, EnumField); err != nil {
+		return nil, err
+	}
+
+	def, _, _ := b.tupleType.field(field)
+	e := def.Enum
+	if e == nil {
+		return nil, ErrMissingEnum
+	}
+	return e, nil
+}
+
+// GetEnum returns the name of the enum value for the given field. The field type must be an `EnumField`, otherwise an error will be returned. Values of open enums which are not declared are returned as their decimal representation. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetEnum(field string) (string, error) {
+	e, value, err := t.readEnum(field)
+	if err == ErrFieldNotPresent {
+		if name, ok := t.fieldDefault(field).(string); ok {
+			return name, nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if name, ok := e.ValueName(value); ok {
+		return name, nil
+	} else if e.Open {
+		return strconv.FormatUint(uint64(value), 10), nil
+	}
+	return "", ErrUnknownEnumValue
+}
+
+// GetEnumValue returns the numeric enum value for the given field. The field type must be an `EnumField`, otherwise an error will be returned. If the field was not written, the value of the default name of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetEnumValue(field string) (uint32, error) {
+	e, value, err := t.readEnum(field)
+	if err == ErrFieldNotPresent {
+		if name, ok := t.fieldDefault(field).(string); ok {
+			if value, ok := e.Value(name); ok {
+				return value, nil
+			}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if !e.Contains(value) {
+		return 0, ErrUnknownEnumValue
+	}
+	return value, nil
+}
+
+// readEnum returns the enum and the numeric value of an enum field. The enum is returned even if the field was not written.
+func (t *Tuple) readEnum(field string) (*Enum, uint32, error) {
+	pos, err := t.fieldOffset(field, EnumField)
+	if err != nil && err != ErrFieldNotPresent {
+		return nil, 0, err
+	}
+
+	f, _, _ := t.Header.Type.field(field)
+	if f.Enum == nil {
+		return nil, 0, ErrMissingEnum
+	} else if err != nil {
+		return f.Enum, 0, err
+	}
+
+	value, err := t.readUint32(pos)
+	return f.Enum, value, err
+}
+
+// checkEnums verifies the values of all the enum fields are declared by their enums. Fields of open enums are not checked.
+func (t *Tuple) checkEnums() error {
+	if !t.Header.Type.enums {
+		return nil
+	}
+
+	for _, version := range t.Header.Type.Versions() {
+		if version.Num > t.Header.TupleVersion {
+			break
+		}
+
+		for _, field := range version.Fields {
+			if field.Type != EnumField || (field.Enum != nil && field.Enum.Open) {
+				continue
+			}
+
+			if _, err := t.GetEnumValue(field.Name); err != nil && err != ErrFieldNotPresent {
+				return err
+			}
+		}
+	}
+	return nil
+}
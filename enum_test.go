@@ -0,0 +1,217 @@
+package namedtuple
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestStatusEnum(open bool) *Enum {
+	return NewEnum("testing", "Status", open,
+		EnumValue{Name: "ACTIVE", Value: 1},
+		EnumValue{Name: "DISABLED", Value: 2},
+		EnumValue{Name: "DELETED", Value: 300},
+	)
+}
+
+func createTestEnumType(open bool) TupleType {
+	Account := New("testing", "account")
+	Account.AddVersion(
+		Field{Name: "status", Type: EnumField, Enum: createTestStatusEnum(open)},
+		Field{Name: "previous", Type: EnumField, Enum: createTestStatusEnum(open), Default: "ACTIVE"},
+	)
+	return Account
+}
+
+func TestEnumValues(t *testing.T) {
+	e := createTestStatusEnum(false)
+	assert.Equal(t, 3, len(e.Values()))
+	assert.Equal(t, EnumValue{Name: "DELETED", Value: 300}, e.Values()[2])
+
+	value, ok := e.Value("DISABLED")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(2), value)
+	_, ok = e.Value("UNKNOWN")
+	assert.False(t, ok)
+
+	name, ok := e.ValueName(300)
+	assert.True(t, ok)
+	assert.Equal(t, "DELETED", name)
+	_, ok = e.ValueName(3)
+	assert.False(t, ok)
+
+	// open enums contain every value
+	assert.True(t, e.Contains(1))
+	assert.False(t, e.Contains(3))
+	assert.True(t, createTestStatusEnum(true).Contains(3))
+}
+
+func TestBuilderPutEnumPass(t *testing.T) {
+	builder := NewBuilder(createTestEnumType(false), make([]byte, 64))
+
+	// small values use the compact type codes
+	wrote, err := builder.PutEnum("status", "DISABLED")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), wrote)
+	assert.Equal(t, UnsignedInt8Code.OpCode, uint8(builder.buffer[0]))
+	assert.Equal(t, uint8(2), uint8(builder.buffer[1]))
+
+	wrote, err = builder.PutEnumValue("previous", 300)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), wrote)
+	assert.Equal(t, UnsignedInt16Code.OpCode, uint8(builder.buffer[2]))
+
+	account, err := builder.Build()
+	assert.Nil(t, err)
+
+	name, err := account.GetEnum("status")
+	assert.Nil(t, err)
+	assert.Equal(t, "DISABLED", name)
+
+	value, err := account.GetEnumValue("previous")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(300), value)
+
+	name, err = account.GetEnum("previous")
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETED", name)
+}
+
+func TestBuilderPutEnumFail(t *testing.T) {
+	builder := NewBuilder(createTestEnumType(false), make([]byte, 64))
+
+	wrote, err := builder.PutEnum("status", "UNKNOWN")
+	assert.Equal(t, ErrUnknownEnumValue, err)
+	assert.Equal(t, uint64(0), wrote)
+
+	wrote, err = builder.PutEnumValue("status", 3)
+	assert.Equal(t, ErrUnknownEnumValue, err)
+	assert.Equal(t, uint64(0), wrote)
+
+	_, err = builder.PutEnum("missing", "ACTIVE")
+	assert.NotNil(t, err)
+
+	// enum fields require an enum
+	Broken := New("testing", "broken")
+	Broken.AddVersion(Field{Name: "status", Type: EnumField})
+	builder = NewBuilder(Broken, make([]byte, 64))
+	_, err = builder.PutEnum("status", "ACTIVE")
+	assert.Equal(t, ErrMissingEnum, err)
+}
+
+func TestBuilderPutEnumOpen(t *testing.T) {
+	builder := NewBuilder(createTestEnumType(true), make([]byte, 64))
+
+	// open enums accept any value, but not unknown names
+	_, err := builder.PutEnum("status", "UNKNOWN")
+	assert.Equal(t, ErrUnknownEnumValue, err)
+	_, err = builder.PutEnumValue("status", 7)
+	assert.Nil(t, err)
+
+	account, err := builder.Build()
+	assert.Nil(t, err)
+
+	name, err := account.GetEnum("status")
+	assert.Nil(t, err)
+	assert.Equal(t, "7", name)
+
+	value, err := account.GetEnumValue("status")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(7), value)
+}
+
+func TestTupleGetEnumDefault(t *testing.T) {
+	builder := NewBuilder(createTestEnumType(false), make([]byte, 64))
+	account, err := builder.Build()
+	assert.Nil(t, err)
+
+	_, err = account.GetEnum("status")
+	assert.Equal(t, ErrFieldNotPresent, err)
+	_, err = account.GetEnumValue("status")
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	name, err := account.GetEnum("previous")
+	assert.Nil(t, err)
+	assert.Equal(t, "ACTIVE", name)
+
+	value, err := account.GetEnumValue("previous")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), value)
+}
+
+func TestDecodeEnum(t *testing.T) {
+	var buf bytes.Buffer
+	encoder, err := NewEncoderVersion(&buf, ProtocolVersionOne)
+	assert.Nil(t, err)
+	for _, value := range []uint32{2, 7} {
+
+		// the open type is used to write a value unknown to the closed type
+		builder := NewBuilder(createTestEnumType(true), make([]byte, 64))
+		builder.PutEnumValue("status", value)
+		account, err := builder.Build()
+		assert.Nil(t, err)
+		assert.Nil(t, encoder.Encode(account))
+	}
+
+	reg := NewRegistry()
+	reg.Register(createTestEnumType(false))
+
+	account, rest, err := DecodeBytes(&reg, buf.Bytes())
+	assert.Nil(t, err)
+	name, err := account.GetEnum("status")
+	assert.Nil(t, err)
+	assert.Equal(t, "DISABLED", name)
+
+	// closed enums reject unknown values
+	_, _, err = DecodeBytes(&reg, rest)
+	assert.Equal(t, ErrUnknownEnumValue, err)
+
+	// open enums do not
+	reg = NewRegistry()
+	reg.Register(createTestEnumType(true))
+	_, rest, err = DecodeBytes(&reg, rest)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(rest))
+}
+
+func TestEnumFingerprint(t *testing.T) {
+
+	// the fingerprint includes the open flag of the enum
+	closed, open := createTestEnumType(false), createTestEnumType(true)
+	assert.NotEqual(t, closed.Fingerprint(1), open.Fingerprint(1))
+	again := createTestEnumType(false)
+	assert.Equal(t, closed.Fingerprint(1), again.Fingerprint(1))
+
+	// the values are included, but not their order
+	for _, values := range [][]EnumValue{
+		{{Name: "ACTIVE", Value: 1}, {Name: "DISABLED", Value: 2}, {Name: "REMOVED", Value: 300}},
+		{{Name: "ACTIVE", Value: 1}, {Name: "DISABLED", Value: 3}, {Name: "DELETED", Value: 300}},
+		{{Name: "ACTIVE", Value: 1}, {Name: "DISABLED", Value: 2}},
+	} {
+		Changed := New("testing", "account")
+		Changed.AddVersion(
+			Field{Name: "status", Type: EnumField, Enum: NewEnum("testing", "Status", false, values...)},
+			Field{Name: "previous", Type: EnumField, Enum: createTestStatusEnum(false), Default: "ACTIVE"},
+		)
+		assert.NotEqual(t, closed.Fingerprint(1), Changed.Fingerprint(1))
+	}
+
+	Reordered := New("testing", "account")
+	Reordered.AddVersion(
+		Field{Name: "status", Type: EnumField, Enum: NewEnum("testing", "Status", false,
+			EnumValue{Name: "DELETED", Value: 300},
+			EnumValue{Name: "ACTIVE", Value: 1},
+			EnumValue{Name: "DISABLED", Value: 2},
+		)},
+		Field{Name: "previous", Type: EnumField, Enum: createTestStatusEnum(false), Default: "ACTIVE"},
+	)
+	assert.Equal(t, closed.Fingerprint(1), Reordered.Fingerprint(1))
+
+	Other := New("testing", "account")
+	Other.AddVersion(
+		Field{Name: "status", Type: EnumField, Enum: NewEnum("testing", "Other", false)},
+		Field{Name: "previous", Type: EnumField, Enum: createTestStatusEnum(false), Default: "ACTIVE"},
+	)
+	assert.NotEqual(t, closed.Fingerprint(1), Other.Fingerprint(1))
+}
@@ -14,6 +14,11 @@ func (b *TupleBuilder) PutFloat32Array(field string, value []float32) (wrote int
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*4); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -30,7 +35,7 @@ func (b *TupleBuilder) PutFloat32Array(field string, value []float32) (wrote int
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*4 + 2
 	} else if size < math.MaxUint16 {
 
 		if b.available() < size*4+3 {
@@ -46,7 +51,7 @@ func (b *TupleBuilder) PutFloat32Array(field string, value []float32) (wrote int
 		// write type code
 		b.buffer[b.pos] = byte(FloatArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*4
 	} else if size < math.MaxUint32 {
 
 		if b.available() < size*4+5 {
@@ -62,7 +67,7 @@ func (b *TupleBuilder) PutFloat32Array(field string, value []float32) (wrote int
 		// write type code
 		b.buffer[b.pos] = byte(FloatArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*4
 	} else {
 
 		if b.available() < size*4+9 {
@@ -78,10 +83,10 @@ func (b *TupleBuilder) PutFloat32Array(field string, value []float32) (wrote int
 		// write type code
 		b.buffer[b.pos] = byte(FloatArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*4
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -94,6 +99,11 @@ func (b *TupleBuilder) PutFloat64Array(field string, value []float64) (wrote int
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*8); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -110,7 +120,7 @@ func (b *TupleBuilder) PutFloat64Array(field string, value []float64) (wrote int
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*8 + 2
 	} else if size < math.MaxUint16 {
 
 		if b.available() < size*8+3 {
@@ -126,7 +136,7 @@ func (b *TupleBuilder) PutFloat64Array(field string, value []float64) (wrote int
 		// write type code
 		b.buffer[b.pos] = byte(DoubleArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*8
 	} else if size < math.MaxUint32 {
 
 		if b.available() < size*8+5 {
@@ -142,7 +152,7 @@ func (b *TupleBuilder) PutFloat64Array(field string, value []float64) (wrote int
 		// write type code
 		b.buffer[b.pos] = byte(DoubleArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*8
 	} else {
 
 		if b.available() < size*8+9 {
@@ -158,10 +168,42 @@ func (b *TupleBuilder) PutFloat64Array(field string, value []float64) (wrote int
 		// write type code
 		b.buffer[b.pos] = byte(DoubleArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*8
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
+
+// GetFloat32Array returns the float array for the given field. The field type must be a `Float32ArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetFloat32Array(field string) ([]float32, error) {
+	pos, length, err := t.readArrayHeader(field, Float32ArrayField, FloatArray8Code, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]float32, length)
+	for i := 0; i < length; i++ {
+		if value[i], err = xbinary.LittleEndian.Float32(t.data, pos+i*4); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// GetFloat64Array returns the float array for the given field. The field type must be a `Float64ArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetFloat64Array(field string) ([]float64, error) {
+	pos, length, err := t.readArrayHeader(field, Float64ArrayField, DoubleArray8Code, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]float64, length)
+	for i := 0; i < length; i++ {
+		if value[i], err = xbinary.LittleEndian.Float64(t.data, pos+i*8); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
@@ -10,6 +10,11 @@ func (b *TupleBuilder) PutFloat32(field string, value float32) (wrote uint64, er
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(5); err != nil {
+		return 0, err
+	}
+
 	// write value
 	// length check performed by xbinary
 	wrote, err = xbinary.LittleEndian.PutFloat32(b.buffer, b.pos+1, value)
@@ -21,7 +26,7 @@ func (b *TupleBuilder) PutFloat32(field string, value float32) (wrote uint64, er
 	b.buffer[b.pos] = byte(FloatCode.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 5
@@ -37,6 +42,11 @@ func (b *TupleBuilder) PutFloat64(field string, value float64) (wrote uint64, er
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(9); err != nil {
+		return 0, err
+	}
+
 	// write value
 	// length check performed by xbinary
 	wrote, err = xbinary.LittleEndian.PutFloat64(b.buffer, b.pos+1, value)
@@ -48,10 +58,48 @@ func (b *TupleBuilder) PutFloat64(field string, value float64) (wrote uint64, er
 	b.buffer[b.pos] = byte(DoubleCode.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 9
 
 	return 9, nil
 }
+
+// GetFloat32 returns the 32-bit float for the given field. The field type must be a `Float32Field`, otherwise an error will be returned. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetFloat32(field string) (float32, error) {
+	pos, err := t.fieldOffset(field, Float32Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(float32); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// verify type code
+	if t.data[pos] != FloatCode.OpCode {
+		return 0, ErrInvalidTypeCode
+	}
+	return xbinary.LittleEndian.Float32(t.data, pos+1)
+}
+
+// GetFloat64 returns the 64-bit float for the given field. The field type must be a `Float64Field`, otherwise an error will be returned. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetFloat64(field string) (float64, error) {
+	pos, err := t.fieldOffset(field, Float64Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(float64); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// verify type code
+	if t.data[pos] != DoubleCode.OpCode {
+		return 0, ErrInvalidTypeCode
+	}
+	return xbinary.LittleEndian.Float64(t.data, pos+1)
+}
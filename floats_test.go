@@ -14,8 +14,8 @@ func TestPutFloat32Fail(t *testing.T) {
 	// float test type
 	TestType := New("testing", "float")
 	TestType.AddVersion(
-		Field{"float32", true, Float32Field},
-		Field{"float64", true, Float64Field},
+		Field{Name: "float32", Required: true, Type: Float32Field},
+		Field{Name: "float64", Required: true, Type: Float64Field},
 	)
 
 	// create builder
@@ -40,8 +40,8 @@ func TestPutFloat32Pass(t *testing.T) {
 	// float test type
 	TestType := New("testing", "float")
 	TestType.AddVersion(
-		Field{"float32", true, Float32Field},
-		Field{"float64", true, Float64Field},
+		Field{Name: "float32", Required: true, Type: Float32Field},
+		Field{Name: "float64", Required: true, Type: Float64Field},
 	)
 
 	// create builder
@@ -60,7 +60,7 @@ func TestPutFloat32Pass(t *testing.T) {
 	assert.Equal(t, float32(3.14159), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["float32"])
+	assert.Equal(t, 0, builder.offset("float32"))
 }
 
 // Float64
@@ -70,8 +70,8 @@ func TestPutFloat64Fail(t *testing.T) {
 	// float test type
 	TestType := New("testing", "float")
 	TestType.AddVersion(
-		Field{"float32", true, Float32Field},
-		Field{"float64", true, Float64Field},
+		Field{Name: "float32", Required: true, Type: Float32Field},
+		Field{Name: "float64", Required: true, Type: Float64Field},
 	)
 
 	// create builder
@@ -96,8 +96,8 @@ func TestPutFloat64Pass(t *testing.T) {
 	// float test type
 	TestType := New("testing", "float")
 	TestType.AddVersion(
-		Field{"float32", true, Float32Field},
-		Field{"float64", true, Float64Field},
+		Field{Name: "float32", Required: true, Type: Float32Field},
+		Field{Name: "float64", Required: true, Type: Float64Field},
 	)
 
 	// create builder
@@ -116,5 +116,31 @@ func TestPutFloat64Pass(t *testing.T) {
 	assert.Equal(t, float64(3.14159), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["float64"])
+	assert.Equal(t, 0, builder.offset("float64"))
+}
+
+func TestTupleGetFloats(t *testing.T) {
+
+	// float test type
+	TestType := New("testing", "float")
+	TestType.AddVersion(
+		Field{Name: "float32", Required: true, Type: Float32Field},
+		Field{Name: "float64", Required: true, Type: Float64Field},
+	)
+
+	// create builder
+	buffer := make([]byte, 14)
+	builder := NewBuilder(TestType, buffer)
+	builder.PutFloat32("float32", 3.14)
+	builder.PutFloat64("float64", -3.14)
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	float32Value, err := tuple.GetFloat32("float32")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(3.14), float32Value)
+
+	float64Value, err := tuple.GetFloat64("float64")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(-3.14), float64Value)
 }
@@ -0,0 +1,229 @@
+package namedtuple
+
+import (
+	"errors"
+	"math"
+	"reflect"
+)
+
+// ErrBuilderLimit is returned from the Put methods of a growable builder if the value would grow the buffer beyond the limit of the builder.
+var ErrBuilderLimit = errors.New("Tuple exceeds the size limit of the builder")
+
+// minBufferSize is the size of the first buffer allocated by a growable builder which was created without one.
+const minBufferSize = 64
+
+// maxFieldSizes is the largest number of bytes written for a scalar field, including the type code. Integers are bounded by the larger of the fixed-width and the varint encoding.
+var maxFieldSizes = map[FieldType]int{
+	Uint8Field:     2,
+	Int8Field:      2,
+	Uint16Field:    4,
+	Int16Field:     4,
+	Uint32Field:    6,
+	Int32Field:     6,
+	Uint64Field:    11,
+	Int64Field:     11,
+	Float32Field:   5,
+	Float64Field:   9,
+	TimestampField: 9,
+	BooleanField:   1,
+	EnumField:      5,
+}
+
+// maxElementSizes is the largest number of bytes written for each element of a numeric array field.
+var maxElementSizes = map[FieldType]int{
+	Uint8ArrayField:     1,
+	Int8ArrayField:      1,
+	Uint16ArrayField:    3,
+	Int16ArrayField:     3,
+	Uint32ArrayField:    5,
+	Int32ArrayField:     5,
+	Uint64ArrayField:    10,
+	Int64ArrayField:     10,
+	Float32ArrayField:   4,
+	Float64ArrayField:   8,
+	TimestampArrayField: 8,
+}
+
+// NewGrowableBuilder creates a builder which grows its buffer when a value does not fit, instead of returning `xbinary.ErrOutOfRange`. The buffer is used until it is full and may be nil. The capacity is doubled on each growth and the bytes written so far are copied into the new buffer. If the limit is greater than 0, the buffer never grows beyond the limit and the Put methods return `ErrBuilderLimit` for values which would exceed it. Tuples built before a growth keep referencing the previous buffer.
+func NewGrowableBuilder(t TupleType, buffer []byte, limit int) TupleBuilder {
+	b := NewBuilder(t, buffer)
+	b.growable = true
+	b.limit = limit
+	return b
+}
+
+// reserve makes sure the buffer of a growable builder has space for at least size more bytes. Builders with a fixed buffer are not changed, their Put methods check the available space themselves.
+func (b *TupleBuilder) reserve(size int) error {
+	if !b.growable || b.available() >= size {
+		return nil
+	}
+
+	needed := b.pos + size
+	if b.limit > 0 && needed > b.limit {
+		return ErrBuilderLimit
+	}
+
+	capacity := 2 * len(b.buffer)
+	if capacity < minBufferSize {
+		capacity = minBufferSize
+	}
+	if capacity < needed {
+		capacity = needed
+	}
+	if b.limit > 0 && capacity > b.limit {
+		capacity = b.limit
+	}
+
+	buffer := make([]byte, capacity)
+	copy(buffer, b.buffer[:b.pos])
+	b.buffer = buffer
+	return nil
+}
+
+// maxArrayHeaderSize is the largest number of bytes used for the type code and the length of an array.
+const maxArrayHeaderSize = 9
+
+// nested creates a builder for a tuple embedded in a field of the builder. The nested tuple is built in the unused space of the buffer, so no buffer is allocated. It starts after the pending bytes of tuples which were built but not written yet and the space for the type code, the length and the header of the nested tuple, so writing the tuple moves it towards the position of the builder without overwriting tuples which are still pending. The nested builder of a growable builder grows as well and shares the remaining limit.
+func (b *TupleBuilder) nested(t TupleType, pending int) TupleBuilder {
+	start := b.pos + pending + nestedPrefixSize(t, len(b.buffer))
+	if start > len(b.buffer) {
+		start = len(b.buffer)
+	}
+
+	if !b.growable {
+		return NewBuilder(t, b.buffer[start:])
+	}
+
+	var limit int
+	if b.limit > 0 {
+		limit = b.limit - b.pos
+	}
+	return NewGrowableBuilder(t, b.buffer[start:], limit)
+}
+
+// nestedPrefixSize returns the largest number of bytes written before the payload of a nested tuple of the given type which is not larger than size. It includes the type code, the length and the tuple header with the type ID and an offset for every field.
+func nestedPrefixSize(t TupleType, size int) int {
+	width := 8
+	if size < math.MaxUint8-1 {
+		width = 1
+	} else if size < math.MaxUint16 {
+		width = 2
+	} else if size < math.MaxUint32 {
+		width = 4
+	}
+	return 1 + width + VersionOneTupleHeaderSize + TypeIDSize + width*len(t.table)
+}
+
+// arrayHeaderSize returns the number of bytes used for the type code and the length of an array or string.
+func arrayHeaderSize(length int) int {
+	if length < math.MaxUint8 {
+		return 2
+	} else if length < math.MaxUint16 {
+		return 3
+	} else if length < math.MaxUint32 {
+		return 5
+	}
+	return 9
+}
+
+// fixedIntSize returns the number of bytes used by the fixed-width encoding of an integer, including the type code. Signed values are passed as the unsigned value of the same width, the same way the Put methods choose the type code.
+func fixedIntSize(value uint64) int {
+	if value < math.MaxUint8 {
+		return 2
+	} else if value < math.MaxUint16 {
+		return 3
+	} else if value < math.MaxUint32 {
+		return 5
+	}
+	return 9
+}
+
+// EstimateSize returns an upper bound for the number of bytes written by a builder for the given field values. The values are keyed by field name and have the Go types accepted by the Put methods, so `[]uint16` for a `Uint16ArrayField` and a Go map for a `MapField`. Integers are bounded by the larger of the fixed-width and the varint encoding, so the estimate holds for any builder encoding. The tuple header is not included, see `TupleHeader.Size`. `ErrFieldDoesNotExist` is returned for unknown fields and `ErrIncompatibleType` if a value does not have the Go type of the field.
+func (t *TupleType) EstimateSize(values map[string]interface{}) (size int, err error) {
+	for name, value := range values {
+		field, _, exists := t.field(name)
+		if !exists {
+			return 0, ErrFieldDoesNotExist
+		}
+
+		n, err := estimateFieldSize(field, value)
+		if err != nil {
+			return 0, err
+		}
+		size += n
+	}
+	return size, nil
+}
+
+// estimateFieldSize returns an upper bound for the number of bytes written for the value of the field.
+func estimateFieldSize(field Field, value interface{}) (int, error) {
+	if size, ok := maxFieldSizes[field.Type]; ok {
+		return size, nil
+	}
+
+	switch field.Type {
+	case StringField:
+		if s, ok := value.(string); ok {
+			return stringSize(s), nil
+		}
+	case TupleField:
+		if tuple, ok := value.(Tuple); ok {
+			return tupleSize(tuple), nil
+		}
+	case TupleArrayField:
+		if tuples, ok := value.([]Tuple); ok {
+			size := arrayHeaderSize(len(tuples))
+			for _, tuple := range tuples {
+				size += tupleSize(tuple)
+			}
+			return size, nil
+		}
+	case StringArrayField:
+		if strings, ok := value.([]string); ok {
+			size := arrayHeaderSize(len(strings))
+			for _, s := range strings {
+				size += stringSize(s)
+			}
+			return size, nil
+		}
+	case BooleanArrayField:
+		if bools, ok := value.([]bool); ok {
+			return arrayHeaderSize(len(bools)) + (len(bools)+7)/8, nil
+		}
+	case MapField:
+		return estimateMapSize(field, value)
+	default:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice {
+			return arrayHeaderSize(rv.Len()) + rv.Len()*maxElementSizes[field.Type], nil
+		}
+	}
+	return 0, ErrIncompatibleType
+}
+
+// estimateMapSize returns the number of bytes written for a map. The keys and values are converted the same way as `PutMap`.
+func estimateMapSize(field Field, value interface{}) (int, error) {
+	if !ValidMapTypes(field.KeyType, field.ValueType) {
+		return 0, ErrUnsupportedType
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map {
+		return 0, ErrIncompatibleType
+	}
+
+	size := arrayHeaderSize(rv.Len())
+	for _, key := range rv.MapKeys() {
+		k, err := mapElementValue(field.KeyType, key)
+		if err != nil {
+			return 0, err
+		}
+
+		v, err := mapElementValue(field.ValueType, rv.MapIndex(key))
+		if err != nil {
+			return 0, err
+		}
+		size += mapElementSize(field.KeyType, k) + mapElementSize(field.ValueType, v)
+	}
+	return size, nil
+}
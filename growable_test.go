@@ -0,0 +1,242 @@
+package namedtuple
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacklabeldata/xbinary"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestGrowableValues(n int) map[string]interface{} {
+	values := map[string]interface{}{
+		"uint8":     make([]uint8, n),
+		"int8":      make([]int8, n),
+		"uint16":    make([]uint16, n),
+		"int16":     make([]int16, n),
+		"uint32":    make([]uint32, n),
+		"int32":     make([]int32, n),
+		"uint64":    make([]uint64, n),
+		"int64":     make([]int64, n),
+		"float32":   make([]float32, n),
+		"float64":   make([]float64, n),
+		"timestamp": make([]time.Time, n),
+	}
+	for i := 0; i < n; i++ {
+		values["uint16"].([]uint16)[i] = uint16(i)
+		values["int16"].([]int16)[i] = -int16(i)
+		values["uint32"].([]uint32)[i] = uint32(i) << 16
+		values["int32"].([]int32)[i] = -int32(i) << 16
+		values["uint64"].([]uint64)[i] = uint64(i) << 40
+		values["int64"].([]int64)[i] = -int64(i) << 40
+		values["timestamp"].([]time.Time)[i] = time.Unix(int64(i), 0)
+	}
+	return values
+}
+
+func putTestGrowableValues(builder *TupleBuilder, values map[string]interface{}) error {
+	puts := []func() (int, error){
+		func() (int, error) { return builder.PutUint8Array("uint8", values["uint8"].([]uint8)) },
+		func() (int, error) { return builder.PutInt8Array("int8", values["int8"].([]int8)) },
+		func() (int, error) { return builder.PutUint16Array("uint16", values["uint16"].([]uint16)) },
+		func() (int, error) { return builder.PutInt16Array("int16", values["int16"].([]int16)) },
+		func() (int, error) { return builder.PutUint32Array("uint32", values["uint32"].([]uint32)) },
+		func() (int, error) { return builder.PutInt32Array("int32", values["int32"].([]int32)) },
+		func() (int, error) { return builder.PutUint64Array("uint64", values["uint64"].([]uint64)) },
+		func() (int, error) { return builder.PutInt64Array("int64", values["int64"].([]int64)) },
+		func() (int, error) { return builder.PutFloat32Array("float32", values["float32"].([]float32)) },
+		func() (int, error) { return builder.PutFloat64Array("float64", values["float64"].([]float64)) },
+		func() (int, error) { return builder.PutTimestampArray("timestamp", values["timestamp"].([]time.Time)) },
+	}
+	for _, put := range puts {
+		if _, err := put(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestGrowableBuilderPass(t *testing.T) {
+	Arrays := createTestArrayType()
+	values := createTestGrowableValues(1000)
+
+	// the buffer is allocated by the builder
+	builder := NewGrowableBuilder(Arrays, nil, 0)
+	assert.Nil(t, putTestGrowableValues(&builder, values))
+	arrays, err := builder.Build()
+	assert.Nil(t, err)
+
+	uint16s, err := arrays.GetUint16Array("uint16")
+	assert.Nil(t, err)
+	assert.Equal(t, values["uint16"], uint16s)
+
+	int64s, err := arrays.GetInt64Array("int64")
+	assert.Nil(t, err)
+	assert.Equal(t, values["int64"], int64s)
+
+	times, err := arrays.GetTimestampArray("timestamp")
+	assert.Nil(t, err)
+	assert.Equal(t, len(values["timestamp"].([]time.Time)), len(times))
+	assert.True(t, times[999].Equal(time.Unix(999, 0)))
+
+	// a fixed buffer of the same size is too small for the arrays
+	builder = NewBuilder(Arrays, make([]byte, 64))
+	assert.Equal(t, xbinary.ErrOutOfRange, putTestGrowableValues(&builder, values))
+}
+
+func TestGrowableBuilderScalars(t *testing.T) {
+	User := createTestTupleType()
+
+	builder := NewGrowableBuilder(User, make([]byte, 4), 0)
+	builder.SetIntEncoding(VarIntEncoding)
+	_, err := builder.PutString("uuid", "0123456789abcdef")
+	assert.Nil(t, err)
+	_, err = builder.PutString("username", strings.Repeat("u", 300))
+	assert.Nil(t, err)
+	_, err = builder.PutUint8("age", 25)
+	assert.Nil(t, err)
+
+	// the bytes written before a growth are kept
+	user, err := builder.Build()
+	assert.Nil(t, err)
+
+	uuid, err := user.GetString("uuid")
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789abcdef", uuid)
+
+	username, err := user.GetString("username")
+	assert.Nil(t, err)
+	assert.Equal(t, 300, len(username))
+}
+
+func TestGrowableBuilderLimit(t *testing.T) {
+	User := createTestTupleType()
+
+	builder := NewGrowableBuilder(User, nil, 32)
+	wrote, err := builder.PutString("username", strings.Repeat("u", 100))
+	assert.Equal(t, ErrBuilderLimit, err)
+	assert.Equal(t, 0, wrote)
+
+	// values which fit are still written
+	wrote, err = builder.PutString("uuid", "0123456789abcdef")
+	assert.Nil(t, err)
+	assert.Equal(t, 18, wrote)
+
+	wrote, err = builder.PutString("username", strings.Repeat("u", 12))
+	assert.Nil(t, err)
+	assert.Equal(t, 14, wrote)
+	assert.Equal(t, 32, len(builder.buffer))
+
+	_, err = builder.PutUint8("age", 25)
+	assert.Equal(t, ErrBuilderLimit, err)
+
+	// nested builders share the remaining limit
+	nested := builder.nested(User, 0)
+	assert.True(t, nested.growable)
+	assert.Equal(t, 0, nested.limit)
+
+	builder = NewGrowableBuilder(User, nil, 32)
+	builder.PutString("uuid", "0123456789abcdef")
+	nested = builder.nested(User, 0)
+	assert.Equal(t, 14, nested.limit)
+}
+
+func TestNestedBuilderInPlace(t *testing.T) {
+	Location := createTestLocationType()
+	Route := New("testing", "route")
+	Route.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "stops", Required: true, Type: TupleArrayField, TupleNamespace: "testing", TupleName: "Location"},
+	)
+
+	buffer := make([]byte, 256)
+	builder := NewBuilder(Route, buffer)
+	builder.PutString("name", "loop")
+
+	// the nested tuples are built in the unused space of the buffer
+	var tuples []Tuple
+	pending := maxArrayHeaderSize
+	for i := 0; i < 3; i++ {
+		nested := builder.nested(Location, pending)
+		assert.Equal(t, &buffer[builder.pos+pending+nestedPrefixSize(Location, len(buffer))], &nested.buffer[0])
+
+		nested.PutFloat32("lon", float32(i))
+		nested.PutFloat32("lat", float32(-i))
+		tuple, err := nested.Build()
+		assert.Nil(t, err)
+		tuples = append(tuples, tuple)
+		pending += tupleSize(tuple)
+	}
+
+	// writing the tuples moves them into place
+	_, err := builder.PutTupleArray("stops", tuples)
+	assert.Nil(t, err)
+	route, err := builder.Build()
+	assert.Nil(t, err)
+
+	reg := NewRegistry()
+	reg.Register(Location)
+	it, err := route.GetTupleArray("stops", &reg)
+	assert.Nil(t, err)
+	for i := 0; it.Next(); i++ {
+		stop := it.Tuple()
+		lon, err := stop.GetFloat32("lon")
+		assert.Nil(t, err)
+		assert.Equal(t, float32(i), lon)
+		lat, err := stop.GetFloat32("lat")
+		assert.Nil(t, err)
+		assert.Equal(t, float32(-i), lat)
+	}
+	assert.Nil(t, it.Err())
+}
+
+func TestEstimateSize(t *testing.T) {
+	Arrays := createTestArrayType()
+	values := createTestGrowableValues(300)
+
+	size, err := Arrays.EstimateSize(values)
+	assert.Nil(t, err)
+
+	// the estimate holds for both integer encodings
+	for _, encoding := range []IntEncoding{FixedIntEncoding, VarIntEncoding} {
+		builder := NewBuilder(Arrays, make([]byte, size))
+		builder.SetIntEncoding(encoding)
+		assert.Nil(t, putTestGrowableValues(&builder, values))
+		assert.True(t, builder.pos <= size)
+	}
+
+	// strings, tuples and maps are estimated from their values
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutString("username", "username")
+	user, err := builder.Build()
+	assert.Nil(t, err)
+
+	size, err = User.EstimateSize(map[string]interface{}{"uuid": "0123456789abcdef", "username": "username", "age": uint8(25)})
+	assert.Nil(t, err)
+	assert.Equal(t, 18+10+2, size)
+
+	Place, Address, _ := createTestOneOfTypes()
+	size, err = Place.EstimateSize(map[string]interface{}{"address": user})
+	assert.Nil(t, err)
+	assert.Equal(t, tupleSize(user), size)
+
+	Inventory := createTestMapType()
+	size, err = Inventory.EstimateSize(map[string]interface{}{"counts": map[string]int{"a": 1, "bc": 2}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2+(3+5)+(4+5), size)
+
+	builder = NewBuilder(Inventory, make([]byte, size))
+	_, err = builder.PutMap("counts", map[string]int{"a": 1, "bc": 2})
+	assert.Nil(t, err)
+
+	// unknown fields and values of the wrong type
+	_, err = Address.EstimateSize(map[string]interface{}{"missing": "value"})
+	assert.Equal(t, ErrFieldDoesNotExist, err)
+	_, err = Address.EstimateSize(map[string]interface{}{"street": 1})
+	assert.Equal(t, ErrIncompatibleType, err)
+	_, err = Inventory.EstimateSize(map[string]interface{}{"counts": map[string]int{"a": -1}})
+	assert.Equal(t, ErrValueOverflow, err)
+}
@@ -3,16 +3,56 @@ package namedtuple
 import (
 	"encoding/binary"
 	"errors"
+	"hash/crc32"
 	"io"
 )
 
+const (
+
+	// ProtocolVersionOne encodes the tuple header and payload.
+	ProtocolVersionOne = 1
+
+	// ProtocolVersionTwo adds the protocol flags and the schema fingerprint before the tuple header and a CRC32C checksum after the payload.
+	ProtocolVersionTwo = 2
+
+	// ProtocolVersionThree frames contain a compressed block of tuples. Each tuple in the block is framed with protocol version 1 or 2.
+	ProtocolVersionThree = 3
+
+	// VersionTwoPrefixSize is the size of the protocol flags and the schema fingerprint.
+	VersionTwoPrefixSize = 9
+
+	// SchemaFlag is set in the protocol flags of a version 2 frame which contains the schema of a tuple type instead of a tuple. The schema is a tuple of the `namedtuple.TupleType` type.
+	SchemaFlag = 1
+
+	// ChecksumSize is the size of the CRC32C checksum in protocol version 2.
+	ChecksumSize = 4
+
+	// BlockHeaderSize is the size of the compressor code, the tuple count and the uncompressed length at the start of a compressed block.
+	BlockHeaderSize = 13
+)
+
+// castagnoli is the CRC32C table used for protocol version 2 checksums
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
 // ProtocolVersionMask is the lower 6 bits of the first byte of the ptotocol header (0b00111111)
 const ProtocolVersionMask = 63
 
 // ProtocolVersionMask is the upper 2 bits of the first byte of the ptotocol header (0b11000000)
 const ProtocolSizeEnumMask = 192
 
-// ParseProtocolHeader returns the number of bytes to read for the content length and the protocol version. The upper 2 bits represent the number of bytes in the content length (0-3 bits = 2**n). The lower 6 bits are the protocol version which determines how the bytes are interpreted.
+// TupleVersionMask is the lower 5 bits of the tuple version byte in the tuple header (0b00011111). The upper 2 bits store the size of the field offsets.
+const TupleVersionMask = 31
+
+// TypeIDFlag is set in the tuple version byte if the tuple header includes the 64-bit type ID after the field count (0b00100000).
+const TypeIDFlag = 32
+
+// ErrTooManyVersions is returned by the builder if more than `TupleVersionMask` versions of a tuple type are satisfied. Higher tuple versions would overlap the `TypeIDFlag`.
+var ErrTooManyVersions = errors.New("Tuple version exceeds the maximum of 31 versions")
+
+// TypeIDSize is the number of bytes used by the type ID in the tuple header.
+const TypeIDSize = 8
+
+// ParseProtocolHeader returns the number of bytes to read for the content length and the protocol version. The upper 2 bits represent the number of bytes in the content length (0-3 bits = 2**n). The lower 6 bits are the protocol version which determines how the bytes are interpreted. Frames of `ProtocolVersionThree` contain a compressed block of tuples instead of a single tuple.
 func ParseProtocolHeader(header uint8) (lenBytes uint8, version uint8) {
 	version = header & ProtocolVersionMask
 	lenBytes = 1 << ((header & ProtocolSizeEnumMask) >> 6)
@@ -30,11 +70,19 @@ type TupleHeader struct {
 	ContentLength   uint64
 	Offsets         []uint64
 	Type            TupleType
+
+	// TypeID is the 64-bit identity of the tuple type. It is only
+	// written if it is not zero.
+	TypeID uint64
 }
 
-// Size returns the Version 1 header size plus the size of all the offsets
+// Size returns the Version 1 header size plus the size of the type ID and all the offsets
 func (t *TupleHeader) Size() int {
-	return VersionOneTupleHeaderSize + int(t.FieldSize)*int(t.FieldCount)
+	size := VersionOneTupleHeaderSize + int(t.FieldSize)*int(t.FieldCount)
+	if t.TypeID != 0 {
+		size += TypeIDSize
+	}
+	return size
 }
 
 // WriteTo writes the TupleHeader into the given writer.
@@ -52,6 +100,14 @@ func (t *TupleHeader) WriteTo(w io.Writer) (int64, error) {
 	binary.LittleEndian.PutUint32(dst[9:], t.FieldCount)
 
 	pos := int64(13)
+
+	// Write type ID
+	if t.TypeID != 0 {
+		dst[0] |= TypeIDFlag
+		binary.LittleEndian.PutUint64(dst[pos:], t.TypeID)
+		pos += TypeIDSize
+	}
+
 	switch t.FieldSize {
 	case 1:
 
@@ -94,3 +150,46 @@ func (t *TupleHeader) WriteTo(w io.Writer) (int64, error) {
 	n, err := w.Write(dst)
 	return int64(n), err
 }
+
+// parseTupleHeader parses a tuple header which was written with `TupleHeader.WriteTo`. The position of the tuple data in the buffer is returned along with the header. The tuple type of the header is not resolved.
+func parseTupleHeader(buffer []byte) (header TupleHeader, pos int, err error) {
+
+	// The buffer needs to be at least 13 bytes. This includes the uint8 tuple version, the uint32 namespace and type hashes and the field count
+	if len(buffer) < VersionOneTupleHeaderSize {
+		return header, 0, ErrTupleLengthTooSmall
+	}
+
+	// The offset size is stored in the upper 2 bits of the tuple version
+	header.FieldSize, _ = ParseProtocolHeader(buffer[0])
+	header.TupleVersion = buffer[0] & TupleVersionMask
+
+	// Read hashes and field count
+	header.NamespaceHash = binary.LittleEndian.Uint32(buffer[1:])
+	header.Hash = binary.LittleEndian.Uint32(buffer[5:])
+	header.FieldCount = binary.LittleEndian.Uint32(buffer[9:])
+	pos = VersionOneTupleHeaderSize
+
+	// Read type ID
+	if buffer[0]&TypeIDFlag != 0 {
+		if len(buffer) < pos+TypeIDSize {
+			return header, 0, ErrTupleLengthTooSmall
+		}
+		header.TypeID = binary.LittleEndian.Uint64(buffer[pos:])
+		pos += TypeIDSize
+	}
+
+	// The buffer must contain all the field offsets
+	if uint64(header.FieldCount)*uint64(header.FieldSize) > uint64(len(buffer)-pos) {
+		return header, 0, ErrTupleLengthTooSmall
+	}
+
+	// Read field offsets
+	header.Offsets, err = readFieldOffsetsAt(header.FieldSize, header.FieldCount, buffer, pos)
+	if err != nil {
+		return header, 0, err
+	}
+	pos += int(header.FieldCount) * int(header.FieldSize)
+
+	header.ContentLength = uint64(len(buffer) - pos)
+	return header, pos, nil
+}
@@ -71,9 +71,9 @@ func TestTupleHeaderSize(t *testing.T) {
 
 func createTestUserType() TupleType {
 	// fields
-	uuid := Field{"uuid", true, StringField}
-	username := Field{"username", true, StringField}
-	age := Field{"age", false, Uint8Field}
+	uuid := Field{Name: "uuid", Required: true, Type: StringField}
+	username := Field{Name: "username", Required: true, Type: StringField}
+	age := Field{Name: "age", Required: false, Type: Uint8Field}
 
 	// create tuple type
 	User := New("testing", "user")
@@ -273,3 +273,53 @@ func TestTupleHeaderWriteToExtraLarge(t *testing.T) {
 	assert.Equal(t, uint64(2), offsets[1])
 	assert.Equal(t, uint64(3), offsets[2])
 }
+
+func TestTupleHeaderWriteToTypeID(t *testing.T) {
+	User := createTestUserType()
+	header := TupleHeader{
+		ProtocolVersion: 0,
+		TupleVersion:    1,
+		NamespaceHash:   User.NamespaceHash,
+		Hash:            User.Hash,
+		FieldCount:      3,
+		FieldSize:       2,
+		ContentLength:   64,
+		Offsets:         []uint64{1, 2, 3},
+		Type:            User,
+		TypeID:          User.ID,
+	}
+	assert.Equal(t, VersionOneTupleHeaderSize+TypeIDSize+2*3, header.Size())
+
+	var buf []byte
+	writer := bytes.NewBuffer(buf)
+	_, err := header.WriteTo(writer)
+	assert.Nil(t, err)
+
+	buffer := writer.Bytes()
+
+	// Check length
+	assert.Equal(t, 27, len(buffer))
+
+	// check tuple version and flag
+	assert.Equal(t, uint8(64|TypeIDFlag|1), buffer[0])
+
+	// Check type ID
+	id, err := xbinary.LittleEndian.Uint64(buffer, 13)
+	assert.Nil(t, err)
+	assert.Equal(t, User.ID, id)
+
+	// Parse header
+	parsed, pos, err := parseTupleHeader(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 27, pos)
+	assert.Equal(t, uint8(1), parsed.TupleVersion)
+	assert.Equal(t, uint8(2), parsed.FieldSize)
+	assert.Equal(t, User.ID, parsed.TypeID)
+	assert.Equal(t, header.Offsets, parsed.Offsets)
+
+	// Truncated header
+	_, _, err = parseTupleHeader(buffer[:20])
+	assert.Equal(t, ErrTupleLengthTooSmall, err)
+	_, _, err = parseTupleHeader(buffer[:26])
+	assert.Equal(t, ErrTupleLengthTooSmall, err)
+}
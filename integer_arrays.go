@@ -13,6 +13,11 @@ func (b *TupleBuilder) PutUint8Array(field string, value []uint8) (wrote int, er
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*1); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -75,7 +80,7 @@ func (b *TupleBuilder) PutUint8Array(field string, value []uint8) (wrote int, er
 		wrote += 9 + size
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -87,6 +92,11 @@ func (b *TupleBuilder) PutInt8Array(field string, value []int8) (wrote int, err
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*1); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -149,7 +159,7 @@ func (b *TupleBuilder) PutInt8Array(field string, value []int8) (wrote int, err
 		wrote += 9 + size
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -161,6 +171,16 @@ func (b *TupleBuilder) PutUint16Array(field string, value []uint16) (wrote int,
 		return 0, err
 	}
 
+	// varints are written element by element
+	if b.varInt(field) {
+		return b.putVarIntArray(field, UVarIntArray8Code, len(value), func(i int) uint64 { return uint64(value[i]) })
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*2); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -175,7 +195,7 @@ func (b *TupleBuilder) PutUint16Array(field string, value []uint16) (wrote int,
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*2 + 2
 	} else if size < math.MaxUint16 {
 
 		// write length
@@ -190,7 +210,7 @@ func (b *TupleBuilder) PutUint16Array(field string, value []uint16) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedShortArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*2
 	} else if size < math.MaxUint32 {
 
 		// write length
@@ -205,7 +225,7 @@ func (b *TupleBuilder) PutUint16Array(field string, value []uint16) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedShortArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*2
 	} else {
 
 		// write length
@@ -220,10 +240,10 @@ func (b *TupleBuilder) PutUint16Array(field string, value []uint16) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedShortArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*2
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -235,6 +255,16 @@ func (b *TupleBuilder) PutInt16Array(field string, value []int16) (wrote int, er
 		return 0, err
 	}
 
+	// varints are written element by element
+	if b.varInt(field) {
+		return b.putVarIntArray(field, VarIntArray8Code, len(value), func(i int) uint64 { return zigZag(int64(value[i])) })
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*2); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -249,7 +279,7 @@ func (b *TupleBuilder) PutInt16Array(field string, value []int16) (wrote int, er
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*2 + 2
 	} else if size < math.MaxUint16 {
 
 		// write length
@@ -264,7 +294,7 @@ func (b *TupleBuilder) PutInt16Array(field string, value []int16) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(ShortArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*2
 	} else if size < math.MaxUint32 {
 
 		// write length
@@ -279,7 +309,7 @@ func (b *TupleBuilder) PutInt16Array(field string, value []int16) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(ShortArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*2
 	} else {
 
 		// write length
@@ -294,10 +324,10 @@ func (b *TupleBuilder) PutInt16Array(field string, value []int16) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(ShortArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*2
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -309,6 +339,16 @@ func (b *TupleBuilder) PutUint32Array(field string, value []uint32) (wrote int,
 		return 0, err
 	}
 
+	// varints are written element by element
+	if b.varInt(field) {
+		return b.putVarIntArray(field, UVarIntArray8Code, len(value), func(i int) uint64 { return uint64(value[i]) })
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*4); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -323,7 +363,7 @@ func (b *TupleBuilder) PutUint32Array(field string, value []uint32) (wrote int,
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*4 + 2
 	} else if size < math.MaxUint16 {
 
 		// write length
@@ -338,7 +378,7 @@ func (b *TupleBuilder) PutUint32Array(field string, value []uint32) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedIntArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*4
 	} else if size < math.MaxUint32 {
 
 		// write length
@@ -353,7 +393,7 @@ func (b *TupleBuilder) PutUint32Array(field string, value []uint32) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedIntArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*4
 	} else {
 
 		// write length
@@ -368,10 +408,10 @@ func (b *TupleBuilder) PutUint32Array(field string, value []uint32) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedIntArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*4
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -383,6 +423,16 @@ func (b *TupleBuilder) PutInt32Array(field string, value []int32) (wrote int, er
 		return 0, err
 	}
 
+	// varints are written element by element
+	if b.varInt(field) {
+		return b.putVarIntArray(field, VarIntArray8Code, len(value), func(i int) uint64 { return zigZag(int64(value[i])) })
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*4); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -397,7 +447,7 @@ func (b *TupleBuilder) PutInt32Array(field string, value []int32) (wrote int, er
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*4 + 2
 	} else if size < math.MaxUint16 {
 
 		// write length
@@ -412,7 +462,7 @@ func (b *TupleBuilder) PutInt32Array(field string, value []int32) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(IntArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*4
 	} else if size < math.MaxUint32 {
 
 		// write length
@@ -427,7 +477,7 @@ func (b *TupleBuilder) PutInt32Array(field string, value []int32) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(IntArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*4
 	} else {
 
 		// write length
@@ -442,10 +492,10 @@ func (b *TupleBuilder) PutInt32Array(field string, value []int32) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(IntArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*4
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -457,6 +507,16 @@ func (b *TupleBuilder) PutUint64Array(field string, value []uint64) (wrote int,
 		return 0, err
 	}
 
+	// varints are written element by element
+	if b.varInt(field) {
+		return b.putVarIntArray(field, UVarIntArray8Code, len(value), func(i int) uint64 { return value[i] })
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*8); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -471,7 +531,7 @@ func (b *TupleBuilder) PutUint64Array(field string, value []uint64) (wrote int,
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*8 + 2
 	} else if size < math.MaxUint16 {
 
 		// write length
@@ -487,7 +547,7 @@ func (b *TupleBuilder) PutUint64Array(field string, value []uint64) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedLongArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*8
 	} else if size < math.MaxUint32 {
 
 		// write length
@@ -503,7 +563,7 @@ func (b *TupleBuilder) PutUint64Array(field string, value []uint64) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedLongArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*8
 	} else {
 		// write length
 		if _, err = xbinary.LittleEndian.PutUint64(b.buffer, b.pos+1, uint64(size)); err != nil {
@@ -518,10 +578,10 @@ func (b *TupleBuilder) PutUint64Array(field string, value []uint64) (wrote int,
 		// write type code
 		b.buffer[b.pos] = byte(UnsignedLongArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*8
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
@@ -533,6 +593,16 @@ func (b *TupleBuilder) PutInt64Array(field string, value []int64) (wrote int, er
 		return 0, err
 	}
 
+	// varints are written element by element
+	if b.varInt(field) {
+		return b.putVarIntArray(field, VarIntArray8Code, len(value), func(i int) uint64 { return zigZag(value[i]) })
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(value)) + len(value)*8); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -547,7 +617,7 @@ func (b *TupleBuilder) PutInt64Array(field string, value []int64) (wrote int, er
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*8 + 2
 	} else if size < math.MaxUint16 {
 
 		// write length
@@ -563,7 +633,7 @@ func (b *TupleBuilder) PutInt64Array(field string, value []int64) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(LongArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*8
 	} else if size < math.MaxUint32 {
 
 		// write length
@@ -579,7 +649,7 @@ func (b *TupleBuilder) PutInt64Array(field string, value []int64) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(LongArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*8
 	} else {
 
 		// write length
@@ -595,10 +665,190 @@ func (b *TupleBuilder) PutInt64Array(field string, value []int64) (wrote int, er
 		// write type code
 		b.buffer[b.pos] = byte(LongArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*8
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
+
+// GetUint8Array returns the 8-bit unsigned array for the given field. The field type must be a `Uint8ArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint8Array(field string) ([]uint8, error) {
+	pos, length, err := t.readArrayHeader(field, Uint8ArrayField, UnsignedByteArray8Code, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]uint8, length)
+	copy(value, t.data[pos:pos+length])
+	return value, nil
+}
+
+// GetInt8Array returns the 8-bit signed array for the given field. The field type must be an `Int8ArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt8Array(field string) ([]int8, error) {
+	pos, length, err := t.readArrayHeader(field, Int8ArrayField, ByteArray8Code, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]int8, length)
+	for i := 0; i < length; i++ {
+		value[i] = int8(t.data[pos+i])
+	}
+	return value, nil
+}
+
+// GetUint16Array returns the 16-bit unsigned array for the given field. The field type must be a `Uint16ArrayField`, otherwise an error will be returned. Arrays written with the `VarIntEncoding` are decoded as well. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint16Array(field string) ([]uint16, error) {
+	if values, ok, err := t.readVarIntArray(field, Uint16ArrayField, UVarIntArray8Code, math.MaxUint16); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]uint16, len(values))
+		for i, v := range values {
+			value[i] = uint16(v)
+		}
+		return value, nil
+	}
+
+	pos, length, err := t.readArrayHeader(field, Uint16ArrayField, UnsignedShortArray8Code, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]uint16, length)
+	if err = xbinary.LittleEndian.Uint16Array(t.data, pos, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetInt16Array returns the 16-bit signed array for the given field. The field type must be an `Int16ArrayField`, otherwise an error will be returned. Arrays written with the `VarIntEncoding` are decoded as well. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt16Array(field string) ([]int16, error) {
+	if values, ok, err := t.readVarIntArray(field, Int16ArrayField, VarIntArray8Code, math.MaxUint16); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]int16, len(values))
+		for i, v := range values {
+			value[i] = int16(unZigZag(v))
+		}
+		return value, nil
+	}
+
+	pos, length, err := t.readArrayHeader(field, Int16ArrayField, ShortArray8Code, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]int16, length)
+	for i := 0; i < length; i++ {
+		if value[i], err = xbinary.LittleEndian.Int16(t.data, pos+i*2); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// GetUint32Array returns the 32-bit unsigned array for the given field. The field type must be a `Uint32ArrayField`, otherwise an error will be returned. Arrays written with the `VarIntEncoding` are decoded as well. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint32Array(field string) ([]uint32, error) {
+	if values, ok, err := t.readVarIntArray(field, Uint32ArrayField, UVarIntArray8Code, math.MaxUint32); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]uint32, len(values))
+		for i, v := range values {
+			value[i] = uint32(v)
+		}
+		return value, nil
+	}
+
+	pos, length, err := t.readArrayHeader(field, Uint32ArrayField, UnsignedIntArray8Code, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]uint32, length)
+	if err = xbinary.LittleEndian.Uint32Array(t.data, pos, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetInt32Array returns the 32-bit signed array for the given field. The field type must be an `Int32ArrayField`, otherwise an error will be returned. Arrays written with the `VarIntEncoding` are decoded as well. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt32Array(field string) ([]int32, error) {
+	if values, ok, err := t.readVarIntArray(field, Int32ArrayField, VarIntArray8Code, math.MaxUint32); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]int32, len(values))
+		for i, v := range values {
+			value[i] = int32(unZigZag(v))
+		}
+		return value, nil
+	}
+
+	pos, length, err := t.readArrayHeader(field, Int32ArrayField, IntArray8Code, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]int32, length)
+	for i := 0; i < length; i++ {
+		if value[i], err = xbinary.LittleEndian.Int32(t.data, pos+i*4); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// GetUint64Array returns the 64-bit unsigned array for the given field. The field type must be a `Uint64ArrayField`, otherwise an error will be returned. Arrays written with the `VarIntEncoding` are decoded as well. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint64Array(field string) ([]uint64, error) {
+	if values, ok, err := t.readVarIntArray(field, Uint64ArrayField, UVarIntArray8Code, math.MaxUint64); ok {
+		return values, err
+	}
+
+	pos, length, err := t.readArrayHeader(field, Uint64ArrayField, UnsignedLongArray8Code, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]uint64, length)
+	if err = xbinary.LittleEndian.Uint64Array(t.data, pos, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetInt64Array returns the 64-bit signed array for the given field. The field type must be an `Int64ArrayField`, otherwise an error will be returned. Arrays written with the `VarIntEncoding` are decoded as well. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt64Array(field string) ([]int64, error) {
+	if values, ok, err := t.readVarIntArray(field, Int64ArrayField, VarIntArray8Code, math.MaxUint64); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]int64, len(values))
+		for i, v := range values {
+			value[i] = unZigZag(v)
+		}
+		return value, nil
+	}
+
+	pos, length, err := t.readArrayHeader(field, Int64ArrayField, LongArray8Code, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]int64, length)
+	for i := 0; i < length; i++ {
+		if value[i], err = xbinary.LittleEndian.Int64(t.data, pos+i*8); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
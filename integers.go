@@ -14,6 +14,11 @@ func (b *TupleBuilder) PutUint8(field string, value uint8) (wrote uint64, err er
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(2); err != nil {
+		return 0, err
+	}
+
 	// minimum bytes is 2 (type code + value)
 	if b.available() < 2 {
 		return 0, xbinary.ErrOutOfRange
@@ -26,7 +31,7 @@ func (b *TupleBuilder) PutUint8(field string, value uint8) (wrote uint64, err er
 	b.buffer[b.pos+1] = byte(value)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 2
@@ -42,6 +47,11 @@ func (b *TupleBuilder) PutInt8(field string, value int8) (wrote uint64, err erro
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(2); err != nil {
+		return 0, err
+	}
+
 	// minimum bytes is 2 (type code + value)
 	if b.available() < 2 {
 		return 0, xbinary.ErrOutOfRange
@@ -54,7 +64,7 @@ func (b *TupleBuilder) PutInt8(field string, value int8) (wrote uint64, err erro
 	b.buffer[b.pos+1] = byte(value)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 2
@@ -62,7 +72,7 @@ func (b *TupleBuilder) PutInt8(field string, value int8) (wrote uint64, err erro
 	return 2, nil
 }
 
-// PutUint16 sets a 16-bit unsigned value for the given field name.The field name must be a Uint16Field otherwise an error will be returned. If the type buffer no longer has enough space to write the value, an xbinary.ErrOutOfRange error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be writtn first. If the value is `< math.MaxUint8`, only 1 byte will be written. Otherwise, the entire 16-bit value will be written.
+// PutUint16 sets a 16-bit unsigned value for the given field name.The field name must be a Uint16Field otherwise an error will be returned. If the type buffer no longer has enough space to write the value, an xbinary.ErrOutOfRange error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be writtn first. If the value is `< math.MaxUint8`, only 1 byte will be written. Otherwise, the entire 16-bit value will be written. If the field is written with the `VarIntEncoding`, the value is written as a varint instead. See `IntEncoding`.
 func (b *TupleBuilder) PutUint16(field string, value uint16) (wrote uint64, err error) {
 
 	// field type should be
@@ -70,6 +80,16 @@ func (b *TupleBuilder) PutUint16(field string, value uint16) (wrote uint64, err
 		return 0, err
 	}
 
+	// varints are written with a single type code
+	if b.varInt(field) {
+		return b.putVarInt(field, UVarIntCode, uint64(value))
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(fixedIntSize(uint64(value))); err != nil {
+		return 0, err
+	}
+
 	if value < math.MaxUint8 {
 
 		// minimum bytes is 2 (type code + value)
@@ -84,7 +104,7 @@ func (b *TupleBuilder) PutUint16(field string, value uint16) (wrote uint64, err
 		b.buffer[b.pos+1] = byte(value)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 2
@@ -103,7 +123,7 @@ func (b *TupleBuilder) PutUint16(field string, value uint16) (wrote uint64, err
 	b.buffer[b.pos] = byte(UnsignedShort16Code.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 3
@@ -113,7 +133,7 @@ func (b *TupleBuilder) PutUint16(field string, value uint16) (wrote uint64, err
 
 }
 
-// PutInt16 sets a 16-bit signed value for the given field name.The field name must be an Int16Field; otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an xbinary.ErrOutOfRange error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the value is `< math.MaxUint8`, only 1 byte will be written. Otherwise, the entire 16-bit value will be written.
+// PutInt16 sets a 16-bit signed value for the given field name.The field name must be an Int16Field; otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an xbinary.ErrOutOfRange error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the value is `< math.MaxUint8`, only 1 byte will be written. Otherwise, the entire 16-bit value will be written. If the field is written with the `VarIntEncoding`, the value is written as a varint instead. See `IntEncoding`.
 func (b *TupleBuilder) PutInt16(field string, value int16) (wrote uint64, err error) {
 
 	// field type should be
@@ -121,6 +141,16 @@ func (b *TupleBuilder) PutInt16(field string, value int16) (wrote uint64, err er
 		return 0, err
 	}
 
+	// varints are written with a single type code
+	if b.varInt(field) {
+		return b.putVarInt(field, VarIntCode, zigZag(int64(value)))
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(fixedIntSize(uint64(uint16(value)))); err != nil {
+		return 0, err
+	}
+
 	if uint16(value) < math.MaxUint8 {
 
 		// minimum bytes is 2 (type code + value)
@@ -135,7 +165,7 @@ func (b *TupleBuilder) PutInt16(field string, value int16) (wrote uint64, err er
 		b.buffer[b.pos+1] = byte(value)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 2
@@ -154,7 +184,7 @@ func (b *TupleBuilder) PutInt16(field string, value int16) (wrote uint64, err er
 	b.buffer[b.pos] = byte(Short16Code.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 3
@@ -164,7 +194,7 @@ func (b *TupleBuilder) PutInt16(field string, value int16) (wrote uint64, err er
 
 }
 
-// PutUint32 sets a 32-bit unsigned value for the given field name. The field name must be a Uint32Field, otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the value is `< math.MaxUint8`, only 1 byte will be written. If the value is `< math.MaxUint16`, only 2 bytes will be written. Otherwise, the entire 32-bit value will be written.
+// PutUint32 sets a 32-bit unsigned value for the given field name. The field name must be a Uint32Field, otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the value is `< math.MaxUint8`, only 1 byte will be written. If the value is `< math.MaxUint16`, only 2 bytes will be written. Otherwise, the entire 32-bit value will be written. If the field is written with the `VarIntEncoding`, the value is written as a varint instead. See `IntEncoding`.
 func (b *TupleBuilder) PutUint32(field string, value uint32) (wrote uint64, err error) {
 
 	// field type should be
@@ -172,6 +202,20 @@ func (b *TupleBuilder) PutUint32(field string, value uint32) (wrote uint64, err
 		return 0, err
 	}
 
+	// varints are written with a single type code
+	if b.varInt(field) {
+		return b.putVarInt(field, UVarIntCode, uint64(value))
+	}
+	return b.putUint32(field, value)
+}
+
+// putUint32 writes a 32-bit unsigned value using the smallest of the `UnsignedInt8Code`, `UnsignedInt16Code` and `UnsignedInt32Code` type codes.
+func (b *TupleBuilder) putUint32(field string, value uint32) (wrote uint64, err error) {
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(fixedIntSize(uint64(value))); err != nil {
+		return 0, err
+	}
 	if value < math.MaxUint8 {
 
 		// minimum bytes is 2 (type code + value)
@@ -186,7 +230,7 @@ func (b *TupleBuilder) PutUint32(field string, value uint32) (wrote uint64, err
 		b.buffer[b.pos+1] = byte(value)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 2
@@ -204,7 +248,7 @@ func (b *TupleBuilder) PutUint32(field string, value uint32) (wrote uint64, err
 		b.buffer[b.pos] = byte(UnsignedInt16Code.OpCode)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 3
@@ -224,7 +268,7 @@ func (b *TupleBuilder) PutUint32(field string, value uint32) (wrote uint64, err
 	b.buffer[b.pos] = byte(UnsignedInt32Code.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 5
@@ -233,7 +277,7 @@ func (b *TupleBuilder) PutUint32(field string, value uint32) (wrote uint64, err
 	return 5, nil
 }
 
-// PutInt32 sets a 32-bit signed value for the given field name. The field name must be a Int32Field. Otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the absolute value is `< math.MaxUint8`, only 1 byte will be written. If the absolute value is `< math.MaxUint16`, only 2 bytes will be written. Otherwise, the entire 32-bit value will be written.
+// PutInt32 sets a 32-bit signed value for the given field name. The field name must be a Int32Field. Otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the absolute value is `< math.MaxUint8`, only 1 byte will be written. If the absolute value is `< math.MaxUint16`, only 2 bytes will be written. Otherwise, the entire 32-bit value will be written. If the field is written with the `VarIntEncoding`, the value is written as a varint instead. See `IntEncoding`.
 func (b *TupleBuilder) PutInt32(field string, value int32) (wrote uint64, err error) {
 
 	// field type should be
@@ -241,6 +285,16 @@ func (b *TupleBuilder) PutInt32(field string, value int32) (wrote uint64, err er
 		return 0, err
 	}
 
+	// varints are written with a single type code
+	if b.varInt(field) {
+		return b.putVarInt(field, VarIntCode, zigZag(int64(value)))
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(fixedIntSize(uint64(uint32(value)))); err != nil {
+		return 0, err
+	}
+
 	unsigned := uint32(value)
 	if unsigned < math.MaxUint8 {
 
@@ -256,7 +310,7 @@ func (b *TupleBuilder) PutInt32(field string, value int32) (wrote uint64, err er
 		b.buffer[b.pos+1] = byte(value)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 2
@@ -275,7 +329,7 @@ func (b *TupleBuilder) PutInt32(field string, value int32) (wrote uint64, err er
 		b.buffer[b.pos] = byte(Int16Code.OpCode)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 3
@@ -295,7 +349,7 @@ func (b *TupleBuilder) PutInt32(field string, value int32) (wrote uint64, err er
 	b.buffer[b.pos] = byte(Int32Code.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 5
@@ -304,7 +358,7 @@ func (b *TupleBuilder) PutInt32(field string, value int32) (wrote uint64, err er
 	return 5, nil
 }
 
-// PutUint64 sets a 64-bit unsigned integer for the given field name. The field name must be a Uint64Field. Otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the absolute value is `< math.MaxUint8`, only 1 byte will be written. If the absolute value is `< math.MaxUint16`, only 2 bytes will be written. If the absolute value is `< math.MaxUint32`, only 4 bytes will be written. Otherwise, the entire 64-bit value will be written.
+// PutUint64 sets a 64-bit unsigned integer for the given field name. The field name must be a Uint64Field. Otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the absolute value is `< math.MaxUint8`, only 1 byte will be written. If the absolute value is `< math.MaxUint16`, only 2 bytes will be written. If the absolute value is `< math.MaxUint32`, only 4 bytes will be written. Otherwise, the entire 64-bit value will be written. If the field is written with the `VarIntEncoding`, the value is written as a varint instead. See `IntEncoding`.
 func (b *TupleBuilder) PutUint64(field string, value uint64) (wrote uint64, err error) {
 
 	// field type should be
@@ -312,6 +366,16 @@ func (b *TupleBuilder) PutUint64(field string, value uint64) (wrote uint64, err
 		return 0, err
 	}
 
+	// varints are written with a single type code
+	if b.varInt(field) {
+		return b.putVarInt(field, UVarIntCode, value)
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(fixedIntSize(value)); err != nil {
+		return 0, err
+	}
+
 	if value < math.MaxUint8 {
 
 		// minimum bytes is 2 (type code + value)
@@ -326,7 +390,7 @@ func (b *TupleBuilder) PutUint64(field string, value uint64) (wrote uint64, err
 		b.buffer[b.pos+1] = byte(value)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 2
@@ -345,7 +409,7 @@ func (b *TupleBuilder) PutUint64(field string, value uint64) (wrote uint64, err
 		b.buffer[b.pos] = byte(UnsignedLong16Code.OpCode)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 3
@@ -365,7 +429,7 @@ func (b *TupleBuilder) PutUint64(field string, value uint64) (wrote uint64, err
 		b.buffer[b.pos] = byte(UnsignedLong32Code.OpCode)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 5
@@ -384,7 +448,7 @@ func (b *TupleBuilder) PutUint64(field string, value uint64) (wrote uint64, err
 	b.buffer[b.pos] = byte(UnsignedLong64Code.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 9
@@ -394,7 +458,7 @@ func (b *TupleBuilder) PutUint64(field string, value uint64) (wrote uint64, err
 
 }
 
-// PutInt64 sets a 64-bit signed integer for the given field name. The field name must be a Int64Field. Otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the absolute value is `< math.MaxUint8`, only 1 byte will be written. If the absolute value is `< math.MaxUint16`, only 2 bytes will be written. If the absolute value is `< math.MaxUint32`, only 4 bytes will be written. Otherwise, the entire 64-bit value will be written.
+// PutInt64 sets a 64-bit signed integer for the given field name. The field name must be a Int64Field. Otherwise, an error will be returned. If the type buffer no longer has enough space to write the value, an `xbinary.ErrOutOfRange` error will be returned. Upon success, the number of bytes written as well as a nil error will be returned. The type code will be written first. If the absolute value is `< math.MaxUint8`, only 1 byte will be written. If the absolute value is `< math.MaxUint16`, only 2 bytes will be written. If the absolute value is `< math.MaxUint32`, only 4 bytes will be written. Otherwise, the entire 64-bit value will be written. If the field is written with the `VarIntEncoding`, the value is written as a varint instead. See `IntEncoding`.
 func (b *TupleBuilder) PutInt64(field string, value int64) (wrote uint64, err error) {
 
 	// field type should be
@@ -402,6 +466,16 @@ func (b *TupleBuilder) PutInt64(field string, value int64) (wrote uint64, err er
 		return 0, err
 	}
 
+	// varints are written with a single type code
+	if b.varInt(field) {
+		return b.putVarInt(field, VarIntCode, zigZag(value))
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(fixedIntSize(uint64(value))); err != nil {
+		return 0, err
+	}
+
 	unsigned := uint64(value)
 	if unsigned < math.MaxUint8 {
 
@@ -417,7 +491,7 @@ func (b *TupleBuilder) PutInt64(field string, value int64) (wrote uint64, err er
 		b.buffer[b.pos+1] = byte(value)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 2
@@ -436,7 +510,7 @@ func (b *TupleBuilder) PutInt64(field string, value int64) (wrote uint64, err er
 		b.buffer[b.pos] = byte(Long16Code.OpCode)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 3
@@ -456,7 +530,7 @@ func (b *TupleBuilder) PutInt64(field string, value int64) (wrote uint64, err er
 		b.buffer[b.pos] = byte(Long32Code.OpCode)
 
 		// set field offset
-		b.offsets[field] = b.pos
+		b.setOffset(field)
 
 		// incr pos
 		b.pos += 5
@@ -476,7 +550,7 @@ func (b *TupleBuilder) PutInt64(field string, value int64) (wrote uint64, err er
 	b.buffer[b.pos] = byte(Long64Code.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 9
@@ -484,3 +558,215 @@ func (b *TupleBuilder) PutInt64(field string, value int64) (wrote uint64, err er
 	// wrote 9 bytes
 	return 9, nil
 }
+
+// GetUint8 returns the 8-bit unsigned value for the given field. The field type must be a `Uint8Field`, otherwise an error will be returned. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint8(field string) (uint8, error) {
+	pos, err := t.fieldOffset(field, Uint8Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(uint8); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// verify type code
+	if t.data[pos] != UnsignedInt8Code.OpCode {
+		return 0, ErrInvalidTypeCode
+	}
+	return t.readUint8(pos + 1)
+}
+
+// GetInt8 returns the 8-bit signed value for the given field. The field type must be an `Int8Field`, otherwise an error will be returned. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt8(field string) (int8, error) {
+	pos, err := t.fieldOffset(field, Int8Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(int8); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// verify type code
+	if t.data[pos] != Int8Code.OpCode {
+		return 0, ErrInvalidTypeCode
+	}
+	value, err := t.readUint8(pos + 1)
+	return int8(value), err
+}
+
+// GetUint16 returns the 16-bit unsigned value for the given field. The field type must be a `Uint16Field`, otherwise an error will be returned. Values written with a single byte or as a varint are widened to 16 bits. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint16(field string) (uint16, error) {
+	pos, err := t.fieldOffset(field, Uint16Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(uint16); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch t.data[pos] {
+	case UnsignedShort8Code.OpCode:
+		value, err := t.readUint8(pos + 1)
+		return uint16(value), err
+	case UnsignedShort16Code.OpCode:
+		return xbinary.LittleEndian.Uint16(t.data, pos+1)
+	case UVarIntCode.OpCode:
+		value, _, err := t.readVarInt(pos+1, math.MaxUint16)
+		return uint16(value), err
+	}
+	return 0, ErrInvalidTypeCode
+}
+
+// GetInt16 returns the 16-bit signed value for the given field. The field type must be an `Int16Field`, otherwise an error will be returned. Values written with a single byte or as a varint are widened to 16 bits. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt16(field string) (int16, error) {
+	pos, err := t.fieldOffset(field, Int16Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(int16); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch t.data[pos] {
+	case Short8Code.OpCode:
+		value, err := t.readUint8(pos + 1)
+		return int16(value), err
+	case Short16Code.OpCode:
+		return xbinary.LittleEndian.Int16(t.data, pos+1)
+	case VarIntCode.OpCode:
+		value, _, err := t.readVarInt(pos+1, math.MaxUint16)
+		return int16(unZigZag(value)), err
+	}
+	return 0, ErrInvalidTypeCode
+}
+
+// GetUint32 returns the 32-bit unsigned value for the given field. The field type must be a `Uint32Field`, otherwise an error will be returned. Values written with the compacted `UnsignedInt8Code` or `UnsignedInt16Code` type codes or as a varint are widened to 32 bits. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint32(field string) (uint32, error) {
+	pos, err := t.fieldOffset(field, Uint32Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(uint32); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return t.readUint32(pos)
+}
+
+// readUint32 reads a 32-bit unsigned value written with the `UnsignedInt8Code`, `UnsignedInt16Code`, `UnsignedInt32Code` or `UVarIntCode` type code at the given position.
+func (t *Tuple) readUint32(pos int) (uint32, error) {
+	switch t.data[pos] {
+	case UnsignedInt8Code.OpCode:
+		value, err := t.readUint8(pos + 1)
+		return uint32(value), err
+	case UnsignedInt16Code.OpCode:
+		value, err := xbinary.LittleEndian.Uint16(t.data, pos+1)
+		return uint32(value), err
+	case UnsignedInt32Code.OpCode:
+		return xbinary.LittleEndian.Uint32(t.data, pos+1)
+	case UVarIntCode.OpCode:
+		value, _, err := t.readVarInt(pos+1, math.MaxUint32)
+		return uint32(value), err
+	}
+	return 0, ErrInvalidTypeCode
+}
+
+// GetInt32 returns the 32-bit signed value for the given field. The field type must be an `Int32Field`, otherwise an error will be returned. Values written with the compacted `Int8Code` or `Int16Code` type codes or as a varint are widened to 32 bits. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt32(field string) (int32, error) {
+	pos, err := t.fieldOffset(field, Int32Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(int32); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch t.data[pos] {
+	case Int8Code.OpCode:
+		value, err := t.readUint8(pos + 1)
+		return int32(value), err
+	case Int16Code.OpCode:
+		value, err := xbinary.LittleEndian.Uint16(t.data, pos+1)
+		return int32(value), err
+	case Int32Code.OpCode:
+		return xbinary.LittleEndian.Int32(t.data, pos+1)
+	case VarIntCode.OpCode:
+		value, _, err := t.readVarInt(pos+1, math.MaxUint32)
+		return int32(unZigZag(value)), err
+	}
+	return 0, ErrInvalidTypeCode
+}
+
+// GetUint64 returns the 64-bit unsigned value for the given field. The field type must be a `Uint64Field`, otherwise an error will be returned. Values written with 1, 2 or 4 bytes or as a varint are widened to 64 bits. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetUint64(field string) (uint64, error) {
+	pos, err := t.fieldOffset(field, Uint64Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(uint64); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch t.data[pos] {
+	case UnsignedLong8Code.OpCode:
+		value, err := t.readUint8(pos + 1)
+		return uint64(value), err
+	case UnsignedLong16Code.OpCode:
+		value, err := xbinary.LittleEndian.Uint16(t.data, pos+1)
+		return uint64(value), err
+	case UnsignedLong32Code.OpCode:
+		value, err := xbinary.LittleEndian.Uint32(t.data, pos+1)
+		return uint64(value), err
+	case UnsignedLong64Code.OpCode:
+		return xbinary.LittleEndian.Uint64(t.data, pos+1)
+	case UVarIntCode.OpCode:
+		value, _, err := t.readVarInt(pos+1, math.MaxUint64)
+		return value, err
+	}
+	return 0, ErrInvalidTypeCode
+}
+
+// GetInt64 returns the 64-bit signed value for the given field. The field type must be an `Int64Field`, otherwise an error will be returned. Values written with 1, 2 or 4 bytes or as a varint are widened to 64 bits. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetInt64(field string) (int64, error) {
+	pos, err := t.fieldOffset(field, Int64Field)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(int64); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch t.data[pos] {
+	case Long8Code.OpCode:
+		value, err := t.readUint8(pos + 1)
+		return int64(value), err
+	case Long16Code.OpCode:
+		value, err := xbinary.LittleEndian.Uint16(t.data, pos+1)
+		return int64(value), err
+	case Long32Code.OpCode:
+		value, err := xbinary.LittleEndian.Uint32(t.data, pos+1)
+		return int64(value), err
+	case Long64Code.OpCode:
+		return xbinary.LittleEndian.Int64(t.data, pos+1)
+	case VarIntCode.OpCode:
+		value, _, err := t.readVarInt(pos+1, math.MaxUint64)
+		return unZigZag(value), err
+	}
+	return 0, ErrInvalidTypeCode
+}
@@ -44,7 +44,7 @@ func TestBuilderPutUint8Pass(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["age"])
+	assert.Equal(t, 0, builder.offset("age"))
 }
 
 func TestBuilderPutInt8Fail(t *testing.T) {
@@ -53,8 +53,8 @@ func TestBuilderPutInt8Fail(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "int8")
 	TestType.AddVersion(
-		Field{"int8", true, Int8Field},
-		Field{"uint8", true, Uint8Field},
+		Field{Name: "int8", Required: true, Type: Int8Field},
+		Field{Name: "uint8", Required: true, Type: Uint8Field},
 	)
 
 	// create builder
@@ -78,8 +78,8 @@ func TestBuilderPutInt8Pass(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "int8")
 	TestType.AddVersion(
-		Field{"int8", true, Int8Field},
-		Field{"uint8", true, Uint8Field},
+		Field{Name: "int8", Required: true, Type: Int8Field},
+		Field{Name: "uint8", Required: true, Type: Uint8Field},
 	)
 
 	// create builder
@@ -96,7 +96,7 @@ func TestBuilderPutInt8Pass(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["int8"])
+	assert.Equal(t, 0, builder.offset("int8"))
 }
 
 func TestBuilderPutUint16Fail_1(t *testing.T) {
@@ -105,8 +105,8 @@ func TestBuilderPutUint16Fail_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -131,8 +131,8 @@ func TestBuilderPutUint16Pass_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -149,7 +149,7 @@ func TestBuilderPutUint16Pass_1(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint16"])
+	assert.Equal(t, 0, builder.offset("uint16"))
 }
 
 func TestBuilderPutUint16Fail_2(t *testing.T) {
@@ -158,8 +158,8 @@ func TestBuilderPutUint16Fail_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -178,8 +178,8 @@ func TestBuilderPutUint16Pass_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -198,7 +198,7 @@ func TestBuilderPutUint16Pass_2(t *testing.T) {
 	assert.Equal(t, uint16(300), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint16"])
+	assert.Equal(t, 0, builder.offset("uint16"))
 }
 
 //
@@ -208,8 +208,8 @@ func TestBuilderPutInt16Fail_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "int16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -234,8 +234,8 @@ func TestBuilderPutInt16Pass_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "int16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -252,7 +252,7 @@ func TestBuilderPutInt16Pass_1(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["int16"])
+	assert.Equal(t, 0, builder.offset("int16"))
 }
 
 func TestBuilderPutInt16Fail_2(t *testing.T) {
@@ -261,8 +261,8 @@ func TestBuilderPutInt16Fail_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "int16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -281,8 +281,8 @@ func TestBuilderPutInt16Pass_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "int16")
 	TestType.AddVersion(
-		Field{"int16", true, Int16Field},
-		Field{"uint16", true, Uint16Field},
+		Field{Name: "int16", Required: true, Type: Int16Field},
+		Field{Name: "uint16", Required: true, Type: Uint16Field},
 	)
 
 	// create builder
@@ -301,7 +301,7 @@ func TestBuilderPutInt16Pass_2(t *testing.T) {
 	assert.Equal(t, int16(-300), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["int16"])
+	assert.Equal(t, 0, builder.offset("int16"))
 }
 
 //
@@ -311,8 +311,8 @@ func TestBuilderPutUint32Fail_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -337,8 +337,8 @@ func TestBuilderPutUint32Pass_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -355,7 +355,7 @@ func TestBuilderPutUint32Pass_1(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint32"])
+	assert.Equal(t, 0, builder.offset("uint32"))
 }
 
 func TestBuilderPutUint32Fail_2(t *testing.T) {
@@ -364,8 +364,8 @@ func TestBuilderPutUint32Fail_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -384,8 +384,8 @@ func TestBuilderPutUint32Pass_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -404,7 +404,7 @@ func TestBuilderPutUint32Pass_2(t *testing.T) {
 	assert.Equal(t, uint16(300), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint32"])
+	assert.Equal(t, 0, builder.offset("uint32"))
 }
 
 func TestBuilderPutUint32Fail_3(t *testing.T) {
@@ -413,8 +413,8 @@ func TestBuilderPutUint32Fail_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -433,8 +433,8 @@ func TestBuilderPutUint32Pass_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -453,7 +453,7 @@ func TestBuilderPutUint32Pass_3(t *testing.T) {
 	assert.Equal(t, uint32(135000), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint32"])
+	assert.Equal(t, 0, builder.offset("uint32"))
 }
 
 //
@@ -463,8 +463,8 @@ func TestBuilderPutInt32Fail_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -489,8 +489,8 @@ func TestBuilderPutInt32Pass_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -507,7 +507,7 @@ func TestBuilderPutInt32Pass_1(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint32"])
+	assert.Equal(t, 0, builder.offset("int32"))
 }
 
 func TestBuilderPutInt32Fail_2(t *testing.T) {
@@ -516,8 +516,8 @@ func TestBuilderPutInt32Fail_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -536,8 +536,8 @@ func TestBuilderPutInt32Pass_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -556,7 +556,7 @@ func TestBuilderPutInt32Pass_2(t *testing.T) {
 	assert.Equal(t, int16(300), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint32"])
+	assert.Equal(t, 0, builder.offset("int32"))
 }
 
 func TestBuilderPutInt32Fail_3(t *testing.T) {
@@ -565,8 +565,8 @@ func TestBuilderPutInt32Fail_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -585,8 +585,8 @@ func TestBuilderPutInt32Pass_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint32")
 	TestType.AddVersion(
-		Field{"int32", true, Int32Field},
-		Field{"uint32", true, Uint32Field},
+		Field{Name: "int32", Required: true, Type: Int32Field},
+		Field{Name: "uint32", Required: true, Type: Uint32Field},
 	)
 
 	// create builder
@@ -605,7 +605,7 @@ func TestBuilderPutInt32Pass_3(t *testing.T) {
 	assert.Equal(t, int32(135000), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint32"])
+	assert.Equal(t, 0, builder.offset("int32"))
 }
 
 //
@@ -615,8 +615,8 @@ func TestBuilderPutUint64Fail_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -641,8 +641,8 @@ func TestBuilderPutUint64Pass_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -659,7 +659,7 @@ func TestBuilderPutUint64Pass_1(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("uint64"))
 }
 
 func TestBuilderPutUint64Fail_2(t *testing.T) {
@@ -668,8 +668,8 @@ func TestBuilderPutUint64Fail_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -688,8 +688,8 @@ func TestBuilderPutUint64Pass_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -708,7 +708,7 @@ func TestBuilderPutUint64Pass_2(t *testing.T) {
 	assert.Equal(t, uint16(300), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("uint64"))
 }
 
 func TestBuilderPutUint64Fail_3(t *testing.T) {
@@ -717,8 +717,8 @@ func TestBuilderPutUint64Fail_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -737,8 +737,8 @@ func TestBuilderPutUint64Pass_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -757,7 +757,7 @@ func TestBuilderPutUint64Pass_3(t *testing.T) {
 	assert.Equal(t, uint64(135000), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("uint64"))
 }
 
 func TestBuilderPutUint64Fail_4(t *testing.T) {
@@ -766,8 +766,8 @@ func TestBuilderPutUint64Fail_4(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -786,8 +786,8 @@ func TestBuilderPutUint64Pass_4(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -806,7 +806,7 @@ func TestBuilderPutUint64Pass_4(t *testing.T) {
 	assert.Equal(t, uint64(17179869184), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("uint64"))
 }
 
 //
@@ -816,8 +816,8 @@ func TestBuilderPutInt64Fail_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -842,8 +842,8 @@ func TestBuilderPutInt64Pass_1(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -860,7 +860,7 @@ func TestBuilderPutInt64Pass_1(t *testing.T) {
 	assert.Equal(t, 20, int(builder.buffer[1]))
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("int64"))
 }
 
 func TestBuilderPutInt64Fail_2(t *testing.T) {
@@ -869,8 +869,8 @@ func TestBuilderPutInt64Fail_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -889,8 +889,8 @@ func TestBuilderPutInt64Pass_2(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -909,7 +909,7 @@ func TestBuilderPutInt64Pass_2(t *testing.T) {
 	assert.Equal(t, int16(300), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("int64"))
 }
 
 func TestBuilderPutInt64Fail_3(t *testing.T) {
@@ -918,8 +918,8 @@ func TestBuilderPutInt64Fail_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -938,8 +938,8 @@ func TestBuilderPutInt64Pass_3(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -958,7 +958,7 @@ func TestBuilderPutInt64Pass_3(t *testing.T) {
 	assert.Equal(t, int64(135000), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("int64"))
 }
 
 func TestBuilderPutInt64Fail_4(t *testing.T) {
@@ -967,8 +967,8 @@ func TestBuilderPutInt64Fail_4(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -987,8 +987,8 @@ func TestBuilderPutInt64Pass_4(t *testing.T) {
 	// integer test type
 	TestType := New("testing", "uint64")
 	TestType.AddVersion(
-		Field{"int64", true, Int64Field},
-		Field{"uint64", true, Uint64Field},
+		Field{Name: "int64", Required: true, Type: Int64Field},
+		Field{Name: "uint64", Required: true, Type: Uint64Field},
 	)
 
 	// create builder
@@ -1007,5 +1007,143 @@ func TestBuilderPutInt64Pass_4(t *testing.T) {
 	assert.Equal(t, int64(17179869184), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["uint64"])
+	assert.Equal(t, 0, builder.offset("int64"))
+}
+
+func TestTupleGetIntegers(t *testing.T) {
+
+	// integer test type
+	TestType := New("testing", "integers")
+	TestType.AddVersion(
+		Field{Name: "uint8", Required: true, Type: Uint8Field},
+		Field{Name: "int8", Required: true, Type: Int8Field},
+		Field{Name: "uint16-8", Required: true, Type: Uint16Field},
+		Field{Name: "uint16-16", Required: true, Type: Uint16Field},
+		Field{Name: "int16-8", Required: true, Type: Int16Field},
+		Field{Name: "int16-16", Required: true, Type: Int16Field},
+		Field{Name: "uint32-8", Required: true, Type: Uint32Field},
+		Field{Name: "uint32-16", Required: true, Type: Uint32Field},
+		Field{Name: "uint32-32", Required: true, Type: Uint32Field},
+		Field{Name: "int32-8", Required: true, Type: Int32Field},
+		Field{Name: "int32-16", Required: true, Type: Int32Field},
+		Field{Name: "int32-32", Required: true, Type: Int32Field},
+		Field{Name: "uint64-8", Required: true, Type: Uint64Field},
+		Field{Name: "uint64-16", Required: true, Type: Uint64Field},
+		Field{Name: "uint64-32", Required: true, Type: Uint64Field},
+		Field{Name: "uint64-64", Required: true, Type: Uint64Field},
+		Field{Name: "int64-8", Required: true, Type: Int64Field},
+		Field{Name: "int64-16", Required: true, Type: Int64Field},
+		Field{Name: "int64-32", Required: true, Type: Int64Field},
+		Field{Name: "int64-64", Required: true, Type: Int64Field},
+	)
+
+	// create builder
+	buffer := make([]byte, 1024)
+	builder := NewBuilder(TestType, buffer)
+	builder.PutUint8("uint8", 200)
+	builder.PutInt8("int8", -100)
+	builder.PutUint16("uint16-8", 20)
+	builder.PutUint16("uint16-16", 60000)
+	builder.PutInt16("int16-8", 20)
+	builder.PutInt16("int16-16", -20000)
+	builder.PutUint32("uint32-8", 20)
+	builder.PutUint32("uint32-16", 60000)
+	builder.PutUint32("uint32-32", 135000)
+	builder.PutInt32("int32-8", 20)
+	builder.PutInt32("int32-16", 60000)
+	builder.PutInt32("int32-32", -135000)
+	builder.PutUint64("uint64-8", 20)
+	builder.PutUint64("uint64-16", 60000)
+	builder.PutUint64("uint64-32", 135000)
+	builder.PutUint64("uint64-64", 1<<40)
+	builder.PutInt64("int64-8", 20)
+	builder.PutInt64("int64-16", 60000)
+	builder.PutInt64("int64-32", 3000000000)
+	builder.PutInt64("int64-64", -1<<40)
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	// 8-bit
+	uint8Value, err := tuple.GetUint8("uint8")
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(200), uint8Value)
+
+	int8Value, err := tuple.GetInt8("int8")
+	assert.Nil(t, err)
+	assert.Equal(t, int8(-100), int8Value)
+
+	// 16-bit
+	uint16Value, err := tuple.GetUint16("uint16-8")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(20), uint16Value)
+
+	uint16Value, err = tuple.GetUint16("uint16-16")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(60000), uint16Value)
+
+	int16Value, err := tuple.GetInt16("int16-8")
+	assert.Nil(t, err)
+	assert.Equal(t, int16(20), int16Value)
+
+	int16Value, err = tuple.GetInt16("int16-16")
+	assert.Nil(t, err)
+	assert.Equal(t, int16(-20000), int16Value)
+
+	// 32-bit
+	uint32Value, err := tuple.GetUint32("uint32-8")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(20), uint32Value)
+
+	uint32Value, err = tuple.GetUint32("uint32-16")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(60000), uint32Value)
+
+	uint32Value, err = tuple.GetUint32("uint32-32")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(135000), uint32Value)
+
+	int32Value, err := tuple.GetInt32("int32-8")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(20), int32Value)
+
+	int32Value, err = tuple.GetInt32("int32-16")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(60000), int32Value)
+
+	int32Value, err = tuple.GetInt32("int32-32")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(-135000), int32Value)
+
+	// 64-bit
+	uint64Value, err := tuple.GetUint64("uint64-8")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20), uint64Value)
+
+	uint64Value, err = tuple.GetUint64("uint64-16")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(60000), uint64Value)
+
+	uint64Value, err = tuple.GetUint64("uint64-32")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(135000), uint64Value)
+
+	uint64Value, err = tuple.GetUint64("uint64-64")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1<<40), uint64Value)
+
+	int64Value, err := tuple.GetInt64("int64-8")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), int64Value)
+
+	int64Value, err = tuple.GetInt64("int64-16")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(60000), int64Value)
+
+	int64Value, err = tuple.GetInt64("int64-32")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3000000000), int64Value)
+
+	int64Value, err = tuple.GetInt64("int64-64")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1<<40), int64Value)
 }
@@ -0,0 +1,440 @@
+package namedtuple
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// JSONFieldError is returned from FromJSON if the JSON value of a field cannot be written into the tuple. The field name is included along with the underlying error.
+type JSONFieldError struct {
+	Field string
+	Err   error
+}
+
+func (e JSONFieldError) Error() string {
+	return fmt.Sprintf("Invalid JSON value for field '%s': %s", e.Field, e.Err)
+}
+
+// ToJSON converts a tuple into a JSON object. The fields of each version the tuple was written with are emitted in declaration order using the field names as keys. Optional fields which were not written are omitted. Timestamps are formatted as RFC3339 strings, enums as their names, nested tuples and maps as objects and arrays as arrays. Map keys are converted to strings. Values of open enums which are not declared are emitted as numbers. The types of nested tuples are resolved using the DefaultRegistry.
+func ToJSON(t Tuple) ([]byte, error) {
+	return ToJSONWithRegistry(t, &DefaultRegistry)
+}
+
+// ToJSONWithRegistry converts a tuple into a JSON object the same way as ToJSON. The types of nested tuples are resolved using the given registry.
+func ToJSONWithRegistry(t Tuple, reg *Registry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.writeJSON(&buf, reg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *Tuple) writeJSON(buf *bytes.Buffer, reg *Registry) error {
+	versions := t.Header.Type.Versions()
+	if int(t.Header.TupleVersion) < len(versions) {
+		versions = versions[:t.Header.TupleVersion]
+	}
+
+	buf.WriteByte('{')
+	first := true
+	for _, version := range versions {
+		for _, field := range version.Fields {
+
+			// default values of fields which were not written are not included
+			if _, err := t.fieldOffset(field.Name, field.Type); err == ErrFieldNotPresent {
+				continue
+			}
+
+			value, err := t.jsonValue(field, reg)
+			if err == ErrFieldNotPresent {
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			// write key and value
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+
+			key, _ := json.Marshal(field.Name)
+			buf.Write(key)
+			buf.WriteByte(':')
+
+			b, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			buf.Write(b)
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// jsonValue reads the field and returns a value which can be encoded using `json.Marshal`.
+func (t *Tuple) jsonValue(field Field, reg *Registry) (v interface{}, err error) {
+	name := field.Name
+	switch field.Type {
+	case Uint8Field:
+		v, err = t.GetUint8(name)
+	case Int8Field:
+		v, err = t.GetInt8(name)
+	case Uint16Field:
+		v, err = t.GetUint16(name)
+	case Int16Field:
+		v, err = t.GetInt16(name)
+	case Uint32Field:
+		v, err = t.GetUint32(name)
+	case Int32Field:
+		v, err = t.GetInt32(name)
+	case Uint64Field:
+		v, err = t.GetUint64(name)
+	case Int64Field:
+		v, err = t.GetInt64(name)
+	case Float32Field:
+		v, err = t.GetFloat32(name)
+	case Float64Field:
+		v, err = t.GetFloat64(name)
+	case StringField:
+		v, err = t.GetString(name)
+	case BooleanField:
+		v, err = t.GetBool(name)
+	case EnumField:
+
+		// values of open enums which are not declared are numbers
+		var value uint32
+		if value, err = t.GetEnumValue(name); err == nil {
+			if n, ok := field.Enum.ValueName(value); ok {
+				v = n
+			} else {
+				v = value
+			}
+		}
+	case TimestampField:
+		var ts time.Time
+		if ts, err = t.GetTimestamp(name); err == nil {
+			v = ts.Format(time.RFC3339Nano)
+		}
+	case TupleField:
+		var nested Tuple
+		if nested, err = t.GetTuple(name, reg); err == nil {
+			var buf bytes.Buffer
+			err = nested.writeJSON(&buf, reg)
+			v = json.RawMessage(buf.Bytes())
+		}
+	case Uint8ArrayField:
+		// []uint8 would be encoded as a base64 string
+		var values []uint8
+		if values, err = t.GetUint8Array(name); err == nil {
+			array := make([]uint16, len(values))
+			for i, value := range values {
+				array[i] = uint16(value)
+			}
+			v = array
+		}
+	case Int8ArrayField:
+		v, err = t.GetInt8Array(name)
+	case Uint16ArrayField:
+		v, err = t.GetUint16Array(name)
+	case Int16ArrayField:
+		v, err = t.GetInt16Array(name)
+	case Uint32ArrayField:
+		v, err = t.GetUint32Array(name)
+	case Int32ArrayField:
+		v, err = t.GetInt32Array(name)
+	case Uint64ArrayField:
+		v, err = t.GetUint64Array(name)
+	case Int64ArrayField:
+		v, err = t.GetInt64Array(name)
+	case Float32ArrayField:
+		v, err = t.GetFloat32Array(name)
+	case Float64ArrayField:
+		v, err = t.GetFloat64Array(name)
+	case StringArrayField:
+		v, err = t.GetStringArray(name)
+	case BooleanArrayField:
+		v, err = t.GetBoolArray(name)
+	case TimestampArrayField:
+		var values []time.Time
+		if values, err = t.GetTimestampArray(name); err == nil {
+			array := make([]string, len(values))
+			for i, value := range values {
+				array[i] = value.Format(time.RFC3339Nano)
+			}
+			v = array
+		}
+	case TupleArrayField:
+		var it *TupleIterator
+		if it, err = t.GetTupleArray(name, reg); err != nil {
+			return nil, err
+		}
+
+		array := make([]json.RawMessage, 0, it.Len())
+		for it.Next() {
+			nested := it.Tuple()
+			var buf bytes.Buffer
+			if err := nested.writeJSON(&buf, reg); err != nil {
+				return nil, err
+			}
+			array = append(array, buf.Bytes())
+		}
+		v, err = array, it.Err()
+	case MapField:
+		var it *MapIterator
+		if it, err = t.GetMapIterator(name); err != nil {
+			return nil, err
+		}
+
+		// object keys are always strings
+		object := make(map[string]interface{}, it.Len())
+		for it.Next() {
+			value := it.Value()
+			if ts, ok := value.(time.Time); ok {
+				value = ts.Format(time.RFC3339Nano)
+			}
+			object[fmt.Sprint(it.Key())] = value
+		}
+		v, err = object, it.Err()
+	default:
+		err = ErrUnsupportedType
+	}
+	return
+}
+
+// FromJSON builds a tuple of the given type from a JSON object using the given buffer. The keys of the object are the field names of the tuple type. Values are type checked against the fields and numeric values must fit into the field type. Timestamps are parsed as RFC3339 strings, enums from their names or numeric values and maps from objects. The integer keys of maps are parsed from the object keys. Null values are treated as missing fields. If a required field is missing, the error from `TupleBuilder.Build` is returned. The types of nested tuples are taken from the `TupleNamespace` and `TupleName` of the field and resolved using the DefaultRegistry.
+func FromJSON(tupleType TupleType, data []byte, buffer []byte) (Tuple, error) {
+	return FromJSONWithRegistry(tupleType, data, buffer, &DefaultRegistry)
+}
+
+// FromJSONWithRegistry builds a tuple from a JSON object the same way as FromJSON. The types of nested tuples are resolved using the given registry.
+func FromJSONWithRegistry(tupleType TupleType, data []byte, buffer []byte, reg *Registry) (Tuple, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return NIL, err
+	}
+
+	builder := NewBuilder(tupleType, buffer)
+	if err := builder.putJSON(object, reg); err != nil {
+		return NIL, err
+	}
+	return builder.Build()
+}
+
+func (b *TupleBuilder) putJSON(object map[string]json.RawMessage, reg *Registry) error {
+
+	// sort the keys so errors are reported in a consistent order
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, _, exists := b.tupleType.field(key)
+		if !exists {
+			return JSONFieldError{key, ErrFieldDoesNotExist}
+		}
+
+		raw := object[key]
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+
+		if err := b.putJSONValue(field, raw, reg); err != nil {
+			return JSONFieldError{key, err}
+		}
+	}
+	return nil
+}
+
+// putJSONValue decodes the JSON value and writes it into the field.
+func (b *TupleBuilder) putJSONValue(field Field, raw json.RawMessage, reg *Registry) error {
+	switch field.Type {
+	case TupleField:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return err
+		}
+
+		nested, err := b.buildJSON(field, object, reg, 0)
+		if err != nil {
+			return err
+		}
+		_, err = b.PutTuple(field.Name, nested)
+		return err
+	case TupleArrayField:
+		var objects []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &objects); err != nil {
+			return err
+		}
+
+		// the tuples are built one after the other
+		tuples := make([]Tuple, len(objects))
+		pending := maxArrayHeaderSize
+		for i, object := range objects {
+			nested, err := b.buildJSON(field, object, reg, pending)
+			if err != nil {
+				return err
+			}
+			tuples[i] = nested
+			pending += tupleSize(nested)
+		}
+		_, err := b.PutTupleArray(field.Name, tuples)
+		return err
+	case EnumField:
+		var name string
+		if err := json.Unmarshal(raw, &name); err == nil {
+			_, err = b.PutEnum(field.Name, name)
+			return err
+		}
+
+		var value uint32
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		_, err := b.PutEnumValue(field.Name, value)
+		return err
+	case TimestampField:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+
+		ts, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		_, err = b.PutTimestamp(field.Name, ts)
+		return err
+	case TimestampArrayField:
+		var array []string
+		if err := json.Unmarshal(raw, &array); err != nil {
+			return err
+		}
+
+		times := make([]time.Time, len(array))
+		for i, s := range array {
+			ts, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return err
+			}
+			times[i] = ts
+		}
+		_, err := b.PutTimestampArray(field.Name, times)
+		return err
+	case MapField:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return err
+		}
+
+		m, err := jsonMap(field, object)
+		if err != nil {
+			return err
+		}
+		_, err = b.PutMap(field.Name, m)
+		return err
+	}
+
+	// decode the value into the widest Go type for the field and let
+	// putValue convert it into the field type
+	typ, err := jsonType(field.Type)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.New(typ)
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return err
+	}
+	return b.putValue(field, value.Elem(), reg)
+}
+
+// jsonMap decodes the keys and values of a JSON object for a map field. Integer keys are parsed from the object keys.
+func jsonMap(field Field, object map[string]json.RawMessage) (map[interface{}]interface{}, error) {
+	keyType, err := jsonType(field.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[interface{}]interface{}, len(object))
+	for k, raw := range object {
+		key := reflect.New(keyType)
+		if field.KeyType == StringField {
+			key.Elem().SetString(k)
+		} else if err := json.Unmarshal([]byte(k), key.Interface()); err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if field.ValueType == TimestampField {
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, err
+			}
+			if value, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, err
+			}
+		} else {
+			valueType, err := jsonType(field.ValueType)
+			if err != nil {
+				return nil, err
+			}
+
+			v := reflect.New(valueType)
+			if err := json.Unmarshal(raw, v.Interface()); err != nil {
+				return nil, err
+			}
+			value = v.Elem().Interface()
+		}
+		m[key.Elem().Interface()] = value
+	}
+	return m, nil
+}
+
+// buildJSON builds a nested tuple from a JSON object. The nested tuple is built in the unused space of the buffer after the pending bytes of other nested tuples, see `TupleBuilder.nested`.
+func (b *TupleBuilder) buildJSON(field Field, object map[string]json.RawMessage, reg *Registry, pending int) (Tuple, error) {
+	tupleType, exists := reg.Get(field.TupleNamespace, field.TupleName)
+	if !exists {
+		return NIL, ErrUnknownTupleType
+	}
+
+	nested := b.nested(tupleType, pending)
+	if err := nested.putJSON(object, reg); err != nil {
+		return NIL, err
+	}
+	return nested.Build()
+}
+
+// jsonType returns the Go type used to decode the JSON value of a field type. Integers are decoded as 64-bit integers and floats as 64-bit floats.
+func jsonType(fieldType FieldType) (reflect.Type, error) {
+	switch fieldType {
+	case Uint8Field, Uint16Field, Uint32Field, Uint64Field:
+		return reflect.TypeOf(uint64(0)), nil
+	case Int8Field, Int16Field, Int32Field, Int64Field:
+		return reflect.TypeOf(int64(0)), nil
+	case Float32Field, Float64Field:
+		return reflect.TypeOf(float64(0)), nil
+	case StringField:
+		return reflect.TypeOf(""), nil
+	case BooleanField:
+		return reflect.TypeOf(false), nil
+	case Uint8ArrayField, Int8ArrayField, Uint16ArrayField, Int16ArrayField,
+		Uint32ArrayField, Int32ArrayField, Uint64ArrayField, Int64ArrayField,
+		Float32ArrayField, Float64ArrayField, StringArrayField, BooleanArrayField:
+
+		// the array type always follows the scalar type
+		elem, err := jsonType(fieldType - 1)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	}
+	return nil, ErrUnsupportedType
+}
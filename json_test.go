@@ -0,0 +1,208 @@
+package namedtuple
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestJSONTypes() (TupleType, TupleType, Registry) {
+	Location := createTestLocationType()
+
+	Event := New("testing", "event")
+	Event.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "count", Required: true, Type: Uint8Field},
+		Field{Name: "offset", Required: false, Type: Int64Field},
+		Field{Name: "active", Required: false, Type: BooleanField},
+		Field{Name: "created", Required: false, Type: TimestampField},
+		Field{Name: "bytes", Required: false, Type: Uint8ArrayField},
+		Field{Name: "tags", Required: false, Type: StringArrayField},
+		Field{Name: "location", Required: false, Type: TupleField, TupleNamespace: "testing", TupleName: "Location"},
+	)
+	Event.AddVersion(
+		Field{Name: "stops", Required: false, Type: TupleArrayField, TupleNamespace: "testing", TupleName: "Location"},
+		Field{Name: "times", Required: false, Type: TimestampArrayField},
+	)
+
+	reg := NewRegistry()
+	reg.Register(Location)
+	reg.Register(Event)
+	return Event, Location, reg
+}
+
+func TestToJSON(t *testing.T) {
+	Event, Location, reg := createTestJSONTypes()
+
+	loc := Location.Builder(make([]byte, 256))
+	loc.PutFloat32("lon", 1.5)
+	loc.PutFloat32("lat", -2.5)
+	location, err := loc.Build()
+	assert.Nil(t, err)
+
+	builder := Event.Builder(make([]byte, 1024))
+	builder.PutString("name", "launch")
+	builder.PutUint8("count", 3)
+	builder.PutBool("active", true)
+	builder.PutTimestamp("created", time.Date(2015, 1, 2, 3, 4, 5, 0, time.UTC))
+	builder.PutUint8Array("bytes", []uint8{1, 2, 255})
+	builder.PutStringArray("tags", []string{"a", "b"})
+	builder.PutTuple("location", location)
+	builder.PutTupleArray("stops", []Tuple{location})
+	event, err := builder.Build()
+	assert.Nil(t, err)
+
+	b, err := ToJSONWithRegistry(event, &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"launch","count":3,"active":true,"created":"2015-01-02T03:04:05Z","bytes":[1,2,255],"tags":["a","b"],"location":{"lon":1.5,"lat":-2.5},"stops":[{"lon":1.5,"lat":-2.5}]}`, string(b))
+
+	// nested types must be registered
+	empty := NewRegistry()
+	_, err = ToJSONWithRegistry(event, &empty)
+	assert.Equal(t, ErrUnknownTupleType, err)
+}
+
+func TestFromJSON(t *testing.T) {
+	Event, _, reg := createTestJSONTypes()
+
+	data := `{
+		"name": "launch",
+		"count": 3,
+		"offset": -42,
+		"active": null,
+		"created": "2015-01-02T03:04:05.5Z",
+		"bytes": [1, 2, 255],
+		"location": {"lon": 1.5, "lat": -2.5},
+		"stops": [{"lon": 1, "lat": 2}, {"lon": 3, "lat": 4}],
+		"times": ["2015-01-02T03:04:05Z"]
+	}`
+	event, err := FromJSONWithRegistry(Event, []byte(data), make([]byte, 1024), &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(2), event.Header.TupleVersion)
+
+	count, err := event.GetUint8("count")
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(3), count)
+
+	offset, err := event.GetInt64("offset")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-42), offset)
+
+	_, err = event.GetBool("active")
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	created, err := event.GetTimestamp("created")
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2015, 1, 2, 3, 4, 5, 500000000, time.UTC).Equal(created))
+
+	bytes, err := event.GetUint8Array("bytes")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{1, 2, 255}, bytes)
+
+	// round trip
+	b, err := ToJSONWithRegistry(event, &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"launch","count":3,"offset":-42,"created":"2015-01-02T03:04:05.5Z","bytes":[1,2,255],"location":{"lon":1.5,"lat":-2.5},"stops":[{"lon":1,"lat":2},{"lon":3,"lat":4}],"times":["2015-01-02T03:04:05Z"]}`, string(b))
+}
+
+func TestFromJSONErrors(t *testing.T) {
+	Event, _, reg := createTestJSONTypes()
+	buffer := make([]byte, 1024)
+
+	// invalid json
+	_, err := FromJSONWithRegistry(Event, []byte(`{"name"`), buffer, &reg)
+	assert.NotNil(t, err)
+
+	// missing required field
+	_, err = FromJSONWithRegistry(Event, []byte(`{"name": "launch"}`), buffer, &reg)
+	assert.EqualError(t, err, "Missing required field: count")
+
+	// unknown field
+	_, err = FromJSONWithRegistry(Event, []byte(`{"name": "launch", "color": "red"}`), buffer, &reg)
+	assert.Equal(t, JSONFieldError{"color", ErrFieldDoesNotExist}, err)
+
+	// overflow
+	_, err = FromJSONWithRegistry(Event, []byte(`{"name": "launch", "count": 256}`), buffer, &reg)
+	assert.Equal(t, JSONFieldError{"count", ErrValueOverflow}, err)
+
+	// incorrect type
+	_, err = FromJSONWithRegistry(Event, []byte(`{"name": 5, "count": 1}`), buffer, &reg)
+	assert.IsType(t, JSONFieldError{}, err)
+	assert.Equal(t, "name", err.(JSONFieldError).Field)
+
+	// invalid timestamp
+	_, err = FromJSONWithRegistry(Event, []byte(`{"name": "launch", "count": 1, "created": "yesterday"}`), buffer, &reg)
+	assert.Equal(t, "created", err.(JSONFieldError).Field)
+
+	// nested error
+	_, err = FromJSONWithRegistry(Event, []byte(`{"name": "launch", "count": 1, "location": {"lon": 1}}`), buffer, &reg)
+	assert.EqualError(t, err, "Invalid JSON value for field 'location': Missing required field: lat")
+
+	// nested types must be registered
+	empty := NewRegistry()
+	_, err = FromJSONWithRegistry(Event, []byte(`{"name": "launch", "count": 1, "location": {"lon": 1, "lat": 2}}`), buffer, &empty)
+	assert.Equal(t, JSONFieldError{"location", ErrUnknownTupleType}, err)
+}
+
+func TestJSONDefaultRegistry(t *testing.T) {
+	Location := createTestLocationType()
+	loc, err := FromJSON(Location, []byte(`{"lon": 1.5, "lat": 2.5}`), make([]byte, 256))
+	assert.Nil(t, err)
+
+	b, err := ToJSON(loc)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"lon":1.5,"lat":2.5}`, string(b))
+}
+
+func TestToJSONDefaults(t *testing.T) {
+	builder := NewBuilder(createTestDefaultType(), make([]byte, 1024))
+	builder.PutString("name", "namedtuple")
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	// default values of fields which were not written are omitted
+	b, err := ToJSON(tuple)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"namedtuple"}`, string(b))
+}
+
+func TestJSONEnum(t *testing.T) {
+	Account := createTestEnumType(true)
+	buffer := make([]byte, 1024)
+
+	// names and numbers are both accepted
+	account, err := FromJSON(Account, []byte(`{"status": "DISABLED", "previous": 7}`), buffer)
+	assert.Nil(t, err)
+
+	// unknown values of open enums are written as numbers
+	b, err := ToJSON(account)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"status":"DISABLED","previous":7}`, string(b))
+
+	_, err = FromJSON(Account, []byte(`{"status": "UNKNOWN"}`), buffer)
+	assert.Equal(t, JSONFieldError{"status", ErrUnknownEnumValue}, err)
+}
+
+func TestJSONMap(t *testing.T) {
+	Inventory := createTestMapType()
+	buffer := make([]byte, 1024)
+
+	data := `{
+		"counts": {"b": 2, "a": 1},
+		"updated": {"-1": "2015-01-01T00:00:00Z"},
+		"flags": {"3": true}
+	}`
+	inventory, err := FromJSON(Inventory, []byte(data), buffer)
+	assert.Nil(t, err)
+
+	b, err := ToJSON(inventory)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"counts":{"a":1,"b":2},"updated":{"-1":"2015-01-01T00:00:00Z"},"flags":{"3":true}}`, string(b))
+
+	// integer keys are parsed
+	_, err = FromJSON(Inventory, []byte(`{"flags": {"one": true}}`), buffer)
+	assert.NotNil(t, err)
+	_, err = FromJSON(Inventory, []byte(`{"flags": {"256": true}}`), buffer)
+	assert.Equal(t, JSONFieldError{"flags", ErrValueOverflow}, err)
+}
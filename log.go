@@ -0,0 +1,537 @@
+package namedtuple
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+var (
+	// ErrInvalidLog is returned if a file does not start with a valid tuple log header.
+	ErrInvalidLog = errors.New("Invalid tuple log header")
+
+	// ErrLogNotClosed is returned when reading a tuple log without an index and footer. The log was not closed, use `RecoverLog` to restore the index.
+	ErrLogNotClosed = errors.New("Tuple log was not closed")
+
+	// ErrCorruptLog is returned if the index of a tuple log does not match its checksum or a tuple before the end of the log can not be decoded.
+	ErrCorruptLog = errors.New("Tuple log is corrupt")
+
+	// ErrRecordOutOfRange is returned when reading a record number which is not in the tuple log.
+	ErrRecordOutOfRange = errors.New("Record number is out of range")
+
+	// ErrLogClosed is returned when writing to a tuple log which was closed.
+	ErrLogClosed = errors.New("Tuple log is closed")
+)
+
+const (
+	// LogFormatVersion is the version of the tuple log file format.
+	LogFormatVersion = 1
+
+	// DefaultLogBlockSize is the default number of records in a block of a tuple log. The index contains the offset of the first record of each block.
+	DefaultLogBlockSize = 256
+
+	// logHeaderSize is the size of the magic, the format version, the block size and the schema length
+	logHeaderSize = 20
+
+	// logFooterSize is the size of the index offset, the record count, the checksum and the magic
+	logFooterSize = 28
+
+	// logIndexEntrySize is the size of the record number and the offset of a block
+	logIndexEntrySize = 16
+)
+
+var (
+	// logMagic starts every tuple log
+	logMagic = []byte("NTLOG\x00")
+
+	// logIndexMagic starts the index. Its first byte is not a valid protocol header, so a scan of the tuples stops at the index.
+	logIndexMagic = []byte("NTLOGIDX")
+
+	// logFooterMagic ends a tuple log which was closed
+	logFooterMagic = []byte("NTLOGEND")
+)
+
+// logIndexEntry is the record number and the offset of the first record of a block
+type logIndexEntry struct {
+	Record int64
+	Offset int64
+}
+
+// offsetWriter counts the bytes written so the offset of each record is known
+type offsetWriter struct {
+	w      io.Writer
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.Write(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+// LogWriter appends tuples to a tuple log. A tuple log is a container file which starts with a header holding a snapshot of the registry, followed by the tuples encoded with protocol version 2, a sparse index and a footer. The tuples are grouped into blocks of a fixed number of records and the index holds the offset of the first record of each block, which allows random access by record number. The index and footer are written by `Close`. Logs which were not closed can be recovered with `RecoverLog` or reopened with `AppendLog`.
+type LogWriter struct {
+	buffer    *bufio.Writer
+	out       *offsetWriter
+	encoder   Encoder
+	reg       *Registry
+	file      *os.File
+	blockSize int
+	records   int64
+	index     []logIndexEntry
+	err       error
+}
+
+// NewLogWriter creates a tuple log in the given writer. The header and a snapshot of the tuple types in the registry are written immediately. Only tuples of the types in the snapshot can be written to the log. If the block size is not greater than 0, `DefaultLogBlockSize` is used.
+func NewLogWriter(w io.Writer, reg *Registry, blockSize int) (*LogWriter, error) {
+	if blockSize <= 0 {
+		blockSize = DefaultLogBlockSize
+	}
+
+	var schema bytes.Buffer
+	if err := writeRegistry(&schema, reg); err != nil {
+		return nil, err
+	}
+
+	// the writer uses the snapshot so types registered later are rejected
+	snapshot, err := readRegistry(schema.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	// write magic, format version, block size and schema length
+	header := make([]byte, logHeaderSize)
+	copy(header, logMagic)
+	header[6] = LogFormatVersion
+	binary.LittleEndian.PutUint32(header[8:], uint32(blockSize))
+	binary.LittleEndian.PutUint64(header[12:], uint64(schema.Len()))
+
+	// the checksum covers the header and the schema
+	var checksum [4]byte
+	binary.LittleEndian.PutUint32(checksum[:], crc32.Update(crc32.Checksum(header, castagnoli), castagnoli, schema.Bytes()))
+
+	l := newLogWriter(w, snapshot, blockSize, 0)
+	for _, p := range [][]byte{header, schema.Bytes(), checksum[:]} {
+		if _, err := l.out.Write(p); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// CreateLog creates or truncates the file and creates a tuple log in it. See `NewLogWriter`. The file is closed by `Close`.
+func CreateLog(path string, reg *Registry, blockSize int) (*LogWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := NewLogWriter(file, reg, blockSize)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	l.file = file
+	return l, nil
+}
+
+// AppendLog opens an existing tuple log to append more tuples. The index and footer of a closed log are removed and written again by `Close`. If the log was not closed, the tuples are scanned to restore the index and a torn tuple at the end of the log is truncated. The snapshot of the registry and the block size of the log are kept.
+func AppendLog(path string) (*LogWriter, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := appendLog(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func appendLog(file *os.File) (*LogWriter, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := readLogHeader(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := readLogFooter(file, info.Size(), header)
+	if err == ErrLogNotClosed {
+		log, err = scanLog(file, info.Size(), header)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// remove the index and footer or the torn tuple
+	if err := file.Truncate(log.end); err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(log.end, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	l := newLogWriter(file, header.reg, header.blockSize, log.end)
+	l.file = file
+	l.records = log.records
+	l.index = log.index
+	return l, nil
+}
+
+// RecoverLog restores the index and footer of a tuple log which was not closed, for example after a crash. A torn tuple at the end of the log is truncated. The number of records in the log is returned. Logs which were closed are not changed.
+func RecoverLog(path string) (int64, error) {
+	l, err := AppendLog(path)
+	if err != nil {
+		return 0, err
+	}
+	return l.Len(), l.Close()
+}
+
+func newLogWriter(w io.Writer, reg *Registry, blockSize int, offset int64) *LogWriter {
+	buffer := bufio.NewWriter(w)
+	out := &offsetWriter{buffer, offset}
+
+	// protocol version 2 adds a checksum to each tuple
+	encoder, _ := NewEncoderVersion(out, ProtocolVersionTwo)
+	return &LogWriter{buffer: buffer, out: out, encoder: encoder, reg: reg, blockSize: blockSize}
+}
+
+// Registry returns the snapshot of the tuple types stored in the log.
+func (l *LogWriter) Registry() *Registry {
+	return l.reg
+}
+
+// Len returns the number of records in the log.
+func (l *LogWriter) Len() int64 {
+	return l.records
+}
+
+// Encode appends the tuple to the log. The tuple type must be in the snapshot of the registry, otherwise `ErrUnknownTupleType` is returned. If the versions of the type the tuple was written with do not match the snapshot, `ErrSchemaMismatch` is returned. If writing fails, the log can not be written anymore and the error is returned by every following call.
+func (l *LogWriter) Encode(t Tuple) error {
+	if l.err != nil {
+		return l.err
+	}
+
+	tupleType, exists := l.reg.GetWithHash(t.Header.NamespaceHash, t.Header.Hash)
+	if !exists {
+		return ErrUnknownTupleType
+	}
+	version := int(t.Header.TupleVersion)
+	if version > tupleType.NumVersions() || tupleType.Fingerprint(version) != t.Header.Type.Fingerprint(version) {
+		return ErrSchemaMismatch
+	}
+
+	// the first record of each block is indexed
+	if l.records%int64(l.blockSize) == 0 {
+		l.index = append(l.index, logIndexEntry{l.records, l.out.offset})
+	}
+
+	if l.err = l.encoder.Encode(t); l.err != nil {
+		return l.err
+	}
+	l.records++
+	return nil
+}
+
+// Flush writes the buffered tuples to the underlying writer. If the log was created with `CreateLog` or `AppendLog`, the file is synced to stable storage as well.
+func (l *LogWriter) Flush() error {
+	if l.err != nil {
+		return l.err
+	}
+	if l.err = l.buffer.Flush(); l.err != nil {
+		return l.err
+	}
+	if l.file != nil {
+		return l.file.Sync()
+	}
+	return nil
+}
+
+// Close writes the index and the footer and flushes the log. If the log was created with `CreateLog` or `AppendLog`, the file is closed as well. The log can not be written after it was closed.
+func (l *LogWriter) Close() error {
+	if l.err == ErrLogClosed {
+		return l.err
+	}
+
+	err := l.writeIndex()
+	if err == nil {
+		err = l.Flush()
+	}
+	if l.file != nil {
+		if closeErr := l.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	l.err = ErrLogClosed
+	return err
+}
+
+// writeIndex writes the index magic, the index entries and the footer.
+func (l *LogWriter) writeIndex() error {
+	if l.err != nil {
+		return l.err
+	}
+
+	index := make([]byte, len(logIndexMagic)+len(l.index)*logIndexEntrySize+logFooterSize)
+	copy(index, logIndexMagic)
+	pos := len(logIndexMagic)
+	for _, entry := range l.index {
+		binary.LittleEndian.PutUint64(index[pos:], uint64(entry.Record))
+		binary.LittleEndian.PutUint64(index[pos+8:], uint64(entry.Offset))
+		pos += logIndexEntrySize
+	}
+
+	// the checksum covers the index, the index offset and the record count
+	binary.LittleEndian.PutUint64(index[pos:], uint64(l.out.offset))
+	binary.LittleEndian.PutUint64(index[pos+8:], uint64(l.records))
+	binary.LittleEndian.PutUint32(index[pos+16:], crc32.Checksum(index[:pos+16], castagnoli))
+	copy(index[pos+20:], logFooterMagic)
+
+	_, l.err = l.out.Write(index)
+	return l.err
+}
+
+// logHeader is the registry snapshot and the block size of a log as well as the offset of the first record
+type logHeader struct {
+	reg       *Registry
+	blockSize int
+	start     int64
+}
+
+// logContent is the index and the number of records of a log as well as the offset after the last record
+type logContent struct {
+	index   []logIndexEntry
+	records int64
+	end     int64
+}
+
+// readLogHeader verifies the magic, the format version and the checksum of the header and reads the registry snapshot.
+func readLogHeader(r io.ReaderAt, size int64) (logHeader, error) {
+	if size < logHeaderSize+4 {
+		return logHeader{}, ErrInvalidLog
+	}
+
+	header := make([]byte, logHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil || !bytes.Equal(header[:6], logMagic) || header[6] != LogFormatVersion {
+		return logHeader{}, ErrInvalidLog
+	}
+
+	// the schema and its checksum must fit into the log
+	blockSize := binary.LittleEndian.Uint32(header[8:])
+	length := binary.LittleEndian.Uint64(header[12:])
+	if blockSize == 0 || length > math.MaxInt64 || int64(length) > size-logHeaderSize-4 {
+		return logHeader{}, ErrInvalidLog
+	}
+
+	// read schema and checksum
+	schema := make([]byte, length+4)
+	if _, err := r.ReadAt(schema, logHeaderSize); err != nil {
+		return logHeader{}, ErrInvalidLog
+	}
+	checksum := crc32.Update(crc32.Checksum(header, castagnoli), castagnoli, schema[:length])
+	if checksum != binary.LittleEndian.Uint32(schema[length:]) {
+		return logHeader{}, ErrInvalidLog
+	}
+
+	reg, err := readRegistry(schema[:length])
+	if err != nil {
+		return logHeader{}, err
+	}
+	return logHeader{reg, int(blockSize), logHeaderSize + int64(length) + 4}, nil
+}
+
+// readLogFooter reads the footer and the index. If the log does not end with a footer, `ErrLogNotClosed` is returned.
+func readLogFooter(r io.ReaderAt, size int64, header logHeader) (logContent, error) {
+	footer := make([]byte, logFooterSize)
+	if size < header.start+int64(len(logIndexMagic))+logFooterSize {
+		return logContent{}, ErrLogNotClosed
+	} else if _, err := r.ReadAt(footer, size-logFooterSize); err != nil {
+		return logContent{}, err
+	} else if !bytes.Equal(footer[20:], logFooterMagic) {
+		return logContent{}, ErrLogNotClosed
+	}
+
+	end := int64(binary.LittleEndian.Uint64(footer))
+	records := int64(binary.LittleEndian.Uint64(footer[8:]))
+	length := size - logFooterSize - end
+	if end < header.start || length < int64(len(logIndexMagic)) || (length-int64(len(logIndexMagic)))%logIndexEntrySize != 0 {
+		return logContent{}, ErrCorruptLog
+	}
+
+	// verify the checksum of the index, the index offset and the record count
+	index := make([]byte, length)
+	if _, err := r.ReadAt(index, end); err != nil {
+		return logContent{}, err
+	}
+	checksum := crc32.Update(crc32.Checksum(index, castagnoli), castagnoli, footer[:16])
+	if !bytes.Equal(index[:len(logIndexMagic)], logIndexMagic) || checksum != binary.LittleEndian.Uint32(footer[16:]) {
+		return logContent{}, ErrCorruptLog
+	}
+
+	content := logContent{records: records, end: end}
+	for pos := len(logIndexMagic); pos < len(index); pos += logIndexEntrySize {
+		entry := logIndexEntry{int64(binary.LittleEndian.Uint64(index[pos:])), int64(binary.LittleEndian.Uint64(index[pos+8:]))}
+		if entry.Offset < header.start || entry.Offset >= end || entry.Record >= records {
+			return logContent{}, ErrCorruptLog
+		}
+		content.index = append(content.index, entry)
+	}
+	return content, nil
+}
+
+// scanLog decodes every tuple of a log which was not closed to restore the index. The scan stops at the end of the file or at the index magic of a log which was being closed. A tuple which extends past the end of the file is torn and the log ends before it. Complete tuples which can not be decoded, for example because they do not match their checksum, return `ErrCorruptLog`.
+func scanLog(r io.ReaderAt, size int64, header logHeader) (logContent, error) {
+	reader := NewTupleReader(header.reg, r, uint64(size))
+	content := logContent{end: header.start}
+	magic := make([]byte, len(logIndexMagic))
+	for content.end < size {
+
+		// the log was being closed
+		if n, _ := r.ReadAt(magic, content.end); n == len(magic) && bytes.Equal(magic, logIndexMagic) {
+			break
+		}
+
+		_, next, err := reader.ReadAt(content.end)
+		if err != nil {
+			_, length, start, headerErr := readFrameHeader(r, content.end)
+			if headerErr == io.ErrUnexpectedEOF || (headerErr == nil && length > uint64(size-start)) {
+				break
+			}
+			return logContent{}, ErrCorruptLog
+		}
+
+		if content.records%int64(header.blockSize) == 0 {
+			content.index = append(content.index, logIndexEntry{content.records, content.end})
+		}
+		content.records++
+		content.end = next
+	}
+	return content, nil
+}
+
+// LogReader reads the tuples of a tuple log written by a `LogWriter`. The tuple types are resolved using the registry snapshot stored in the log, so the log can be read without the code which wrote it. Tuples can be read sequentially with `Next` or by record number with `Read`. The content of each tuple is read into a buffer which is reused, so tuples returned from the reader are only valid until the next call.
+type LogReader struct {
+	header  logHeader
+	content logContent
+	reader  *TupleReader
+	file    *os.File
+	record  int64
+	offset  int64
+}
+
+// NewLogReader reads the header, the index and the footer of the tuple log of the given size. If the log was not closed, `ErrLogNotClosed` is returned.
+func NewLogReader(r io.ReaderAt, size int64) (*LogReader, error) {
+	header, err := readLogHeader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := readLogFooter(r, size, header)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := NewTupleReader(header.reg, r, uint64(size))
+	return &LogReader{header: header, content: content, reader: reader, offset: header.start}, nil
+}
+
+// OpenLog opens the file and reads the tuple log in it. See `NewLogReader`. The file is closed by `Close`.
+func OpenLog(path string) (*LogReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	l, err := NewLogReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	l.file = file
+	return l, nil
+}
+
+// Registry returns the snapshot of the tuple types stored in the log.
+func (l *LogReader) Registry() *Registry {
+	return l.header.reg
+}
+
+// Len returns the number of records in the log.
+func (l *LogReader) Len() int64 {
+	return l.content.records
+}
+
+// Next reads the next record. At the end of the log, `io.EOF` is returned.
+func (l *LogReader) Next() (Tuple, error) {
+	if l.record >= l.content.records {
+		return EmptyTuple, io.EOF
+	}
+
+	t, next, err := l.reader.ReadAt(l.offset)
+	if err != nil {
+		return EmptyTuple, err
+	}
+	l.record++
+	l.offset = next
+	return t, nil
+}
+
+// SeekRecord moves the reader to the given record number, so it is returned by the next call to `Next`. The block of the record is looked up in the index and the records before it in the same block are skipped without decoding them. If the log does not contain the record, `ErrRecordOutOfRange` is returned.
+func (l *LogReader) SeekRecord(record int64) error {
+	if record < 0 || record >= l.content.records {
+		return ErrRecordOutOfRange
+	}
+
+	index := l.content.index
+	i := sort.Search(len(index), func(i int) bool { return index[i].Record > record }) - 1
+	if i < 0 {
+		return ErrCorruptLog
+	}
+
+	offset := index[i].Offset
+	for skip := record - index[i].Record; skip > 0; skip-- {
+		_, length, start, err := readFrameHeader(l.reader.r, offset)
+		if err != nil {
+			return err
+		}
+		offset = start + int64(length)
+	}
+
+	l.record = record
+	l.offset = offset
+	return nil
+}
+
+// Read reads the record with the given number. See `SeekRecord`.
+func (l *LogReader) Read(record int64) (Tuple, error) {
+	if err := l.SeekRecord(record); err != nil {
+		return EmptyTuple, err
+	}
+	return l.Next()
+}
+
+// Close closes the file if the log was opened with `OpenLog`.
+func (l *LogReader) Close() error {
+	if l.file != nil {
+		return l.file.Close()
+	}
+	return nil
+}
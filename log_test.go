@@ -0,0 +1,256 @@
+package namedtuple
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestLog writes a location tuple for each longitude to the log
+func writeTestLog(t *testing.T, log *LogWriter, first, count int) {
+	Location := createTestLocationType()
+	builder := Location.Builder(make([]byte, 256))
+	for i := first; i < first+count; i++ {
+		builder.PutFloat32("lon", float32(i))
+		builder.PutFloat32("lat", 50.5)
+		loc, err := builder.Build()
+		assert.Nil(t, err)
+		assert.Nil(t, log.Encode(loc))
+	}
+}
+
+// assertTestLog reads each record of the log by record number
+func assertTestLog(t *testing.T, log *LogReader, records int) {
+	assert.Equal(t, int64(records), log.Len())
+	for _, record := range []int{0, records / 2, records - 1, 1} {
+		loc, err := log.Read(int64(record))
+		assert.Nil(t, err)
+
+		lon, err := loc.GetFloat32("lon")
+		assert.Nil(t, err)
+		assert.Equal(t, float32(record), lon)
+	}
+}
+
+func createTestLogRegistry() Registry {
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+	return reg
+}
+
+func TestLogWriteRead(t *testing.T) {
+	reg := createTestLogRegistry()
+
+	var buffer bytes.Buffer
+	log, err := NewLogWriter(&buffer, &reg, 16)
+	assert.Nil(t, err)
+	writeTestLog(t, log, 0, 100)
+	assert.Equal(t, int64(100), log.Len())
+	assert.Nil(t, log.Close())
+	assert.Equal(t, ErrLogClosed, log.Encode(EmptyTuple))
+
+	reader, err := NewLogReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(reader.content.index))
+	assert.True(t, reader.Registry().ContainsName("testing", "Location"))
+	assertTestLog(t, reader, 100)
+
+	// sequential reads continue after the last record read
+	loc, err := reader.Next()
+	assert.Nil(t, err)
+	lon, _ := loc.GetFloat32("lon")
+	assert.Equal(t, float32(2), lon)
+
+	assert.Nil(t, reader.SeekRecord(98))
+	_, err = reader.Next()
+	assert.Nil(t, err)
+	_, err = reader.Next()
+	assert.Nil(t, err)
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = reader.Read(100)
+	assert.Equal(t, ErrRecordOutOfRange, err)
+	_, err = reader.Read(-1)
+	assert.Equal(t, ErrRecordOutOfRange, err)
+}
+
+func TestLogWriterSnapshot(t *testing.T) {
+	reg := createTestLogRegistry()
+
+	var buffer bytes.Buffer
+	log, err := NewLogWriter(&buffer, &reg, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultLogBlockSize, log.blockSize)
+
+	// types registered after the log was created are not in the snapshot
+	Message := createTestMessageType()
+	reg.Register(Message)
+	builder := Message.Builder(make([]byte, 256))
+	builder.PutString("userid", "eliquious")
+	builder.PutString("payload", "payload")
+	msg, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, ErrUnknownTupleType, log.Encode(msg))
+
+	// tuples of a different version of a type in the snapshot
+	Location := createTestLocationType()
+	Location.AddVersion(Field{Name: "name", Type: StringField})
+	builder = Location.Builder(make([]byte, 256))
+	builder.PutFloat32("lon", 1)
+	builder.PutFloat32("lat", 2)
+	builder.PutString("name", "name")
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, ErrSchemaMismatch, log.Encode(loc))
+	assert.Equal(t, int64(0), log.Len())
+}
+
+func TestLogInvalid(t *testing.T) {
+	reg := createTestLogRegistry()
+
+	var buffer bytes.Buffer
+	log, err := NewLogWriter(&buffer, &reg, 4)
+	assert.Nil(t, err)
+	writeTestLog(t, log, 0, 10)
+	assert.Nil(t, log.Flush())
+
+	// the log was not closed
+	b := buffer.Bytes()
+	_, err = NewLogReader(bytes.NewReader(b), int64(len(b)))
+	assert.Equal(t, ErrLogNotClosed, err)
+
+	assert.Nil(t, log.Close())
+	b = buffer.Bytes()
+
+	// the index does not match its checksum
+	corrupt := append([]byte{}, b...)
+	corrupt[len(corrupt)-logFooterSize-1]++
+	_, err = NewLogReader(bytes.NewReader(corrupt), int64(len(corrupt)))
+	assert.Equal(t, ErrCorruptLog, err)
+
+	// the schema does not match the checksum of the header
+	corrupt = append([]byte{}, b...)
+	corrupt[logHeaderSize+4]++
+	_, err = NewLogReader(bytes.NewReader(corrupt), int64(len(corrupt)))
+	assert.Equal(t, ErrInvalidLog, err)
+
+	_, err = NewLogReader(bytes.NewReader([]byte("NTLOG")), 5)
+	assert.Equal(t, ErrInvalidLog, err)
+
+	// the schema length does not fit into a log which is shorter than the header and the checksum
+	for _, length := range []uint64{1 << 40, math.MaxUint64} {
+		short := append([]byte{}, b[:logHeaderSize+2]...)
+		binary.LittleEndian.PutUint64(short[12:], length)
+		_, err = NewLogReader(bytes.NewReader(short), int64(len(short)))
+		assert.Equal(t, ErrInvalidLog, err)
+
+		corrupt = append([]byte{}, b...)
+		binary.LittleEndian.PutUint64(corrupt[12:], length)
+		_, err = NewLogReader(bytes.NewReader(corrupt), int64(len(corrupt)))
+		assert.Equal(t, ErrInvalidLog, err)
+	}
+}
+
+func TestLogAppend(t *testing.T) {
+	reg := createTestLogRegistry()
+	path := filepath.Join(t.TempDir(), "locations.ntlog")
+
+	log, err := CreateLog(path, &reg, 8)
+	assert.Nil(t, err)
+	writeTestLog(t, log, 0, 20)
+	assert.Nil(t, log.Close())
+
+	// the block size and the registry of the log are kept
+	log, err = AppendLog(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, log.blockSize)
+	assert.Equal(t, int64(20), log.Len())
+	writeTestLog(t, log, 20, 30)
+	assert.Nil(t, log.Close())
+
+	reader, err := OpenLog(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(reader.content.index))
+	assertTestLog(t, reader, 50)
+	assert.Nil(t, reader.Close())
+
+	_, err = AppendLog(filepath.Join(t.TempDir(), "missing.ntlog"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLogRecover(t *testing.T) {
+	reg := createTestLogRegistry()
+	path := filepath.Join(t.TempDir(), "locations.ntlog")
+
+	// a crash before the log was closed leaves a torn tuple at the end
+	log, err := CreateLog(path, &reg, 8)
+	assert.Nil(t, err)
+	writeTestLog(t, log, 0, 30)
+	assert.Nil(t, log.Flush())
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Nil(t, os.Truncate(path, info.Size()-3))
+
+	_, err = OpenLog(path)
+	assert.Equal(t, ErrLogNotClosed, err)
+
+	records, err := RecoverLog(path)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(29), records)
+
+	reader, err := OpenLog(path)
+	assert.Nil(t, err)
+	assertTestLog(t, reader, 29)
+	assert.Nil(t, reader.Close())
+
+	// recovering a closed log does not change it
+	info, err = os.Stat(path)
+	assert.Nil(t, err)
+	records, err = RecoverLog(path)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(29), records)
+	recovered, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, info.Size(), recovered.Size())
+}
+
+func TestLogRecoverCorrupt(t *testing.T) {
+	reg := createTestLogRegistry()
+
+	var buffer bytes.Buffer
+	log, err := NewLogWriter(&buffer, &reg, 8)
+	assert.Nil(t, err)
+	writeTestLog(t, log, 0, 10)
+	assert.Nil(t, log.Flush())
+	b := buffer.Bytes()
+
+	header, err := readLogHeader(bytes.NewReader(b), int64(len(b)))
+	assert.Nil(t, err)
+
+	// the last tuple is incomplete
+	for _, size := range []int{len(b) - 1, len(b) - 5} {
+		content, err := scanLog(bytes.NewReader(b[:size]), int64(size), header)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(9), content.records)
+		assert.Equal(t, 2, len(content.index))
+	}
+
+	// the last tuple is complete but does not match its checksum
+	corrupt := append([]byte{}, b...)
+	corrupt[len(corrupt)-1]++
+	_, err = scanLog(bytes.NewReader(corrupt), int64(len(corrupt)), header)
+	assert.Equal(t, ErrCorruptLog, err)
+
+	// tuples before the end of the log do not match their checksum
+	corrupt = append([]byte{}, b...)
+	corrupt[header.start+10]++
+	_, err = scanLog(bytes.NewReader(corrupt), int64(len(corrupt)), header)
+	assert.Equal(t, ErrCorruptLog, err)
+}
@@ -0,0 +1,350 @@
+package namedtuple
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/blacklabeldata/xbinary"
+)
+
+// ErrDuplicateMapKey is returned from PutMap if two keys of the map are converted into the same key of the map field type.
+var ErrDuplicateMapKey = errors.New("Duplicate map key")
+
+// mapElement describes the encoding of a scalar type used as a map key or value
+type mapElement struct {
+	Type  reflect.Type
+	Code  TypeCode
+	Width int
+}
+
+// mapElements are the field types which can be used as map keys and values. Numbers are always written with the full width of their type. Strings and booleans are written the same way as `PutString` and `PutBool`.
+var mapElements = map[FieldType]mapElement{
+	Uint8Field:     {reflect.TypeOf(uint8(0)), UnsignedInt8Code, 1},
+	Int8Field:      {reflect.TypeOf(int8(0)), Int8Code, 1},
+	Uint16Field:    {reflect.TypeOf(uint16(0)), UnsignedShort16Code, 2},
+	Int16Field:     {reflect.TypeOf(int16(0)), Short16Code, 2},
+	Uint32Field:    {reflect.TypeOf(uint32(0)), UnsignedInt32Code, 4},
+	Int32Field:     {reflect.TypeOf(int32(0)), Int32Code, 4},
+	Uint64Field:    {reflect.TypeOf(uint64(0)), UnsignedLong64Code, 8},
+	Int64Field:     {reflect.TypeOf(int64(0)), Long64Code, 8},
+	Float32Field:   {reflect.TypeOf(float32(0)), FloatCode, 4},
+	Float64Field:   {reflect.TypeOf(float64(0)), DoubleCode, 8},
+	TimestampField: {timeType, TimestampCode, 8},
+	StringField:    {reflect.TypeOf(""), String8Code, 0},
+	BooleanField:   {reflect.TypeOf(false), TrueCode, 0},
+}
+
+// ValidMapTypes returns true if the field types can be used as the key and value types of a `MapField`. Keys must be strings or integers and values can be any numeric type, a string, a boolean or a timestamp.
+func ValidMapTypes(keyType, valueType FieldType) bool {
+	_, key := mapElements[keyType]
+	_, value := mapElements[valueType]
+	return key && value && (keyType == StringField || keyType < Float32Field)
+}
+
+// PutMap writes a map for the given field. The field type must be a `MapField`, otherwise an error will be returned. The value must be a Go map and each key and value is converted to the key and value types of the field. `ErrValueOverflow` is returned if a number does not fit. The type code is written first, then the number of entries, and finally each key followed by its value. Keys and values are written with their own type code and the entries are sorted by key so equal maps are always encoded the same way. If the buffer does not have enough space for the entire map, an `xbinary.ErrOutOfRange` error will be returned. If successful, the number of bytes written will be returned as well as a nil error.
+func (b *TupleBuilder) PutMap(field string, value interface{}) (wrote int, err error) {
+
+	// field type should be
+	if err = b.typeCheck(field, MapField); err != nil {
+		return 0, err
+	}
+
+	def, _, _ := b.tupleType.field(field)
+	if !ValidMapTypes(def.KeyType, def.ValueType) {
+		return 0, ErrUnsupportedType
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map {
+		return 0, ErrIncompatibleType
+	}
+
+	// convert entries
+	entries := make(mapEntries, 0, rv.Len())
+	var size int
+	for _, key := range rv.MapKeys() {
+		k, err := mapElementValue(def.KeyType, key)
+		if err != nil {
+			return 0, err
+		}
+
+		v, err := mapElementValue(def.ValueType, rv.MapIndex(key))
+		if err != nil {
+			return 0, err
+		}
+
+		entries = append(entries, mapEntry{k, v})
+		size += mapElementSize(def.KeyType, k) + mapElementSize(def.ValueType, v)
+	}
+
+	// sort entries by key
+	sort.Sort(entries)
+	for i := 1; i < len(entries); i++ {
+		if entries[i-1].Key == entries[i].Key {
+			return 0, ErrDuplicateMapKey
+		}
+	}
+
+	// write type code and length
+	wrote, err = b.putArrayHeader(Map8Code, len(entries), size)
+	if err != nil {
+		return 0, err
+	}
+
+	// write entries
+	for _, entry := range entries {
+		wrote += putMapElement(b.buffer, b.pos+wrote, def.KeyType, entry.Key)
+		wrote += putMapElement(b.buffer, b.pos+wrote, def.ValueType, entry.Value)
+	}
+
+	b.setOffset(field)
+	b.pos += wrote
+	return
+}
+
+// mapElementValue converts a key or value into the Go type of the given field type.
+func mapElementValue(fieldType FieldType, value reflect.Value) (interface{}, error) {
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil, ErrIncompatibleType
+	}
+
+	v := reflect.New(mapElements[fieldType].Type).Elem()
+	if err := convert(v, value); err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// mapElementSize returns the number of bytes needed to write the key or value including the type code.
+func mapElementSize(fieldType FieldType, value interface{}) int {
+	switch fieldType {
+	case StringField:
+		return stringSize(value.(string))
+	case BooleanField:
+		return 1
+	}
+	return 1 + mapElements[fieldType].Width
+}
+
+// putMapElement writes the type code and the key or value at the given position. The buffer must be large enough to hold the value. The number of bytes written is returned.
+func putMapElement(buffer []byte, pos int, fieldType FieldType, value interface{}) int {
+	switch v := value.(type) {
+	case string:
+		return putString(buffer, pos, v)
+	case bool:
+		if v {
+			buffer[pos] = byte(TrueCode.OpCode)
+		} else {
+			buffer[pos] = byte(FalseCode.OpCode)
+		}
+		return 1
+	case uint8:
+		buffer[pos+1] = byte(v)
+	case int8:
+		buffer[pos+1] = byte(v)
+	case uint16:
+		xbinary.LittleEndian.PutUint16(buffer, pos+1, v)
+	case int16:
+		xbinary.LittleEndian.PutInt16(buffer, pos+1, v)
+	case uint32:
+		xbinary.LittleEndian.PutUint32(buffer, pos+1, v)
+	case int32:
+		xbinary.LittleEndian.PutInt32(buffer, pos+1, v)
+	case uint64:
+		xbinary.LittleEndian.PutUint64(buffer, pos+1, v)
+	case int64:
+		xbinary.LittleEndian.PutInt64(buffer, pos+1, v)
+	case float32:
+		xbinary.LittleEndian.PutFloat32(buffer, pos+1, v)
+	case float64:
+		xbinary.LittleEndian.PutFloat64(buffer, pos+1, v)
+	case time.Time:
+		xbinary.LittleEndian.PutInt64(buffer, pos+1, v.UnixNano())
+	}
+
+	element := mapElements[fieldType]
+	buffer[pos] = byte(element.Code.OpCode)
+	return 1 + element.Width
+}
+
+// mapEntry is a converted key and value
+type mapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// mapEntries sorts entries by key. All the keys must have the same type.
+type mapEntries []mapEntry
+
+func (m mapEntries) Len() int      { return len(m) }
+func (m mapEntries) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m mapEntries) Less(i, j int) bool {
+	if key, ok := m[i].Key.(string); ok {
+		return key < m[j].Key.(string)
+	}
+
+	a, b := reflect.ValueOf(m[i].Key), reflect.ValueOf(m[j].Key)
+	switch a.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	}
+	return a.Int() < b.Int()
+}
+
+// GetMap returns the entries of the map for the given field. The field type must be a `MapField`, otherwise an error will be returned. Keys and values have the Go types returned by the getters of their field types, such as `string` for a `StringField` or `time.Time` for a `TimestampField`. Use `GetMapIterator` to read the entries without copying them into a Go map. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetMap(field string) (map[interface{}]interface{}, error) {
+	it, err := t.GetMapIterator(field)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make(map[interface{}]interface{}, it.Len())
+	for it.Next() {
+		value[it.Key()] = it.Value()
+	}
+	return value, it.Err()
+}
+
+// GetMapIterator returns an iterator over the entries of the map for the given field. The field type must be a `MapField`, otherwise an error will be returned. The entries are read as the iterator advances, in the order of their keys. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetMapIterator(field string) (*MapIterator, error) {
+	pos, length, err := t.readArrayLength(field, MapField, Map8Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// each entry uses at least 3 bytes
+	if length > uint64(len(t.data)-pos)/3 {
+		return nil, xbinary.ErrOutOfRange
+	}
+
+	def, _, _ := t.Header.Type.field(field)
+	if !ValidMapTypes(def.KeyType, def.ValueType) {
+		return nil, ErrUnsupportedType
+	}
+	return &MapIterator{data: t.data, pos: pos, length: int(length), keyType: def.KeyType, valueType: def.ValueType}, nil
+}
+
+// readMapElement reads a key or value with its type code at the given position. The value and the position after the value are returned.
+func (t *Tuple) readMapElement(pos int, fieldType FieldType) (value interface{}, next int, err error) {
+	switch fieldType {
+	case StringField:
+		return t.readString(pos)
+	case BooleanField:
+		opcode, err := t.readUint8(pos)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// the value is stored in the type code
+		switch opcode {
+		case TrueCode.OpCode:
+			return true, pos + 1, nil
+		case FalseCode.OpCode:
+			return false, pos + 1, nil
+		}
+		return nil, 0, ErrInvalidTypeCode
+	}
+
+	// verify type code
+	element := mapElements[fieldType]
+	if opcode, err := t.readUint8(pos); err != nil {
+		return nil, 0, err
+	} else if opcode != element.Code.OpCode {
+		return nil, 0, ErrInvalidTypeCode
+	}
+
+	switch fieldType {
+	case Uint8Field:
+		value, err = t.readUint8(pos + 1)
+	case Int8Field:
+		var v uint8
+		v, err = t.readUint8(pos + 1)
+		value = int8(v)
+	case Uint16Field:
+		value, err = xbinary.LittleEndian.Uint16(t.data, pos+1)
+	case Int16Field:
+		value, err = xbinary.LittleEndian.Int16(t.data, pos+1)
+	case Uint32Field:
+		value, err = xbinary.LittleEndian.Uint32(t.data, pos+1)
+	case Int32Field:
+		value, err = xbinary.LittleEndian.Int32(t.data, pos+1)
+	case Uint64Field:
+		value, err = xbinary.LittleEndian.Uint64(t.data, pos+1)
+	case Int64Field:
+		value, err = xbinary.LittleEndian.Int64(t.data, pos+1)
+	case Float32Field:
+		value, err = xbinary.LittleEndian.Float32(t.data, pos+1)
+	case Float64Field:
+		value, err = xbinary.LittleEndian.Float64(t.data, pos+1)
+	case TimestampField:
+		var v int64
+		v, err = xbinary.LittleEndian.Int64(t.data, pos+1)
+		value = time.Unix(0, v)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, pos + 1 + element.Width, nil
+}
+
+// MapIterator iterates over the entries of a `MapField`. Each key and value is read when the iterator advances. `Next` returns false at the end of the map or if an error occurred, which is returned by `Err`.
+type MapIterator struct {
+	data      []byte
+	pos       int
+	length    int
+	index     int
+	keyType   FieldType
+	valueType FieldType
+	key       interface{}
+	value     interface{}
+	err       error
+}
+
+// Len returns the number of entries in the map.
+func (it *MapIterator) Len() int {
+	return it.length
+}
+
+// Next reads the next entry of the map. It returns false at the end of the map or if an error occurred.
+func (it *MapIterator) Next() bool {
+	it.key, it.value = nil, nil
+	if it.err != nil || it.index >= it.length {
+		return false
+	}
+
+	parent := Tuple{data: it.data}
+	key, pos, err := parent.readMapElement(it.pos, it.keyType)
+	if err == nil {
+		it.value, pos, err = parent.readMapElement(pos, it.valueType)
+	}
+	if err != nil {
+		it.err = err
+		it.value = nil
+		return false
+	}
+
+	it.key = key
+	it.pos = pos
+	it.index++
+	return true
+}
+
+// Key returns the key read by the last call to `Next`.
+func (it *MapIterator) Key() interface{} {
+	return it.key
+}
+
+// Value returns the value read by the last call to `Next`.
+func (it *MapIterator) Value() interface{} {
+	return it.value
+}
+
+// Err returns the first error which occurred while iterating over the map.
+func (it *MapIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,175 @@
+package namedtuple
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blacklabeldata/xbinary"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestMapType() TupleType {
+	Inventory := New("testing", "inventory")
+	Inventory.AddVersion(
+		Field{Name: "counts", Type: MapField, KeyType: StringField, ValueType: Uint32Field},
+		Field{Name: "updated", Type: MapField, KeyType: Int64Field, ValueType: TimestampField},
+		Field{Name: "flags", Type: MapField, KeyType: Uint8Field, ValueType: BooleanField},
+	)
+	return Inventory
+}
+
+func TestValidMapTypes(t *testing.T) {
+	assert.True(t, ValidMapTypes(StringField, StringField))
+	assert.True(t, ValidMapTypes(Int8Field, Float64Field))
+	assert.True(t, ValidMapTypes(Uint64Field, TimestampField))
+
+	// keys must be strings or integers
+	assert.False(t, ValidMapTypes(Float32Field, StringField))
+	assert.False(t, ValidMapTypes(BooleanField, StringField))
+	assert.False(t, ValidMapTypes(TimestampField, StringField))
+
+	// values must be scalars
+	assert.False(t, ValidMapTypes(StringField, StringArrayField))
+	assert.False(t, ValidMapTypes(StringField, TupleField))
+	assert.False(t, ValidMapTypes(StringField, MapField))
+}
+
+func TestBuilderPutMapPass(t *testing.T) {
+	builder := NewBuilder(createTestMapType(), make([]byte, 1024))
+
+	// entries are sorted by key
+	wrote, err := builder.PutMap("counts", map[string]int{"b": 2, "a": 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 18, wrote)
+	assert.Equal(t, []byte{
+		byte(Map8Code.OpCode), 2,
+		byte(String8Code.OpCode), 1, 'a', byte(UnsignedInt32Code.OpCode), 1, 0, 0, 0,
+		byte(String8Code.OpCode), 1, 'b', byte(UnsignedInt32Code.OpCode), 2, 0, 0, 0,
+	}, builder.buffer[:18])
+
+	now := time.Unix(1420070400, 5)
+	_, err = builder.PutMap("updated", map[int64]time.Time{-1: now, 10: now.Add(time.Hour)})
+	assert.Nil(t, err)
+
+	_, err = builder.PutMap("flags", map[interface{}]interface{}{uint8(3): true, 1: false})
+	assert.Nil(t, err)
+
+	inventory, err := builder.Build()
+	assert.Nil(t, err)
+
+	counts, err := inventory.GetMap("counts")
+	assert.Nil(t, err)
+	assert.Equal(t, map[interface{}]interface{}{"a": uint32(1), "b": uint32(2)}, counts)
+
+	updated, err := inventory.GetMap("updated")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(updated))
+	assert.True(t, now.Equal(updated[int64(-1)].(time.Time)))
+	assert.True(t, now.Add(time.Hour).Equal(updated[int64(10)].(time.Time)))
+
+	flags, err := inventory.GetMap("flags")
+	assert.Nil(t, err)
+	assert.Equal(t, map[interface{}]interface{}{uint8(1): false, uint8(3): true}, flags)
+}
+
+func TestBuilderPutMapFail(t *testing.T) {
+	builder := NewBuilder(createTestMapType(), make([]byte, 1024))
+
+	// not a map
+	_, err := builder.PutMap("counts", []string{"a"})
+	assert.Equal(t, ErrIncompatibleType, err)
+
+	// incompatible values
+	_, err = builder.PutMap("counts", map[string]string{"a": "b"})
+	assert.Equal(t, ErrIncompatibleType, err)
+	_, err = builder.PutMap("counts", map[string]int{"a": -1})
+	assert.Equal(t, ErrValueOverflow, err)
+	_, err = builder.PutMap("flags", map[int]bool{256: true})
+	assert.Equal(t, ErrValueOverflow, err)
+
+	// keys which are converted into the same key
+	_, err = builder.PutMap("flags", map[interface{}]interface{}{1: true, uint8(1): false})
+	assert.Equal(t, ErrDuplicateMapKey, err)
+
+	// wrong field type
+	Other := New("testing", "other")
+	Other.AddVersion(Field{Name: "counts", Type: StringArrayField})
+	builder = NewBuilder(Other, make([]byte, 1024))
+	_, err = builder.PutMap("counts", map[string]int{})
+	assert.NotNil(t, err)
+
+	// unsupported key type
+	Other = New("testing", "other")
+	Other.AddVersion(Field{Name: "counts", Type: MapField, KeyType: Float64Field, ValueType: Uint32Field})
+	builder = NewBuilder(Other, make([]byte, 1024))
+	_, err = builder.PutMap("counts", map[float64]int{})
+	assert.Equal(t, ErrUnsupportedType, err)
+
+	// buffer too small
+	builder = NewBuilder(createTestMapType(), make([]byte, 8))
+	_, err = builder.PutMap("counts", map[string]int{"a": 1, "b": 2})
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+}
+
+func TestTupleGetMapIterator(t *testing.T) {
+	builder := NewBuilder(createTestMapType(), make([]byte, 1024))
+	builder.PutMap("counts", map[string]uint32{"c": 3, "a": 1, "b": 2})
+	inventory, err := builder.Build()
+	assert.Nil(t, err)
+
+	it, err := inventory.GetMapIterator("counts")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, it.Len())
+
+	var keys []interface{}
+	var values []interface{}
+	for it.Next() {
+		keys = append(keys, it.Key())
+		values = append(values, it.Value())
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []interface{}{"a", "b", "c"}, keys)
+	assert.Equal(t, []interface{}{uint32(1), uint32(2), uint32(3)}, values)
+
+	// iterator is exhausted
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Key())
+
+	_, err = inventory.GetMapIterator("updated")
+	assert.Equal(t, ErrFieldNotPresent, err)
+}
+
+func TestTupleGetMapFail(t *testing.T) {
+	builder := NewBuilder(createTestMapType(), make([]byte, 1024))
+	builder.PutMap("counts", map[string]uint32{"a": 1})
+	inventory, err := builder.Build()
+	assert.Nil(t, err)
+
+	// invalid value type code
+	inventory.data[5] = byte(UnsignedInt8Code.OpCode)
+	_, err = inventory.GetMap("counts")
+	assert.Equal(t, ErrInvalidTypeCode, err)
+
+	// truncated entry
+	inventory.data[5] = byte(UnsignedInt32Code.OpCode)
+	inventory.data = inventory.data[:7]
+	_, err = inventory.GetMap("counts")
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+
+	// length larger than the data
+	inventory.data[1] = 100
+	_, err = inventory.GetMapIterator("counts")
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+}
+
+func TestMapFingerprint(t *testing.T) {
+	Other := New("testing", "inventory")
+	Other.AddVersion(
+		Field{Name: "counts", Type: MapField, KeyType: StringField, ValueType: Uint64Field},
+		Field{Name: "updated", Type: MapField, KeyType: Int64Field, ValueType: TimestampField},
+		Field{Name: "flags", Type: MapField, KeyType: Uint8Field, ValueType: BooleanField},
+	)
+
+	Inventory := createTestMapType()
+	assert.NotEqual(t, Inventory.Fingerprint(1), Other.Fingerprint(1))
+}
@@ -0,0 +1,810 @@
+package namedtuple
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+
+	// ErrNotStruct is returned from TypeOf and Marshal if the value
+	// is not a struct or a pointer to a struct.
+	ErrNotStruct = errors.New("Value must be a struct or a pointer to a struct")
+
+	// ErrInvalidUnmarshal is returned from Unmarshal if the value is
+	// not a non-nil pointer to a struct.
+	ErrInvalidUnmarshal = errors.New("Unmarshal requires a non-nil pointer to a struct")
+
+	// ErrUnsupportedType is returned if a struct field or a tuple
+	// field type cannot be marshaled.
+	ErrUnsupportedType = errors.New("Unsupported type")
+
+	// ErrIncompatibleType is returned if the Go type of a struct field
+	// cannot be converted to or from the tuple field type.
+	ErrIncompatibleType = errors.New("Go type is not compatible with the field type")
+
+	// ErrValueOverflow is returned if a value does not fit into the
+	// tuple field or the struct field.
+	ErrValueOverflow = errors.New("Value overflows the field type")
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structField describes a single exported struct field. Pointer fields are optional and the Type is the type being pointed to.
+type structField struct {
+	Index    int
+	Name     string
+	Required bool
+	Pointer  bool
+	OneOf    string
+	VarInt   bool
+	Type     reflect.Type
+}
+
+// structCache stores the parsed fields for each struct type
+var structCache = struct {
+	sync.Mutex
+	fields map[reflect.Type][]structField
+}{fields: make(map[reflect.Type][]structField)}
+
+// structFields returns the exported fields of a struct type. The field names and options are read from the `nt` struct tag, for example `nt:"username,required"`. The `oneof=group` option adds the field to a oneof group and the `varint` option writes integers with the `VarIntEncoding`. Fields tagged with `nt:"-"` are skipped and fields without a name in the tag use the Go field name.
+func structFields(t reflect.Type) []structField {
+	structCache.Lock()
+	defer structCache.Unlock()
+
+	if fields, ok := structCache.fields[t]; ok {
+		return fields
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		// skip unexported fields
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag := sf.Tag.Get("nt")
+		if tag == "-" {
+			continue
+		}
+
+		field := structField{Index: i, Name: sf.Name, Type: sf.Type}
+		options := strings.Split(tag, ",")
+		if options[0] != "" {
+			field.Name = options[0]
+		}
+		for _, option := range options[1:] {
+			if option == "required" {
+				field.Required = true
+			} else if strings.HasPrefix(option, "oneof=") {
+				field.OneOf = strings.TrimPrefix(option, "oneof=")
+			} else if option == "varint" {
+				field.VarInt = true
+			}
+		}
+
+		if sf.Type.Kind() == reflect.Ptr {
+			field.Pointer = true
+			field.Type = sf.Type.Elem()
+		}
+		fields = append(fields, field)
+	}
+
+	structCache.fields[t] = fields
+	return fields
+}
+
+// structType returns the struct type of the value or a pointer to the value.
+func structType(v interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t == timeType {
+		return nil, ErrNotStruct
+	}
+	return t, nil
+}
+
+// fieldTypeOf returns the field type used to store values of the given Go type. Go `int` and `uint` values are stored as 64-bit integers, `time.Time` values as timestamps and structs as nested tuples. Slices of any of these are stored as arrays and maps with string or integer keys are stored as maps.
+func fieldTypeOf(t reflect.Type) (FieldType, error) {
+	if t == timeType {
+		return TimestampField, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Uint8:
+		return Uint8Field, nil
+	case reflect.Int8:
+		return Int8Field, nil
+	case reflect.Uint16:
+		return Uint16Field, nil
+	case reflect.Int16:
+		return Int16Field, nil
+	case reflect.Uint32:
+		return Uint32Field, nil
+	case reflect.Int32:
+		return Int32Field, nil
+	case reflect.Uint64, reflect.Uint:
+		return Uint64Field, nil
+	case reflect.Int64, reflect.Int:
+		return Int64Field, nil
+	case reflect.Float32:
+		return Float32Field, nil
+	case reflect.Float64:
+		return Float64Field, nil
+	case reflect.String:
+		return StringField, nil
+	case reflect.Bool:
+		return BooleanField, nil
+	case reflect.Struct:
+		return TupleField, nil
+	case reflect.Slice:
+
+		// the array type always follows the scalar type
+		elem, err := fieldTypeOf(t.Elem())
+		if err != nil || elem%2 != 0 {
+			return 0, ErrUnsupportedType
+		}
+		return elem + 1, nil
+	case reflect.Map:
+		key, err := fieldTypeOf(t.Key())
+		if err != nil {
+			return 0, err
+		}
+		value, err := fieldTypeOf(t.Elem())
+		if err != nil || !ValidMapTypes(key, value) {
+			return 0, ErrUnsupportedType
+		}
+		return MapField, nil
+	}
+	return 0, ErrUnsupportedType
+}
+
+// TypeOf derives a TupleType from a struct or a pointer to a struct. The tuple type has the name of the struct type and a single version containing all the exported fields in declaration order. Nested struct fields reference a tuple type with the same namespace and the name of the nested struct type.
+func TypeOf(namespace string, v interface{}) (TupleType, error) {
+	t, err := structType(v)
+	if err != nil {
+		return TupleType{}, err
+	}
+	return typeOf(namespace, t.Name(), t)
+}
+
+func typeOf(namespace, name string, t reflect.Type) (TupleType, error) {
+	tupleType := New(namespace, name)
+
+	var fields []Field
+	for _, sf := range structFields(t) {
+		fieldType, err := fieldTypeOf(sf.Type)
+		if err != nil {
+			return TupleType{}, err
+		}
+
+		field := Field{Name: sf.Name, Required: sf.Required, Type: fieldType, OneOf: sf.OneOf}
+		if sf.VarInt {
+			field.Encoding = VarIntEncoding
+		}
+		switch fieldType {
+		case TupleField:
+			field.TupleNamespace = namespace
+			field.TupleName = sf.Type.Name()
+		case TupleArrayField:
+			field.TupleNamespace = namespace
+			field.TupleName = sf.Type.Elem().Name()
+		case MapField:
+			field.KeyType, _ = fieldTypeOf(sf.Type.Key())
+			field.ValueType, _ = fieldTypeOf(sf.Type.Elem())
+		}
+		fields = append(fields, field)
+	}
+	tupleType.AddVersion(fields...)
+	return tupleType, nil
+}
+
+// nestedType returns the registered tuple type referenced by the `TupleNamespace` and `TupleName` of a nested tuple field. If the type is not registered, `ErrUnknownTupleType` is returned.
+func nestedType(field Field, reg *Registry) (TupleType, error) {
+	tupleType, exists := reg.Get(field.TupleNamespace, field.TupleName)
+	if !exists {
+		return TupleType{}, ErrUnknownTupleType
+	}
+	return tupleType, nil
+}
+
+// Marshal writes the exported fields of a struct into a new tuple of the given type using the given buffer. Struct fields which are not part of the tuple type are ignored as well as nil pointers, nil slices and nil maps for optional fields. Numeric values are converted to the tuple field type and `ErrValueOverflow` is returned if the value does not fit. Nested structs and slices of structs are marshaled with the tuple type referenced by the field, which is resolved using the DefaultRegistry. The fields of the nested struct are matched to the fields of that type by name.
+func Marshal(v interface{}, tupleType TupleType, buffer []byte) (Tuple, error) {
+	return MarshalWithRegistry(v, tupleType, buffer, &DefaultRegistry)
+}
+
+// MarshalWithRegistry writes a struct into a new tuple the same way as Marshal. The types of nested tuples are resolved using the given registry.
+func MarshalWithRegistry(v interface{}, tupleType TupleType, buffer []byte, reg *Registry) (Tuple, error) {
+	if _, err := structType(v); err != nil {
+		return NIL, err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return NIL, ErrNotStruct
+		}
+		rv = rv.Elem()
+	}
+
+	b := NewBuilder(tupleType, buffer)
+	if err := b.marshal(rv, reg); err != nil {
+		return NIL, err
+	}
+	return b.Build()
+}
+
+func (b *TupleBuilder) marshal(rv reflect.Value, reg *Registry) error {
+	for _, sf := range structFields(rv.Type()) {
+		field, _, exists := b.tupleType.field(sf.Name)
+		if !exists {
+			continue
+		}
+
+		value := rv.Field(sf.Index)
+		if sf.Pointer {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		} else if (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.IsNil() && !field.Required {
+			continue
+		}
+
+		if err := b.putValue(field, value, reg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *TupleBuilder) putValue(field Field, value reflect.Value, reg *Registry) (err error) {
+	name := field.Name
+	switch field.Type {
+	case Uint8Field:
+		var v uint64
+		if v, err = uintValue(value, 8); err == nil {
+			_, err = b.PutUint8(name, uint8(v))
+		}
+	case Int8Field:
+		var v int64
+		if v, err = intValue(value, 8); err == nil {
+			_, err = b.PutInt8(name, int8(v))
+		}
+	case Uint16Field:
+		var v uint64
+		if v, err = uintValue(value, 16); err == nil {
+			_, err = b.PutUint16(name, uint16(v))
+		}
+	case Int16Field:
+		var v int64
+		if v, err = intValue(value, 16); err == nil {
+			_, err = b.PutInt16(name, int16(v))
+		}
+	case Uint32Field:
+		var v uint64
+		if v, err = uintValue(value, 32); err == nil {
+			_, err = b.PutUint32(name, uint32(v))
+		}
+	case Int32Field:
+		var v int64
+		if v, err = intValue(value, 32); err == nil {
+			_, err = b.PutInt32(name, int32(v))
+		}
+	case Uint64Field:
+		var v uint64
+		if v, err = uintValue(value, 64); err == nil {
+			_, err = b.PutUint64(name, v)
+		}
+	case Int64Field:
+		var v int64
+		if v, err = intValue(value, 64); err == nil {
+			_, err = b.PutInt64(name, v)
+		}
+	case Float32Field:
+		var v float64
+		if v, err = floatValue(value, 32); err == nil {
+			_, err = b.PutFloat32(name, float32(v))
+		}
+	case Float64Field:
+		var v float64
+		if v, err = floatValue(value, 64); err == nil {
+			_, err = b.PutFloat64(name, v)
+		}
+	case EnumField:
+
+		// enums accept the names or the numeric values
+		if value.Kind() == reflect.String {
+			_, err = b.PutEnum(name, value.String())
+			break
+		}
+
+		var v uint64
+		if v, err = uintValue(value, 32); err == nil {
+			_, err = b.PutEnumValue(name, uint32(v))
+		}
+	case StringField:
+		if value.Kind() != reflect.String {
+			return ErrIncompatibleType
+		}
+		_, err = b.PutString(name, value.String())
+	case TimestampField:
+		if value.Type() != timeType {
+			return ErrIncompatibleType
+		}
+		_, err = b.PutTimestamp(name, value.Interface().(time.Time))
+	case BooleanField:
+		if value.Kind() != reflect.Bool {
+			return ErrIncompatibleType
+		}
+		_, err = b.PutBool(name, value.Bool())
+	case TupleField:
+		var tuple Tuple
+		if tuple, err = b.marshalTuple(field, value, reg, 0); err == nil {
+			_, err = b.PutTuple(name, tuple)
+		}
+	case TupleArrayField:
+		var tuples []Tuple
+		if tuples, err = b.marshalTupleArray(field, value, reg); err == nil {
+			_, err = b.PutTupleArray(name, tuples)
+		}
+	case Uint8ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]uint8(nil))); err == nil {
+			_, err = b.PutUint8Array(name, v.([]uint8))
+		}
+	case Int8ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]int8(nil))); err == nil {
+			_, err = b.PutInt8Array(name, v.([]int8))
+		}
+	case Uint16ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]uint16(nil))); err == nil {
+			_, err = b.PutUint16Array(name, v.([]uint16))
+		}
+	case Int16ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]int16(nil))); err == nil {
+			_, err = b.PutInt16Array(name, v.([]int16))
+		}
+	case Uint32ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]uint32(nil))); err == nil {
+			_, err = b.PutUint32Array(name, v.([]uint32))
+		}
+	case Int32ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]int32(nil))); err == nil {
+			_, err = b.PutInt32Array(name, v.([]int32))
+		}
+	case Uint64ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]uint64(nil))); err == nil {
+			_, err = b.PutUint64Array(name, v.([]uint64))
+		}
+	case Int64ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]int64(nil))); err == nil {
+			_, err = b.PutInt64Array(name, v.([]int64))
+		}
+	case Float32ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]float32(nil))); err == nil {
+			_, err = b.PutFloat32Array(name, v.([]float32))
+		}
+	case Float64ArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]float64(nil))); err == nil {
+			_, err = b.PutFloat64Array(name, v.([]float64))
+		}
+	case TimestampArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]time.Time(nil))); err == nil {
+			_, err = b.PutTimestampArray(name, v.([]time.Time))
+		}
+	case StringArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]string(nil))); err == nil {
+			_, err = b.PutStringArray(name, v.([]string))
+		}
+	case BooleanArrayField:
+		var v interface{}
+		if v, err = sliceValue(value, reflect.TypeOf([]bool(nil))); err == nil {
+			_, err = b.PutBoolArray(name, v.([]bool))
+		}
+	case MapField:
+		if value.Kind() != reflect.Map {
+			return ErrIncompatibleType
+		}
+		_, err = b.PutMap(name, value.Interface())
+	default:
+		err = ErrUnsupportedType
+	}
+	return
+}
+
+// marshalTuple builds a nested tuple of the type referenced by the field from a struct value. The nested tuple is built in the unused space of the buffer after the pending bytes of other nested tuples, see `TupleBuilder.nested`.
+func (b *TupleBuilder) marshalTuple(field Field, value reflect.Value, reg *Registry, pending int) (Tuple, error) {
+	if value.Kind() != reflect.Struct || value.Type() == timeType {
+		return NIL, ErrIncompatibleType
+	}
+
+	tupleType, err := nestedType(field, reg)
+	if err != nil {
+		return NIL, err
+	}
+
+	nested := b.nested(tupleType, pending)
+	if err := nested.marshal(value, reg); err != nil {
+		return NIL, err
+	}
+	return nested.Build()
+}
+
+// marshalTupleArray builds a nested tuple for each struct in a slice. The tuples are built one after the other in the unused space of the buffer.
+func (b *TupleBuilder) marshalTupleArray(field Field, value reflect.Value, reg *Registry) ([]Tuple, error) {
+	if value.Kind() != reflect.Slice {
+		return nil, ErrIncompatibleType
+	}
+
+	tuples := make([]Tuple, value.Len())
+	pending := maxArrayHeaderSize
+	for i := range tuples {
+		tuple, err := b.marshalTuple(field, value.Index(i), reg, pending)
+		if err != nil {
+			return nil, err
+		}
+		tuples[i] = tuple
+		pending += tupleSize(tuple)
+	}
+	return tuples, nil
+}
+
+// Unmarshal reads the fields of the tuple into a struct. The value must be a non-nil pointer to a struct. Struct fields which are not part of the tuple type are left unchanged and fields which are not present in the tuple are set to their default value or to their zero value if the field does not have a default value. Nested tuples and tuple arrays are read with the tuple type referenced by the field, which is resolved using the DefaultRegistry. If a nested tuple is of a different type, `ErrIncorrectTupleType` is returned.
+func Unmarshal(t Tuple, v interface{}) error {
+	return UnmarshalWithRegistry(t, v, &DefaultRegistry)
+}
+
+// UnmarshalWithRegistry reads the fields of the tuple into a struct the same way as Unmarshal. The types of nested tuples are resolved using the given registry.
+func UnmarshalWithRegistry(t Tuple, v interface{}, reg *Registry) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidUnmarshal
+	}
+	return t.unmarshal(rv.Elem(), reg)
+}
+
+func (t *Tuple) unmarshal(rv reflect.Value, reg *Registry) error {
+	for _, sf := range structFields(rv.Type()) {
+		field, _, exists := t.Header.Type.field(sf.Name)
+		if !exists {
+			continue
+		}
+
+		target := rv.Field(sf.Index)
+		value, err := t.getValue(field, sf.Type, reg)
+		if err == ErrFieldNotPresent {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if sf.Pointer {
+			ptr := reflect.New(sf.Type)
+			ptr.Elem().Set(value)
+			value = ptr
+		}
+		target.Set(value)
+	}
+	return nil
+}
+
+// getValue reads the field and converts it into a value of the given Go type.
+func (t *Tuple) getValue(field Field, typ reflect.Type, reg *Registry) (reflect.Value, error) {
+	var v interface{}
+	var err error
+
+	name := field.Name
+	switch field.Type {
+	case Uint8Field:
+		v, err = t.GetUint8(name)
+	case Int8Field:
+		v, err = t.GetInt8(name)
+	case Uint16Field:
+		v, err = t.GetUint16(name)
+	case Int16Field:
+		v, err = t.GetInt16(name)
+	case Uint32Field:
+		v, err = t.GetUint32(name)
+	case Int32Field:
+		v, err = t.GetInt32(name)
+	case Uint64Field:
+		v, err = t.GetUint64(name)
+	case Int64Field:
+		v, err = t.GetInt64(name)
+	case Float32Field:
+		v, err = t.GetFloat32(name)
+	case Float64Field:
+		v, err = t.GetFloat64(name)
+	case StringField:
+		v, err = t.GetString(name)
+	case TimestampField:
+		v, err = t.GetTimestamp(name)
+	case BooleanField:
+		v, err = t.GetBool(name)
+	case EnumField:
+		if typ.Kind() == reflect.String {
+			v, err = t.GetEnum(name)
+		} else {
+			v, err = t.GetEnumValue(name)
+		}
+	case TupleField:
+		return t.unmarshalTuple(field, typ, reg)
+	case TupleArrayField:
+		return t.unmarshalTupleArray(field, typ, reg)
+	case Uint8ArrayField:
+		v, err = t.GetUint8Array(name)
+	case Int8ArrayField:
+		v, err = t.GetInt8Array(name)
+	case Uint16ArrayField:
+		v, err = t.GetUint16Array(name)
+	case Int16ArrayField:
+		v, err = t.GetInt16Array(name)
+	case Uint32ArrayField:
+		v, err = t.GetUint32Array(name)
+	case Int32ArrayField:
+		v, err = t.GetInt32Array(name)
+	case Uint64ArrayField:
+		v, err = t.GetUint64Array(name)
+	case Int64ArrayField:
+		v, err = t.GetInt64Array(name)
+	case Float32ArrayField:
+		v, err = t.GetFloat32Array(name)
+	case Float64ArrayField:
+		v, err = t.GetFloat64Array(name)
+	case TimestampArrayField:
+		v, err = t.GetTimestampArray(name)
+	case StringArrayField:
+		v, err = t.GetStringArray(name)
+	case BooleanArrayField:
+		v, err = t.GetBoolArray(name)
+	case MapField:
+		v, err = t.GetMap(name)
+	default:
+		err = ErrUnsupportedType
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	value := reflect.New(typ).Elem()
+	if err := convert(value, reflect.ValueOf(v)); err != nil {
+		return reflect.Value{}, err
+	}
+	return value, nil
+}
+
+// unmarshalTuple reads a nested tuple of the type referenced by the field into a new struct value of the given type.
+func (t *Tuple) unmarshalTuple(field Field, typ reflect.Type, reg *Registry) (reflect.Value, error) {
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return reflect.Value{}, ErrIncompatibleType
+	}
+
+	// missing fields do not need the nested type
+	if _, err := t.fieldOffset(field.Name, field.Type); err != nil {
+		return reflect.Value{}, err
+	}
+
+	tupleType, err := nestedType(field, reg)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	nested, err := t.readTuple(field.Name, tupleType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	value := reflect.New(typ).Elem()
+	if err := nested.unmarshal(value, reg); err != nil {
+		return reflect.Value{}, err
+	}
+	return value, nil
+}
+
+// unmarshalTupleArray reads a nested tuple array into a new slice of structs of the given type.
+func (t *Tuple) unmarshalTupleArray(field Field, typ reflect.Type, reg *Registry) (reflect.Value, error) {
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() != reflect.Struct || typ.Elem() == timeType {
+		return reflect.Value{}, ErrIncompatibleType
+	}
+
+	// missing fields do not need the nested type
+	if _, err := t.fieldOffset(field.Name, field.Type); err != nil {
+		return reflect.Value{}, err
+	}
+
+	tupleType, err := nestedType(field, reg)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	it, err := t.readTupleArray(field.Name, func(buffer []byte) (Tuple, error) {
+		return parseTuple(buffer, tupleType)
+	})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	slice := reflect.MakeSlice(typ, 0, it.Len())
+	for it.Next() {
+		nested := it.Tuple()
+		value := reflect.New(typ.Elem()).Elem()
+		if err := nested.unmarshal(value, reg); err != nil {
+			return reflect.Value{}, err
+		}
+		slice = reflect.Append(slice, value)
+	}
+	return slice, it.Err()
+}
+
+// uintValue converts an integer value into an unsigned integer with the given number of bits.
+func uintValue(value reflect.Value, bits uint) (uint64, error) {
+	var v uint64
+	switch value.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v = value.Uint()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := value.Int()
+		if i < 0 {
+			return 0, ErrValueOverflow
+		}
+		v = uint64(i)
+	default:
+		return 0, ErrIncompatibleType
+	}
+
+	if bits < 64 && v>>bits != 0 {
+		return 0, ErrValueOverflow
+	}
+	return v, nil
+}
+
+// intValue converts an integer value into a signed integer with the given number of bits.
+func intValue(value reflect.Value, bits uint) (int64, error) {
+	var v int64
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v = value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := value.Uint()
+		if u > 1<<(bits-1)-1 {
+			return 0, ErrValueOverflow
+		}
+		v = int64(u)
+	default:
+		return 0, ErrIncompatibleType
+	}
+
+	if bits < 64 && (v < -1<<(bits-1) || v > 1<<(bits-1)-1) {
+		return 0, ErrValueOverflow
+	}
+	return v, nil
+}
+
+// floatValue converts a floating point value into a float with the given number of bits.
+func floatValue(value reflect.Value, bits uint) (float64, error) {
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v := value.Float()
+		if bits == 32 && reflect.Zero(reflect.TypeOf(float32(0))).OverflowFloat(v) {
+			return 0, ErrValueOverflow
+		}
+		return v, nil
+	}
+	return 0, ErrIncompatibleType
+}
+
+// sliceValue converts a slice into a slice of the given type. The slice is returned as is if it is already of the given type.
+func sliceValue(value reflect.Value, typ reflect.Type) (interface{}, error) {
+	if value.Type() == typ {
+		return value.Interface(), nil
+	}
+
+	slice := reflect.New(typ).Elem()
+	if err := convert(slice, value); err != nil {
+		return nil, err
+	}
+	return slice.Interface(), nil
+}
+
+// convert sets the destination to the source value. Numeric values are converted to the destination type, slices are converted element by element and maps entry by entry.
+func convert(dst, src reflect.Value) error {
+	if src.Kind() == reflect.Interface {
+		src = src.Elem()
+	}
+	if !src.IsValid() {
+		return ErrIncompatibleType
+	} else if src.Type() == dst.Type() {
+		dst.Set(src)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := intValue(src, uint(dst.Type().Bits()))
+		if err != nil {
+			return err
+		}
+		dst.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := uintValue(src, uint(dst.Type().Bits()))
+		if err != nil {
+			return err
+		}
+		dst.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := floatValue(src, uint(dst.Type().Bits()))
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(v)
+	case reflect.String:
+		if src.Kind() != reflect.String {
+			return ErrIncompatibleType
+		}
+		dst.SetString(src.String())
+	case reflect.Bool:
+		if src.Kind() != reflect.Bool {
+			return ErrIncompatibleType
+		}
+		dst.SetBool(src.Bool())
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice {
+			return ErrIncompatibleType
+		}
+
+		slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := convert(slice.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case reflect.Map:
+		if src.Kind() != reflect.Map {
+			return ErrIncompatibleType
+		}
+
+		m := reflect.MakeMap(dst.Type())
+		for _, key := range src.MapKeys() {
+			k := reflect.New(dst.Type().Key()).Elem()
+			if err := convert(k, key); err != nil {
+				return err
+			}
+
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := convert(v, src.MapIndex(key)); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+	case reflect.Interface:
+		if !src.Type().AssignableTo(dst.Type()) {
+			return ErrIncompatibleType
+		}
+		dst.Set(src)
+	default:
+		return ErrIncompatibleType
+	}
+	return nil
+}
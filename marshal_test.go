@@ -0,0 +1,453 @@
+package namedtuple
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type marshalLocation struct {
+	Lat float32 `nt:"lat,required"`
+	Lon float32 `nt:"lon,required"`
+}
+
+// marshalRegistry creates a registry with the tuple types of the nested structs
+func marshalRegistry(t *testing.T) *Registry {
+	Location, err := TypeOf("testing", marshalLocation{})
+	assert.Nil(t, err)
+
+	reg := NewRegistry()
+	assert.Nil(t, reg.Register(Location))
+	return &reg
+}
+
+type marshalUser struct {
+	UUID     string           `nt:"uuid,required"`
+	Username string           `nt:"username,required"`
+	Age      *uint8           `nt:"age"`
+	Scores   []int32          `nt:"scores"`
+	Created  time.Time        `nt:"created"`
+	Location *marshalLocation `nt:"location"`
+	Ignored  string           `nt:"-"`
+	private  string
+}
+
+func TestTypeOf(t *testing.T) {
+	User, err := TypeOf("testing", &marshalUser{})
+	assert.Nil(t, err)
+	assert.Equal(t, "testing", User.Namespace)
+	assert.Equal(t, "marshalUser", User.Name)
+	assert.Equal(t, 1, User.NumVersions())
+	assert.Equal(t, []Field{
+		{Name: "uuid", Required: true, Type: StringField},
+		{Name: "username", Required: true, Type: StringField},
+		{Name: "age", Required: false, Type: Uint8Field},
+		{Name: "scores", Required: false, Type: Int32ArrayField},
+		{Name: "created", Required: false, Type: TimestampField},
+		{Name: "location", Required: false, Type: TupleField, TupleNamespace: "testing", TupleName: "marshalLocation"},
+	}, User.Versions()[0].Fields)
+}
+
+func TestTypeOfErrors(t *testing.T) {
+	_, err := TypeOf("testing", 5)
+	assert.Equal(t, ErrNotStruct, err)
+
+	_, err = TypeOf("testing", time.Now())
+	assert.Equal(t, ErrNotStruct, err)
+
+	// map keys must be strings or integers
+	_, err = TypeOf("testing", struct{ Map map[float64]int }{})
+	assert.Equal(t, ErrUnsupportedType, err)
+
+	_, err = TypeOf("testing", struct{ Matrix [][]int }{})
+	assert.Equal(t, ErrUnsupportedType, err)
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	User, err := TypeOf("testing", marshalUser{})
+	assert.Nil(t, err)
+	reg := marshalRegistry(t)
+
+	age := uint8(25)
+	created := time.Unix(1420070400, 0)
+	user := marshalUser{
+		UUID:     "0123456789abcdef",
+		Username: "bugs",
+		Age:      &age,
+		Scores:   []int32{1, -2, 300},
+		Created:  created,
+		Location: &marshalLocation{1.5, -2.5},
+		Ignored:  "ignored",
+	}
+
+	tuple, err := MarshalWithRegistry(&user, User, make([]byte, 1024), reg)
+	assert.Nil(t, err)
+	assert.True(t, tuple.Is(User))
+
+	uuid, err := tuple.GetString("uuid")
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789abcdef", uuid)
+
+	var out marshalUser
+	err = UnmarshalWithRegistry(tuple, &out, reg)
+	assert.Nil(t, err)
+	assert.Equal(t, user.UUID, out.UUID)
+	assert.Equal(t, user.Username, out.Username)
+	assert.Equal(t, age, *out.Age)
+	assert.Equal(t, user.Scores, out.Scores)
+	assert.True(t, created.Equal(out.Created))
+	assert.Equal(t, *user.Location, *out.Location)
+	assert.Equal(t, "", out.Ignored)
+
+	// nested types must be registered
+	_, err = Marshal(&user, User, make([]byte, 1024))
+	assert.Equal(t, ErrUnknownTupleType, err)
+	err = Unmarshal(tuple, &out)
+	assert.Equal(t, ErrUnknownTupleType, err)
+}
+
+func TestMarshalNestedRegisteredType(t *testing.T) {
+
+	// the registered type declares the fields in a different order
+	Location := New("testing", "marshalLocation")
+	Location.AddVersion(
+		Field{Name: "lon", Required: true, Type: Float32Field},
+		Field{Name: "lat", Required: true, Type: Float32Field},
+	)
+	reg := NewRegistry()
+	assert.Nil(t, reg.Register(Location))
+
+	User, err := TypeOf("testing", marshalUser{})
+	assert.Nil(t, err)
+
+	user := marshalUser{UUID: "a", Username: "b", Location: &marshalLocation{1.5, -2.5}}
+	tuple, err := MarshalWithRegistry(user, User, make([]byte, 1024), &reg)
+	assert.Nil(t, err)
+
+	// the nested tuple is of the registered type
+	location, err := tuple.GetTuple("location", &reg)
+	assert.Nil(t, err)
+	assert.True(t, location.Is(Location))
+	lat, err := location.GetFloat32("lat")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1.5), lat)
+	lon, err := location.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(-2.5), lon)
+
+	var out marshalUser
+	err = UnmarshalWithRegistry(tuple, &out, &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, *user.Location, *out.Location)
+}
+
+func TestMarshalOptionalFields(t *testing.T) {
+	User, err := TypeOf("testing", marshalUser{})
+	assert.Nil(t, err)
+
+	tuple, err := Marshal(marshalUser{UUID: "a", Username: "b"}, User, make([]byte, 1024))
+	assert.Nil(t, err)
+
+	// fields which are not present are set to the zero value
+	age := uint8(1)
+	out := marshalUser{Age: &age, Scores: []int32{1}, Location: &marshalLocation{}}
+	err = Unmarshal(tuple, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, "a", out.UUID)
+	assert.Nil(t, out.Age)
+	assert.Nil(t, out.Scores)
+	assert.Nil(t, out.Location)
+}
+
+func TestUnmarshalDefaults(t *testing.T) {
+	builder := NewBuilder(createTestDefaultType(), make([]byte, 1024))
+	builder.PutString("name", "namedtuple")
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	// fields which are not present are set to their default value
+	var out struct {
+		Name     string  `nt:"name"`
+		Age      *uint8  `nt:"age"`
+		Nickname string  `nt:"nickname"`
+		Email    *string `nt:"email"`
+	}
+	err = Unmarshal(tuple, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, "namedtuple", out.Name)
+	assert.Equal(t, uint8(18), *out.Age)
+	assert.Equal(t, "anonymous", out.Nickname)
+	assert.Nil(t, out.Email)
+}
+
+func TestMarshalMissingRequiredField(t *testing.T) {
+	User, err := TypeOf("testing", marshalUser{})
+	assert.Nil(t, err)
+
+	// the only version requires the username
+	_, err = Marshal(struct {
+		UUID string `nt:"uuid"`
+	}{"a"}, User, make([]byte, 1024))
+	assert.NotNil(t, err)
+}
+
+func TestMarshalConversion(t *testing.T) {
+	AType := New("testing", "A")
+	AType.AddVersion(
+		Field{Name: "Name", Required: true, Type: StringField},
+		Field{Name: "BirthDay", Required: true, Type: TimestampField},
+		Field{Name: "Phone", Required: true, Type: StringField},
+		Field{Name: "Siblings", Required: true, Type: Uint8Field},
+		Field{Name: "Money", Required: true, Type: Float32Field},
+	)
+
+	now := time.Now()
+	a := A{"Bugs Bunny", now, "555-555-5555", 3, false, 999.5}
+	tuple, err := Marshal(a, AType, make([]byte, 1024))
+	assert.Nil(t, err)
+
+	siblings, err := tuple.GetUint8("Siblings")
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(3), siblings)
+
+	var out A
+	err = Unmarshal(tuple, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, out.Siblings)
+	assert.Equal(t, 999.5, out.Money)
+	assert.False(t, out.Spouse)
+
+	// values must fit into the field type
+	a.Siblings = 256
+	_, err = Marshal(a, AType, make([]byte, 1024))
+	assert.Equal(t, ErrValueOverflow, err)
+
+	a.Siblings = -1
+	_, err = Marshal(a, AType, make([]byte, 1024))
+	assert.Equal(t, ErrValueOverflow, err)
+}
+
+func TestMarshalIncompatibleType(t *testing.T) {
+	Type := New("testing", "T")
+	Type.AddVersion(Field{Name: "Value", Required: true, Type: StringField})
+
+	_, err := Marshal(struct{ Value int }{1}, Type, make([]byte, 1024))
+	assert.Equal(t, ErrIncompatibleType, err)
+
+	tuple, err := Marshal(struct{ Value string }{"a"}, Type, make([]byte, 1024))
+	assert.Nil(t, err)
+
+	var out struct{ Value int }
+	err = Unmarshal(tuple, &out)
+	assert.Equal(t, ErrIncompatibleType, err)
+}
+
+func TestMarshalErrors(t *testing.T) {
+	User := createTestTupleType()
+
+	_, err := Marshal("user", User, make([]byte, 1024))
+	assert.Equal(t, ErrNotStruct, err)
+
+	var user *marshalUser
+	_, err = Marshal(user, User, make([]byte, 1024))
+	assert.Equal(t, ErrNotStruct, err)
+
+	var out marshalUser
+	err = Unmarshal(NIL, out)
+	assert.Equal(t, ErrInvalidUnmarshal, err)
+
+	err = Unmarshal(NIL, user)
+	assert.Equal(t, ErrInvalidUnmarshal, err)
+}
+
+func TestUnmarshalIncorrectNestedType(t *testing.T) {
+	Location := New("testing", "other")
+	Location.AddVersion(Field{Name: "lat", Required: true, Type: Float32Field})
+
+	b := NewBuilder(Location, make([]byte, 128))
+	b.PutFloat32("lat", 1.0)
+	location, err := b.Build()
+	assert.Nil(t, err)
+
+	User, err := TypeOf("testing", marshalUser{})
+	assert.Nil(t, err)
+
+	b = NewBuilder(User, make([]byte, 1024))
+	b.PutString("uuid", "a")
+	b.PutString("username", "b")
+	b.PutTuple("location", location)
+	tuple, err := b.Build()
+	assert.Nil(t, err)
+
+	var out marshalUser
+	err = UnmarshalWithRegistry(tuple, &out, marshalRegistry(t))
+	assert.Equal(t, ErrIncorrectTupleType, err)
+}
+
+func TestMarshalBooleansAndStrings(t *testing.T) {
+	type flags struct {
+		Active bool     `nt:"active,required"`
+		Bits   []bool   `nt:"bits"`
+		Emails []string `nt:"emails"`
+	}
+
+	Flags, err := TypeOf("testing", flags{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Field{
+		{Name: "active", Required: true, Type: BooleanField},
+		{Name: "bits", Required: false, Type: BooleanArrayField},
+		{Name: "emails", Required: false, Type: StringArrayField},
+	}, Flags.Versions()[0].Fields)
+
+	in := flags{true, []bool{false, true}, []string{"a@b.c", "d@e.f"}}
+	tuple, err := Marshal(in, Flags, make([]byte, 1024))
+	assert.Nil(t, err)
+
+	var out flags
+	err = Unmarshal(tuple, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, in, out)
+}
+
+type marshalRoute struct {
+	Name  string            `nt:"name,required"`
+	Stops []marshalLocation `nt:"stops"`
+}
+
+func TestMarshalTupleArray(t *testing.T) {
+	Route, err := TypeOf("testing", marshalRoute{})
+	assert.Nil(t, err)
+	assert.Equal(t, Field{Name: "stops", Type: TupleArrayField, TupleNamespace: "testing", TupleName: "marshalLocation"}, Route.Versions()[0].Fields[1])
+
+	route := marshalRoute{
+		Name:  "loop",
+		Stops: []marshalLocation{{1.5, -2.5}, {3.5, 4.5}, {-5.5, 6.5}},
+	}
+
+	reg := marshalRegistry(t)
+	tuple, err := MarshalWithRegistry(route, Route, make([]byte, 1024), reg)
+	assert.Nil(t, err)
+
+	var out marshalRoute
+	err = UnmarshalWithRegistry(tuple, &out, reg)
+	assert.Nil(t, err)
+	assert.Equal(t, route, out)
+
+	// empty array
+	route.Stops = []marshalLocation{}
+	tuple, err = MarshalWithRegistry(route, Route, make([]byte, 1024), reg)
+	assert.Nil(t, err)
+
+	err = UnmarshalWithRegistry(tuple, &out, reg)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(out.Stops))
+}
+
+func TestMarshalEnum(t *testing.T) {
+	in := struct {
+		Status   string `nt:"status"`
+		Previous uint32 `nt:"previous"`
+	}{"DISABLED", 300}
+
+	tuple, err := Marshal(in, createTestEnumType(false), make([]byte, 1024))
+	assert.Nil(t, err)
+
+	// enum fields can be read as names or numbers
+	var out struct {
+		Status   uint32 `nt:"status"`
+		Previous string `nt:"previous"`
+	}
+	err = Unmarshal(tuple, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), out.Status)
+	assert.Equal(t, "DELETED", out.Previous)
+
+	in.Status = "UNKNOWN"
+	_, err = Marshal(in, createTestEnumType(false), make([]byte, 1024))
+	assert.NotNil(t, err)
+}
+
+func TestMarshalMap(t *testing.T) {
+	type inventory struct {
+		Counts map[string]int `nt:"counts"`
+		Flags  map[uint8]bool `nt:"flags"`
+	}
+
+	// maps are derived from the key and value types
+	Inventory, err := TypeOf("testing", inventory{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Field{
+		{Name: "counts", Type: MapField, KeyType: StringField, ValueType: Int64Field},
+		{Name: "flags", Type: MapField, KeyType: Uint8Field, ValueType: BooleanField},
+	}, Inventory.Versions()[0].Fields)
+
+	// nil maps are not written
+	tuple, err := Marshal(inventory{Counts: map[string]int{"a": 1}}, createTestMapType(), make([]byte, 1024))
+	assert.Nil(t, err)
+	_, err = tuple.GetMap("flags")
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	var out struct {
+		Counts map[string]uint64 `nt:"counts"`
+		Flags  map[uint8]bool    `nt:"flags"`
+	}
+	err = Unmarshal(tuple, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]uint64{"a": 1}, out.Counts)
+	assert.Nil(t, out.Flags)
+}
+
+func TestMarshalOneOf(t *testing.T) {
+	type place struct {
+		Name    string  `nt:"name,required"`
+		Street  *string `nt:"street,oneof=location"`
+		Code    *string `nt:"code,oneof=location"`
+		Visited *bool   `nt:"visited"`
+	}
+
+	Place, err := TypeOf("testing", place{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Field{
+		{Name: "name", Required: true, Type: StringField},
+		{Name: "street", Type: StringField, OneOf: "location"},
+		{Name: "code", Type: StringField, OneOf: "location"},
+		{Name: "visited", Type: BooleanField},
+	}, Place.Versions()[0].Fields)
+
+	code := "GB-LND"
+	tuple, err := Marshal(place{Name: "London", Code: &code}, Place, make([]byte, 1024))
+	assert.Nil(t, err)
+	field, err := tuple.OneOf("location")
+	assert.Nil(t, err)
+	assert.Equal(t, "code", field)
+
+	// only one field of the group can be set
+	_, err = Marshal(place{Name: "London", Street: &code, Code: &code}, Place, make([]byte, 1024))
+	assert.Equal(t, ErrOneOfConflict, err)
+}
+
+func TestMarshalVarInt(t *testing.T) {
+	type counter struct {
+		Count uint64   `nt:"count,varint"`
+		Steps []int32  `nt:"steps,varint"`
+		Total *float64 `nt:"total"`
+	}
+
+	Counter, err := TypeOf("testing", counter{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Field{
+		{Name: "count", Type: Uint64Field, Encoding: VarIntEncoding},
+		{Name: "steps", Type: Int32ArrayField, Encoding: VarIntEncoding},
+		{Name: "total", Type: Float64Field},
+	}, Counter.Versions()[0].Fields)
+
+	tuple, err := Marshal(counter{Count: 1000, Steps: []int32{-1, 1}}, Counter, make([]byte, 1024))
+	assert.Nil(t, err)
+	assert.Equal(t, UVarIntCode.OpCode, tuple.data[0])
+
+	var out counter
+	assert.Nil(t, Unmarshal(tuple, &out))
+	assert.Equal(t, uint64(1000), out.Count)
+	assert.Equal(t, []int32{-1, 1}, out.Steps)
+}
@@ -0,0 +1,96 @@
+package namedtuple
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	// ErrOneOfConflict is returned when more than one field of a oneof group is written.
+	ErrOneOfConflict = errors.New("Another field of the oneof group was already written")
+
+	// ErrUnknownOneOf is returned when the tuple type does not have a oneof group with the given name.
+	ErrUnknownOneOf = errors.New("Unknown oneof group")
+)
+
+// checkOneOf returns `ErrOneOfConflict` if a different field of the same oneof group has already been written. Writing the same field again is allowed.
+func (b *TupleBuilder) checkOneOf(field Field) error {
+	for i, other := range b.tupleType.table {
+		if b.offsets[i] != notWritten && other.Name != field.Name && other.OneOf == field.OneOf {
+			return ErrOneOfConflict
+		}
+	}
+	return nil
+}
+
+// checkTupleType verifies the tuple is of the type referenced by a member of a oneof group. Fields which do not belong to a group or do not reference a tuple type accept any tuple.
+func (b *TupleBuilder) checkTupleType(field string, value Tuple) error {
+	f, _, _ := b.tupleType.field(field)
+	if f.OneOf == "" || f.TupleName == "" {
+		return nil
+	}
+
+	if value.Header.NamespaceHash != syncHash.Hash([]byte(f.TupleNamespace)) || value.Header.Hash != syncHash.Hash([]byte(f.TupleName)) {
+		return ErrIncorrectTupleType
+	}
+	return nil
+}
+
+// OneOf returns the name of the field which was written for the given oneof group. If none of the fields in the group were written, `ErrFieldNotPresent` is returned. If the tuple type does not have the group, `ErrUnknownOneOf` is returned.
+func (t *Tuple) OneOf(group string) (string, error) {
+	var found bool
+	var index int
+	for _, version := range t.Header.Type.versions {
+		for _, field := range version {
+			if field.OneOf == group {
+				found = true
+
+				// fields of later versions are not present in the header
+				if index < len(t.Header.Offsets) && t.Header.Offsets[index] != math.MaxUint64 {
+					return field.Name, nil
+				}
+			}
+			index++
+		}
+	}
+
+	if !found {
+		return "", ErrUnknownOneOf
+	}
+	return "", ErrFieldNotPresent
+}
+
+// GetOneOf returns the name of the field which was written for the given oneof group and the tuple embedded in the field. The tuple type is resolved from the hashes in the embedded header using the registry, so the caller can switch on either the field name or the tuple type. The field must be a `TupleField`, otherwise `ErrIncorrectFieldType` is returned.
+func (t *Tuple) GetOneOf(group string, reg *Registry) (field string, value Tuple, err error) {
+	if field, err = t.OneOf(group); err != nil {
+		return "", NIL, err
+	}
+
+	value, err = t.GetTuple(field, reg)
+	if err != nil {
+		return "", NIL, err
+	}
+	return field, value, nil
+}
+
+// checkOneOfs verifies at most one field of each oneof group was written. Tuples which were not written with a builder may contain several.
+func (t *Tuple) checkOneOfs() error {
+	if !t.Header.Type.oneofs {
+		return nil
+	}
+
+	written := make(map[string]bool)
+	var index int
+	for _, version := range t.Header.Type.versions {
+		for _, field := range version {
+			if field.OneOf != "" && index < len(t.Header.Offsets) && t.Header.Offsets[index] != math.MaxUint64 {
+				if written[field.OneOf] {
+					return ErrOneOfConflict
+				}
+				written[field.OneOf] = true
+			}
+			index++
+		}
+	}
+	return nil
+}
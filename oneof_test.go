@@ -0,0 +1,196 @@
+package namedtuple
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestOneOfTypes() (Place, Address, Coordinates TupleType) {
+	Address = New("testing", "address")
+	Address.AddVersion(Field{Name: "street", Required: true, Type: StringField})
+
+	Coordinates = New("testing", "coordinates")
+	Coordinates.AddVersion(
+		Field{Name: "lat", Required: true, Type: Float64Field},
+		Field{Name: "lng", Required: true, Type: Float64Field},
+	)
+
+	Place = New("testing", "place")
+	Place.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "address", Type: TupleField, TupleNamespace: "testing", TupleName: "address", OneOf: "location"},
+	)
+	Place.AddVersion(
+		Field{Name: "coordinates", Type: TupleField, TupleNamespace: "testing", TupleName: "coordinates", OneOf: "location"},
+		Field{Name: "code", Type: StringField, OneOf: "location"},
+	)
+	return
+}
+
+func createTestOneOfRegistry() Registry {
+	Place, Address, Coordinates := createTestOneOfTypes()
+	reg := NewRegistry()
+	reg.Register(Place)
+	reg.Register(Address)
+	reg.Register(Coordinates)
+	return reg
+}
+
+func TestBuilderPutOneOfPass(t *testing.T) {
+	Place, _, Coordinates := createTestOneOfTypes()
+
+	builder := NewBuilder(Coordinates, make([]byte, 64))
+	builder.PutFloat64("lat", 51.5)
+	builder.PutFloat64("lng", -0.12)
+	coordinates, err := builder.Build()
+	assert.Nil(t, err)
+
+	builder = NewBuilder(Place, make([]byte, 256))
+	builder.PutString("name", "London")
+	_, err = builder.PutTuple("coordinates", coordinates)
+	assert.Nil(t, err)
+
+	// the same field can be written again
+	_, err = builder.PutTuple("coordinates", coordinates)
+	assert.Nil(t, err)
+
+	place, err := builder.Build()
+	assert.Nil(t, err)
+
+	reg := createTestOneOfRegistry()
+	field, value, err := place.GetOneOf("location", &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, "coordinates", field)
+	assert.True(t, value.Is(Coordinates))
+
+	lat, err := value.GetFloat64("lat")
+	assert.Nil(t, err)
+	assert.Equal(t, 51.5, lat)
+
+	// the other fields of the group are not present
+	_, err = place.GetTuple("address", &reg)
+	assert.Equal(t, ErrFieldNotPresent, err)
+}
+
+func TestBuilderPutOneOfFail(t *testing.T) {
+	Place, Address, Coordinates := createTestOneOfTypes()
+
+	builder := NewBuilder(Address, make([]byte, 64))
+	builder.PutString("street", "221B Baker Street")
+	address, err := builder.Build()
+	assert.Nil(t, err)
+
+	builder = NewBuilder(Place, make([]byte, 256))
+	_, err = builder.PutTuple("address", address)
+	assert.Nil(t, err)
+
+	// only one field of the group can be written
+	wrote, err := builder.PutString("code", "GB-LND")
+	assert.Equal(t, ErrOneOfConflict, err)
+	assert.Equal(t, 0, wrote)
+
+	// the tuple must be of the type referenced by the field
+	builder = NewBuilder(Place, make([]byte, 256))
+	_, err = builder.PutTuple("coordinates", address)
+	assert.Equal(t, ErrIncorrectTupleType, err)
+
+	builder = NewBuilder(Coordinates, make([]byte, 64))
+	builder.PutFloat64("lat", 51.5)
+	builder.PutFloat64("lng", -0.12)
+	coordinates, err := builder.Build()
+	assert.Nil(t, err)
+
+	builder = NewBuilder(Place, make([]byte, 256))
+	_, err = builder.PutTuple("coordinates", coordinates)
+	assert.Nil(t, err)
+	_, err = builder.PutTuple("address", address)
+	assert.Equal(t, ErrOneOfConflict, err)
+}
+
+func TestTupleOneOf(t *testing.T) {
+	Place, _, _ := createTestOneOfTypes()
+
+	builder := NewBuilder(Place, make([]byte, 256))
+	builder.PutString("name", "London")
+	place, err := builder.Build()
+	assert.Nil(t, err)
+
+	_, err = place.OneOf("location")
+	assert.Equal(t, ErrFieldNotPresent, err)
+	_, err = place.OneOf("unknown")
+	assert.Equal(t, ErrUnknownOneOf, err)
+
+	builder.PutString("name", "London")
+	builder.PutString("code", "GB-LND")
+	place, err = builder.Build()
+	assert.Nil(t, err)
+
+	field, err := place.OneOf("location")
+	assert.Nil(t, err)
+	assert.Equal(t, "code", field)
+
+	// the field is not a tuple
+	reg := createTestOneOfRegistry()
+	_, _, err = place.GetOneOf("location", &reg)
+	assert.Equal(t, ErrIncorrectFieldType, err)
+}
+
+func TestDecodeOneOfConflict(t *testing.T) {
+	Place, _, _ := createTestOneOfTypes()
+
+	// the type without the group is used to write both fields
+	Other := New("testing", "place")
+	Other.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "address", Type: TupleField},
+	)
+	Other.AddVersion(
+		Field{Name: "coordinates", Type: TupleField},
+		Field{Name: "code", Type: StringField},
+	)
+
+	builder := NewBuilder(Other, make([]byte, 256))
+	builder.PutString("name", "London")
+	builder.PutString("code", "GB-LND")
+	place, err := builder.Build()
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	encoder, err := NewEncoderVersion(&buf, ProtocolVersionOne)
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(place))
+
+	// the first tuple shares the buffer of the builder
+	builder = NewBuilder(Other, make([]byte, 256))
+	builder.PutString("name", "London")
+	builder.PutString("code", "GB-LND")
+	builder.PutTuple("address", place)
+	place, err = builder.Build()
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(place))
+
+	reg := NewRegistry()
+	reg.Register(Place)
+
+	place, rest, err := DecodeBytes(&reg, buf.Bytes())
+	assert.Nil(t, err)
+	field, err := place.OneOf("location")
+	assert.Nil(t, err)
+	assert.Equal(t, "code", field)
+
+	_, _, err = DecodeBytes(&reg, rest)
+	assert.Equal(t, ErrOneOfConflict, err)
+}
+
+func TestOneOfFingerprint(t *testing.T) {
+	Place, _, _ := createTestOneOfTypes()
+
+	Other := New("testing", "place")
+	Other.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "address", Type: TupleField, TupleNamespace: "testing", TupleName: "address"},
+	)
+	assert.NotEqual(t, Place.Fingerprint(1), Other.Fingerprint(1))
+}
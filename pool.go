@@ -0,0 +1,40 @@
+package namedtuple
+
+import "sync"
+
+// BuilderPool reuses the builders of a tuple type across goroutines. Builders are taken from the pool with `Get`, used by a single goroutine and returned with `Put`, so building a tuple does not allocate a builder or a buffer in steady state. The pool is safe for concurrent use.
+type BuilderPool struct {
+	tupleType TupleType
+	pool      sync.Pool
+}
+
+// NewBuilderPool creates a pool of builders for the tuple type. New builders write into a buffer of the given size. If the limit is greater than 0, the builders are growable and grow their buffer up to the limit, see `NewGrowableBuilder`. Grown buffers are kept when the builder is returned to the pool.
+func NewBuilderPool(t TupleType, size, limit int) *BuilderPool {
+	p := &BuilderPool{tupleType: t}
+	p.pool.New = func() interface{} {
+		var b TupleBuilder
+		if limit > 0 {
+			b = NewGrowableBuilder(t, make([]byte, size), limit)
+		} else {
+			b = NewBuilder(t, make([]byte, size))
+		}
+		return &b
+	}
+	return p
+}
+
+// Get returns a reset builder from the pool or creates a new one if the pool is empty.
+func (p *BuilderPool) Get() *TupleBuilder {
+	return p.pool.Get().(*TupleBuilder)
+}
+
+// Put resets the builder and returns it to the pool. Tuples built with the builder share its buffer, so they must not be used after the builder is returned. The integer encoding is reset to the default encoding. Builders of other tuple types are not added to the pool.
+func (p *BuilderPool) Put(b *TupleBuilder) {
+	if b.tupleType.ID != p.tupleType.ID {
+		return
+	}
+
+	b.Reset()
+	b.encoding = DefaultIntEncoding
+	p.pool.Put(b)
+}
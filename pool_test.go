@@ -0,0 +1,97 @@
+package namedtuple
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/blacklabeldata/xbinary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderPool(t *testing.T) {
+	User := createTestTupleType()
+	pool := NewBuilderPool(User, 64, 0)
+
+	builder := pool.Get()
+	assert.Equal(t, 64, len(builder.buffer))
+	builder.SetIntEncoding(VarIntEncoding)
+	builder.PutString("uuid", "0123456789abcdef")
+	pool.Put(builder)
+
+	// returned builders are reset
+	builder = pool.Get()
+	assert.Equal(t, 0, builder.pos)
+	assert.Equal(t, notWritten, builder.offset("uuid"))
+	assert.Equal(t, DefaultIntEncoding, builder.encoding)
+
+	// builders of the pool do not grow without a limit
+	_, err := builder.PutString("username", strings.Repeat("u", 100))
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+	pool.Put(builder)
+
+	// builders of other types are not added
+	Other := createTestArrayType()
+	other := NewBuilder(Other, make([]byte, 64))
+	pool.Put(&other)
+	assert.Equal(t, User.ID, pool.Get().tupleType.ID)
+}
+
+func TestBuilderPoolGrowable(t *testing.T) {
+	User := createTestTupleType()
+	pool := NewBuilderPool(User, 16, 256)
+
+	builder := pool.Get()
+	builder.PutString("uuid", "0123456789abcdef")
+	_, err := builder.PutString("username", strings.Repeat("u", 100))
+	assert.Nil(t, err)
+	_, err = builder.PutString("username", strings.Repeat("u", 200))
+	assert.Equal(t, ErrBuilderLimit, err)
+}
+
+func TestBuilderPoolConcurrent(t *testing.T) {
+	User := createTestTupleType()
+	pool := NewBuilderPool(User, 128, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				builder := pool.Get()
+				builder.PutString("uuid", "0123456789abcdef")
+				builder.PutString("username", strings.Repeat("u", i))
+				builder.PutUint8("age", uint8(j))
+				user, err := builder.Build()
+				assert.Nil(t, err)
+
+				username, err := user.GetString("username")
+				assert.Nil(t, err)
+				assert.Equal(t, i, len(username))
+
+				age, err := user.GetUint8("age")
+				assert.Nil(t, err)
+				assert.Equal(t, uint8(j), age)
+				pool.Put(builder)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestBuilderPoolAllocations(t *testing.T) {
+	User := createTestTupleType()
+	pool := NewBuilderPool(User, 128, 0)
+
+	// only the offsets of the tuple header are allocated
+	allocs := testing.AllocsPerRun(100, func() {
+		builder := pool.Get()
+		builder.PutString("uuid", "0123456789abcdef")
+		builder.PutString("username", "username")
+		builder.PutUint8("age", 25)
+		builder.Build()
+		pool.Put(builder)
+	})
+	assert.Equal(t, float64(1), allocs)
+}
@@ -0,0 +1,104 @@
+package namedtuple
+
+import "io"
+
+// TupleReader decodes consecutive tuples from an io.ReaderAt, such as an os.File. The content of each tuple is read into a buffer which is reused by the next call, so reading does not allocate a new buffer per tuple. Tuples returned from the reader are only valid until the next call to `Next` or `ReadAt`. For memory mapped files, use `DecodeBytes` instead which does not copy the data at all.
+type TupleReader struct {
+	reg     *Registry
+	r       io.ReaderAt
+	maxSize uint64
+	offset  int64
+	buffer  []byte
+}
+
+// NewTupleReader creates a new TupleReader using a type Registry, an io.ReaderAt and the maximum size of a tuple. The reader starts at offset 0.
+func NewTupleReader(reg *Registry, r io.ReaderAt, maxSize uint64) *TupleReader {
+	return &TupleReader{reg: reg, r: r, maxSize: maxSize}
+}
+
+// Offset returns the offset of the next tuple.
+func (r *TupleReader) Offset() int64 {
+	return r.offset
+}
+
+// SetOffset sets the offset of the next tuple. The offset must be the start of a tuple.
+func (r *TupleReader) SetOffset(offset int64) {
+	r.offset = offset
+}
+
+// Next reads the tuple at the current offset and advances the offset to the following tuple. At the end of the input, `io.EOF` is returned.
+func (r *TupleReader) Next() (Tuple, error) {
+	t, next, err := r.ReadAt(r.offset)
+	if err != nil {
+		return EmptyTuple, err
+	}
+	r.offset = next
+	return t, nil
+}
+
+// ReadAt reads the tuple at the given offset. The offset of the following tuple is returned as well. If the offset is at the end of the input, `io.EOF` is returned. If the input ends before the tuple, `io.ErrUnexpectedEOF` is returned. Schema frames written by `NewSchemaEncoder` are skipped, the types must be registered in the registry. Compressed blocks are only supported by `Decoder`, for blocks `ErrUnexpectedBlock` is returned.
+func (r *TupleReader) ReadAt(offset int64) (t Tuple, next int64, err error) {
+	for next = offset; ; {
+		version, length, start, err := readFrameHeader(r.r, next)
+		if err != nil {
+			return EmptyTuple, offset, err
+		}
+
+		// Verify length against maxSize
+		if length > r.maxSize {
+			return EmptyTuple, offset, ErrTupleExceedsMaxSize
+		}
+
+		// Read content into the reused buffer
+		if uint64(cap(r.buffer)) < length {
+			r.buffer = make([]byte, length)
+		}
+		content := r.buffer[:length]
+		if n, err := r.r.ReadAt(content, start); n < len(content) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return EmptyTuple, offset, err
+		}
+
+		// Skip schema frames
+		next = start + int64(length)
+		if isSchemaFrame(version, content) {
+			continue
+		}
+
+		t, err = decodeContent(r.reg, version, content)
+		if err != nil {
+			return EmptyTuple, offset, err
+		}
+		return t, next, nil
+	}
+}
+
+// readFrameHeader reads the protocol header and the content length of the tuple at the given offset. The protocol version, the content length and the offset of the content are returned. If the offset is at the end of the input, `io.EOF` is returned. If the input ends within the header, `io.ErrUnexpectedEOF` is returned.
+func readFrameHeader(r io.ReaderAt, offset int64) (version uint8, length uint64, start int64, err error) {
+
+	// Read protocol header and content length. The header may be
+	// shorter than 9 bytes at the end of the input.
+	var header [9]byte
+	n, err := r.ReadAt(header[:], offset)
+	if n == 0 {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, 0, 0, err
+	} else if err != nil && err != io.EOF {
+		return 0, 0, 0, err
+	}
+
+	// Parse content length
+	byteCount, version := ParseProtocolHeader(header[0])
+	if n < 1+int(byteCount) {
+		return 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	length, err = parseLength(byteCount, header[1:1+byteCount])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return version, length, offset + 1 + int64(byteCount), nil
+}
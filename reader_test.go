@@ -0,0 +1,143 @@
+package namedtuple
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encodeTestLocations encodes a location tuple for each longitude using the given protocol version
+func encodeTestLocations(t *testing.T, version uint8, lons ...float32) []byte {
+	var buf []byte
+	out := bytes.NewBuffer(buf)
+	encoder, err := NewEncoderVersion(out, version)
+	assert.Nil(t, err)
+
+	Location := createTestLocationType()
+	for _, lon := range lons {
+		builder := Location.Builder(make([]byte, 256))
+		builder.PutFloat32("lon", lon)
+		builder.PutFloat32("lat", 50.5)
+		loc, err := builder.Build()
+		assert.Nil(t, err)
+		assert.Nil(t, encoder.Encode(loc))
+	}
+	return out.Bytes()
+}
+
+func TestTupleReader(t *testing.T) {
+	b := encodeTestLocations(t, ProtocolVersionOne, 1, 2, 3)
+
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	reader := NewTupleReader(&reg, bytes.NewReader(b), 1024)
+	for _, expected := range []float32{1, 2, 3} {
+		loc, err := reader.Next()
+		assert.Nil(t, err)
+
+		lon, err := loc.GetFloat32("lon")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, lon)
+	}
+	assert.Equal(t, int64(len(b)), reader.Offset())
+
+	// end of input
+	_, err := reader.Next()
+	assert.Equal(t, io.EOF, err)
+
+	// random access
+	size := int64(len(b) / 3)
+	loc, next, err := reader.ReadAt(size)
+	assert.Nil(t, err)
+	assert.Equal(t, 2*size, next)
+
+	lon, err := loc.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(2), lon)
+
+	// set offset
+	reader.SetOffset(2 * size)
+	loc, err = reader.Next()
+	assert.Nil(t, err)
+	lon, err = loc.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(3), lon)
+}
+
+func TestTupleReaderVersionTwo(t *testing.T) {
+	b := encodeTestLocations(t, ProtocolVersionTwo, 1, 2)
+
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	reader := NewTupleReader(&reg, bytes.NewReader(b), 1024)
+	for _, expected := range []float32{1, 2} {
+		loc, err := reader.Next()
+		assert.Nil(t, err)
+
+		lon, err := loc.GetFloat32("lon")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, lon)
+	}
+}
+
+func TestTupleReaderSchemaFrames(t *testing.T) {
+	reg := createTestOneOfRegistry()
+	places := encodeTestPlaces(t, &reg, "home", "work")
+	b := append(encodeTestLocations(t, ProtocolVersionOne, 1), places...)
+	reg.Register(createTestLocationType())
+
+	// schema frames are skipped
+	reader := NewTupleReader(&reg, bytes.NewReader(b), DefaultMaxSize)
+	loc, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "Location", loc.Header.Type.Name)
+	for _, expected := range []string{"home", "work"} {
+		place, err := reader.Next()
+		assert.Nil(t, err)
+		name, _ := place.GetString("name")
+		assert.Equal(t, expected, name)
+	}
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+
+	// the offset is not moved if only schema frames are left
+	_, _, rest, _ := sliceFrame(places)
+	schemaFrame := places[:len(places)-len(rest)]
+	reader = NewTupleReader(&reg, bytes.NewReader(schemaFrame), DefaultMaxSize)
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, int64(0), reader.Offset())
+}
+
+func TestTupleReaderFail(t *testing.T) {
+	b := encodeTestLocations(t, ProtocolVersionOne, 1)
+
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	// truncated content
+	reader := NewTupleReader(&reg, bytes.NewReader(b[:len(b)-1]), 1024)
+	_, err := reader.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Equal(t, int64(0), reader.Offset())
+
+	// truncated length
+	reader = NewTupleReader(&reg, bytes.NewReader([]byte{64, 1}), 1024)
+	_, err = reader.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	// exceeds max size
+	reader = NewTupleReader(&reg, bytes.NewReader(b), 4)
+	_, err = reader.Next()
+	assert.Equal(t, ErrTupleExceedsMaxSize, err)
+
+	// unknown type
+	empty := NewRegistry()
+	reader = NewTupleReader(&empty, bytes.NewReader(b), 1024)
+	_, err = reader.Next()
+	assert.Equal(t, ErrUnknownTupleType, err)
+}
@@ -1,10 +1,25 @@
 package namedtuple
 
 import (
+	"errors"
+	"fmt"
 	"hash/fnv"
 	"sync"
 )
 
+// ErrTypeAlreadyRegistered is returned from Register if a type with the same namespace and name has already been registered.
+var ErrTypeAlreadyRegistered = errors.New("Tuple type already registered")
+
+// CollisionError is returned from Register if the hashes of a type collide with the hashes of a different type which has already been registered. Both types are included so the colliding names can be inspected.
+type CollisionError struct {
+	Type     TupleType
+	Existing TupleType
+}
+
+func (c CollisionError) Error() string {
+	return fmt.Sprintf("Tuple type %s.%s collides with %s.%s", c.Type.Namespace, c.Type.Name, c.Existing.Namespace, c.Existing.Name)
+}
+
 var DefaultRegistry Registry
 
 func init() {
@@ -12,13 +27,39 @@ func init() {
 }
 
 func NewRegistry() Registry {
-	return Registry{content: make(map[uint64]TupleType), hasher: NewHasher(fnv.New32a())}
+	return Registry{&registryState{content: make(map[uint64]TupleType), ids: make(map[uint64]TupleType), hasher: NewHasher(fnv.New32a())}}
 }
 
+// Registry stores tuple types by their hashes and identities. Copies of a registry, such as the registry of a `Decoder`, share the registered types and the lock, so types registered with one copy are visible in all copies.
 type Registry struct {
-	content map[uint64]TupleType
-	hasher  SynchronizedHash
-	mutex   sync.Mutex
+	*registryState
+}
+
+// registryState is shared by all copies of a registry
+type registryState struct {
+	content  map[uint64]TupleType
+	ids      map[uint64]TupleType
+	hasher   SynchronizedHash
+	mutex    sync.Mutex
+	resolver Resolver
+}
+
+// Resolver looks up tuple types which are not registered, for example from a schema registry service. See `Registry.SetResolver`.
+type Resolver interface {
+
+	// Resolve returns the tuple type with the given namespace and name.
+	Resolve(namespace, name string) (TupleType, error)
+
+	// ResolveHash returns the tuple type with the given namespace hash and type hash.
+	ResolveHash(namespace, name uint32) (TupleType, error)
+}
+
+// SetResolver sets the resolver used by `Get` and `GetWithHash` for types which are not registered. Resolved types are registered, so each type is only resolved once and the registry acts as a local cache. Errors from the resolver are reported as missing types. All copies of the registry use the resolver.
+func (r *Registry) SetResolver(resolver Resolver) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.resolver = resolver
 }
 
 func (r *Registry) Contains(t TupleType) bool {
@@ -40,41 +81,132 @@ func (r *Registry) ContainsName(namespace, name string) bool {
 
 func (r *Registry) Get(namespace, name string) (tupleType TupleType, exists bool) {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
 	tupleType, exists = r.content[r.typeSignature(namespace, name)]
-	return
+	resolver := r.resolver
+	r.mutex.Unlock()
+
+	if exists || resolver == nil {
+		return
+	}
+	tupleType, err := resolver.Resolve(namespace, name)
+	return r.resolved(tupleType, err, tupleType.Namespace == namespace && tupleType.Name == name)
 }
 
 func (r *Registry) GetWithHash(namespace, name uint32) (tupleType TupleType, exists bool) {
+	r.mutex.Lock()
+	tupleType, exists = r.content[r.typeSignatureHash(namespace, name)]
+	resolver := r.resolver
+	r.mutex.Unlock()
+
+	if exists || resolver == nil {
+		return
+	}
+	tupleType, err := resolver.ResolveHash(namespace, name)
+	return r.resolved(tupleType, err, tupleType.NamespaceHash == namespace && tupleType.Hash == name)
+}
+
+// resolved registers a type returned by the resolver. The type is only used if it matches the requested type. If the type was registered concurrently, the registered type is returned.
+func (r *Registry) resolved(t TupleType, err error, matches bool) (TupleType, bool) {
+	if err != nil || !matches {
+		return TupleType{}, false
+	}
+
+	if err := r.Register(t); err == ErrTypeAlreadyRegistered {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		t, exists := r.content[r.typeSignature(t.Namespace, t.Name)]
+		return t, exists
+	} else if err != nil {
+		return TupleType{}, false
+	}
+	return t, true
+}
+
+// GetWithID returns the tuple type with the given 64-bit identity. See `TypeID`.
+func (r *Registry) GetWithID(id uint64) (tupleType TupleType, exists bool) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	tupleType, exists = r.content[r.typeSignatureHash(namespace, name)]
+	tupleType, exists = r.ids[id]
 	return
 }
 
-func (r *Registry) Register(t TupleType) {
+// Register adds the tuple type to the registry. If a type with the same namespace and name has already been registered, `ErrTypeAlreadyRegistered` is returned. If either the 32-bit hashes or the 64-bit identity of the type collide with a different type, a `CollisionError` is returned. The registry is not modified if an error is returned.
+func (r *Registry) Register(t TupleType) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	hash := r.typeSignature(t.Namespace, t.Name)
-	if _, exists := r.content[hash]; !exists {
-		r.content[hash] = t
+	return r.register(t)
+}
+
+// Replace removes the registered type with the namespace and name of the old type and registers the new type in a single step, so other goroutines never see the type as missing. If the new type can not be registered, the error of `Register` is returned and the old type is kept.
+func (r *Registry) Replace(old, t TupleType) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, removed := r.unregister(old)
+	if err := r.register(t); err != nil {
+		if removed {
+			r.register(existing)
+		}
+		return err
 	}
+	return nil
+}
+
+func (r *Registry) register(t TupleType) error {
+	if existing, collides := r.collision(t.Namespace, t.Name); collides {
+		if existing.Namespace == t.Namespace && existing.Name == t.Name {
+			return ErrTypeAlreadyRegistered
+		}
+		return CollisionError{t, existing}
+	}
+
+	r.content[r.typeSignature(t.Namespace, t.Name)] = t
+	r.ids[TypeID(t.Namespace, t.Name)] = t
+	return nil
 }
 
+// Collision returns the registered type which shares either the 32-bit hashes or the 64-bit identity with the given namespace and name. The registered type may have the same namespace and name.
+func (r *Registry) Collision(namespace, name string) (existing TupleType, collides bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.collision(namespace, name)
+}
+
+func (r *Registry) collision(namespace, name string) (existing TupleType, collides bool) {
+	if existing, collides = r.content[r.typeSignature(namespace, name)]; collides {
+		return
+	}
+	existing, collides = r.ids[TypeID(namespace, name)]
+	return
+}
+
+// Unregister removes the tuple type from the registry. Types with colliding hashes are not removed.
 func (r *Registry) Unregister(t TupleType) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	r.unregister(t)
+}
+
+// unregister removes the registered type with the namespace and name of the given type and returns it.
+func (r *Registry) unregister(t TupleType) (existing TupleType, removed bool) {
 	hash := r.typeSignature(t.Namespace, t.Name)
-	if _, exists := r.content[hash]; exists {
+	if existing, exists := r.content[hash]; exists && existing.Namespace == t.Namespace && existing.Name == t.Name {
 		delete(r.content, hash)
+		delete(r.ids, TypeID(t.Namespace, t.Name))
+		return existing, true
 	}
+	return TupleType{}, false
 }
 
 func (r *Registry) Size() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	return len(r.content)
 }
 
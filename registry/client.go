@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// StatusError is returned when the service responds with an unexpected status code. The message is the body of the response.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("registry: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client looks up tuple types from the service. Types are cached in a local registry, which resolves missing types from the service. Use the registry of the client to decode tuples of types which are only known to the service.
+type Client struct {
+	url    string
+	client *http.Client
+	reg    namedtuple.Registry
+}
+
+// NewClient creates a client for the service at the given URL. If the HTTP client is nil, `http.DefaultClient` is used.
+func NewClient(url string, client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := &Client{url: strings.TrimRight(url, "/"), client: client, reg: namedtuple.NewRegistry()}
+	c.reg.SetResolver(c)
+	return c
+}
+
+// Registry returns the local cache of the client. Types which are not cached are resolved from the service.
+func (c *Client) Registry() *namedtuple.Registry {
+	return &c.reg
+}
+
+// Namespaces returns the sorted namespaces of the types in the service.
+func (c *Client) Namespaces() ([]string, error) {
+	var namespaces []string
+	if err := c.do("GET", "/namespaces", nil, &namespaces, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+// Types returns the types in the service. If the namespace is not empty, only the types of the namespace are returned. The types are not cached.
+func (c *Client) Types(namespace string) ([]namedtuple.TupleType, error) {
+	path := "/types"
+	if namespace != "" {
+		path += "?namespace=" + url.QueryEscape(namespace)
+	}
+
+	var types []namedtuple.TupleType
+	if err := c.do("GET", path, nil, &types, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// Get returns the type with the given namespace and name from the local cache or from the service.
+func (c *Client) Get(namespace, name string) (namedtuple.TupleType, error) {
+	if c.reg.ContainsName(namespace, name) {
+		t, _ := c.reg.Get(namespace, name)
+		return t, nil
+	}
+
+	t, err := c.Resolve(namespace, name)
+	if err != nil {
+		return t, err
+	}
+	return c.cache(t), nil
+}
+
+// GetWithHash returns the type with the given namespace hash and type hash from the local cache or from the service.
+func (c *Client) GetWithHash(namespace, name uint32) (namedtuple.TupleType, error) {
+	if c.reg.ContainsHash(uint64(namespace)<<32 | uint64(name)) {
+		t, _ := c.reg.GetWithHash(namespace, name)
+		return t, nil
+	}
+
+	t, err := c.ResolveHash(namespace, name)
+	if err != nil {
+		return t, err
+	}
+	return c.cache(t), nil
+}
+
+// Register registers the type with the service. Registered types may only be extended with new versions, see `CheckCompatibility`. The local cache is updated with the registered type.
+func (c *Client) Register(t namedtuple.TupleType) (namedtuple.TupleType, error) {
+	body, err := json.Marshal(t)
+	if err != nil {
+		return namedtuple.TupleType{}, err
+	}
+
+	var registered namedtuple.TupleType
+	if err := c.do("PUT", typePath(t.Namespace, t.Name), body, &registered, http.StatusOK, http.StatusCreated); err != nil {
+		return namedtuple.TupleType{}, err
+	}
+
+	// the cached revision is replaced
+	return registered, c.reg.Replace(registered, registered)
+}
+
+// Resolve fetches the type with the given namespace and name from the service. It implements `namedtuple.Resolver`.
+func (c *Client) Resolve(namespace, name string) (namedtuple.TupleType, error) {
+	var t namedtuple.TupleType
+	err := c.do("GET", typePath(namespace, name), nil, &t, http.StatusOK)
+	return t, err
+}
+
+// ResolveHash fetches the type with the given namespace hash and type hash from the service. It implements `namedtuple.Resolver`.
+func (c *Client) ResolveHash(namespace, name uint32) (namedtuple.TupleType, error) {
+	var t namedtuple.TupleType
+	err := c.do("GET", fmt.Sprintf("/hashes/%d/%d", namespace, name), nil, &t, http.StatusOK)
+	return t, err
+}
+
+// cache adds the type to the local cache. If the type was cached concurrently, the cached type is returned.
+func (c *Client) cache(t namedtuple.TupleType) namedtuple.TupleType {
+	if err := c.reg.Register(t); err == namedtuple.ErrTypeAlreadyRegistered {
+		t, _ = c.reg.Get(t.Namespace, t.Name)
+	}
+	return t
+}
+
+// do sends the request and decodes the JSON response into value. Missing types are returned as `namedtuple.ErrUnknownTupleType` and other status codes as a `StatusError`.
+func (c *Client) do(method, path string, body []byte, value interface{}, expected ...int) error {
+	req, err := http.NewRequest(method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	} else if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return json.Unmarshal(data, value)
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound && method == "GET" {
+		return namedtuple.ErrUnknownTupleType
+	}
+	return StatusError{resp.StatusCode, strings.TrimSpace(string(data))}
+}
+
+// typePath returns the path of the type with the given namespace and name
+func typePath(namespace, name string) string {
+	return "/types/" + url.PathEscape(namespace) + "/" + url.PathEscape(name)
+}
@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestClient starts a test server and returns a client for it and the number of requests
+func createTestClient() (*Client, *httptest.Server, *int32) {
+	server, _ := createTestServer()
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		server.ServeHTTP(w, r)
+	}))
+	return NewClient(ts.URL+"/", nil), ts, &requests
+}
+
+func TestClientGet(t *testing.T) {
+	client, ts, requests := createTestClient()
+	defer ts.Close()
+
+	namespaces, err := client.Namespaces()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"geo", "users"}, namespaces)
+
+	types, err := client.Types("geo")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(types))
+	assert.Equal(t, "Location", types[0].Name)
+
+	// types are cached
+	User, err := client.Get("users", "User")
+	assert.Nil(t, err)
+	expected := createTestUserType(1)
+	assert.Equal(t, expected.Fingerprint(1), User.Fingerprint(1))
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+	_, err = client.Get("users", "User")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+
+	Location := createTestLocationType()
+	other, err := client.GetWithHash(Location.NamespaceHash, Location.Hash)
+	assert.Nil(t, err)
+	assert.Equal(t, "Location", other.Name)
+	client.GetWithHash(Location.NamespaceHash, Location.Hash)
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+	assert.Equal(t, 2, client.Registry().Size())
+
+	// missing types
+	_, err = client.Get("users", "Missing")
+	assert.Equal(t, namedtuple.ErrUnknownTupleType, err)
+	_, err = client.GetWithHash(1, 2)
+	assert.Equal(t, namedtuple.ErrUnknownTupleType, err)
+}
+
+func TestClientRegister(t *testing.T) {
+	client, ts, _ := createTestClient()
+	defer ts.Close()
+
+	// the cache is updated with new versions
+	_, err := client.Get("users", "User")
+	assert.Nil(t, err)
+	User, err := client.Register(createTestUserType(2))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, User.NumVersions())
+	cached, _ := client.Registry().Get("users", "User")
+	assert.Equal(t, 2, cached.NumVersions())
+
+	// incompatible types are rejected
+	_, err = client.Register(createTestUserType(1))
+	assert.IsType(t, StatusError{}, err)
+	assert.Equal(t, http.StatusConflict, err.(StatusError).StatusCode)
+	assert.Contains(t, err.Error(), ErrIncompatibleType.Error())
+}
+
+func TestClientDecoder(t *testing.T) {
+	client, ts, _ := createTestClient()
+	defer ts.Close()
+
+	Location := createTestLocationType()
+	builder := namedtuple.NewBuilder(Location, make([]byte, 128))
+	builder.PutFloat32("lon", 7.5)
+	builder.PutFloat32("lat", 50.5)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+
+	var out bytes.Buffer
+	encoder := namedtuple.NewEncoder(&out)
+	assert.Nil(t, encoder.Encode(loc))
+
+	// the type is resolved from the service while decoding
+	assert.Equal(t, 0, client.Registry().Size())
+	decoded, _, err := namedtuple.DecodeBytes(client.Registry(), out.Bytes())
+	assert.Nil(t, err)
+	lon, err := decoded.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(7.5), lon)
+	assert.Equal(t, 1, client.Registry().Size())
+
+	// the service is not available
+	ts.Close()
+	_, err = client.Get("users", "User")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,203 @@
+// Package registry serves a namedtuple.Registry over HTTP and provides a client which resolves tuple types from the service.
+//
+// The service has the following endpoints. Tuple types are encoded as JSON objects, see namedtuple.TupleType.MarshalJSON.
+//
+//	GET /namespaces                        sorted JSON array of the namespaces
+//	GET /types?namespace=users             JSON array of the types, optionally of a single namespace
+//	GET /types/{namespace}/{name}          the type with the given namespace and name
+//	PUT /types/{namespace}/{name}          registers a new type or new versions of a type
+//	GET /hashes/{namespaceHash}/{hash}     the type with the given hashes
+//	GET /snapshot                          binary snapshot written by namedtuple.Registry.WriteTo
+//
+// Errors are returned as plain text with the matching status code.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// ErrIncompatibleType is returned when registering a tuple type which does not keep the versions of the registered type.
+var ErrIncompatibleType = errors.New("Tuple type is not compatible with the registered type")
+
+// CheckCompatibility returns an error if tuples written with the registered type can not be read with the new revision of the type or the other way around. Versions are append-only, so the new revision must start with the exact versions of the registered type. New versions may be added.
+func CheckCompatibility(registered, t namedtuple.TupleType) error {
+	if registered.Namespace != t.Namespace || registered.Name != t.Name {
+		return fmt.Errorf("%s: %s.%s is not %s.%s", ErrIncompatibleType, t.Namespace, t.Name, registered.Namespace, registered.Name)
+	} else if t.NumVersions() < registered.NumVersions() {
+		return fmt.Errorf("%s: %s.%s has %d versions, the registered type has %d", ErrIncompatibleType, t.Namespace, t.Name, t.NumVersions(), registered.NumVersions())
+	}
+
+	for version := 1; version <= registered.NumVersions(); version++ {
+		if t.Fingerprint(version) != registered.Fingerprint(version) {
+			return fmt.Errorf("%s: version %d of %s.%s was changed", ErrIncompatibleType, version, t.Namespace, t.Name)
+		}
+	}
+	return nil
+}
+
+// Server serves the tuple types of a registry over HTTP. Registrations are serialized, so the compatibility check and the update of the registry are atomic.
+type Server struct {
+	reg   *namedtuple.Registry
+	mutex sync.Mutex
+
+	// Save is called with the registry after each registration, for example to write a snapshot. If it returns an error, the registration is reverted and the error is returned to the client.
+	Save func(reg *namedtuple.Registry) error
+}
+
+// NewServer creates a server for the registry.
+func NewServer(reg *namedtuple.Registry) *Server {
+	return &Server{reg: reg}
+}
+
+// ServeHTTP handles the requests to the endpoints of the service.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "namespaces" && r.Method == "GET":
+		s.namespaces(w)
+	case len(path) == 1 && path[0] == "types" && r.Method == "GET":
+		s.types(w, r.URL.Query().Get("namespace"))
+	case len(path) == 3 && path[0] == "types" && r.Method == "GET":
+		t, exists := s.reg.Get(path[1], path[2])
+		s.writeType(w, t, exists)
+	case len(path) == 3 && path[0] == "types" && r.Method == "PUT":
+		s.register(w, r, path[1], path[2])
+	case len(path) == 3 && path[0] == "hashes" && r.Method == "GET":
+		s.hashes(w, path[1], path[2])
+	case len(path) == 1 && path[0] == "snapshot" && r.Method == "GET":
+		w.Header().Set("Content-Type", "application/octet-stream")
+		s.reg.WriteTo(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// namespaces writes the sorted namespaces of the registered types.
+func (s *Server) namespaces(w http.ResponseWriter) {
+	namespaces := []string{}
+	for _, t := range s.reg.Types() {
+		if len(namespaces) == 0 || namespaces[len(namespaces)-1] != t.Namespace {
+			namespaces = append(namespaces, t.Namespace)
+		}
+	}
+	writeJSON(w, http.StatusOK, namespaces)
+}
+
+// types writes the registered types. If the namespace is not empty, only the types of the namespace are written.
+func (s *Server) types(w http.ResponseWriter, namespace string) {
+	types := []namedtuple.TupleType{}
+	for _, t := range s.reg.Types() {
+		if namespace == "" || t.Namespace == namespace {
+			types = append(types, t)
+		}
+	}
+	writeJSON(w, http.StatusOK, types)
+}
+
+// hashes writes the type with the given hashes. The hashes are decimal or hexadecimal with a 0x prefix.
+func (s *Server) hashes(w http.ResponseWriter, namespaceHash, hash string) {
+	namespace, err := strconv.ParseUint(namespaceHash, 0, 32)
+	if err != nil {
+		http.Error(w, "invalid namespace hash: "+namespaceHash, http.StatusBadRequest)
+		return
+	}
+	name, err := strconv.ParseUint(hash, 0, 32)
+	if err != nil {
+		http.Error(w, "invalid type hash: "+hash, http.StatusBadRequest)
+		return
+	}
+
+	t, exists := s.reg.GetWithHash(uint32(namespace), uint32(name))
+	s.writeType(w, t, exists)
+}
+
+// register registers a new type or replaces a registered type with a compatible revision. New types are created with 201, updated and unchanged types return 200.
+func (s *Server) register(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	var t namedtuple.TupleType
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if t.Namespace != namespace || t.Name != name {
+		http.Error(w, fmt.Sprintf("type %s.%s does not match the path", t.Namespace, t.Name), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registered, exists := s.reg.Get(namespace, name)
+	if exists {
+		if err := CheckCompatibility(registered, t); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		} else if t.Fingerprint(t.NumVersions()) == registered.Fingerprint(registered.NumVersions()) {
+			writeJSON(w, http.StatusOK, registered)
+			return
+		}
+	}
+
+	// hash collisions with other types are conflicts as well
+	var err error
+	if exists {
+		err = s.reg.Replace(registered, t)
+	} else {
+		err = s.reg.Register(t)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if s.Save != nil {
+		if err := s.Save(s.reg); err != nil {
+			s.revert(t, registered, exists)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	status := http.StatusOK
+	if !exists {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, t)
+}
+
+// revert restores the registered type after a failed registration.
+func (s *Server) revert(t, registered namedtuple.TupleType, exists bool) {
+	if exists {
+		s.reg.Replace(t, registered)
+	} else {
+		s.reg.Unregister(t)
+	}
+}
+
+// writeType writes the type or a 404 if it does not exist.
+func (s *Server) writeType(w http.ResponseWriter, t namedtuple.TupleType, exists bool) {
+	if !exists {
+		http.Error(w, "tuple type not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// writeJSON writes the value as JSON with the given status code.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
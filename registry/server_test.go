@@ -0,0 +1,203 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestUserType(versions int) namedtuple.TupleType {
+	User := namedtuple.New("users", "User")
+	User.AddVersion(
+		namedtuple.Field{Name: "uuid", Required: true, Type: namedtuple.StringField},
+		namedtuple.Field{Name: "username", Required: true, Type: namedtuple.StringField})
+	if versions > 1 {
+		User.AddVersion(namedtuple.Field{Name: "age", Required: false, Type: namedtuple.Uint8Field})
+	}
+	return User
+}
+
+func createTestLocationType() namedtuple.TupleType {
+	Location := namedtuple.New("geo", "Location")
+	Location.AddVersion(
+		namedtuple.Field{Name: "lon", Required: true, Type: namedtuple.Float32Field},
+		namedtuple.Field{Name: "lat", Required: true, Type: namedtuple.Float32Field})
+	return Location
+}
+
+func createTestServer() (*Server, *namedtuple.Registry) {
+	reg := namedtuple.NewRegistry()
+	reg.Register(createTestUserType(1))
+	reg.Register(createTestLocationType())
+	return NewServer(&reg), &reg
+}
+
+// request sends a request to the handler and returns the response
+func request(handler http.Handler, method, path string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	User := createTestUserType(1)
+	assert.Nil(t, CheckCompatibility(User, User))
+
+	// new versions can be added
+	assert.Nil(t, CheckCompatibility(User, createTestUserType(2)))
+
+	// versions can not be removed
+	err := CheckCompatibility(createTestUserType(2), User)
+	assert.Contains(t, err.Error(), ErrIncompatibleType.Error())
+	assert.Contains(t, err.Error(), "has 1 versions")
+
+	// versions can not be changed
+	Changed := namedtuple.New("users", "User")
+	Changed.AddVersion(namedtuple.Field{Name: "uuid", Required: true, Type: namedtuple.StringField})
+	err = CheckCompatibility(User, Changed)
+	assert.Contains(t, err.Error(), "version 1 of users.User was changed")
+
+	// other types
+	err = CheckCompatibility(User, createTestLocationType())
+	assert.Contains(t, err.Error(), "geo.Location is not users.User")
+}
+
+func TestServerGet(t *testing.T) {
+	server, _ := createTestServer()
+
+	w := request(server, "GET", "/namespaces", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `["geo","users"]`, w.Body.String())
+
+	w = request(server, "GET", "/types", nil)
+	var types []namedtuple.TupleType
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &types))
+	assert.Equal(t, 2, len(types))
+	assert.Equal(t, "Location", types[0].Name)
+
+	w = request(server, "GET", "/types?namespace=users", nil)
+	types = nil
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &types))
+	assert.Equal(t, 1, len(types))
+	assert.Equal(t, "User", types[0].Name)
+
+	w = request(server, "GET", "/types/users/User", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var User namedtuple.TupleType
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &User))
+	expected := createTestUserType(1)
+	assert.Equal(t, expected.Fingerprint(1), User.Fingerprint(1))
+
+	// hashes can be decimal or hexadecimal
+	Location := createTestLocationType()
+	for _, path := range []string{
+		fmt.Sprintf("/hashes/%d/%d", Location.NamespaceHash, Location.Hash),
+		fmt.Sprintf("/hashes/%#x/%#x", Location.NamespaceHash, Location.Hash),
+	} {
+		w = request(server, "GET", path, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var other namedtuple.TupleType
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &other))
+		assert.Equal(t, "Location", other.Name)
+	}
+
+	w = request(server, "GET", "/snapshot", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	snapshot, err := namedtuple.ReadRegistry(w.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, snapshot.Size())
+
+	// missing types and invalid requests
+	assert.Equal(t, http.StatusNotFound, request(server, "GET", "/types/users/Missing", nil).Code)
+	assert.Equal(t, http.StatusNotFound, request(server, "GET", "/hashes/1/2", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, request(server, "GET", "/hashes/x/2", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, request(server, "GET", "/hashes/1/4294967296", nil).Code)
+	assert.Equal(t, http.StatusNotFound, request(server, "GET", "/types/users", nil).Code)
+	assert.Equal(t, http.StatusNotFound, request(server, "DELETE", "/types/users/User", nil).Code)
+}
+
+func TestServerRegister(t *testing.T) {
+	server, reg := createTestServer()
+
+	// new types are created
+	Message := namedtuple.New("chat", "Message")
+	Message.AddVersion(namedtuple.Field{Name: "text", Required: true, Type: namedtuple.StringField})
+	body, _ := json.Marshal(Message)
+	w := request(server, "PUT", "/types/chat/Message", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.True(t, reg.ContainsName("chat", "Message"))
+
+	// registering the same type again does not change anything
+	w = request(server, "PUT", "/types/chat/Message", body)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// new versions are added
+	body, _ = json.Marshal(createTestUserType(2))
+	w = request(server, "PUT", "/types/users/User", body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	User, _ := reg.Get("users", "User")
+	assert.Equal(t, 2, User.NumVersions())
+
+	// versions can not be removed
+	body, _ = json.Marshal(createTestUserType(1))
+	w = request(server, "PUT", "/types/users/User", body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), ErrIncompatibleType.Error())
+	User, _ = reg.Get("users", "User")
+	assert.Equal(t, 2, User.NumVersions())
+
+	// the type must match the path
+	w = request(server, "PUT", "/types/users/Other", body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(server, "PUT", "/types/users/User", []byte("{"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestServerSave(t *testing.T) {
+	server, reg := createTestServer()
+
+	saved := 0
+	server.Save = func(r *namedtuple.Registry) error {
+		assert.Equal(t, reg, r)
+		saved++
+		return nil
+	}
+
+	body, _ := json.Marshal(createTestUserType(2))
+	assert.Equal(t, http.StatusOK, request(server, "PUT", "/types/users/User", body).Code)
+	assert.Equal(t, 1, saved)
+
+	// unchanged types are not saved
+	assert.Equal(t, http.StatusOK, request(server, "PUT", "/types/users/User", body).Code)
+	assert.Equal(t, 1, saved)
+
+	// failed saves are reverted
+	server.Save = func(r *namedtuple.Registry) error {
+		return errors.New("disk full")
+	}
+
+	Message := namedtuple.New("chat", "Message")
+	Message.AddVersion(namedtuple.Field{Name: "text", Required: true, Type: namedtuple.StringField})
+	body, _ = json.Marshal(Message)
+	w := request(server, "PUT", "/types/chat/Message", body)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "disk full")
+	assert.False(t, reg.ContainsName("chat", "Message"))
+
+	User := createTestUserType(2)
+	User.AddVersion(namedtuple.Field{Name: "email", Required: false, Type: namedtuple.StringField})
+	body, _ = json.Marshal(User)
+	assert.Equal(t, http.StatusInternalServerError, request(server, "PUT", "/types/users/User", body).Code)
+	registered, _ := reg.Get("users", "User")
+	assert.Equal(t, 2, registered.NumVersions())
+}
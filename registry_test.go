@@ -1,7 +1,9 @@
 package namedtuple
 
 import (
-	// "fmt"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,14 +12,14 @@ import (
 
 func createTestTupleType() TupleType {
 	// fields
-	uuid := Field{"uuid", true, StringField}
-	username := Field{"username", true, StringField}
-	age := Field{"age", false, Uint8Field}
-	location := Field{"location", false, TupleField}
+	uuid := Field{Name: "uuid", Required: true, Type: StringField}
+	username := Field{Name: "username", Required: true, Type: StringField}
+	age := Field{Name: "age", Required: false, Type: Uint8Field}
+	location := Field{Name: "location", Required: false, Type: TupleField}
 
-	lat := Field{"lat", false, Float32Field}
-	lon := Field{"lon", false, Float32Field}
-	alt := Field{"alt", false, Float64Field}
+	lat := Field{Name: "lat", Required: false, Type: Float32Field}
+	lon := Field{Name: "lon", Required: false, Type: Float32Field}
+	alt := Field{Name: "alt", Required: false, Type: Float64Field}
 
 	// create tuple type
 	User := New("testing", "user")
@@ -191,3 +193,248 @@ func TestRegistryGetWithHashFalse(t *testing.T) {
 	assert.Equal(t, TupleType{}, tupleType)
 	assert.Equal(t, false, exists)
 }
+
+func TestRegistryRegisterDuplicate(t *testing.T) {
+
+	// create new empty registry
+	reg := NewRegistry()
+
+	// create type
+	User := createTestTupleType()
+
+	// add User type twice
+	assert.Nil(t, reg.Register(User))
+	assert.Equal(t, ErrTypeAlreadyRegistered, reg.Register(User))
+	assert.Equal(t, 1, reg.Size())
+}
+
+func TestRegistryRegisterCollision(t *testing.T) {
+
+	// create new empty registry
+	reg := NewRegistry()
+
+	// the type names have the same 32-bit FNV-1a hash
+	A := New("testing", "type129599")
+	B := New("testing", "type732382")
+	assert.Equal(t, A.Hash, B.Hash)
+	assert.NotEqual(t, A.ID, B.ID)
+
+	// add A type
+	assert.Nil(t, reg.Register(A))
+
+	// B collides with A
+	existing, collides := reg.Collision(B.Namespace, B.Name)
+	assert.True(t, collides)
+	assert.Equal(t, A, existing)
+
+	err := reg.Register(B)
+	assert.Equal(t, CollisionError{B, A}, err)
+	assert.Equal(t, "Tuple type testing.type732382 collides with testing.type129599", err.Error())
+	assert.Equal(t, 1, reg.Size())
+
+	// unregistering B does not remove A
+	reg.Unregister(B)
+	assert.Equal(t, 1, reg.Size())
+	assert.True(t, reg.ContainsName(A.Namespace, A.Name))
+
+	// unrelated types do not collide
+	_, collides = reg.Collision("testing", "other")
+	assert.False(t, collides)
+}
+
+func TestRegistryGetWithID(t *testing.T) {
+
+	// create new empty registry
+	reg := NewRegistry()
+
+	// create type
+	User := createTestTupleType()
+	assert.Equal(t, TypeID(User.Namespace, User.Name), User.ID)
+
+	// not registered yet
+	_, exists := reg.GetWithID(User.ID)
+	assert.False(t, exists)
+
+	// make sure the registry contains the same User type
+	reg.Register(User)
+	tupleType, exists := reg.GetWithID(User.ID)
+	assert.Equal(t, User, tupleType)
+	assert.True(t, exists)
+
+	// removed along with the type
+	reg.Unregister(User)
+	_, exists = reg.GetWithID(User.ID)
+	assert.False(t, exists)
+}
+
+// mapResolver resolves types from a map and counts the lookups
+type mapResolver struct {
+	types   []TupleType
+	lookups int32
+}
+
+func (m *mapResolver) Resolve(namespace, name string) (TupleType, error) {
+	atomic.AddInt32(&m.lookups, 1)
+	for _, t := range m.types {
+		if t.Namespace == namespace && t.Name == name {
+			return t, nil
+		}
+	}
+	return TupleType{}, ErrUnknownTupleType
+}
+
+func (m *mapResolver) ResolveHash(namespace, name uint32) (TupleType, error) {
+	atomic.AddInt32(&m.lookups, 1)
+	for _, t := range m.types {
+		if t.NamespaceHash == namespace && t.Hash == name {
+			return t, nil
+		}
+	}
+	return TupleType{}, ErrUnknownTupleType
+}
+
+func TestRegistrySetResolver(t *testing.T) {
+	User := createTestTupleType()
+	Location := createTestLocationType()
+	resolver := &mapResolver{types: []TupleType{User, Location}}
+
+	reg := NewRegistry()
+	reg.SetResolver(resolver)
+	assert.False(t, reg.ContainsName("testing", "user"))
+
+	// resolved types are cached
+	tupleType, exists := reg.Get("testing", "user")
+	assert.True(t, exists)
+	assert.Equal(t, User.Fingerprint(User.NumVersions()), tupleType.Fingerprint(tupleType.NumVersions()))
+	assert.True(t, reg.ContainsName("testing", "user"))
+	reg.Get("testing", "user")
+	assert.Equal(t, int32(1), resolver.lookups)
+
+	tupleType, exists = reg.GetWithHash(Location.NamespaceHash, Location.Hash)
+	assert.True(t, exists)
+	assert.Equal(t, "Location", tupleType.Name)
+	assert.Equal(t, int32(2), resolver.lookups)
+
+	// missing types are not cached
+	_, exists = reg.Get("testing", "missing")
+	assert.False(t, exists)
+	_, exists = reg.GetWithHash(1, 2)
+	assert.False(t, exists)
+	assert.Equal(t, 2, reg.Size())
+
+	// copies of the registry use the resolver and share the resolved types
+	resolver.types = append(resolver.types, createTestMessageType())
+	copied := reg
+	_, exists = copied.Get("testing", "Message")
+	assert.True(t, exists)
+	assert.True(t, reg.ContainsName("testing", "Message"))
+}
+
+func TestRegistryCopiesConcurrent(t *testing.T) {
+	var types []TupleType
+	for i := 0; i < 50; i++ {
+		tupleType := New("testing", fmt.Sprintf("type%d", i))
+		tupleType.AddVersion(Field{Name: "value", Type: Uint8Field})
+		types = append(types, tupleType)
+	}
+
+	reg := NewRegistry()
+	reg.SetResolver(&mapResolver{types: types})
+
+	// copies resolve and register types while the original is read
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		copied := reg
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for _, tupleType := range types {
+				_, exists := copied.GetWithHash(tupleType.NamespaceHash, tupleType.Hash)
+				assert.True(t, exists)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for _, tupleType := range types {
+				_, exists := reg.Get(tupleType.Namespace, tupleType.Name)
+				assert.True(t, exists)
+				reg.Size()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, len(types), reg.Size())
+}
+
+func TestRegistryReplace(t *testing.T) {
+	reg := NewRegistry()
+	User := createTestTupleType()
+	assert.Nil(t, reg.Register(User))
+
+	// the registered type is replaced
+	Updated := New("testing", "user")
+	Updated.AddVersion(User.Versions()[0].Fields...)
+	assert.Nil(t, reg.Replace(User, Updated))
+	registered, exists := reg.Get("testing", "user")
+	assert.True(t, exists)
+	assert.Equal(t, 1, registered.NumVersions())
+	assert.Equal(t, 1, reg.Size())
+
+	// types which are not registered are added
+	A := New("testing", "type129599")
+	assert.Nil(t, reg.Replace(A, A))
+	assert.True(t, reg.ContainsName("testing", "type129599"))
+
+	// the old type is kept if the new type collides
+	B := New("testing", "type732382")
+	assert.Equal(t, CollisionError{B, A}, reg.Replace(Updated, B))
+	registered, exists = reg.Get("testing", "user")
+	assert.True(t, exists)
+	assert.Equal(t, 1, registered.NumVersions())
+	assert.Equal(t, 2, reg.Size())
+}
+
+func TestTypeID(t *testing.T) {
+
+	// the namespace and name are separated
+	assert.NotEqual(t, TypeID("ab", "c"), TypeID("a", "bc"))
+	assert.Equal(t, TypeID("a", "bc"), TypeID("a", "bc"))
+}
+
+func TestTupleTypeFingerprint(t *testing.T) {
+	User := createTestTupleType()
+
+	// the fingerprint only includes the given versions
+	Other := New("testing", "user")
+	Other.AddVersion(User.Versions()[0].Fields...)
+	assert.Equal(t, User.Fingerprint(1), Other.Fingerprint(1))
+	assert.NotEqual(t, User.Fingerprint(2), Other.Fingerprint(2))
+	assert.Equal(t, Other.Fingerprint(1), Other.Fingerprint(2))
+
+	// required flags are included
+	Optional := New("testing", "user")
+	Optional.AddVersion(
+		Field{Name: "uuid", Required: true, Type: StringField},
+		Field{Name: "username", Required: false, Type: StringField},
+		Field{Name: "age", Required: false, Type: Uint8Field},
+	)
+	assert.NotEqual(t, User.Fingerprint(1), Optional.Fingerprint(1))
+
+	// the namespace and name are included
+	Renamed := New("testing", "person")
+	Renamed.AddVersion(User.Versions()[0].Fields...)
+	assert.NotEqual(t, User.Fingerprint(1), Renamed.Fingerprint(1))
+}
+
+func TestTypeCodeString(t *testing.T) {
+	assert.Equal(t, "String8Code", String8Code.String())
+	assert.Equal(t, "TupleArray64Code", TupleArray64Code.String())
+	assert.Equal(t, "TypeCode(200)", TypeCode{200, 0}.String())
+
+	code, ok := LookupTypeCode(69)
+	assert.True(t, ok)
+	assert.Equal(t, String8Code, code)
+
+	_, ok = LookupTypeCode(200)
+	assert.False(t, ok)
+}
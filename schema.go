@@ -0,0 +1,700 @@
+package namedtuple
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidSchema is returned when a serialized tuple type can not be read, for example if the hashes do not match the names or a field type is unknown.
+var ErrInvalidSchema = errors.New("Invalid tuple type schema")
+
+// SchemaNamespace is the namespace of the tuple types used to serialize tuple types. Tuple types are stored as tuples themselves, so a schema can be read by any reader of tuples.
+const SchemaNamespace = "namedtuple"
+
+var (
+	// schemaEnumType stores an `Enum`. The names and values are stored in declaration order.
+	schemaEnumType = New(SchemaNamespace, "Enum")
+
+	// schemaConstraintsType stores the bounds of `Constraints` which are set.
+	schemaConstraintsType = New(SchemaNamespace, "Constraints")
+
+	// schemaFieldType stores a `Field`. Default values are stored as strings.
+	schemaFieldType = New(SchemaNamespace, "Field")
+
+	// schemaVersionType stores the fields of a version.
+	schemaVersionType = New(SchemaNamespace, "Version")
+
+	// schemaTupleType stores a `TupleType` along with its hashes.
+	schemaTupleType = New(SchemaNamespace, "TupleType")
+
+	// schemaFieldTypeEnum names the field types, so the JSON representation of a schema is readable.
+	schemaFieldTypeEnum *Enum
+
+	// schemaRegistry contains the tuple types used to serialize tuple types
+	schemaRegistry = NewRegistry()
+)
+
+func init() {
+	fieldTypes := make([]EnumValue, 0, len(fieldTypeNames))
+	for fieldType := Uint8Field; fieldType <= MapField; fieldType++ {
+		fieldTypes = append(fieldTypes, EnumValue{Name: fieldType.String(), Value: uint32(fieldType)})
+	}
+	schemaFieldTypeEnum = NewEnum(SchemaNamespace, "FieldType", false, fieldTypes...)
+
+	schemaEnumType.AddVersion(
+		Field{Name: "namespace", Required: true, Type: StringField},
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "open", Required: true, Type: BooleanField},
+		Field{Name: "names", Required: true, Type: StringArrayField},
+		Field{Name: "values", Required: true, Type: Uint32ArrayField},
+	)
+	schemaConstraintsType.AddVersion(
+		Field{Name: "min", Type: Float64Field},
+		Field{Name: "max", Type: Float64Field},
+		Field{Name: "min_time", Type: TimestampField},
+		Field{Name: "max_time", Type: TimestampField},
+		Field{Name: "min_length", Type: Int64Field},
+		Field{Name: "max_length", Type: Int64Field},
+		Field{Name: "pattern", Type: StringField},
+		Field{Name: "min_items", Type: Int64Field},
+		Field{Name: "max_items", Type: Int64Field},
+	)
+	schemaFieldType.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "type", Required: true, Type: EnumField, Enum: schemaFieldTypeEnum},
+		Field{Name: "required", Required: true, Type: BooleanField},
+		Field{Name: "tuple_namespace", Type: StringField},
+		Field{Name: "tuple_name", Type: StringField},
+		Field{Name: "key_type", Type: EnumField, Enum: schemaFieldTypeEnum},
+		Field{Name: "value_type", Type: EnumField, Enum: schemaFieldTypeEnum},
+		Field{Name: "oneof", Type: StringField},
+		Field{Name: "encoding", Type: Uint8Field},
+		Field{Name: "default", Type: StringField},
+		Field{Name: "enum", Type: TupleField, TupleNamespace: SchemaNamespace, TupleName: "Enum"},
+		Field{Name: "constraints", Type: TupleField, TupleNamespace: SchemaNamespace, TupleName: "Constraints"},
+	)
+	schemaVersionType.AddVersion(
+		Field{Name: "fields", Required: true, Type: TupleArrayField, TupleNamespace: SchemaNamespace, TupleName: "Field"},
+	)
+	schemaTupleType.AddVersion(
+		Field{Name: "namespace", Required: true, Type: StringField},
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "namespace_hash", Required: true, Type: Uint32Field},
+		Field{Name: "hash", Required: true, Type: Uint32Field},
+		Field{Name: "versions", Required: true, Type: TupleArrayField, TupleNamespace: SchemaNamespace, TupleName: "Version"},
+	)
+
+	for _, t := range []TupleType{schemaEnumType, schemaConstraintsType, schemaFieldType, schemaVersionType, schemaTupleType} {
+		schemaRegistry.Register(t)
+	}
+}
+
+// Types returns the registered tuple types sorted by namespace and name.
+func (r *Registry) Types() []TupleType {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	types := make([]TupleType, 0, len(r.content))
+	for _, t := range r.content {
+		types = append(types, t)
+	}
+	sort.Sort(byTypeName(types))
+	return types
+}
+
+// byTypeName sorts tuple types by namespace and name
+type byTypeName []TupleType
+
+func (b byTypeName) Len() int      { return len(b) }
+func (b byTypeName) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byTypeName) Less(i, j int) bool {
+	if b[i].Namespace != b[j].Namespace {
+		return b[i].Namespace < b[j].Namespace
+	}
+	return b[i].Name < b[j].Name
+}
+
+// encodeType stores the tuple type as a tuple of the `namedtuple.TupleType` schema type.
+func encodeType(t TupleType) (Tuple, error) {
+	versions := make([]Tuple, len(t.versions))
+	for i, version := range t.versions {
+		fields := make([]Tuple, len(version))
+		for j, field := range version {
+			var err error
+			if fields[j], err = encodeField(field); err != nil {
+				return NIL, err
+			}
+		}
+
+		b := NewGrowableBuilder(schemaVersionType, nil, 0)
+		if _, err := b.PutTupleArray("fields", fields); err != nil {
+			return NIL, err
+		}
+
+		var err error
+		if versions[i], err = b.Build(); err != nil {
+			return NIL, err
+		}
+	}
+
+	b := NewGrowableBuilder(schemaTupleType, nil, 0)
+	if _, err := b.PutString("namespace", t.Namespace); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutString("name", t.Name); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutUint32("namespace_hash", t.NamespaceHash); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutUint32("hash", t.Hash); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutTupleArray("versions", versions); err != nil {
+		return NIL, err
+	}
+	return b.Build()
+}
+
+// encodeField stores the field as a tuple of the `namedtuple.Field` schema type. Optional attributes which are not set are not written.
+func encodeField(field Field) (Tuple, error) {
+	b := NewGrowableBuilder(schemaFieldType, nil, 0)
+	if _, err := b.PutString("name", field.Name); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutEnumValue("type", uint32(field.Type)); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutBool("required", field.Required); err != nil {
+		return NIL, err
+	}
+
+	// attributes are written in a fixed order so the encoding is stable
+	names := []string{"tuple_namespace", "tuple_name", "oneof"}
+	for i, value := range []string{field.TupleNamespace, field.TupleName, field.OneOf} {
+		if value == "" {
+			continue
+		}
+		if _, err := b.PutString(names[i], value); err != nil {
+			return NIL, err
+		}
+	}
+
+	if field.Type == MapField {
+		if _, err := b.PutEnumValue("key_type", uint32(field.KeyType)); err != nil {
+			return NIL, err
+		}
+		if _, err := b.PutEnumValue("value_type", uint32(field.ValueType)); err != nil {
+			return NIL, err
+		}
+	}
+
+	if field.Encoding != DefaultIntEncoding {
+		if _, err := b.PutUint8("encoding", uint8(field.Encoding)); err != nil {
+			return NIL, err
+		}
+	}
+
+	if field.Default != nil {
+		value, err := formatDefault(field.Default)
+		if err != nil {
+			return NIL, err
+		}
+		if _, err := b.PutString("default", value); err != nil {
+			return NIL, err
+		}
+	}
+
+	if field.Enum != nil {
+		enum, err := encodeEnum(field.Enum)
+		if err != nil {
+			return NIL, err
+		}
+		if _, err := b.PutTuple("enum", enum); err != nil {
+			return NIL, err
+		}
+	}
+
+	if !field.Constraints.IsZero() {
+		constraints, err := encodeConstraints(field.Constraints)
+		if err != nil {
+			return NIL, err
+		}
+		if _, err := b.PutTuple("constraints", constraints); err != nil {
+			return NIL, err
+		}
+	}
+	return b.Build()
+}
+
+// encodeEnum stores the enum as a tuple of the `namedtuple.Enum` schema type.
+func encodeEnum(e *Enum) (Tuple, error) {
+	names := make([]string, len(e.values))
+	values := make([]uint32, len(e.values))
+	for i, v := range e.values {
+		names[i] = v.Name
+		values[i] = v.Value
+	}
+
+	b := NewGrowableBuilder(schemaEnumType, nil, 0)
+	if _, err := b.PutString("namespace", e.Namespace); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutString("name", e.Name); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutBool("open", e.Open); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutStringArray("names", names); err != nil {
+		return NIL, err
+	}
+	if _, err := b.PutUint32Array("values", values); err != nil {
+		return NIL, err
+	}
+	return b.Build()
+}
+
+// encodeConstraints stores the constraints as a tuple of the `namedtuple.Constraints` schema type. Bounds which are nil are not written.
+func encodeConstraints(c Constraints) (Tuple, error) {
+	b := NewGrowableBuilder(schemaConstraintsType, nil, 0)
+
+	// bounds are written in a fixed order so the encoding is stable
+	floats := []string{"min", "max"}
+	for i, value := range []*float64{c.Min, c.Max} {
+		if value == nil {
+			continue
+		}
+		if _, err := b.PutFloat64(floats[i], *value); err != nil {
+			return NIL, err
+		}
+	}
+
+	times := []string{"min_time", "max_time"}
+	for i, value := range []*time.Time{c.MinTime, c.MaxTime} {
+		if value == nil {
+			continue
+		}
+		if _, err := b.PutTimestamp(times[i], *value); err != nil {
+			return NIL, err
+		}
+	}
+
+	ints := []string{"min_length", "max_length", "min_items", "max_items"}
+	for i, value := range []*int{c.MinLength, c.MaxLength, c.MinItems, c.MaxItems} {
+		if value == nil {
+			continue
+		}
+		if _, err := b.PutInt64(ints[i], int64(*value)); err != nil {
+			return NIL, err
+		}
+	}
+
+	if c.Pattern != nil {
+		if _, err := b.PutString("pattern", c.Pattern.String()); err != nil {
+			return NIL, err
+		}
+	}
+	return b.Build()
+}
+
+// formatDefault formats a default value as a string. Timestamps are formatted as RFC3339 strings with nanoseconds.
+func formatDefault(value interface{}) (string, error) {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339Nano), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.String:
+		return rv.String(), nil
+	}
+	return "", ErrUnsupportedType
+}
+
+// parseDefault parses a default value formatted with formatDefault into the Go type returned by the getter of the field type. The defaults of enum fields are names.
+func parseDefault(fieldType FieldType, value string) (interface{}, error) {
+	if fieldType == EnumField {
+		return value, nil
+	}
+
+	element, ok := mapElements[fieldType]
+	if !ok {
+		return nil, ErrInvalidSchema
+	}
+
+	v := reflect.New(element.Type).Elem()
+	var err error
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(value, 10, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(value, 10, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(value, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.String:
+		v.SetString(value)
+	default:
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, value); err == nil {
+			v.Set(reflect.ValueOf(t))
+		}
+	}
+
+	if err != nil {
+		return nil, ErrInvalidSchema
+	}
+	return v.Interface(), nil
+}
+
+// decodeType reads a tuple type stored with encodeType. `ErrInvalidSchema` is returned if the tuple is not a `namedtuple.TupleType` or the stored hashes do not match the namespace and name.
+func decodeType(t Tuple) (TupleType, error) {
+	if !t.Is(schemaTupleType) {
+		return TupleType{}, ErrInvalidSchema
+	}
+
+	namespace, err := t.GetString("namespace")
+	if err != nil {
+		return TupleType{}, err
+	}
+	name, err := t.GetString("name")
+	if err != nil {
+		return TupleType{}, err
+	}
+
+	tupleType := New(namespace, name)
+	namespaceHash, err := t.GetUint32("namespace_hash")
+	if err != nil {
+		return TupleType{}, err
+	}
+	hash, err := t.GetUint32("hash")
+	if err != nil {
+		return TupleType{}, err
+	}
+	if namespaceHash != tupleType.NamespaceHash || hash != tupleType.Hash {
+		return TupleType{}, ErrInvalidSchema
+	}
+
+	versions, err := t.GetTupleArray("versions", &schemaRegistry)
+	if err != nil {
+		return TupleType{}, err
+	}
+	for versions.Next() {
+		version := versions.Tuple()
+		it, err := version.GetTupleArray("fields", &schemaRegistry)
+		if err != nil {
+			return TupleType{}, err
+		}
+
+		fields := make([]Field, 0, it.Len())
+		for it.Next() {
+			field, err := decodeField(it.Tuple())
+			if err != nil {
+				return TupleType{}, err
+			}
+			fields = append(fields, field)
+		}
+		if it.Err() != nil {
+			return TupleType{}, it.Err()
+		}
+		tupleType.AddVersion(fields...)
+	}
+	return tupleType, versions.Err()
+}
+
+// decodeField reads a field stored with encodeField.
+func decodeField(t Tuple) (field Field, err error) {
+	if field.Name, err = t.GetString("name"); err != nil {
+		return
+	}
+
+	fieldType, err := t.GetEnumValue("type")
+	if err != nil {
+		return
+	} else if _, ok := fieldTypeNames[FieldType(fieldType)]; !ok {
+		return field, ErrInvalidSchema
+	}
+	field.Type = FieldType(fieldType)
+
+	if field.Required, err = t.GetBool("required"); err != nil {
+		return
+	}
+
+	strings := map[string]*string{"tuple_namespace": &field.TupleNamespace, "tuple_name": &field.TupleName, "oneof": &field.OneOf}
+	for name, value := range strings {
+		if *value, err = t.GetString(name); err != nil && err != ErrFieldNotPresent {
+			return
+		}
+	}
+
+	if field.Type == MapField {
+		var keyType, valueType uint32
+		if keyType, err = t.GetEnumValue("key_type"); err != nil {
+			return
+		}
+		if valueType, err = t.GetEnumValue("value_type"); err != nil {
+			return
+		}
+		field.KeyType = FieldType(keyType)
+		field.ValueType = FieldType(valueType)
+		if !ValidMapTypes(field.KeyType, field.ValueType) {
+			return field, ErrInvalidSchema
+		}
+	}
+
+	encoding, err := t.GetUint8("encoding")
+	if err != nil && err != ErrFieldNotPresent {
+		return
+	}
+	field.Encoding = IntEncoding(encoding)
+
+	value, err := t.GetString("default")
+	if err == nil {
+		if field.Default, err = parseDefault(field.Type, value); err != nil {
+			return
+		}
+	} else if err != ErrFieldNotPresent {
+		return
+	}
+
+	enum, err := t.GetTuple("enum", &schemaRegistry)
+	if err == nil {
+		if field.Enum, err = decodeEnum(enum); err != nil {
+			return
+		}
+	} else if err != ErrFieldNotPresent {
+		return
+	}
+
+	constraints, err := t.GetTuple("constraints", &schemaRegistry)
+	if err == nil {
+		if field.Constraints, err = decodeConstraints(constraints); err != nil {
+			return
+		}
+	} else if err != ErrFieldNotPresent {
+		return
+	}
+	return field, nil
+}
+
+// decodeEnum reads an enum stored with encodeEnum.
+func decodeEnum(t Tuple) (*Enum, error) {
+	namespace, err := t.GetString("namespace")
+	if err != nil {
+		return nil, err
+	}
+	name, err := t.GetString("name")
+	if err != nil {
+		return nil, err
+	}
+	open, err := t.GetBool("open")
+	if err != nil {
+		return nil, err
+	}
+	names, err := t.GetStringArray("names")
+	if err != nil {
+		return nil, err
+	}
+	numbers, err := t.GetUint32Array("values")
+	if err != nil {
+		return nil, err
+	} else if len(names) != len(numbers) {
+		return nil, ErrInvalidSchema
+	}
+
+	values := make([]EnumValue, len(names))
+	for i := range names {
+		values[i] = EnumValue{names[i], numbers[i]}
+	}
+	return NewEnum(namespace, name, open, values...), nil
+}
+
+// decodeConstraints reads the constraints stored with encodeConstraints.
+func decodeConstraints(t Tuple) (c Constraints, err error) {
+	floats := map[string]**float64{"min": &c.Min, "max": &c.Max}
+	for name, bound := range floats {
+		var value float64
+		if value, err = t.GetFloat64(name); err == nil {
+			*bound = &value
+		} else if err != ErrFieldNotPresent {
+			return
+		}
+	}
+
+	times := map[string]**time.Time{"min_time": &c.MinTime, "max_time": &c.MaxTime}
+	for name, bound := range times {
+		var value time.Time
+		if value, err = t.GetTimestamp(name); err == nil {
+			*bound = &value
+		} else if err != ErrFieldNotPresent {
+			return
+		}
+	}
+
+	ints := map[string]**int{"min_length": &c.MinLength, "max_length": &c.MaxLength, "min_items": &c.MinItems, "max_items": &c.MaxItems}
+	for name, bound := range ints {
+		var value int64
+		if value, err = t.GetInt64(name); err == nil {
+			n := int(value)
+			*bound = &n
+		} else if err != ErrFieldNotPresent {
+			return
+		}
+	}
+
+	pattern, err := t.GetString("pattern")
+	if err == nil {
+		if c.Pattern, err = regexp.Compile(pattern); err != nil {
+			return c, ErrInvalidSchema
+		}
+	} else if err != ErrFieldNotPresent {
+		return
+	}
+	return c, nil
+}
+
+// writeRegistry writes every tuple type of the registry as a separate tuple. The tuples are encoded with protocol version 2, so each type is protected by a checksum.
+func writeRegistry(w io.Writer, reg *Registry) error {
+	encoder, err := NewEncoderVersion(w, ProtocolVersionTwo)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range reg.Types() {
+		schema, err := encodeType(t)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRegistry reads the tuple types written with writeRegistry into a new registry.
+func readRegistry(data []byte) (*Registry, error) {
+	reg := NewRegistry()
+	for len(data) > 0 {
+		var schema Tuple
+		var err error
+		if schema, data, err = DecodeBytes(&schemaRegistry, data); err != nil {
+			return nil, err
+		}
+
+		t, err := decodeType(schema)
+		if err != nil {
+			return nil, err
+		}
+		if err := reg.Register(t); err != nil {
+			return nil, err
+		}
+	}
+	return &reg, nil
+}
+
+// WriteTo writes a snapshot of the registered tuple types, so services can load the registry with `ReadRegistry` instead of building it in code. Each tuple type is written as a tuple of the `namedtuple.TupleType` schema type with its namespace, name, hashes and versions, including the fields with their types, required flags, defaults, enums and constraints. The types are sorted by namespace and name and the attributes are written in a fixed order, so the same registry is always written the same way. The tuples are encoded with protocol version 2, so each type is protected by a checksum. The number of bytes written is returned.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	out := &offsetWriter{w: w}
+	err := writeRegistry(out, r)
+	return out.offset, err
+}
+
+// ReadRegistry reads a snapshot written with `Registry.WriteTo` into a new registry. `ErrInvalidSchema` is returned if a stored hash does not match the namespace and name of a type.
+func ReadRegistry(r io.Reader) (*Registry, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return readRegistry(data)
+}
+
+// WriteJSON writes the registered tuple types as a JSON array. Each type is written as a JSON object, see `TupleType.MarshalJSON`. The output is indented and stable the same way as `WriteTo`.
+func (r *Registry) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r.Types(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadRegistryJSON reads the tuple types written with `Registry.WriteJSON` into a new registry. The hashes of each type are verified the same way as `ReadRegistry`.
+func ReadRegistryJSON(r io.Reader) (*Registry, error) {
+	var types []TupleType
+	if err := json.NewDecoder(r).Decode(&types); err != nil {
+		return nil, err
+	}
+
+	reg := NewRegistry()
+	for _, t := range types {
+		if err := reg.Register(t); err != nil {
+			return nil, err
+		}
+	}
+	return &reg, nil
+}
+
+// MarshalJSON writes the tuple type as a JSON object with the same attributes as the tuples written by `Registry.WriteTo`. Field types are written as the names of the field type constants and defaults as strings.
+func (t TupleType) MarshalJSON() ([]byte, error) {
+	schema, err := encodeType(t)
+	if err != nil {
+		return nil, err
+	}
+	return ToJSONWithRegistry(schema, &schemaRegistry)
+}
+
+// UnmarshalJSON reads a tuple type written with `MarshalJSON`. `ErrInvalidSchema` is returned if the hashes do not match the namespace and name.
+func (t *TupleType) UnmarshalJSON(data []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+
+	builder := NewGrowableBuilder(schemaTupleType, nil, 0)
+	if err := builder.putJSON(object, &schemaRegistry); err != nil {
+		return err
+	}
+	schema, err := builder.Build()
+	if err != nil {
+		return err
+	}
+
+	tupleType, err := decodeType(schema)
+	if err != nil {
+		return err
+	}
+	*t = tupleType
+	return nil
+}
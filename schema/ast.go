@@ -1,12 +1,16 @@
 package schema
 
-import "sync"
+import (
+    "sort"
+    "sync"
+)
 
 // PackageList is an interface for a package registry.
 type PackageList interface {
     Add(pkg Package)
     Remove(pkg string)
     Get(name string) (Package, bool)
+    List() []Package
 }
 
 // packageList contains a registry of known packages
@@ -36,6 +40,25 @@ func (p *packageList) Get(name string) (pkg Package, ok bool) {
     return
 }
 
+func (p *packageList) List() (pkgs []Package) {
+    p.lock.Lock()
+    for _, pkg := range p.pkgList {
+        pkgs = append(pkgs, pkg)
+    }
+    p.lock.Unlock()
+
+    // sort by name so the order is stable
+    sort.Sort(byName(pkgs))
+    return
+}
+
+// byName sorts packages by name
+type byName []Package
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
 // NewPackageList creates a new package registry
 func NewPackageList() PackageList {
     var lock sync.Mutex
@@ -45,8 +68,10 @@ func NewPackageList() PackageList {
 // Package contains an entire schema document.
 type Package struct {
     Name    string
+    File    string
     Imports []Import
     Types   []Type
+    Enums   []Enum
 }
 
 // Import references one or more Types from another Package
@@ -55,16 +80,41 @@ type Import struct {
     TypeNames   []string
 }
 
+// Enum declares symbolic names for the values of a field. Open enums accept values which are not declared.
+type Enum struct {
+    Name   string
+    Line   int
+    IsOpen bool
+    Values []EnumValue
+}
+
+// EnumValue is a single name and value of an Enum.
+type EnumValue struct {
+    Name  string
+    Value uint32
+    Line  int
+}
+
 // Type represents a data type. It encapsulates several versions, each with their own fields.
 type Type struct {
     Name     string
+    Line     int
     Versions []Version
 }
 
 // Version is the only construct for adding one or more Fields to a Type.
 type Version struct {
     Number int
+    Line   int
     Fields []Field
+    OneOfs []OneOf
+}
+
+// OneOf is a group of optional Fields of which at most one can be written. The fields of the group are included in the Fields of the Version in declaration order.
+type OneOf struct {
+    Name   string
+    Line   int
+    Fields []string
 }
 
 // Field is the lowest level of granularity in a schema. Fields belong to a single Version within a Type. They are effectively immutable and should not be changed.
@@ -73,4 +123,38 @@ type Field struct {
     IsArray    bool
     Type       string
     Name       string
+    Line       int
+
+    // KeyType and ValueType are the types of the keys and values of a
+    // map field, such as `map<string, uint32>`. Both are empty for
+    // other fields.
+    KeyType   string
+    ValueType string
+
+    // Constraints restrict the values of the field. They are checked
+    // when a tuple is built.
+    Constraints []Constraint
+
+    // Default is returned for optional fields which were not written.
+    // It is nil if the field does not have a default value.
+    Default *Literal
+
+    // OneOf is the name of the group the field belongs to. It is
+    // empty if the field does not belong to a group.
+    OneOf string
+}
+
+// Constraint restricts the values of a Field, such as `min 0` or `pattern "^[a-z]+$"`. The value is either a number or an unquoted string.
+type Constraint struct {
+    Name     string
+    Value    string
+    IsString bool
+    Line     int
+}
+
+// Literal is a value in a schema, such as the default value of a Field. The value is either a number, a boolean or an unquoted string.
+type Literal struct {
+    Value    string
+    IsString bool
+    Line     int
 }
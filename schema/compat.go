@@ -0,0 +1,276 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// Incompatibility is a breaking change between two revisions of a package. It includes the file and line number of the offending declaration in the new revision, or in the old revision if the declaration was removed.
+type Incompatibility struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (i Incompatibility) String() string {
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+}
+
+// CheckCompatibility compares two revisions of a package and returns the breaking changes. Tuples written with the old revision must be readable with the new revision and the other way around, so the versions of a type are append-only. Removing, retyping or reordering fields, moving fields between oneof groups, adding required fields to existing versions, adding any field to a version which is followed by other versions and removing or renumbering versions are all reported. Renaming a package or a type changes the hashes in the tuple header and is reported as well. New types and new versions are allowed.
+func CheckCompatibility(oldPkg, newPkg Package) []Incompatibility {
+	c := checker{old: oldPkg, new: newPkg}
+
+	// the package name is hashed as the namespace of every type
+	if oldPkg.Name != newPkg.Name {
+		c.reportf(newPkg.File, 1, "package '%s' was renamed to '%s' which changes the namespace hash from 0x%08x to 0x%08x",
+			oldPkg.Name, newPkg.Name, namedtuple.New(oldPkg.Name, "").NamespaceHash, namedtuple.New(newPkg.Name, "").NamespaceHash)
+	}
+
+	// compare the types with the same name
+	var removed, added []Type
+	for _, oldType := range oldPkg.Types {
+		if newType, ok := findType(newPkg, oldType.Name); ok {
+			c.checkType(oldType, newType)
+		} else {
+			removed = append(removed, oldType)
+		}
+	}
+	for _, newType := range newPkg.Types {
+		if _, ok := findType(oldPkg, newType.Name); !ok {
+			added = append(added, newType)
+		}
+	}
+
+	// a removed type with the same versions as an added type was renamed
+	for _, oldType := range removed {
+		renamed := false
+		for _, newType := range added {
+			if sameVersions(oldType, newType) {
+				c.reportf(newPkg.File, newType.Line, "type '%s' was renamed to '%s' which changes the type hash from 0x%08x to 0x%08x",
+					oldType.Name, newType.Name, namedtuple.New(oldPkg.Name, oldType.Name).Hash, namedtuple.New(newPkg.Name, newType.Name).Hash)
+				renamed = true
+				break
+			}
+		}
+		if !renamed {
+			c.reportf(oldPkg.File, oldType.Line, "type '%s' was removed", oldType.Name)
+		}
+	}
+
+	sort.Stable(byLine(c.changes))
+	return c.changes
+}
+
+// CheckAllCompatibility compares two revisions of a package list. Packages are matched by name and packages which were removed from the new revision are reported.
+func CheckAllCompatibility(oldList, newList PackageList) []Incompatibility {
+	var changes []Incompatibility
+	for _, oldPkg := range oldList.List() {
+		newPkg, ok := newList.Get(oldPkg.Name)
+		if !ok {
+			changes = append(changes, Incompatibility{oldPkg.File, 1, fmt.Sprintf("package '%s' was removed", oldPkg.Name)})
+			continue
+		}
+		changes = append(changes, CheckCompatibility(oldPkg, newPkg)...)
+	}
+	return changes
+}
+
+// checker collects the incompatibilities between two revisions of a package
+type checker struct {
+	old     Package
+	new     Package
+	changes []Incompatibility
+}
+
+func (c *checker) reportf(file string, line int, format string, args ...interface{}) {
+	c.changes = append(c.changes, Incompatibility{file, line, fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) checkType(oldType, newType Type) {
+	for i, oldVersion := range oldType.Versions {
+
+		// versions can not be removed
+		if i >= len(newType.Versions) {
+			c.reportf(c.old.File, oldVersion.Line, "version %d of type '%s' was removed", oldVersion.Number, oldType.Name)
+			continue
+		}
+
+		// versions are positional so the number can not change
+		newVersion := newType.Versions[i]
+		if oldVersion.Number != newVersion.Number {
+			c.reportf(c.new.File, newVersion.Line, "version %d of type '%s' was renumbered to %d", oldVersion.Number, oldType.Name, newVersion.Number)
+		}
+
+		// the fields of the version were moved to a different position
+		if !sameFields(oldVersion, newVersion) {
+			if j := findVersion(newType, oldVersion); j >= 0 {
+				c.reportf(c.new.File, newType.Versions[j].Line, "version %d of type '%s' was moved to position %d", oldVersion.Number, oldType.Name, j+1)
+				continue
+			}
+		}
+		c.checkVersion(oldType, newType, oldVersion, newVersion, i < len(newType.Versions)-1)
+	}
+}
+
+// checkVersion compares the fields of a version. If the version is followed by other versions, the offsets of their fields depend on the number of fields in the version, so no fields can be added.
+func (c *checker) checkVersion(oldType, newType Type, oldVersion, newVersion Version, followed bool) {
+	for i, oldField := range oldVersion.Fields {
+		j, newField, ok := findField(newVersion, oldField.Name)
+		if !ok {
+
+			// the field was moved into another version
+			if version, _, ok := findFieldInType(newType, oldField.Name); ok {
+				c.reportf(c.new.File, version.Line, "field '%s' of type '%s' was moved from version %d to version %d", oldField.Name, oldType.Name, oldVersion.Number, version.Number)
+			} else {
+				c.reportf(c.old.File, oldField.Line, "field '%s' was removed from version %d of type '%s'", oldField.Name, oldVersion.Number, oldType.Name)
+			}
+			continue
+		}
+
+		// field offsets are positional
+		if i != j {
+			c.reportf(c.new.File, newField.Line, "field '%s' of type '%s' was moved from position %d to %d", oldField.Name, oldType.Name, i+1, j+1)
+		}
+
+		if !sameType(oldField, newField) {
+			c.reportf(c.new.File, newField.Line, "field '%s' of type '%s' was changed from '%s' to '%s'", oldField.Name, oldType.Name, typeName(oldField), typeName(newField))
+		}
+
+		if oldField.OneOf != newField.OneOf {
+			c.reportf(c.new.File, newField.Line, "field '%s' of type '%s' was moved from %s to %s", oldField.Name, oldType.Name, groupName(oldField), groupName(newField))
+		}
+
+		if oldField.IsRequired != newField.IsRequired {
+			if newField.IsRequired {
+				c.reportf(c.new.File, newField.Line, "field '%s' of type '%s' was changed from optional to required", oldField.Name, oldType.Name)
+			} else {
+				c.reportf(c.new.File, newField.Line, "field '%s' of type '%s' was changed from required to optional", oldField.Name, oldType.Name)
+			}
+		}
+	}
+
+	// new required fields can not be added to existing versions and optional fields only to the last version
+	for _, newField := range newVersion.Fields {
+		if _, _, ok := findField(oldVersion, newField.Name); ok {
+			continue
+		} else if newField.IsRequired {
+			c.reportf(c.new.File, newField.Line, "required field '%s' was added to existing version %d of type '%s'", newField.Name, newVersion.Number, newType.Name)
+		} else if followed {
+			c.reportf(c.new.File, newField.Line, "optional field '%s' was added to version %d of type '%s' which is not the last version", newField.Name, newVersion.Number, newType.Name)
+		}
+	}
+}
+
+// findType returns the type with the given name
+func findType(pkg Package, name string) (Type, bool) {
+	for _, typ := range pkg.Types {
+		if typ.Name == name {
+			return typ, true
+		}
+	}
+	return Type{}, false
+}
+
+// findVersion returns the position of a version with the same fields in the type or -1 if there is none
+func findVersion(typ Type, version Version) int {
+	for i, v := range typ.Versions {
+		if sameFields(v, version) {
+			return i
+		}
+	}
+	return -1
+}
+
+// findField returns the position of the field with the given name in the version
+func findField(version Version, name string) (int, Field, bool) {
+	for i, field := range version.Fields {
+		if field.Name == name {
+			return i, field, true
+		}
+	}
+	return -1, Field{}, false
+}
+
+// findFieldInType returns the version containing the field with the given name
+func findFieldInType(typ Type, name string) (Version, Field, bool) {
+	for _, version := range typ.Versions {
+		if _, field, ok := findField(version, name); ok {
+			return version, field, true
+		}
+	}
+	return Version{}, Field{}, false
+}
+
+// sameVersions returns true if both types have the same versions
+func sameVersions(a, b Type) bool {
+	if len(a.Versions) != len(b.Versions) {
+		return false
+	}
+	for i := range a.Versions {
+		if a.Versions[i].Number != b.Versions[i].Number || !sameFields(a.Versions[i], b.Versions[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameFields returns true if both versions have the same fields in the same order
+func sameFields(a, b Version) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].IsRequired != b.Fields[i].IsRequired || a.Fields[i].OneOf != b.Fields[i].OneOf || !sameType(a.Fields[i], b.Fields[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameType returns true if both fields are compiled into the same field type. Aliases such as 'int' and 'int64' are the same type. Maps are the same type if their keys and values are.
+func sameType(a, b Field) bool {
+	if a.IsArray != b.IsArray {
+		return false
+	}
+	if a.Type == "map" || b.Type == "map" {
+		return a.Type == b.Type && sameType(Field{Type: a.KeyType}, Field{Type: b.KeyType}) && sameType(Field{Type: a.ValueType}, Field{Type: b.ValueType})
+	}
+	aTypes, aReserved := fieldTypes[a.Type]
+	bTypes, bReserved := fieldTypes[b.Type]
+	if aReserved && bReserved {
+		return aTypes == bTypes
+	}
+	return a.Type == b.Type
+}
+
+// typeName returns the type of the field as it is declared in the schema
+func typeName(field Field) string {
+	if field.KeyType != "" {
+		return field.Type + "<" + field.KeyType + ", " + field.ValueType + ">"
+	} else if field.IsArray {
+		return "[]" + field.Type
+	}
+	return field.Type
+}
+
+// groupName describes the oneof group of the field
+func groupName(field Field) string {
+	if field.OneOf == "" {
+		return "no oneof"
+	}
+	return "oneof '" + field.OneOf + "'"
+}
+
+// byLine sorts incompatibilities by file and line number
+type byLine []Incompatibility
+
+func (b byLine) Len() int      { return len(b) }
+func (b byLine) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byLine) Less(i, j int) bool {
+	if b[i].File != b[j].File {
+		return b[i].File < b[j].File
+	}
+	return b[i].Line < b[j].Line
+}
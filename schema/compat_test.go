@@ -0,0 +1,344 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const compatUsers = `
+package users
+
+type User {
+    version 1 {
+        required string uuid, username
+        optional uint8 age
+    }
+
+    version 2 {
+        optional []string emails
+    }
+}
+
+type Group {
+    version 1 {
+        required string name
+    }
+}
+`
+
+func parseCompatPackage(t *testing.T, file, text string) Package {
+	pkg, err := NewParser(NewPackageList()).Parse(file, text)
+	assert.Nil(t, err)
+	return pkg
+}
+
+func messages(changes []Incompatibility) []string {
+	var msgs []string
+	for _, change := range changes {
+		msgs = append(msgs, change.Message)
+	}
+	return msgs
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	old := parseCompatPackage(t, "old.ent", compatUsers)
+
+	// adding types, versions and optional fields is allowed
+	pkg := parseCompatPackage(t, "new.ent", `
+    package users
+
+    type User {
+        version 1 {
+            required string uuid, username
+            optional byte age
+        }
+
+        version 2 {
+            optional []string emails
+        }
+
+        version 3 {
+            required timestamp created
+            optional int64 score
+        }
+    }
+
+    type Group {
+        version 1 {
+            required string name
+            optional string description
+        }
+    }
+
+    type Role {
+        version 1 {
+            required string name
+        }
+    }
+    `)
+	assert.Empty(t, CheckCompatibility(old, pkg))
+}
+
+func TestCheckCompatibilityFields(t *testing.T) {
+	old := parseCompatPackage(t, "old.ent", compatUsers)
+	pkg := parseCompatPackage(t, "new.ent", `
+    package users
+
+    type User {
+        version 1 {
+            required string username, uuid
+            required string email
+        }
+
+        version 2 {
+            optional string emails
+            required uint8 age
+        }
+    }
+
+    type Group {
+        version 1 {
+            optional string name
+        }
+    }
+    `)
+
+	assert.Equal(t, []string{
+		"field 'uuid' of type 'User' was moved from position 1 to 2",
+		"field 'username' of type 'User' was moved from position 2 to 1",
+		"required field 'email' was added to existing version 1 of type 'User'",
+		"field 'age' of type 'User' was moved from version 1 to version 2",
+		"field 'emails' of type 'User' was changed from '[]string' to 'string'",
+		"required field 'age' was added to existing version 2 of type 'User'",
+		"field 'name' of type 'Group' was changed from required to optional",
+	}, messages(CheckCompatibility(old, pkg)))
+}
+
+func TestCheckCompatibilityFieldCount(t *testing.T) {
+	old := parseCompatPackage(t, "old.ent", compatUsers)
+
+	// the offsets of the fields in later versions would move
+	pkg := parseCompatPackage(t, "new.ent", `
+    package users
+
+    type User {
+        version 1 {
+            required string uuid, username
+            optional uint8 age
+            optional int64 score
+        }
+
+        version 2 {
+            optional []string emails
+        }
+    }
+
+    type Group {
+        version 1 {
+            required string name
+        }
+
+        version 2 {
+            optional string description
+        }
+    }
+    `)
+
+	assert.Equal(t, []string{
+		"optional field 'score' was added to version 1 of type 'User' which is not the last version",
+	}, messages(CheckCompatibility(old, pkg)))
+
+	// the last version can not be extended if a version is added after it
+	pkg = parseCompatPackage(t, "new.ent", `
+    package users
+
+    type User {
+        version 1 {
+            required string uuid, username
+            optional uint8 age
+        }
+
+        version 2 {
+            optional []string emails
+            optional int64 score
+        }
+
+        version 3 {
+            optional string nickname
+        }
+    }
+
+    type Group {
+        version 1 {
+            required string name
+        }
+    }
+    `)
+
+	assert.Equal(t, []string{
+		"optional field 'score' was added to version 2 of type 'User' which is not the last version",
+	}, messages(CheckCompatibility(old, pkg)))
+}
+
+func TestCheckCompatibilityMaps(t *testing.T) {
+	old := parseCompatPackage(t, "old.ent", `
+    package users
+
+    type User {
+        version 1 {
+            optional map<string, uint32> counts
+            optional map<string, string> labels
+        }
+    }
+    `)
+	pkg := parseCompatPackage(t, "new.ent", `
+    package users
+
+    type User {
+        version 1 {
+            optional map<string, uint64> counts
+            optional map<string, string> labels
+        }
+    }
+    `)
+
+	assert.Equal(t, []string{
+		"field 'counts' of type 'User' was changed from 'map<string, uint32>' to 'map<string, uint64>'",
+	}, messages(CheckCompatibility(old, pkg)))
+}
+
+func TestCheckCompatibilityOneOf(t *testing.T) {
+	old := parseCompatPackage(t, "old.ent", `
+    package places
+
+    type Place {
+        version 1 {
+            oneof location {
+                optional string street
+                optional string code
+            }
+            optional string name
+        }
+    }
+    `)
+	pkg := parseCompatPackage(t, "new.ent", `
+    package places
+
+    type Place {
+        version 1 {
+            oneof location {
+                optional string street
+            }
+            optional string code
+            oneof label {
+                optional string name
+            }
+        }
+    }
+    `)
+
+	assert.Equal(t, []string{
+		"field 'code' of type 'Place' was moved from oneof 'location' to no oneof",
+		"field 'name' of type 'Place' was moved from no oneof to oneof 'label'",
+	}, messages(CheckCompatibility(old, pkg)))
+}
+
+func TestCheckCompatibilityVersions(t *testing.T) {
+	old := parseCompatPackage(t, "old.ent", compatUsers)
+	pkg := parseCompatPackage(t, "new.ent", `
+    package users
+
+    type User {
+        version 1 {
+            optional []string emails
+        }
+
+        version 2 {
+            required string uuid, username
+            optional uint8 age
+        }
+    }
+
+    type Group {
+    }
+    `)
+
+	changes := CheckCompatibility(old, pkg)
+	assert.Equal(t, []string{
+		"version 2 of type 'User' was moved to position 1",
+		"version 1 of type 'User' was moved to position 2",
+		"version 1 of type 'Group' was removed",
+	}, messages(changes))
+	assert.Equal(t, Incompatibility{"old.ent", 16, "version 1 of type 'Group' was removed"}, changes[2])
+	assert.Equal(t, "old.ent:16: version 1 of type 'Group' was removed", changes[2].String())
+
+	// renumbered version
+	pkg = parseCompatPackage(t, "new.ent", `
+    package users
+
+    type User {
+        version 1 {
+            required string uuid, username
+            optional uint8 age
+        }
+
+        version 3 {
+            optional []string emails
+        }
+    }
+
+    type Group {
+        version 1 {
+            required string name
+        }
+    }
+    `)
+	assert.Equal(t, []string{"version 2 of type 'User' was renumbered to 3"}, messages(CheckCompatibility(old, pkg)))
+}
+
+func TestCheckCompatibilityRenames(t *testing.T) {
+	old := parseCompatPackage(t, "old.ent", compatUsers)
+	pkg := parseCompatPackage(t, "new.ent", `
+    package accounts
+
+    type Account {
+        version 1 {
+            required string uuid, username
+            optional uint8 age
+        }
+
+        version 2 {
+            optional []string emails
+        }
+    }
+    `)
+
+	changes := CheckCompatibility(old, pkg)
+	assert.Equal(t, 3, len(changes))
+	assert.Contains(t, changes[0].Message, "package 'users' was renamed to 'accounts' which changes the namespace hash from 0x")
+	assert.Contains(t, changes[1].Message, "type 'User' was renamed to 'Account' which changes the type hash from 0x")
+	assert.Equal(t, Incompatibility{"old.ent", 15, "type 'Group' was removed"}, changes[2])
+}
+
+func TestCheckAllCompatibility(t *testing.T) {
+	oldList := NewPackageList()
+	_, err := NewParser(oldList).Parse("users.ent", compatUsers)
+	assert.Nil(t, err)
+	_, err = NewParser(oldList).Parse("locale.ent", `
+    package locale
+
+    type Location {
+        version 1 {
+            required float64 latitude, longitude
+        }
+    }
+    `)
+	assert.Nil(t, err)
+
+	newList := NewPackageList()
+	_, err = NewParser(newList).Parse("users.ent", compatUsers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []Incompatibility{{"locale.ent", 1, "package 'locale' was removed"}}, CheckAllCompatibility(oldList, newList))
+}
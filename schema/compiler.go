@@ -0,0 +1,558 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blacklabeldata/namedtuple"
+)
+
+// CompileError represents an error while compiling a package. It includes the file and line number of the offending declaration.
+type CompileError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (c CompileError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", c.File, c.Line, c.Message)
+}
+
+// fieldTypes maps the reserved type names to their namedtuple field types. The first value is the scalar type and the second is the array type.
+var fieldTypes = map[string][2]namedtuple.FieldType{
+	"string":    {namedtuple.StringField, namedtuple.StringArrayField},
+	"byte":      {namedtuple.Uint8Field, namedtuple.Uint8ArrayField},
+	"uint8":     {namedtuple.Uint8Field, namedtuple.Uint8ArrayField},
+	"int8":      {namedtuple.Int8Field, namedtuple.Int8ArrayField},
+	"uint16":    {namedtuple.Uint16Field, namedtuple.Uint16ArrayField},
+	"int16":     {namedtuple.Int16Field, namedtuple.Int16ArrayField},
+	"uint32":    {namedtuple.Uint32Field, namedtuple.Uint32ArrayField},
+	"int32":     {namedtuple.Int32Field, namedtuple.Int32ArrayField},
+	"uint64":    {namedtuple.Uint64Field, namedtuple.Uint64ArrayField},
+	"int64":     {namedtuple.Int64Field, namedtuple.Int64ArrayField},
+	"int":       {namedtuple.Int64Field, namedtuple.Int64ArrayField},
+	"float32":   {namedtuple.Float32Field, namedtuple.Float32ArrayField},
+	"float64":   {namedtuple.Float64Field, namedtuple.Float64ArrayField},
+	"float":     {namedtuple.Float64Field, namedtuple.Float64ArrayField},
+	"timestamp": {namedtuple.TimestampField, namedtuple.TimestampArrayField},
+	"tuple":     {namedtuple.TupleField, namedtuple.TupleArrayField},
+	"bool":      {namedtuple.BooleanField, namedtuple.BooleanArrayField},
+}
+
+// Compile converts all the types in the package into namedtuple.TupleType values using the package name as the namespace. Imported types are resolved using the package list. Each compiled type is registered in the given registry. A CompileError is returned for unknown or ambiguous types.
+func Compile(pkgList PackageList, pkg Package, reg *namedtuple.Registry) ([]namedtuple.TupleType, error) {
+	c := compiler{pkgList, pkg, make(map[string]*namedtuple.Enum)}
+
+	// enum names share the namespace of the types
+	names := make(map[string]bool)
+	for _, e := range pkg.Enums {
+		if names[e.Name] {
+			return nil, c.errorf(e.Line, "enum '%s' is declared more than once", e.Name)
+		}
+		names[e.Name] = true
+
+		if _, err := c.compileEnum(pkg.Name, e); err != nil {
+			return nil, err
+		}
+	}
+
+	// compile all the types before registering any of them
+	var types []namedtuple.TupleType
+	for _, typ := range pkg.Types {
+
+		// type names must be unique within the package
+		if names[typ.Name] {
+			return nil, c.errorf(typ.Line, "type '%s' is declared more than once", typ.Name)
+		}
+		names[typ.Name] = true
+
+		tupleType, err := c.compileType(typ)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, tupleType)
+	}
+
+	// verify the types do not collide with registered types
+	for i, tupleType := range types {
+		if existing, collides := reg.Collision(tupleType.Namespace, tupleType.Name); collides {
+			return nil, c.errorf(pkg.Types[i].Line, "type '%s' collides with registered type '%s.%s'", tupleType.Name, existing.Namespace, existing.Name)
+		}
+	}
+
+	// register types
+	for i, tupleType := range types {
+		if err := reg.Register(tupleType); err != nil {
+			return nil, c.errorf(pkg.Types[i].Line, "%s", err)
+		}
+	}
+	return types, nil
+}
+
+// CompileAll compiles every package in the package list and registers the types in the given registry.
+func CompileAll(pkgList PackageList, reg *namedtuple.Registry) ([]namedtuple.TupleType, error) {
+	var types []namedtuple.TupleType
+	for _, pkg := range pkgList.List() {
+		compiled, err := Compile(pkgList, pkg, reg)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, compiled...)
+	}
+	return types, nil
+}
+
+// compiler resolves type names for a single package
+type compiler struct {
+	pkgList PackageList
+	pkg     Package
+	enums   map[string]*namedtuple.Enum
+}
+
+func (c *compiler) errorf(line int, format string, args ...interface{}) error {
+	return CompileError{c.pkg.File, line, fmt.Sprintf(format, args...)}
+}
+
+func (c *compiler) compileType(typ Type) (namedtuple.TupleType, error) {
+	tupleType := namedtuple.New(c.pkg.Name, typ.Name)
+
+	names := make(map[string]bool)
+	groups := make(map[string]bool)
+	for i, version := range typ.Versions {
+
+		// versions are positional so the numbers must be sequential
+		if version.Number != i+1 {
+			return tupleType, c.errorf(version.Line, "expected version %d of type '%s', not %d", i+1, typ.Name, version.Number)
+		}
+
+		// oneof names must be unique across all versions
+		for _, group := range version.OneOfs {
+			if groups[group.Name] {
+				return tupleType, c.errorf(group.Line, "oneof '%s' is declared more than once in type '%s'", group.Name, typ.Name)
+			}
+			groups[group.Name] = true
+		}
+
+		fields := make([]namedtuple.Field, len(version.Fields))
+		for j, field := range version.Fields {
+
+			// field names must be unique across all versions
+			if names[field.Name] {
+				return tupleType, c.errorf(field.Line, "field '%s' is declared more than once in type '%s'", field.Name, typ.Name)
+			}
+			names[field.Name] = true
+
+			// at most one field of a group is written
+			if field.OneOf != "" && field.IsRequired {
+				return tupleType, c.errorf(field.Line, "field '%s' of oneof '%s' can not be required", field.Name, field.OneOf)
+			}
+
+			compiled, err := c.compileField(field)
+			if err != nil {
+				return tupleType, err
+			}
+			compiled.OneOf = field.OneOf
+			fields[j] = compiled
+		}
+		tupleType.AddVersion(fields...)
+	}
+	return tupleType, nil
+}
+
+func (c *compiler) compileField(field Field) (namedtuple.Field, error) {
+	compiled := namedtuple.Field{Name: field.Name, Required: field.IsRequired}
+
+	// maps of reserved types
+	if field.Type == "map" {
+		return c.compileMap(field, compiled)
+	}
+
+	// reserved types
+	if types, ok := fieldTypes[field.Type]; ok {
+		if field.IsArray {
+			compiled.Type = types[1]
+		} else {
+			compiled.Type = types[0]
+		}
+
+		constraints, err := c.compileConstraints(field, compiled.Type)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.Constraints = constraints
+
+		value, err := c.compileDefault(field, compiled)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.Default = value
+		return compiled, nil
+	}
+
+	// tuple types and enums
+	namespace, enum, err := c.resolve(field)
+	if err != nil {
+		return compiled, err
+	}
+
+	if enum != nil {
+		if field.IsArray {
+			return compiled, c.errorf(field.Line, "field '%s' can not be an array of enum '%s'", field.Name, field.Type)
+		}
+		compiled.Type = namedtuple.EnumField
+
+		if compiled.Enum, err = c.compileEnum(namespace, *enum); err != nil {
+			return compiled, err
+		}
+		if _, err := c.compileConstraints(field, compiled.Type); err != nil {
+			return compiled, err
+		}
+
+		value, err := c.compileDefault(field, compiled)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.Default = value
+		return compiled, nil
+	}
+
+	if field.IsArray {
+		compiled.Type = namedtuple.TupleArrayField
+	} else {
+		compiled.Type = namedtuple.TupleField
+	}
+
+	constraints, err := c.compileConstraints(field, compiled.Type)
+	if err != nil {
+		return compiled, err
+	}
+	compiled.Constraints = constraints
+
+	if _, err := c.compileDefault(field, compiled); err != nil {
+		return compiled, err
+	}
+	compiled.TupleNamespace = namespace
+	compiled.TupleName = field.Type
+	return compiled, nil
+}
+
+// compileMap converts a map field. The key type must be a string or an integer and the value type must be a reserved scalar type other than a tuple.
+func (c *compiler) compileMap(field Field, compiled namedtuple.Field) (namedtuple.Field, error) {
+	if field.KeyType == "" {
+		return compiled, c.errorf(field.Line, "map field '%s' requires key and value types", field.Name)
+	} else if field.IsArray {
+		return compiled, c.errorf(field.Line, "field '%s' can not be an array of maps", field.Name)
+	}
+
+	keyTypes, keyReserved := fieldTypes[field.KeyType]
+	valueTypes, valueReserved := fieldTypes[field.ValueType]
+	if !keyReserved || !namedtuple.ValidMapTypes(keyTypes[0], namedtuple.StringField) {
+		return compiled, c.errorf(field.Line, "map key type '%s' of field '%s' must be a string or an integer", field.KeyType, field.Name)
+	} else if !valueReserved || !namedtuple.ValidMapTypes(namedtuple.StringField, valueTypes[0]) {
+		return compiled, c.errorf(field.Line, "map value type '%s' of field '%s' is not supported", field.ValueType, field.Name)
+	}
+	compiled.Type = namedtuple.MapField
+	compiled.KeyType = keyTypes[0]
+	compiled.ValueType = valueTypes[0]
+
+	constraints, err := c.compileConstraints(field, compiled.Type)
+	if err != nil {
+		return compiled, err
+	}
+	compiled.Constraints = constraints
+
+	if _, err := c.compileDefault(field, compiled); err != nil {
+		return compiled, err
+	}
+	return compiled, nil
+}
+
+// resolve determines the namespace of a tuple type or enum referenced by a field. The type must be declared in the current package or imported from exactly one other package. If the type is an enum, the enum declaration is returned as well.
+func (c *compiler) resolve(field Field) (namespace string, enum *Enum, err error) {
+	var candidates []string
+
+	// types and enums declared in the current package
+	if e, ok := findTypeOrEnum(c.pkg, field.Type); ok {
+		candidates = append(candidates, c.pkg.Name)
+		enum = e
+	}
+
+	// imported types
+	for _, imp := range c.pkg.Imports {
+		for _, name := range imp.TypeNames {
+			if name == field.Type {
+				candidates = append(candidates, imp.PackageName)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", nil, c.errorf(field.Line, "unknown type '%s'", field.Type)
+	case 1:
+		namespace = candidates[0]
+	default:
+		return "", nil, c.errorf(field.Line, "ambiguous type '%s' could refer to %s", field.Type, strings.Join(candidates, ", "))
+	}
+
+	// verify imported type exists
+	if namespace != c.pkg.Name {
+		pkg, ok := c.pkgList.Get(namespace)
+		if !ok {
+			return "", nil, c.errorf(field.Line, "unknown package '%s' for type '%s'", namespace, field.Type)
+		}
+
+		enum, ok = findTypeOrEnum(pkg, field.Type)
+		if !ok {
+			return "", nil, c.errorf(field.Line, "unknown type '%s' in package '%s'", field.Type, namespace)
+		}
+	}
+	return namespace, enum, nil
+}
+
+// findTypeOrEnum returns true if the package declares a type or an enum with the given name. If it is an enum, the enum is returned.
+func findTypeOrEnum(pkg Package, name string) (enum *Enum, ok bool) {
+	for _, typ := range pkg.Types {
+		if typ.Name == name {
+			return nil, true
+		}
+	}
+	for i := range pkg.Enums {
+		if pkg.Enums[i].Name == name {
+			return &pkg.Enums[i], true
+		}
+	}
+	return nil, false
+}
+
+// compileEnum converts an enum declared in the given package. Enums are compiled once per package so all the fields referencing an enum share it. Names and values must be unique.
+func (c *compiler) compileEnum(namespace string, e Enum) (*namedtuple.Enum, error) {
+	key := namespace + "." + e.Name
+	if compiled, ok := c.enums[key]; ok {
+		return compiled, nil
+	}
+
+	// errors in imported enums are reported in the file of the current package
+	names := make(map[string]bool)
+	numbers := make(map[uint32]string)
+	values := make([]namedtuple.EnumValue, len(e.Values))
+	for i, v := range e.Values {
+		if names[v.Name] {
+			return nil, c.errorf(v.Line, "enum value '%s' is declared more than once in enum '%s'", v.Name, e.Name)
+		}
+		names[v.Name] = true
+
+		if name, exists := numbers[v.Value]; exists {
+			return nil, c.errorf(v.Line, "enum value '%s' has the same value %d as '%s' in enum '%s'", v.Name, v.Value, name, e.Name)
+		}
+		numbers[v.Value] = v.Name
+
+		values[i] = namedtuple.EnumValue{Name: v.Name, Value: v.Value}
+	}
+
+	compiled := namedtuple.NewEnum(namespace, e.Name, e.IsOpen, values...)
+	c.enums[key] = compiled
+	return compiled, nil
+}
+
+// compileConstraints converts the constraints of a field. Each constraint must be valid for the field type: `min` and `max` for numbers and timestamps, `minlen`, `maxlen` and `pattern` for strings and `minitems` and `maxitems` for arrays and maps. Constraints on arrays other than `minitems` and `maxitems` apply to each element.
+func (c *compiler) compileConstraints(field Field, fieldType namedtuple.FieldType) (constraints namedtuple.Constraints, err error) {
+	array := fieldType%2 == 1
+	scalar := fieldType &^ 1
+	if fieldType == namedtuple.MapField {
+
+		// only the number of entries of a map can be constrained
+		scalar = fieldType
+	}
+	numeric := scalar < namedtuple.TimestampField
+
+	names := make(map[string]bool)
+	for _, constraint := range field.Constraints {
+		if names[constraint.Name] {
+			return constraints, c.errorf(constraint.Line, "constraint '%s' is declared more than once for field '%s'", constraint.Name, field.Name)
+		}
+		names[constraint.Name] = true
+
+		// verify the constraint is valid for the field type
+		var valid bool
+		switch constraint.Name {
+		case "min", "max":
+			valid = numeric || scalar == namedtuple.TimestampField
+		case "minlen", "maxlen", "pattern":
+			valid = scalar == namedtuple.StringField
+		case "minitems", "maxitems":
+			valid = array
+		default:
+			return constraints, c.errorf(constraint.Line, "unknown constraint '%s' for field '%s'", constraint.Name, field.Name)
+		}
+		if !valid {
+			return constraints, c.errorf(constraint.Line, "constraint '%s' is not valid for field '%s' of type '%s'", constraint.Name, field.Name, typeName(field))
+		}
+
+		if err := c.compileConstraint(&constraints, constraint, scalar); err != nil {
+			return constraints, err
+		}
+	}
+
+	// verify the bounds
+	switch {
+	case constraints.Min != nil && constraints.Max != nil && *constraints.Min > *constraints.Max,
+		constraints.MinTime != nil && constraints.MaxTime != nil && constraints.MinTime.After(*constraints.MaxTime),
+		constraints.MinLength != nil && constraints.MaxLength != nil && *constraints.MinLength > *constraints.MaxLength,
+		constraints.MinItems != nil && constraints.MaxItems != nil && *constraints.MinItems > *constraints.MaxItems:
+		return constraints, c.errorf(field.Line, "the minimum of field '%s' is greater than the maximum", field.Name)
+	}
+	return constraints, nil
+}
+
+// compileConstraint parses the value of a single constraint into the constraints
+func (c *compiler) compileConstraint(constraints *namedtuple.Constraints, constraint Constraint, scalar namedtuple.FieldType) error {
+	invalid := c.errorf(constraint.Line, "invalid value '%s' for constraint '%s'", constraint.Value, constraint.Name)
+
+	switch constraint.Name {
+	case "min", "max":
+
+		// timestamp bounds are RFC3339 strings
+		if scalar == namedtuple.TimestampField {
+			if !constraint.IsString {
+				return invalid
+			}
+			ts, err := time.Parse(time.RFC3339Nano, constraint.Value)
+			if err != nil {
+				return invalid
+			}
+			if constraint.Name == "min" {
+				constraints.MinTime = &ts
+			} else {
+				constraints.MaxTime = &ts
+			}
+			return nil
+		}
+
+		if constraint.IsString {
+			return invalid
+		}
+		value, err := strconv.ParseFloat(constraint.Value, 64)
+		if err != nil {
+			return invalid
+		}
+		if constraint.Name == "min" {
+			constraints.Min = &value
+		} else {
+			constraints.Max = &value
+		}
+	case "pattern":
+		if !constraint.IsString {
+			return invalid
+		}
+		pattern, err := regexp.Compile(constraint.Value)
+		if err != nil {
+			return c.errorf(constraint.Line, "invalid pattern for constraint '%s': %s", constraint.Name, err)
+		}
+		constraints.Pattern = pattern
+	default:
+
+		// lengths and number of items are non-negative integers
+		if constraint.IsString {
+			return invalid
+		}
+		value, err := strconv.Atoi(constraint.Value)
+		if err != nil || value < 0 {
+			return invalid
+		}
+
+		switch constraint.Name {
+		case "minlen":
+			constraints.MinLength = &value
+		case "maxlen":
+			constraints.MaxLength = &value
+		case "minitems":
+			constraints.MinItems = &value
+		case "maxitems":
+			constraints.MaxItems = &value
+		}
+	}
+	return nil
+}
+
+// compileDefault converts the default value of a field into the Go type returned by the Tuple getter for the field type. Only optional scalar fields can have default values. Timestamps are RFC3339 strings and enum defaults are the quoted name of a value.
+func (c *compiler) compileDefault(field Field, compiled namedtuple.Field) (interface{}, error) {
+	literal := field.Default
+	if literal == nil {
+		return nil, nil
+	}
+	fieldType := compiled.Type
+
+	if field.IsRequired {
+		return nil, c.errorf(literal.Line, "required field '%s' can not have a default value", field.Name)
+	}
+	if fieldType%2 == 1 || fieldType == namedtuple.TupleField {
+		return nil, c.errorf(literal.Line, "field '%s' of type '%s' can not have a default value", field.Name, typeName(field))
+	}
+
+	// strings, timestamps and enum names are quoted, all other values are not
+	invalid := c.errorf(literal.Line, "invalid default value '%s' for field '%s' of type '%s'", literal.Value, field.Name, typeName(field))
+	if literal.IsString != (fieldType == namedtuple.StringField || fieldType == namedtuple.TimestampField || fieldType == namedtuple.EnumField) {
+		return nil, invalid
+	}
+
+	var value interface{}
+	var err error
+	switch fieldType {
+	case namedtuple.Uint8Field:
+		var v uint64
+		v, err = strconv.ParseUint(literal.Value, 10, 8)
+		value = uint8(v)
+	case namedtuple.Int8Field:
+		var v int64
+		v, err = strconv.ParseInt(literal.Value, 10, 8)
+		value = int8(v)
+	case namedtuple.Uint16Field:
+		var v uint64
+		v, err = strconv.ParseUint(literal.Value, 10, 16)
+		value = uint16(v)
+	case namedtuple.Int16Field:
+		var v int64
+		v, err = strconv.ParseInt(literal.Value, 10, 16)
+		value = int16(v)
+	case namedtuple.Uint32Field:
+		var v uint64
+		v, err = strconv.ParseUint(literal.Value, 10, 32)
+		value = uint32(v)
+	case namedtuple.Int32Field:
+		var v int64
+		v, err = strconv.ParseInt(literal.Value, 10, 32)
+		value = int32(v)
+	case namedtuple.Uint64Field:
+		value, err = strconv.ParseUint(literal.Value, 10, 64)
+	case namedtuple.Int64Field:
+		value, err = strconv.ParseInt(literal.Value, 10, 64)
+	case namedtuple.Float32Field:
+		var v float64
+		v, err = strconv.ParseFloat(literal.Value, 32)
+		value = float32(v)
+	case namedtuple.Float64Field:
+		value, err = strconv.ParseFloat(literal.Value, 64)
+	case namedtuple.StringField:
+		value = literal.Value
+	case namedtuple.BooleanField:
+		if literal.Value != "true" && literal.Value != "false" {
+			return nil, invalid
+		}
+		value = literal.Value == "true"
+	case namedtuple.TimestampField:
+		value, err = time.Parse(time.RFC3339Nano, literal.Value)
+	case namedtuple.EnumField:
+		if _, ok := compiled.Enum.Value(literal.Value); !ok {
+			return nil, invalid
+		}
+		value = literal.Value
+	}
+	if err != nil {
+		return nil, invalid
+	}
+	return value, nil
+}
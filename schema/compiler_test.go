@@ -0,0 +1,624 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blacklabeldata/namedtuple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	locale := `
+    package locale
+
+    type Location {
+        version 1 {
+            required float64 latitude, longitude
+        }
+    }
+    `
+
+	users := `
+    package users
+
+    from locale import Location
+
+    type User {
+        version 1 {
+            required string uuid, username
+            optional uint8 age
+            optional []string emails
+        }
+
+        version 2 {
+            optional Location location
+            optional []User friends
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	parser := NewParser(pkgList)
+
+	_, err := parser.Parse("locale.ent", locale)
+	assert.Nil(t, err)
+	pkg, err := parser.Parse("users.ent", users)
+	assert.Nil(t, err)
+
+	// compile users package
+	reg := namedtuple.NewRegistry()
+	types, err := Compile(pkgList, pkg, &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(types))
+	assert.True(t, reg.ContainsName("users", "User"))
+	assert.False(t, reg.ContainsName("locale", "Location"))
+
+	// test type
+	User := types[0]
+	assert.Equal(t, "users", User.Namespace)
+	assert.Equal(t, "User", User.Name)
+	assert.Equal(t, 2, User.NumVersions())
+
+	// test fields
+	versions := User.Versions()
+	assert.Equal(t, []namedtuple.Field{
+		{Name: "uuid", Required: true, Type: namedtuple.StringField},
+		{Name: "username", Required: true, Type: namedtuple.StringField},
+		{Name: "age", Required: false, Type: namedtuple.Uint8Field},
+		{Name: "emails", Required: false, Type: namedtuple.StringArrayField},
+	}, versions[0].Fields)
+	assert.Equal(t, []namedtuple.Field{
+		{Name: "location", Required: false, Type: namedtuple.TupleField, TupleNamespace: "locale", TupleName: "Location"},
+		{Name: "friends", Required: false, Type: namedtuple.TupleArrayField, TupleNamespace: "users", TupleName: "User"},
+	}, versions[1].Fields)
+}
+
+func TestCompileAll(t *testing.T) {
+	pkgList := NewPackageList()
+	parser := NewParser(pkgList)
+
+	_, err := parser.Parse("a.ent", "package a\ntype A {\nversion 1 {\nrequired string name\n}\n}")
+	assert.Nil(t, err)
+	_, err = parser.Parse("b.ent", "package b\nfrom a import A\ntype B {\nversion 1 {\nrequired A a\n}\n}")
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	types, err := CompileAll(pkgList, &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(types))
+	assert.True(t, reg.ContainsName("a", "A"))
+	assert.True(t, reg.ContainsName("b", "B"))
+}
+
+func TestCompileUnknownType(t *testing.T) {
+	text := `package users
+
+    type User {
+        version 1 {
+            required string uuid
+            optional Location location
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	_, err = Compile(pkgList, pkg, &reg)
+	assert.Equal(t, CompileError{"users.ent", 6, "unknown type 'Location'"}, err)
+	assert.Equal(t, "users.ent:6: unknown type 'Location'", err.Error())
+	assert.Equal(t, 0, reg.Size())
+}
+
+func TestCompileUnknownImport(t *testing.T) {
+	text := `package users
+
+    from locale import Location
+
+    type User {
+        version 1 {
+            optional Location location
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	_, err = Compile(pkgList, pkg, &reg)
+	assert.Equal(t, CompileError{"users.ent", 7, "unknown package 'locale' for type 'Location'"}, err)
+}
+
+func TestCompileAmbiguousType(t *testing.T) {
+	text := `package users
+
+    from locale import Location
+
+    type Location {
+        version 1 {
+            required string name
+        }
+    }
+
+    type User {
+        version 1 {
+            optional Location location
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	_, err = Compile(pkgList, pkg, &reg)
+	assert.Equal(t, CompileError{"users.ent", 13, "ambiguous type 'Location' could refer to users, locale"}, err)
+}
+
+func TestCompileVersionNumbers(t *testing.T) {
+	text := `package users
+
+    type User {
+        version 2 {
+            required string uuid
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	_, err = Compile(pkgList, pkg, &reg)
+	assert.Equal(t, CompileError{"users.ent", 4, "expected version 1 of type 'User', not 2"}, err)
+}
+
+func TestCompileDuplicateField(t *testing.T) {
+	text := `package users
+
+    type User {
+        version 1 {
+            required string uuid
+        }
+        version 2 {
+            optional string uuid
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	_, err = Compile(pkgList, pkg, &reg)
+	assert.Equal(t, CompileError{"users.ent", 8, "field 'uuid' is declared more than once in type 'User'"}, err)
+}
+
+func TestCompileRegisteredType(t *testing.T) {
+	text := `package users
+
+    type User {
+        version 1 {
+            required string uuid
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	_, err = Compile(pkgList, pkg, &reg)
+	assert.Nil(t, err)
+
+	// compiling the package again collides with the registered type
+	_, err = Compile(pkgList, pkg, &reg)
+	assert.Equal(t, CompileError{"users.ent", 3, "type 'User' collides with registered type 'users.User'"}, err)
+	assert.Equal(t, 1, reg.Size())
+}
+
+func TestCompileConstraints(t *testing.T) {
+	text := `package users
+
+    type User {
+        version 1 {
+            required string username (minlen 3, maxlen 32, pattern "^[a-z]+$")
+            optional uint8 age (min 13, max 150)
+            optional []float64 scores (min 0, max 1.5, maxitems 10)
+            optional timestamp created (min "2000-01-01T00:00:00Z")
+            optional string bio
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	types, err := Compile(pkgList, pkg, &reg)
+	assert.Nil(t, err)
+
+	fields := types[0].Versions()[0].Fields
+	assert.Equal(t, 5, len(fields))
+
+	username := fields[0].Constraints
+	assert.Equal(t, 3, *username.MinLength)
+	assert.Equal(t, 32, *username.MaxLength)
+	assert.Equal(t, "^[a-z]+$", username.Pattern.String())
+	assert.Nil(t, username.Min)
+
+	age := fields[1].Constraints
+	assert.Equal(t, 13.0, *age.Min)
+	assert.Equal(t, 150.0, *age.Max)
+
+	scores := fields[2].Constraints
+	assert.Equal(t, 0.0, *scores.Min)
+	assert.Equal(t, 1.5, *scores.Max)
+	assert.Equal(t, 10, *scores.MaxItems)
+	assert.Nil(t, scores.MinItems)
+
+	created := fields[3].Constraints
+	assert.Equal(t, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), created.MinTime.UTC())
+	assert.Nil(t, created.MaxTime)
+
+	assert.True(t, fields[4].Constraints.IsZero())
+}
+
+func TestCompileConstraintsFail(t *testing.T) {
+	tests := []struct {
+		field   string
+		message string
+	}{
+		{`optional uint8 age (minimum 1)`, "unknown constraint 'minimum' for field 'age'"},
+		{`optional uint8 age (min 1, min 2)`, "constraint 'min' is declared more than once for field 'age'"},
+		{`optional uint8 age (minlen 1)`, "constraint 'minlen' is not valid for field 'age' of type 'uint8'"},
+		{`optional string name (maxitems 1)`, "constraint 'maxitems' is not valid for field 'name' of type 'string'"},
+		{`optional uint8 age (min "1")`, "invalid value '1' for constraint 'min'"},
+		{`optional timestamp created (max 1)`, "invalid value '1' for constraint 'max'"},
+		{`optional timestamp created (max "yesterday")`, "invalid value 'yesterday' for constraint 'max'"},
+		{`optional string name (maxlen -1)`, "invalid value '-1' for constraint 'maxlen'"},
+		{`optional []string names (minitems 1.5)`, "invalid value '1.5' for constraint 'minitems'"},
+		{`optional string name (pattern "[a-z")`, "invalid pattern for constraint 'pattern': error parsing regexp: missing closing ]: `[a-z`"},
+		{`optional uint8 age (min 10, max 1)`, "the minimum of field 'age' is greater than the maximum"},
+	}
+
+	for _, test := range tests {
+		text := `package users
+
+    type User {
+        version 1 {
+            ` + test.field + `
+        }
+    }
+    `
+
+		pkgList := NewPackageList()
+		pkg, err := NewParser(pkgList).Parse("users.ent", text)
+		assert.Nil(t, err)
+
+		reg := namedtuple.NewRegistry()
+		_, err = Compile(pkgList, pkg, &reg)
+		assert.Equal(t, CompileError{"users.ent", 5, test.message}, err, test.field)
+	}
+}
+
+func TestCompileDefaults(t *testing.T) {
+	text := `package users
+
+    type User {
+        version 1 {
+            optional uint8 age = 18
+            optional int64 balance = -1
+            optional float32 score = 0.5
+            optional string nickname = "anonymous"
+            optional bool active = true
+            optional timestamp created = "2015-01-01T00:00:00Z"
+            optional string email
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	types, err := Compile(pkgList, pkg, &reg)
+	assert.Nil(t, err)
+
+	fields := types[0].Versions()[0].Fields
+	assert.Equal(t, uint8(18), fields[0].Default)
+	assert.Equal(t, int64(-1), fields[1].Default)
+	assert.Equal(t, float32(0.5), fields[2].Default)
+	assert.Equal(t, "anonymous", fields[3].Default)
+	assert.Equal(t, true, fields[4].Default)
+	assert.Equal(t, time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), fields[5].Default.(time.Time).UTC())
+	assert.Nil(t, fields[6].Default)
+}
+
+func TestCompileDefaultsFail(t *testing.T) {
+	tests := []struct {
+		field   string
+		message string
+	}{
+		{`required uint8 age = 18`, "required field 'age' can not have a default value"},
+		{`optional []uint8 ages = 18`, "field 'ages' of type '[]uint8' can not have a default value"},
+		{`optional uint8 age = 256`, "invalid default value '256' for field 'age' of type 'uint8'"},
+		{`optional uint8 age = -1`, "invalid default value '-1' for field 'age' of type 'uint8'"},
+		{`optional int8 age = 1.5`, "invalid default value '1.5' for field 'age' of type 'int8'"},
+		{`optional uint8 age = "18"`, "invalid default value '18' for field 'age' of type 'uint8'"},
+		{`optional string name = 1`, "invalid default value '1' for field 'name' of type 'string'"},
+		{`optional bool active = 1`, "invalid default value '1' for field 'active' of type 'bool'"},
+		{`optional uint8 age = true`, "invalid default value 'true' for field 'age' of type 'uint8'"},
+		{`optional timestamp created = "yesterday"`, "invalid default value 'yesterday' for field 'created' of type 'timestamp'"},
+	}
+
+	for _, test := range tests {
+		text := `package users
+
+    type User {
+        version 1 {
+            ` + test.field + `
+        }
+    }
+    `
+
+		pkgList := NewPackageList()
+		pkg, err := NewParser(pkgList).Parse("users.ent", text)
+		assert.Nil(t, err)
+
+		reg := namedtuple.NewRegistry()
+		_, err = Compile(pkgList, pkg, &reg)
+		assert.Equal(t, CompileError{"users.ent", 5, test.message}, err, test.field)
+	}
+}
+
+func TestCompileEnum(t *testing.T) {
+	common := `package common
+
+    open enum Role {
+        ADMIN = 1
+        GUEST = 2
+    }
+    `
+
+	users := `package users
+
+    from common import Role
+
+    enum Status {
+        ACTIVE = 1,
+        DISABLED = 2
+    }
+
+    type User {
+        version 1 {
+            required Status status
+            optional Status previous = "ACTIVE"
+            optional Role role
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	parser := NewParser(pkgList)
+	_, err := parser.Parse("common.ent", common)
+	assert.Nil(t, err)
+	pkg, err := parser.Parse("users.ent", users)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	types, err := Compile(pkgList, pkg, &reg)
+	assert.Nil(t, err)
+
+	fields := types[0].Versions()[0].Fields
+	assert.Equal(t, namedtuple.EnumField, fields[0].Type)
+	assert.Equal(t, "users", fields[0].Enum.Namespace)
+	assert.Equal(t, "Status", fields[0].Enum.Name)
+	assert.False(t, fields[0].Enum.Open)
+	assert.Equal(t, []namedtuple.EnumValue{{Name: "ACTIVE", Value: 1}, {Name: "DISABLED", Value: 2}}, fields[0].Enum.Values())
+
+	// fields referencing the same enum share it
+	assert.True(t, fields[0].Enum == fields[1].Enum)
+	assert.Equal(t, "ACTIVE", fields[1].Default)
+
+	// imported enums use the namespace of their package
+	assert.Equal(t, "common", fields[2].Enum.Namespace)
+	assert.True(t, fields[2].Enum.Open)
+}
+
+func TestCompileEnumFail(t *testing.T) {
+	tests := []struct {
+		text    string
+		line    int
+		message string
+	}{
+		{`enum Status { ACTIVE = 1 }
+    enum Status { DISABLED = 2 }`, 3, "enum 'Status' is declared more than once"},
+		{`enum User { ACTIVE = 1 }
+    type User { version 1 { optional string name } }`, 3, "type 'User' is declared more than once"},
+		{`enum Status {
+        ACTIVE = 1
+        ACTIVE = 2
+    }`, 4, "enum value 'ACTIVE' is declared more than once in enum 'Status'"},
+		{`enum Status {
+        ACTIVE = 1
+        ENABLED = 1
+    }`, 4, "enum value 'ENABLED' has the same value 1 as 'ACTIVE' in enum 'Status'"},
+		{`enum Status { ACTIVE = 1 }
+    type User { version 1 {
+        optional []Status history
+    } }`, 4, "field 'history' can not be an array of enum 'Status'"},
+		{`enum Status { ACTIVE = 1 }
+    type User { version 1 {
+        optional Status status = "UNKNOWN"
+    } }`, 4, "invalid default value 'UNKNOWN' for field 'status' of type 'Status'"},
+		{`enum Status { ACTIVE = 1 }
+    type User { version 1 {
+        optional Status status = 1
+    } }`, 4, "invalid default value '1' for field 'status' of type 'Status'"},
+	}
+
+	for _, test := range tests {
+		text := `package users
+    ` + test.text
+
+		pkgList := NewPackageList()
+		pkg, err := NewParser(pkgList).Parse("users.ent", text)
+		assert.Nil(t, err, test.text)
+
+		reg := namedtuple.NewRegistry()
+		_, err = Compile(pkgList, pkg, &reg)
+		assert.Equal(t, CompileError{"users.ent", test.line, test.message}, err, test.text)
+	}
+}
+
+func TestCompileMap(t *testing.T) {
+	text := `package users
+
+    type User {
+        version 1 {
+            optional map<string, uint32> counts (maxitems 10)
+            required map<int, timestamp> updated
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("users.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	types, err := Compile(pkgList, pkg, &reg)
+	assert.Nil(t, err)
+
+	fields := types[0].Versions()[0].Fields
+	assert.Equal(t, namedtuple.MapField, fields[0].Type)
+	assert.Equal(t, namedtuple.StringField, fields[0].KeyType)
+	assert.Equal(t, namedtuple.Uint32Field, fields[0].ValueType)
+	assert.Equal(t, 10, *fields[0].Constraints.MaxItems)
+	assert.Equal(t, namedtuple.Int64Field, fields[1].KeyType)
+	assert.Equal(t, namedtuple.TimestampField, fields[1].ValueType)
+}
+
+func TestCompileMapFail(t *testing.T) {
+	tests := []struct {
+		field   string
+		message string
+	}{
+		{`optional map counts`, "map field 'counts' requires key and value types"},
+		{`optional []map<string, uint32> counts`, "field 'counts' can not be an array of maps"},
+		{`optional map<float64, uint32> counts`, "map key type 'float64' of field 'counts' must be a string or an integer"},
+		{`optional map<User, uint32> counts`, "map key type 'User' of field 'counts' must be a string or an integer"},
+		{`optional map<string, tuple> counts`, "map value type 'tuple' of field 'counts' is not supported"},
+		{`optional map<string, User> counts`, "map value type 'User' of field 'counts' is not supported"},
+		{`optional map<string, uint32> counts (min 1)`, "constraint 'min' is not valid for field 'counts' of type 'map<string, uint32>'"},
+		{`optional map<string, uint32> counts = 1`, "field 'counts' of type 'map<string, uint32>' can not have a default value"},
+	}
+
+	for _, test := range tests {
+		text := `package users
+
+    type User {
+        version 1 {
+            ` + test.field + `
+        }
+    }
+    `
+
+		pkgList := NewPackageList()
+		pkg, err := NewParser(pkgList).Parse("users.ent", text)
+		assert.Nil(t, err, test.field)
+
+		reg := namedtuple.NewRegistry()
+		_, err = Compile(pkgList, pkg, &reg)
+		assert.Equal(t, CompileError{"users.ent", 5, test.message}, err, test.field)
+	}
+}
+
+func TestCompileOneOf(t *testing.T) {
+	text := `package places
+
+    type Place {
+        version 1 {
+            oneof location {
+                optional Address address
+                optional string code
+            }
+        }
+    }
+
+    type Address {
+        version 1 {
+            required string street
+        }
+    }
+    `
+
+	pkgList := NewPackageList()
+	pkg, err := NewParser(pkgList).Parse("places.ent", text)
+	assert.Nil(t, err)
+
+	reg := namedtuple.NewRegistry()
+	types, err := Compile(pkgList, pkg, &reg)
+	assert.Nil(t, err)
+
+	fields := types[0].Versions()[0].Fields
+	assert.Equal(t, namedtuple.Field{Name: "address", Type: namedtuple.TupleField, TupleNamespace: "places", TupleName: "Address", OneOf: "location"}, fields[0])
+	assert.Equal(t, namedtuple.Field{Name: "code", Type: namedtuple.StringField, OneOf: "location"}, fields[1])
+}
+
+func TestCompileOneOfFail(t *testing.T) {
+	tests := []struct {
+		text    string
+		message string
+	}{
+		{`package places
+
+    type Place {
+        version 1 {
+            oneof location {
+                required string code
+            }
+        }
+    }
+    `, "field 'code' of oneof 'location' can not be required"},
+		{`package places
+
+    type Place {
+        version 1 {
+            oneof location {
+                optional string code
+            }
+        }
+        version 2 {
+            oneof location {
+                optional string street
+            }
+        }
+    }
+    `, "oneof 'location' is declared more than once in type 'Place'"},
+	}
+
+	for _, test := range tests {
+		pkgList := NewPackageList()
+		pkg, err := NewParser(pkgList).Parse("places.ent", test.text)
+		assert.Nil(t, err)
+
+		reg := namedtuple.NewRegistry()
+		_, err = Compile(pkgList, pkg, &reg)
+		if assert.NotNil(t, err) {
+			assert.Equal(t, test.message, err.(CompileError).Message)
+		}
+	}
+}
@@ -34,6 +34,17 @@ const (
 	TokenPackage                            // 19 Package keyword
 	TokenPackageName                        // 20 Package name
 	TokenAsterisk                           // 21 Package all
+	TokenOpenParen                          // 22 Open constraints (
+	TokenCloseParen                         // 23 Close constraints )
+	TokenString                             // 24 Quoted string literal
+	TokenNumber                             // 25 Number literal
+	TokenEquals                             // 26 Default value =
+	TokenBoolean                            // 27 Boolean literal
+	TokenEnum                               // 28 Enum keyword
+	TokenOpen                               // 29 Open enum keyword
+	TokenOpenAngleBracket                   // 30 Open map types <
+	TokenCloseAngleBracket                  // 31 Close map types >
+	TokenOneOf                              // 32 Oneof keyword
 )
 
 // Constant Punctuation and Keywords
@@ -54,6 +65,14 @@ const (
 	as         = "as"
 	pkg        = "package"
 	asterisk   = "*"
+	openParen  = "("
+	closeParen = ")"
+	equals     = "="
+	enum       = "enum"
+	open       = "open"
+	openAngle  = "<"
+	closeAngle = ">"
+	oneof      = "oneof"
 )
 
 // eof represents the end of file/input
@@ -73,6 +92,7 @@ var TypeNames = []string{"string", "byte",
 type Token struct {
 	Type  TokenType // Type, such as itemNumber
 	Value string    // Value, such as "23.2"
+	Line  int       // Line number of the token, starting at 1
 }
 
 // Used to print tokens
@@ -133,7 +153,7 @@ func (l *Lexer) emit(t TokenType) {
 		return
 	}
 
-	tok := Token{t, l.input[l.Start:l.Pos]}
+	tok := Token{t, l.input[l.Start:l.Pos], strings.Count(l.input[:l.Start], "\n") + 1}
 	l.handler(tok)
 	l.Start = l.Pos
 }
@@ -226,7 +246,7 @@ func (l *Lexer) Offset() int {
 // by passing back a nil pointer that will be the next
 // state thus terminating the lexer
 func (l *Lexer) errorf(format string, args ...interface{}) stateFn {
-	l.handler(Token{TokenError, fmt.Sprintf(fmt.Sprintf("%s[%d:%d] ", l.Name, l.LineNum(), l.Offset())+format, args...)})
+	l.handler(Token{TokenError, fmt.Sprintf(fmt.Sprintf("%s[%d:%d] ", l.Name, l.LineNum(), l.Offset())+format, args...), l.LineNum() + 1})
 	return nil
 }
 
@@ -265,6 +285,27 @@ OUTER:
 			l.emit(TokenOptional)
 			l.skipWhitespace()
 			return lexType
+		} else if strings.HasPrefix(remaining, oneof) { // Start oneof group
+			// state function which lexes a oneof group
+			return lexOneOf
+		} else if strings.HasPrefix(remaining, enum) { // Start enum
+			// state function which lexes an enum
+			return lexEnum
+		} else if strings.HasPrefix(remaining, open) { // Start open enum
+			l.Pos += len(open)
+			l.emit(TokenOpen)
+			l.skipWhitespace()
+
+			if !strings.HasPrefix(l.remaining(), enum) {
+				return l.errorf("expected enum")
+			}
+			return lexEnum
+		} else if strings.HasPrefix(remaining, openParen) { // Start field constraints
+			// state function which lexes the constraints of a field
+			return lexConstraints
+		} else if strings.HasPrefix(remaining, equals) { // Start default value
+			// state function which lexes the default value of a field
+			return lexDefault
 		} else if strings.HasPrefix(remaining, openScope) { // Open scope
 			l.Pos += len(openScope)
 			l.emit(TokenOpenCurlyBracket)
@@ -526,5 +567,240 @@ func lexType(l *Lexer) stateFn {
 		return l.errorf("expected identifier")
 	}
 
+	// map key and value types
+	if strings.HasPrefix(l.remaining(), openAngle) {
+		return lexMapTypes(l)
+	}
+
+	return lexIdentifier(l, lexText, true)
+}
+
+// lexMapTypes lexes the key and value types of a map field.
+//
+//	optional map<string, uint32> counts
+func lexMapTypes(l *Lexer) stateFn {
+	l.Pos += len(openAngle)
+	l.emit(TokenOpenAngleBracket)
+	l.skipWhitespace()
+
+	if !lexLetters(l, TokenValueType) {
+		return l.errorf("expected map key type")
+	}
+	l.skipWhitespace()
+
+	if !strings.HasPrefix(l.remaining(), comma) {
+		return l.errorf("expected ,")
+	}
+	l.Pos += len(comma)
+	l.emit(TokenComma)
+	l.skipWhitespace()
+
+	if !lexLetters(l, TokenValueType) {
+		return l.errorf("expected map value type")
+	}
+	l.skipWhitespace()
+
+	if !strings.HasPrefix(l.remaining(), closeAngle) {
+		return l.errorf("expected >")
+	}
+	l.Pos += len(closeAngle)
+	l.emit(TokenCloseAngleBracket)
+
 	return lexIdentifier(l, lexText, true)
 }
+
+// lexOneOf lexes the keyword and the name of a oneof group. The fields of the group are lexed as usual.
+//
+//	oneof location { optional Address address }
+func lexOneOf(l *Lexer) stateFn {
+	l.Pos += len(oneof)
+	l.emit(TokenOneOf)
+	l.skipWhitespace()
+
+	return lexIdentifier(l, lexText, false)
+}
+
+// lexConstraints lexes a parenthesized list of field constraints. Each constraint is a name followed by a number or a quoted string, separated by commas.
+//
+//	required string username (minlen 3, maxlen 32, pattern "^[a-z]+$")
+func lexConstraints(l *Lexer) stateFn {
+	l.Pos += len(openParen)
+	l.emit(TokenOpenParen)
+
+	for {
+		l.skipWhitespace()
+
+		// constraint name
+		if !lexLetters(l, TokenIdentifier) {
+			return l.errorf("expected constraint name")
+		}
+		l.skipWhitespace()
+
+		// constraint value
+		if !lexValue(l) {
+			return nil
+		}
+		l.skipWhitespace()
+
+		// next constraint or end of constraints
+		switch l.next() {
+		case ',':
+			l.emit(TokenComma)
+		case ')':
+			l.emit(TokenCloseParen)
+			return lexText
+		default:
+			l.backup()
+			return l.errorf("expected , or )")
+		}
+	}
+}
+
+// lexEnum lexes an enum declaration. Each value is a name, an equals sign and a number. Values are separated by commas or new lines.
+//
+//	enum Status { ACTIVE = 1, DISABLED = 2 }
+func lexEnum(l *Lexer) stateFn {
+	l.Pos += len(enum)
+	l.emit(TokenEnum)
+	l.skipWhitespace()
+
+	// enum name
+	if !lexLetters(l, TokenIdentifier) {
+		return l.errorf("expected enum name")
+	}
+	l.skipWhitespace()
+
+	if !strings.HasPrefix(l.remaining(), openScope) {
+		return l.errorf("expected {")
+	}
+	l.Pos += len(openScope)
+	l.emit(TokenOpenCurlyBracket)
+
+	for {
+		l.skipWhitespace()
+		remaining := l.remaining()
+
+		if strings.HasPrefix(remaining, comment) {
+			if index := strings.Index(remaining, "\n"); index > 0 {
+				l.Pos += index
+			} else {
+				l.Pos += len(remaining)
+			}
+			l.emit(TokenComment)
+			continue
+		} else if strings.HasPrefix(remaining, closeScope) {
+			l.Pos += len(closeScope)
+			l.emit(TokenCloseCurlyBracket)
+			return lexText
+		} else if strings.HasPrefix(remaining, comma) {
+			l.Pos += len(comma)
+			l.emit(TokenComma)
+			continue
+		}
+
+		// value name
+		for r := l.next(); unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'; r = l.next() {
+		}
+		l.backup()
+		if l.Pos == l.Start {
+			return l.errorf("expected enum value name")
+		}
+		l.emit(TokenIdentifier)
+		l.skipWhitespace()
+
+		// value
+		if !strings.HasPrefix(l.remaining(), equals) {
+			return l.errorf("expected =")
+		}
+		l.Pos += len(equals)
+		l.emit(TokenEquals)
+		l.skipWhitespace()
+
+		if !lexNumber(l) {
+			return l.errorf("invalid number")
+		}
+	}
+}
+
+// lexDefault lexes the default value of a field.
+//
+//	optional uint8 age = 18
+func lexDefault(l *Lexer) stateFn {
+	l.Pos += len(equals)
+	l.emit(TokenEquals)
+	l.skipWhitespace()
+
+	if !lexValue(l) {
+		return nil
+	}
+	return lexText
+}
+
+// lexValue lexes a number, a quoted string or a boolean. If the value is invalid an error token is emitted and false is returned.
+func lexValue(l *Lexer) bool {
+	switch r := l.peek(); {
+	case r == '"' || r == '`':
+		if !lexString(l) {
+			l.errorf("unterminated string")
+			return false
+		}
+	case r == '-' || r == '+' || unicode.IsDigit(r):
+		if !lexNumber(l) {
+			l.errorf("invalid number")
+			return false
+		}
+	case unicode.IsLetter(r):
+		word := strings.FieldsFunc(l.remaining(), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})[0]
+		if word != "true" && word != "false" {
+			l.errorf("expected value")
+			return false
+		}
+		l.Pos += len(word)
+		l.emit(TokenBoolean)
+	default:
+		l.errorf("expected value")
+		return false
+	}
+	return true
+}
+
+// lexString lexes a double quoted string with escape sequences or a back quoted raw string. The token includes the quotes.
+func lexString(l *Lexer) bool {
+	quote := l.next()
+	for {
+		switch r := l.next(); {
+		case r == quote:
+			l.emit(TokenString)
+			return true
+		case r == '\\' && quote == '"':
+			if l.next() == eof {
+				return false
+			}
+		case r == eof || r == '\n':
+			return false
+		}
+	}
+}
+
+// lexNumber lexes an optionally signed integer or floating point number.
+func lexNumber(l *Lexer) bool {
+	l.accept("+-")
+	digits := "0123456789"
+	l.acceptRun(digits)
+	if l.accept(".") {
+		l.acceptRun(digits)
+	}
+	if l.accept("eE") {
+		l.accept("+-")
+		l.acceptRun(digits)
+	}
+
+	// the number must be followed by whitespace, a comma or the end of the constraints or enum
+	if r := l.peek(); r != ',' && r != ')' && r != '}' && !unicode.IsSpace(r) {
+		return false
+	}
+	l.emit(TokenNumber)
+	return true
+}
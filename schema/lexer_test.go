@@ -494,6 +494,9 @@ func TestLoop(t *testing.T) {
     // var start = time.Now()
     l.run()
     // fmt.Println(time.Now().Sub(start).Seconds())
+
+    // the whole type was lexed
+    assert.Equal(t, TokenCloseCurlyBracket, token.Type)
 }
 
 func TestComplexFile(t *testing.T) {
@@ -611,3 +614,300 @@ func BenchmarkMultiFieldStmt(b *testing.B) {
         l.run()
     }
 }
+
+func TestConstraints(t *testing.T) {
+
+    text := `required string username (minlen 3, pattern "^[a-z]+$", max -1.5e3)`
+    var tokens []Token
+    l := NewLexer("TestConstraints", text, func(t Token) {
+        tokens = append(tokens, t)
+    })
+
+    // lex content
+    l.run()
+
+    // there should be 13 tokens
+    assert.Equal(t, 13, len(tokens))
+
+    expected := []Token{
+        {TokenRequired, "required", 1},
+        {TokenValueType, "string", 1},
+        {TokenIdentifier, "username", 1},
+        {TokenOpenParen, "(", 1},
+        {TokenIdentifier, "minlen", 1},
+        {TokenNumber, "3", 1},
+        {TokenComma, ",", 1},
+        {TokenIdentifier, "pattern", 1},
+        {TokenString, `"^[a-z]+$"`, 1},
+        {TokenComma, ",", 1},
+        {TokenIdentifier, "max", 1},
+        {TokenNumber, "-1.5e3", 1},
+        {TokenCloseParen, ")", 1},
+    }
+    for i, tok := range expected {
+        if i < len(tokens) {
+            assert.Equal(t, tok.Type, tokens[i].Type)
+            assert.Equal(t, tok.Value, tokens[i].Value)
+        }
+    }
+}
+
+func TestConstraintsFail(t *testing.T) {
+
+    texts := []string{
+        `required string username (3)`,
+        `required string username (minlen)`,
+        `required string username (minlen 3x)`,
+        `required string username (pattern "abc)`,
+        `required string username (minlen 3 maxlen 4)`,
+    }
+
+    for _, text := range texts {
+        var tokens []Token
+        l := NewLexer("TestConstraintsFail", text, func(t Token) {
+            tokens = append(tokens, t)
+        })
+
+        // lex content
+        l.run()
+
+        // the last token should be an error
+        if assert.NotEqual(t, 0, len(tokens)) {
+            assert.Equal(t, TokenError, tokens[len(tokens)-1].Type, text)
+        }
+    }
+}
+
+func TestDefaultValue(t *testing.T) {
+
+    texts := map[string]Token{
+        `optional uint8 age = 18`:                             {TokenNumber, "18", 1},
+        `optional string name = "anonymous"`:                  {TokenString, `"anonymous"`, 1},
+        `optional bool active = true`:                         {TokenBoolean, "true", 1},
+        `optional bool active=false (min 1)`:                  {TokenBoolean, "false", 1},
+        `optional timestamp created = "2015-01-01T00:00:00Z"`: {TokenString, `"2015-01-01T00:00:00Z"`, 1},
+    }
+
+    for text, expected := range texts {
+        var tokens []Token
+        l := NewLexer("TestDefaultValue", text, func(t Token) {
+            tokens = append(tokens, t)
+        })
+
+        // lex content
+        l.run()
+
+        // expecting equals token followed by the value
+        if assert.True(t, len(tokens) >= 5, text) {
+            assert.Equal(t, TokenEquals, tokens[3].Type)
+            assert.Equal(t, "=", tokens[3].Value)
+            assert.Equal(t, expected.Type, tokens[4].Type)
+            assert.Equal(t, expected.Value, tokens[4].Value)
+        }
+    }
+}
+
+func TestDefaultValueFail(t *testing.T) {
+
+    texts := []string{
+        `optional uint8 age =`,
+        `optional uint8 age = eighteen`,
+        `optional string name = "anonymous`,
+    }
+
+    for _, text := range texts {
+        var tokens []Token
+        l := NewLexer("TestDefaultValueFail", text, func(t Token) {
+            tokens = append(tokens, t)
+        })
+
+        // lex content
+        l.run()
+
+        // the last token should be an error
+        if assert.NotEqual(t, 0, len(tokens)) {
+            assert.Equal(t, TokenError, tokens[len(tokens)-1].Type, text)
+        }
+    }
+}
+
+func TestEnum(t *testing.T) {
+
+    text := `enum Status {
+        ACTIVE = 1, // comment
+        DISABLED_2 = 2
+    }`
+    var tokens []Token
+    l := NewLexer("TestEnum", text, func(t Token) {
+        tokens = append(tokens, t)
+    })
+
+    // lex content
+    l.run()
+
+    // there should be 12 tokens
+    assert.Equal(t, 12, len(tokens))
+
+    expected := []Token{
+        {TokenEnum, "enum", 1},
+        {TokenIdentifier, "Status", 1},
+        {TokenOpenCurlyBracket, "{", 1},
+        {TokenIdentifier, "ACTIVE", 2},
+        {TokenEquals, "=", 2},
+        {TokenNumber, "1", 2},
+        {TokenComma, ",", 2},
+        {TokenComment, "// comment", 2},
+        {TokenIdentifier, "DISABLED_2", 3},
+        {TokenEquals, "=", 3},
+        {TokenNumber, "2", 3},
+        {TokenCloseCurlyBracket, "}", 4},
+    }
+    for i, tok := range expected {
+        if i < len(tokens) {
+            assert.Equal(t, tok.Type, tokens[i].Type)
+            assert.Equal(t, tok.Value, tokens[i].Value)
+        }
+    }
+}
+
+func TestOpenEnum(t *testing.T) {
+
+    text := `open enum Status { ACTIVE = 1 }`
+    var tokens []Token
+    l := NewLexer("TestOpenEnum", text, func(t Token) {
+        tokens = append(tokens, t)
+    })
+
+    // lex content
+    l.run()
+
+    expected := []Token{
+        {TokenOpen, "open", 1},
+        {TokenEnum, "enum", 1},
+        {TokenIdentifier, "Status", 1},
+        {TokenOpenCurlyBracket, "{", 1},
+        {TokenIdentifier, "ACTIVE", 1},
+        {TokenEquals, "=", 1},
+        {TokenNumber, "1", 1},
+        {TokenCloseCurlyBracket, "}", 1},
+    }
+    if assert.Equal(t, len(expected), len(tokens)) {
+        for i, tok := range expected {
+            assert.Equal(t, tok.Type, tokens[i].Type)
+            assert.Equal(t, tok.Value, tokens[i].Value)
+        }
+    }
+}
+
+func TestEnumFail(t *testing.T) {
+
+    texts := []string{
+        `enum {`,
+        `enum Status`,
+        `enum Status { ACTIVE }`,
+        `enum Status { ACTIVE = one }`,
+        `enum Status { ACTIVE = 1`,
+        `open Status { ACTIVE = 1 }`,
+    }
+
+    for _, text := range texts {
+        var tokens []Token
+        l := NewLexer("TestEnumFail", text, func(t Token) {
+            tokens = append(tokens, t)
+        })
+
+        // lex content
+        l.run()
+
+        // the last token should be an error
+        if assert.NotEqual(t, 0, len(tokens)) {
+            assert.Equal(t, TokenError, tokens[len(tokens)-1].Type, text)
+        }
+    }
+}
+
+func TestMapType(t *testing.T) {
+
+    text := `optional map< string , uint32> counts, totals`
+    var tokens []Token
+    l := NewLexer("TestMapType", text, func(t Token) {
+        tokens = append(tokens, t)
+    })
+
+    // lex content
+    l.run()
+
+    expected := []Token{
+        {TokenOptional, "optional", 1},
+        {TokenValueType, "map", 1},
+        {TokenOpenAngleBracket, "<", 1},
+        {TokenValueType, "string", 1},
+        {TokenComma, ",", 1},
+        {TokenValueType, "uint32", 1},
+        {TokenCloseAngleBracket, ">", 1},
+        {TokenIdentifier, "counts", 1},
+        {TokenComma, ",", 1},
+        {TokenIdentifier, "totals", 1},
+    }
+    if assert.True(t, len(tokens) >= len(expected)) {
+        for i, tok := range expected {
+            assert.Equal(t, tok.Type, tokens[i].Type)
+            assert.Equal(t, tok.Value, tokens[i].Value)
+        }
+    }
+}
+
+func TestMapTypeFail(t *testing.T) {
+
+    texts := []string{
+        `optional map<> counts`,
+        `optional map<string> counts`,
+        `optional map<string, > counts`,
+        `optional map<string, uint32 counts`,
+    }
+
+    for _, text := range texts {
+        var tokens []Token
+        l := NewLexer("TestMapTypeFail", text, func(t Token) {
+            tokens = append(tokens, t)
+        })
+
+        // lex content
+        l.run()
+
+        // the last token should be an error
+        if assert.NotEqual(t, 0, len(tokens)) {
+            assert.Equal(t, TokenError, tokens[len(tokens)-1].Type, text)
+        }
+    }
+}
+
+func TestOneOf(t *testing.T) {
+
+    text := `oneof location {
+        optional Address address
+    }`
+    var tokens []Token
+    l := NewLexer("TestOneOf", text, func(t Token) {
+        tokens = append(tokens, t)
+    })
+
+    // lex content
+    l.run()
+
+    expected := []Token{
+        {TokenOneOf, "oneof", 1},
+        {TokenIdentifier, "location", 1},
+        {TokenOpenCurlyBracket, "{", 1},
+        {TokenOptional, "optional", 2},
+        {TokenValueType, "Address", 2},
+        {TokenIdentifier, "address", 2},
+        {TokenCloseCurlyBracket, "}", 3},
+    }
+    if assert.True(t, len(tokens) >= len(expected)) {
+        for i, tok := range expected {
+            assert.Equal(t, tok.Type, tokens[i].Type)
+            assert.Equal(t, tok.Value, tokens[i].Value)
+        }
+    }
+}
@@ -49,7 +49,7 @@ func LoadDirectory(dir string, parser Parser) (err error) {
                 case true:
 
                     // return error if there is one
-                    if err := LoadDirectory(fi.Name(), parser); err != nil {
+                    if err := LoadDirectory(filepath.Join(dir, fi.Name()), parser); err != nil {
                         return err
                     }
                 case false:
@@ -123,12 +123,17 @@ func (p *parser) Parse(name string, text string) (pkg Package, err error) {
     defer p.lock.Unlock()
     p.name = name
 
+    // reset state from previous documents
+    p.tokens = p.tokens[:0]
+    p.pos = 0
+
     l := NewLexer(name, text, func(tok Token) {
         p.tokens = append(p.tokens, tok)
     })
     l.run()
 
     pkg, err = p.parsePackage()
+    pkg.File = name
 
     // if no error, add to package list
     if err == nil {
@@ -143,7 +148,7 @@ func (p *parser) advance(skip int) {
 
 func (p *parser) current() (tok Token) {
     if p.pos >= len(p.tokens) {
-        tok = Token{TokenError, "end of input"}
+        tok = Token{Type: TokenError, Value: "end of input"}
     } else {
         tok = p.tokens[p.pos]
         if tok.Type == TokenComment {
@@ -278,7 +283,17 @@ func (p *parser) parseImports(pkg *Package) (err error) {
 func (p *parser) parseTypes(pkg *Package) (err error) {
 
     // iterate over type defs
-    for p.current().Type == TokenTypeDef {
+    for {
+
+        // enums can be declared before, after or between types
+        if tokType := p.current().Type; tokType == TokenEnum || tokType == TokenOpen {
+            if err := p.parseEnum(pkg); err != nil {
+                return err
+            }
+            continue
+        } else if tokType != TokenTypeDef {
+            break
+        }
 
         var t Type
 
@@ -295,6 +310,7 @@ func (p *parser) parseTypes(pkg *Package) (err error) {
 
         // set type name
         t.Name = tok.Value
+        t.Line = tok.Line
 
         // consume open scope
         if _, err := p.typeCheck(TokenOpenCurlyBracket, "expected open bracket"); err != nil {
@@ -318,6 +334,95 @@ func (p *parser) parseTypes(pkg *Package) (err error) {
     return nil
 }
 
+func (p *parser) parseMapTypes(field *Field) (err error) {
+    if field.Type != "map" {
+        return SyntaxError{"only map fields have key and value types, not '" + field.Type + "'"}
+    }
+    p.advance(1)
+
+    tok, err := p.typeCheck(TokenValueType, "expected map key type")
+    if err != nil {
+        return err
+    }
+    field.KeyType = tok.Value
+
+    if _, err = p.typeCheck(TokenComma, "expected comma"); err != nil {
+        return err
+    }
+
+    tok, err = p.typeCheck(TokenValueType, "expected map value type")
+    if err != nil {
+        return err
+    }
+    field.ValueType = tok.Value
+
+    _, err = p.typeCheck(TokenCloseAngleBracket, "expected close angle bracket")
+    return err
+}
+
+func (p *parser) parseEnum(pkg *Package) (err error) {
+    var e Enum
+
+    // consume optional 'open' keyword
+    if p.current().Type == TokenOpen {
+        e.IsOpen = true
+        p.advance(1)
+    }
+
+    // consume 'enum' keyword
+    if _, err := p.typeCheck(TokenEnum, "expected 'enum' keyword"); err != nil {
+        return err
+    }
+
+    // consume enum name
+    tok, err := p.typeCheck(TokenIdentifier, "expected enum name")
+    if err != nil {
+        return err
+    }
+    e.Name = tok.Value
+    e.Line = tok.Line
+
+    // consume open scope
+    if _, err := p.typeCheck(TokenOpenCurlyBracket, "expected open bracket"); err != nil {
+        return err
+    }
+
+    // parse values, commas are optional
+    for {
+        tok = p.next()
+        switch tok.Type {
+        case TokenComma:
+            continue
+        case TokenCloseCurlyBracket:
+            pkg.Enums = append(pkg.Enums, e)
+            return nil
+        case TokenIdentifier:
+        case TokenError:
+            return SyntaxError{p.name + ": " + tok.Value}
+        default:
+            return SyntaxError{p.name + ": expected enum value name"}
+        }
+        value := EnumValue{Name: tok.Value, Line: tok.Line}
+
+        // consume equals
+        if _, err := p.typeCheck(TokenEquals, "expected '=' after enum value name"); err != nil {
+            return err
+        }
+
+        // consume value
+        tok, err = p.typeCheck(TokenNumber, "expected enum value")
+        if err != nil {
+            return err
+        }
+        num, err := strconv.ParseUint(tok.Value, 10, 32)
+        if err != nil {
+            return SyntaxError{p.name + ": invalid enum value " + tok.Value}
+        }
+        value.Value = uint32(num)
+        e.Values = append(e.Values, value)
+    }
+}
+
 func (p *parser) parseVersions(pkg *Package, t *Type) (err error) {
 
     // iterate over versions
@@ -343,6 +448,7 @@ func (p *parser) parseVersions(pkg *Package, t *Type) (err error) {
 
         // set version num
         ver.Number = num
+        ver.Line = tok.Line
 
         // consume open scope
         if _, err := p.typeCheck(TokenOpenCurlyBracket, "expected open bracket"); err != nil {
@@ -357,6 +463,10 @@ func (p *parser) parseVersions(pkg *Package, t *Type) (err error) {
                 if err = p.parseField(pkg, &ver); err != nil {
                     return err
                 }
+            case TokenOneOf:
+                if err = p.parseOneOf(pkg, &ver); err != nil {
+                    return err
+                }
             default:
                 break OUTER
             }
@@ -373,6 +483,51 @@ func (p *parser) parseVersions(pkg *Package, t *Type) (err error) {
     return nil
 }
 
+// parseOneOf parses a oneof group and adds its fields to the version.
+func (p *parser) parseOneOf(pkg *Package, ver *Version) (err error) {
+
+    // consume 'oneof' keyword
+    if _, err = p.typeCheck(TokenOneOf, "expected 'oneof' keyword"); err != nil {
+        return err
+    }
+
+    // consume group name
+    tok, err := p.typeCheck(TokenIdentifier, "expected oneof name")
+    if err != nil {
+        return err
+    }
+    group := OneOf{Name: tok.Value, Line: tok.Line}
+
+    // consume open scope
+    if _, err = p.typeCheck(TokenOpenCurlyBracket, "expected open bracket"); err != nil {
+        return err
+    }
+
+    // parse fields
+    first := len(ver.Fields)
+    for p.current().Type == TokenRequired || p.current().Type == TokenOptional {
+        if err = p.parseField(pkg, ver); err != nil {
+            return err
+        }
+    }
+
+    // consume close scope
+    if _, err = p.typeCheck(TokenCloseCurlyBracket, "expected close bracket"); err != nil {
+        return err
+    }
+
+    if first == len(ver.Fields) {
+        return SyntaxError{p.name + ": oneof '" + group.Name + "' does not contain any fields"}
+    }
+    for i := first; i < len(ver.Fields); i++ {
+        ver.Fields[i].OneOf = group.Name
+        group.Fields = append(group.Fields, ver.Fields[i].Name)
+    }
+
+    ver.OneOfs = append(ver.OneOfs, group)
+    return nil
+}
+
 func (p *parser) parseField(pkg *Package, ver *Version) (err error) {
 
     var field Field
@@ -406,48 +561,22 @@ func (p *parser) parseField(pkg *Package, ver *Version) (err error) {
         return SyntaxError{"expected field type, not '" + p.current().Value + "'"}
     }
 
-    typeName := p.current().Value
-    var found bool
-    for _, t := range TypeNames {
-        if typeName == t {
-            field.Type = typeName
-            found = true
-            break
-        }
-    }
+    // Type names are resolved when the package is compiled which allows
+    // references to the current type and types declared later in the package.
+    field.Type = p.current().Value
 
-    // eval import stmts
-    if !found {
-    OUTER:
-        for _, imp := range pkg.Imports {
-            for _, typ := range imp.TypeNames {
-                if typ == typeName {
-                    found = true
-                    field.Type = typeName
-                    break OUTER
-                }
-            }
-        }
-    }
+    // Skip type name
+    p.advance(1)
 
-    // eval previous package types
-    for _, typ := range pkg.Types {
-        if typ.Name == typeName {
-            found = true
-            field.Type = typeName
-            break
+    // Consume map key and value types
+    if p.current().Type == TokenOpenAngleBracket {
+        if err = p.parseMapTypes(&field); err != nil {
+            return err
         }
     }
 
-    // If type has still not been found, return error
-    if !found {
-        return SyntaxError{"unknown type '" + typeName + "'"}
-    }
-
-    // Skip type name
-    p.advance(1)
-
     // Consume field names
+    var fields []Field
     for {
 
         // Perform type check
@@ -458,9 +587,8 @@ func (p *parser) parseField(pkg *Package, ver *Version) (err error) {
 
         // Set field name
         field.Name = tok.Value
-
-        // Add field to version
-        ver.Fields = append(ver.Fields, field)
+        field.Line = tok.Line
+        fields = append(fields, field)
 
         // Determine if next token is a comma
         tok, err = p.typeCheck(TokenComma, "")
@@ -474,5 +602,93 @@ func (p *parser) parseField(pkg *Package, ver *Version) (err error) {
     }
     p.backup()
 
+    // The default value applies to all the field names
+    if p.current().Type == TokenEquals {
+        p.next()
+        literal, err := p.parseLiteral()
+        if err != nil {
+            return err
+        }
+        for i := range fields {
+            fields[i].Default = &literal
+        }
+    }
+
+    // Constraints apply to all the field names
+    if p.current().Type == TokenOpenParen {
+        constraints, err := p.parseConstraints()
+        if err != nil {
+            return err
+        }
+        for i := range fields {
+            fields[i].Constraints = constraints
+        }
+    }
+
+    // Add fields to version
+    ver.Fields = append(ver.Fields, fields...)
     return nil
 }
+
+func (p *parser) parseConstraints() (constraints []Constraint, err error) {
+
+    // consume open paren
+    if _, err = p.typeCheck(TokenOpenParen, "expected open paren"); err != nil {
+        return
+    }
+
+    for {
+        var c Constraint
+
+        // consume constraint name
+        tok, err := p.typeCheck(TokenIdentifier, "expected constraint name")
+        if err != nil {
+            return nil, err
+        }
+        c.Name = tok.Value
+        c.Line = tok.Line
+
+        // consume constraint value
+        if p.current().Type != TokenNumber && p.current().Type != TokenString && p.current().Type != TokenError {
+            return nil, SyntaxError{p.name + ": expected value for constraint '" + c.Name + "'"}
+        }
+        literal, err := p.parseLiteral()
+        if err != nil {
+            return nil, err
+        }
+        c.Value = literal.Value
+        c.IsString = literal.IsString
+        constraints = append(constraints, c)
+
+        // consume comma or close paren
+        tok = p.next()
+        switch tok.Type {
+        case TokenComma:
+        case TokenCloseParen:
+            return constraints, nil
+        case TokenError:
+            return nil, SyntaxError{p.name + ": " + tok.Value}
+        default:
+            return nil, SyntaxError{p.name + ": expected close paren"}
+        }
+    }
+}
+
+func (p *parser) parseLiteral() (literal Literal, err error) {
+    tok := p.next()
+    literal.Line = tok.Line
+    switch tok.Type {
+    case TokenNumber, TokenBoolean:
+        literal.Value = tok.Value
+    case TokenString:
+        literal.IsString = true
+        if literal.Value, err = strconv.Unquote(tok.Value); err != nil {
+            return literal, SyntaxError{p.name + ": invalid string " + tok.Value}
+        }
+    case TokenError:
+        return literal, SyntaxError{p.name + ": " + tok.Value}
+    default:
+        return literal, SyntaxError{p.name + ": expected value"}
+    }
+    return literal, nil
+}
@@ -128,3 +128,240 @@ func BenchmarkParse(b *testing.B) {
     // t.Logf("%#v\n", pkg)
     // t.Log(err)
 }
+
+func TestParseConstraints(t *testing.T) {
+
+    text := `
+    package users
+
+    type User {
+        version 1 {
+            required string uuid, username (minlen 3, pattern "^[a-z]+$")
+            optional uint8 age (max 150)
+            optional []string emails
+        }
+    }
+    `
+
+    pkgList := NewPackageList()
+    pkg, err := NewParser(pkgList).Parse("TestParseConstraints", text)
+    assert.Nil(t, err)
+
+    fields := pkg.Types[0].Versions[0].Fields
+    assert.Equal(t, 4, len(fields))
+
+    // the constraints apply to every field name
+    expected := []Constraint{
+        {Name: "minlen", Value: "3", Line: 6},
+        {Name: "pattern", Value: "^[a-z]+$", IsString: true, Line: 6},
+    }
+    assert.Equal(t, "uuid", fields[0].Name)
+    assert.Equal(t, expected, fields[0].Constraints)
+    assert.Equal(t, "username", fields[1].Name)
+    assert.Equal(t, expected, fields[1].Constraints)
+
+    assert.Equal(t, "age", fields[2].Name)
+    assert.Equal(t, []Constraint{{Name: "max", Value: "150", Line: 7}}, fields[2].Constraints)
+
+    assert.Equal(t, "emails", fields[3].Name)
+    assert.Nil(t, fields[3].Constraints)
+}
+
+func TestParseConstraintsFail(t *testing.T) {
+
+    text := `
+    package users
+
+    type User {
+        version 1 {
+            required string username (minlen 3,)
+        }
+    }
+    `
+
+    pkgList := NewPackageList()
+    _, err := NewParser(pkgList).Parse("TestParseConstraintsFail", text)
+    assert.NotNil(t, err)
+}
+
+func TestParseDefault(t *testing.T) {
+
+    text := `
+    package users
+
+    type User {
+        version 1 {
+            optional uint8 age, rank = 18 (min 13)
+            optional string nickname = "anonymous"
+            optional bool active = true
+            optional string email
+        }
+    }
+    `
+
+    pkgList := NewPackageList()
+    pkg, err := NewParser(pkgList).Parse("TestParseDefault", text)
+    assert.Nil(t, err)
+
+    fields := pkg.Types[0].Versions[0].Fields
+    assert.Equal(t, 5, len(fields))
+
+    // the default value and constraints apply to every field name
+    assert.Equal(t, &Literal{Value: "18", Line: 6}, fields[0].Default)
+    assert.Equal(t, &Literal{Value: "18", Line: 6}, fields[1].Default)
+    assert.Equal(t, []Constraint{{Name: "min", Value: "13", Line: 6}}, fields[1].Constraints)
+
+    assert.Equal(t, &Literal{Value: "anonymous", IsString: true, Line: 7}, fields[2].Default)
+    assert.Equal(t, &Literal{Value: "true", Line: 8}, fields[3].Default)
+    assert.Nil(t, fields[4].Default)
+}
+
+func TestParseEnum(t *testing.T) {
+
+    text := `
+    package users
+
+    enum Status {
+        ACTIVE = 1,
+        DISABLED = 2
+    }
+
+    type User {
+        version 1 {
+            optional Status status = "ACTIVE"
+        }
+    }
+
+    // commas are optional
+    open enum Role {
+        ADMIN = 1
+        GUEST = 4294967295
+    }
+    `
+
+    pkgList := NewPackageList()
+    pkg, err := NewParser(pkgList).Parse("TestParseEnum", text)
+    assert.Nil(t, err)
+
+    assert.Equal(t, []Enum{
+        {Name: "Status", Line: 4, Values: []EnumValue{{"ACTIVE", 1, 5}, {"DISABLED", 2, 6}}},
+        {Name: "Role", Line: 16, IsOpen: true, Values: []EnumValue{{"ADMIN", 1, 17}, {"GUEST", 4294967295, 18}}},
+    }, pkg.Enums)
+
+    field := pkg.Types[0].Versions[0].Fields[0]
+    assert.Equal(t, "Status", field.Type)
+    assert.Equal(t, &Literal{Value: "ACTIVE", IsString: true, Line: 11}, field.Default)
+}
+
+func TestParseEnumFail(t *testing.T) {
+
+    texts := []string{
+        `package users
+        enum Status { ACTIVE = 4294967296 }`,
+        `package users
+        enum Status { ACTIVE = -1 }`,
+        `package users
+        enum Status { ACTIVE = 1.5 }`,
+    }
+
+    for _, text := range texts {
+        pkgList := NewPackageList()
+        _, err := NewParser(pkgList).Parse("TestParseEnumFail", text)
+        assert.NotNil(t, err, text)
+    }
+}
+
+func TestParseMap(t *testing.T) {
+
+    text := `
+    package users
+
+    type User {
+        version 1 {
+            optional map<string, uint32> counts (maxitems 10)
+            required string name
+        }
+    }
+    `
+
+    pkgList := NewPackageList()
+    pkg, err := NewParser(pkgList).Parse("TestParseMap", text)
+    assert.Nil(t, err)
+
+    fields := pkg.Types[0].Versions[0].Fields
+    assert.Equal(t, 2, len(fields))
+    assert.Equal(t, "map", fields[0].Type)
+    assert.Equal(t, "string", fields[0].KeyType)
+    assert.Equal(t, "uint32", fields[0].ValueType)
+    assert.Equal(t, []Constraint{{Name: "maxitems", Value: "10", Line: 6}}, fields[0].Constraints)
+    assert.Equal(t, "", fields[1].KeyType)
+}
+
+func TestParseMapFail(t *testing.T) {
+
+    text := `
+    package users
+
+    type User {
+        version 1 {
+            optional list<string, uint32> counts
+        }
+    }
+    `
+
+    pkgList := NewPackageList()
+    _, err := NewParser(pkgList).Parse("TestParseMapFail", text)
+    assert.NotNil(t, err)
+}
+
+func TestParseOneOf(t *testing.T) {
+
+    text := `
+    package places
+
+    type Place {
+        version 1 {
+            required string name
+            oneof location {
+                optional Address address
+                optional Coordinates coordinates
+            }
+            optional string code
+        }
+    }
+    `
+
+    pkgList := NewPackageList()
+    pkg, err := NewParser(pkgList).Parse("TestParseOneOf", text)
+    assert.Nil(t, err)
+
+    version := pkg.Types[0].Versions[0]
+    assert.Equal(t, []OneOf{{Name: "location", Line: 7, Fields: []string{"address", "coordinates"}}}, version.OneOfs)
+
+    // fields of the group are kept in declaration order
+    assert.Equal(t, 4, len(version.Fields))
+    assert.Equal(t, "", version.Fields[0].OneOf)
+    assert.Equal(t, "location", version.Fields[1].OneOf)
+    assert.Equal(t, "location", version.Fields[2].OneOf)
+    assert.Equal(t, "", version.Fields[3].OneOf)
+}
+
+func TestParseOneOfFail(t *testing.T) {
+
+    texts := []string{
+        `package places
+        type Place { version 1 { oneof location { } } }`,
+        `package places
+        type Place { version 1 { oneof { optional string code } } }`,
+        `package places
+        type Place { version 1 { oneof location optional string code } }`,
+        `package places
+        type Place { version 1 { oneof location { optional string code } }`,
+    }
+
+    for _, text := range texts {
+        pkgList := NewPackageList()
+        _, err := NewParser(pkgList).Parse("TestParseOneOfFail", text)
+        assert.NotNil(t, err, text)
+    }
+}
@@ -0,0 +1,233 @@
+package namedtuple
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryTypes(t *testing.T) {
+	reg := createTestOneOfRegistry()
+	reg.Register(createTestMapType())
+
+	types := reg.Types()
+	assert.Equal(t, 4, len(types))
+	for i, name := range []string{"address", "coordinates", "inventory", "place"} {
+		assert.Equal(t, name, types[i].Name)
+	}
+}
+
+func TestSchemaRoundTrip(t *testing.T) {
+	Place, Address, Coordinates := createTestOneOfTypes()
+	types := []TupleType{
+		Place, Address, Coordinates,
+		createTestEnumType(true),
+		createTestConstrainedType(),
+		createTestDefaultType(),
+		createTestMapType(),
+		createTestVarIntType(),
+	}
+
+	for _, tupleType := range types {
+		schema, err := encodeType(tupleType)
+		assert.Nil(t, err)
+
+		decoded, err := decodeType(schema)
+		assert.Nil(t, err)
+		assert.Equal(t, tupleType.ID, decoded.ID)
+		assert.Equal(t, tupleType.NumVersions(), decoded.NumVersions())
+		assert.Equal(t, tupleType.Fingerprint(tupleType.NumVersions()), decoded.Fingerprint(decoded.NumVersions()))
+
+		for i, version := range tupleType.Versions() {
+			for j, field := range version.Fields {
+				other := decoded.Versions()[i].Fields[j]
+				assert.Equal(t, field.Name, other.Name)
+				assert.Equal(t, field.Type, other.Type)
+				assert.Equal(t, field.Required, other.Required)
+				assert.Equal(t, field.OneOf, other.OneOf)
+				assert.Equal(t, field.KeyType, other.KeyType)
+				assert.Equal(t, field.ValueType, other.ValueType)
+				assert.Equal(t, field.Encoding, other.Encoding)
+			}
+		}
+	}
+
+	// enums keep their values and defaults
+	schema, _ := encodeType(createTestEnumType(true))
+	Account, err := decodeType(schema)
+	assert.Nil(t, err)
+	previous, _, _ := Account.field("previous")
+	assert.Equal(t, "ACTIVE", previous.Default)
+	assert.True(t, previous.Enum.Open)
+	assert.Equal(t, createTestStatusEnum(true).Values(), previous.Enum.Values())
+
+	// defaults keep their type
+	schema, _ = encodeType(createTestDefaultType())
+	Defaults, err := decodeType(schema)
+	assert.Nil(t, err)
+	age, _, _ := Defaults.field("age")
+	assert.Equal(t, uint8(18), age.Default)
+	created, _, _ := Defaults.field("created")
+	assert.True(t, time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC).Equal(created.Default.(time.Time)))
+
+	// constraints are kept
+	schema, _ = encodeType(createTestConstrainedType())
+	User, err := decodeType(schema)
+	assert.Nil(t, err)
+	assert.True(t, User.constrained)
+	username, _, _ := User.field("username")
+	assert.Equal(t, 3, *username.Constraints.MinLength)
+	assert.Equal(t, "^[a-z]+$", username.Constraints.Pattern.String())
+	assert.Nil(t, username.Constraints.Min)
+	scores, _, _ := User.field("scores")
+	assert.Equal(t, float64(1), *scores.Constraints.Max)
+}
+
+func TestSchemaInvalidHash(t *testing.T) {
+	User := createTestTupleType()
+	schema, err := encodeType(User)
+	assert.Nil(t, err)
+
+	// a stored hash which does not match the name is rejected
+	builder := NewBuilder(schemaTupleType, make([]byte, 1024))
+	builder.PutString("namespace", User.Namespace)
+	builder.PutString("name", User.Name)
+	builder.PutUint32("namespace_hash", User.NamespaceHash)
+	builder.PutUint32("hash", User.Hash+1)
+	versions, _ := schema.GetTupleArray("versions", &schemaRegistry)
+	var fields []Tuple
+	for versions.Next() {
+		fields = append(fields, versions.Tuple())
+	}
+	builder.PutTupleArray("versions", fields)
+	invalid, err := builder.Build()
+	assert.Nil(t, err)
+
+	_, err = decodeType(invalid)
+	assert.Equal(t, ErrInvalidSchema, err)
+
+	// tuples of other types are not schemas
+	_, err = decodeType(fields[0])
+	assert.Equal(t, ErrInvalidSchema, err)
+}
+
+func TestRegistrySnapshot(t *testing.T) {
+	reg := createTestOneOfRegistry()
+
+	var buffer bytes.Buffer
+	assert.Nil(t, writeRegistry(&buffer, &reg))
+
+	snapshot, err := readRegistry(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, reg.Size(), snapshot.Size())
+	for _, tupleType := range reg.Types() {
+		other, exists := snapshot.Get(tupleType.Namespace, tupleType.Name)
+		assert.True(t, exists)
+		assert.Equal(t, tupleType.Fingerprint(tupleType.NumVersions()), other.Fingerprint(other.NumVersions()))
+	}
+
+	// the encoding is stable
+	var again bytes.Buffer
+	assert.Nil(t, writeRegistry(&again, snapshot))
+	assert.Equal(t, buffer.Bytes(), again.Bytes())
+
+	// truncated snapshots are rejected
+	_, err = readRegistry(buffer.Bytes()[:buffer.Len()-1])
+	assert.NotNil(t, err)
+}
+
+func createTestSchemaRegistry() Registry {
+	reg := createTestOneOfRegistry()
+	reg.Register(createTestEnumType(false))
+	reg.Register(createTestMapType())
+	reg.Register(createTestDefaultType())
+	return reg
+}
+
+func TestRegistryWriteTo(t *testing.T) {
+	reg := createTestSchemaRegistry()
+
+	var buffer bytes.Buffer
+	n, err := reg.WriteTo(&buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(buffer.Len()), n)
+
+	loaded, err := ReadRegistry(bytes.NewReader(buffer.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, reg.Size(), loaded.Size())
+
+	// the loaded registry writes the same snapshot
+	var again bytes.Buffer
+	_, err = loaded.WriteTo(&again)
+	assert.Nil(t, err)
+	assert.Equal(t, buffer.Bytes(), again.Bytes())
+
+	// tuples can be read with the loaded types
+	Inventory := createTestMapType()
+	builder := NewBuilder(Inventory, make([]byte, 256))
+	builder.PutMap("counts", map[string]uint32{"apples": 3})
+	inventory, err := builder.Build()
+	assert.Nil(t, err)
+
+	var out bytes.Buffer
+	encoder, _ := NewEncoderVersion(&out, ProtocolVersionTwo)
+	assert.Nil(t, encoder.Encode(inventory))
+	decoded, _, err := DecodeBytes(loaded, out.Bytes())
+	assert.Nil(t, err)
+	counts, err := decoded.GetMap("counts")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), counts["apples"])
+
+	// an empty snapshot
+	empty := NewRegistry()
+	buffer.Reset()
+	n, err = empty.WriteTo(&buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), n)
+	loaded, err = ReadRegistry(&buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, loaded.Size())
+}
+
+func TestRegistryJSON(t *testing.T) {
+	reg := createTestSchemaRegistry()
+
+	var buffer bytes.Buffer
+	assert.Nil(t, reg.WriteJSON(&buffer))
+	text := buffer.String()
+	assert.Contains(t, text, `"namespace": "testing"`)
+	assert.Contains(t, text, `"type": "MapField"`)
+	assert.Contains(t, text, `"key_type": "StringField"`)
+	assert.Contains(t, text, `"default": "ACTIVE"`)
+
+	loaded, err := ReadRegistryJSON(bytes.NewReader(buffer.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, reg.Size(), loaded.Size())
+	for _, tupleType := range reg.Types() {
+		other, exists := loaded.Get(tupleType.Namespace, tupleType.Name)
+		assert.True(t, exists)
+		assert.Equal(t, tupleType.Fingerprint(tupleType.NumVersions()), other.Fingerprint(other.NumVersions()))
+	}
+
+	// the JSON and binary snapshots contain the same types
+	var binary, fromJSON bytes.Buffer
+	reg.WriteTo(&binary)
+	loaded.WriteTo(&fromJSON)
+	assert.Equal(t, binary.Bytes(), fromJSON.Bytes())
+
+	// hashes which do not match the names
+	Address := New("testing", "address")
+	invalid := strings.Replace(text, fmt.Sprintf(`"hash": %d`, Address.Hash), `"hash": 1`, 1)
+	assert.NotEqual(t, text, invalid)
+	_, err = ReadRegistryJSON(strings.NewReader(invalid))
+	assert.Equal(t, ErrInvalidSchema, err)
+
+	// unknown field types
+	invalid = strings.Replace(text, `"type": "MapField"`, `"type": "ListField"`, 1)
+	_, err = ReadRegistryJSON(strings.NewReader(invalid))
+	assert.NotNil(t, err)
+}
@@ -14,6 +14,11 @@ func (b *TupleBuilder) PutString(field string, value string) (wrote int, err err
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(stringSize(value)); err != nil {
+		return 0, err
+	}
+
 	size := len(value)
 	if size < math.MaxUint8 {
 
@@ -81,7 +86,116 @@ func (b *TupleBuilder) PutString(field string, value string) (wrote int, err err
 		wrote += 9 + size
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
+	b.pos += wrote
+	return
+}
+
+// GetString returns the string value for the given field. The field type must be a `StringField`, otherwise an error will be returned. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetString(field string) (string, error) {
+	pos, length, err := t.readArrayHeader(field, StringField, String8Code, 1)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(string); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return xbinary.LittleEndian.String(t.data, pos, length)
+}
+
+// PutStringArray writes an array of strings for the given field. The field type must be a `StringArrayField`, otherwise an error will be returned. The type code is written first, then the number of strings, and finally each string. Each string is written the same way as `PutString`, with its own type code and length. If the buffer does not have enough space for the entire array, an `xbinary.ErrOutOfRange` error will be returned. If successful, the number of bytes written will be returned as well as a nil error.
+func (b *TupleBuilder) PutStringArray(field string, value []string) (wrote int, err error) {
+
+	// field type should be
+	if err = b.typeCheck(field, StringArrayField); err != nil {
+		return 0, err
+	}
+
+	// calculate total size
+	var size int
+	for _, s := range value {
+		size += stringSize(s)
+	}
+
+	// write type code and length
+	wrote, err = b.putArrayHeader(StringArray8Code, len(value), size)
+	if err != nil {
+		return 0, err
+	}
+
+	// write strings
+	for _, s := range value {
+		wrote += putString(b.buffer, b.pos+wrote, s)
+	}
+
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
+
+// stringSize returns the number of bytes needed to write the string including the type code and length.
+func stringSize(value string) int {
+	size := len(value)
+	if size < math.MaxUint8 {
+		return size + 2
+	} else if size < math.MaxUint16 {
+		return size + 3
+	} else if size < math.MaxUint32 {
+		return size + 5
+	}
+	return size + 9
+}
+
+// putString writes the type code, the length and the string at the given position. The buffer must be large enough to hold the value. The number of bytes written is returned.
+func putString(buffer []byte, pos int, value string) int {
+	size := len(value)
+	if size < math.MaxUint8 {
+		buffer[pos] = byte(String8Code.OpCode)
+		buffer[pos+1] = byte(size)
+	} else if size < math.MaxUint16 {
+		buffer[pos] = byte(String16Code.OpCode)
+		xbinary.LittleEndian.PutUint16(buffer, pos+1, uint16(size))
+	} else if size < math.MaxUint32 {
+		buffer[pos] = byte(String32Code.OpCode)
+		xbinary.LittleEndian.PutUint32(buffer, pos+1, uint32(size))
+	} else {
+		buffer[pos] = byte(String64Code.OpCode)
+		xbinary.LittleEndian.PutUint64(buffer, pos+1, uint64(size))
+	}
+
+	header := stringSize(value) - size
+	copy(buffer[pos+header:], value)
+	return header + size
+}
+
+// GetStringArray returns the array of strings for the given field. The field type must be a `StringArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetStringArray(field string) ([]string, error) {
+	pos, length, err := t.readArrayLength(field, StringArrayField, StringArray8Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// each string uses at least 2 bytes
+	if length > uint64(len(t.data)-pos)/2 {
+		return nil, xbinary.ErrOutOfRange
+	}
+
+	value := make([]string, length)
+	for i := range value {
+		if value[i], pos, err = t.readString(pos); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// readString reads a string with its type code and length at the given position. The string and the position after the string are returned.
+func (t *Tuple) readString(pos int) (value string, next int, err error) {
+	b, next, err := t.readBytes(pos, String8Code)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(b), next, nil
+}
@@ -13,8 +13,8 @@ func TestPutStringFail_1(t *testing.T) {
 	// float test type
 	TestType := New("testing", "string")
 	TestType.AddVersion(
-		Field{"string", true, StringField},
-		Field{"bool", true, BooleanField},
+		Field{Name: "string", Required: true, Type: StringField},
+		Field{Name: "bool", Required: true, Type: BooleanField},
 	)
 
 	// create builder
@@ -37,8 +37,8 @@ func TestPutStringPass_1(t *testing.T) {
 	// float test type
 	TestType := New("testing", "string")
 	TestType.AddVersion(
-		Field{"string", true, StringField},
-		Field{"bool", true, BooleanField},
+		Field{Name: "string", Required: true, Type: StringField},
+		Field{Name: "bool", Required: true, Type: BooleanField},
 	)
 
 	// create builder
@@ -58,15 +58,15 @@ func TestPutStringPass_1(t *testing.T) {
 	assert.Equal(t, "namedtuple", value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["string"])
+	assert.Equal(t, 0, builder.offset("string"))
 }
 func TestPutStringFail_2(t *testing.T) {
 	// create test type
 	// float test type
 	TestType := New("testing", "string")
 	TestType.AddVersion(
-		Field{"string", true, StringField},
-		Field{"bool", true, BooleanField},
+		Field{Name: "string", Required: true, Type: StringField},
+		Field{Name: "bool", Required: true, Type: BooleanField},
 	)
 
 	// create builder
@@ -93,8 +93,8 @@ func TestPutStringPass_2(t *testing.T) {
 	// float test type
 	TestType := New("testing", "string")
 	TestType.AddVersion(
-		Field{"string", true, StringField},
-		Field{"bool", true, BooleanField},
+		Field{Name: "string", Required: true, Type: StringField},
+		Field{Name: "bool", Required: true, Type: BooleanField},
 	)
 
 	// create builder
@@ -117,7 +117,7 @@ func TestPutStringPass_2(t *testing.T) {
 	assert.Equal(t, input, output)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["string"])
+	assert.Equal(t, 0, builder.offset("string"))
 }
 
 func TestPutStringFail_3(t *testing.T) {
@@ -125,8 +125,8 @@ func TestPutStringFail_3(t *testing.T) {
 	// float test type
 	TestType := New("testing", "string")
 	TestType.AddVersion(
-		Field{"string", true, StringField},
-		Field{"bool", true, BooleanField},
+		Field{Name: "string", Required: true, Type: StringField},
+		Field{Name: "bool", Required: true, Type: BooleanField},
 	)
 
 	// create builder
@@ -153,8 +153,8 @@ func TestPutStringPass_3(t *testing.T) {
 	// float test type
 	TestType := New("testing", "string")
 	TestType.AddVersion(
-		Field{"string", true, StringField},
-		Field{"bool", true, BooleanField},
+		Field{Name: "string", Required: true, Type: StringField},
+		Field{Name: "bool", Required: true, Type: BooleanField},
 	)
 
 	// create builder
@@ -177,5 +177,128 @@ func TestPutStringPass_3(t *testing.T) {
 	assert.Equal(t, input, output)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["string"])
+	assert.Equal(t, 0, builder.offset("string"))
+}
+
+func TestTupleGetString(t *testing.T) {
+
+	// string test type
+	TestType := New("testing", "string")
+	TestType.AddVersion(
+		Field{Name: "string-8", Required: true, Type: StringField},
+		Field{Name: "string-16", Required: true, Type: StringField},
+		Field{Name: "string-32", Required: true, Type: StringField},
+	)
+
+	// create builder
+	buffer := make([]byte, 140000)
+	builder := NewBuilder(TestType, buffer)
+	builder.PutString("string-8", "namedtuple")
+	builder.PutString("string-16", string(make([]byte, 300)))
+	builder.PutString("string-32", string(make([]byte, 135000)))
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	value, err := tuple.GetString("string-8")
+	assert.Nil(t, err)
+	assert.Equal(t, "namedtuple", value)
+
+	value, err = tuple.GetString("string-16")
+	assert.Nil(t, err)
+	assert.Equal(t, 300, len(value))
+
+	value, err = tuple.GetString("string-32")
+	assert.Nil(t, err)
+	assert.Equal(t, 135000, len(value))
+}
+
+func TestPutStringArrayFail(t *testing.T) {
+	TestType := New("testing", "string")
+	TestType.AddVersion(
+		Field{Name: "strings", Required: true, Type: StringArrayField},
+		Field{Name: "string", Required: true, Type: StringField},
+	)
+
+	// create builder
+	buffer := make([]byte, 10)
+	builder := NewBuilder(TestType, buffer)
+
+	// fails type check
+	wrote, err := builder.PutStringArray("string", []string{"a"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, wrote)
+
+	// fails length check
+	wrote, err = builder.PutStringArray("strings", []string{"named", "tuple"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, wrote)
+}
+
+func TestPutStringArrayPass(t *testing.T) {
+	TestType := New("testing", "string")
+	TestType.AddVersion(
+		Field{Name: "strings", Required: true, Type: StringArrayField},
+	)
+
+	// create builder
+	buffer := make([]byte, 16)
+	builder := NewBuilder(TestType, buffer)
+
+	// successful write
+	wrote, err := builder.PutStringArray("strings", []string{"named", "tuple"})
+	assert.Nil(t, err)
+	assert.Equal(t, 16, wrote)
+
+	// test data validity
+	assert.Equal(t, StringArray8Code.OpCode, buffer[0])
+	assert.Equal(t, uint8(2), buffer[1])
+	assert.Equal(t, String8Code.OpCode, buffer[2])
+	assert.Equal(t, uint8(5), buffer[3])
+	assert.Equal(t, "named", string(buffer[4:9]))
+	assert.Equal(t, String8Code.OpCode, buffer[9])
+	assert.Equal(t, uint8(5), buffer[10])
+	assert.Equal(t, "tuple", string(buffer[11:16]))
+
+	// validate field offset
+	assert.Equal(t, 0, builder.offset("strings"))
+}
+
+func TestTupleGetStringArray(t *testing.T) {
+	TestType := New("testing", "string")
+	TestType.AddVersion(
+		Field{Name: "strings", Required: true, Type: StringArrayField},
+		Field{Name: "many", Required: true, Type: StringArrayField},
+		Field{Name: "empty", Required: true, Type: StringArrayField},
+	)
+
+	many := make([]string, 300)
+	for i := range many {
+		many[i] = string(make([]byte, i))
+	}
+
+	// create builder
+	buffer := make([]byte, 65536)
+	builder := NewBuilder(TestType, buffer)
+	builder.PutStringArray("strings", []string{"named", "", "tuple"})
+	builder.PutStringArray("many", many)
+	builder.PutStringArray("empty", []string{})
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	value, err := tuple.GetStringArray("strings")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"named", "", "tuple"}, value)
+
+	value, err = tuple.GetStringArray("many")
+	assert.Nil(t, err)
+	assert.Equal(t, many, value)
+
+	value, err = tuple.GetStringArray("empty")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, value)
+
+	// truncated tuple
+	tuple.data = tuple.data[:8]
+	_, err = tuple.GetStringArray("strings")
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
 }
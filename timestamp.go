@@ -15,6 +15,11 @@ func (b *TupleBuilder) PutTimestamp(field string, value time.Time) (wrote uint64
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(9); err != nil {
+		return 0, err
+	}
+
 	// write value
 	// length check performed by xbinary
 	wrote, err = xbinary.LittleEndian.PutInt64(b.buffer, b.pos+1, value.UnixNano())
@@ -26,7 +31,7 @@ func (b *TupleBuilder) PutTimestamp(field string, value time.Time) (wrote uint64
 	b.buffer[b.pos] = byte(TimestampCode.OpCode)
 
 	// set field offset
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 
 	// incr pos
 	b.pos += 9
@@ -42,6 +47,11 @@ func (b *TupleBuilder) PutTimestampArray(field string, times []time.Time) (wrote
 		return 0, err
 	}
 
+	// grow the buffer of a growable builder
+	if err = b.reserve(arrayHeaderSize(len(times)) + len(times)*8); err != nil {
+		return 0, err
+	}
+
 	// convert times to int64
 	var value = make([]int64, len(times))
 	for i := 0; i < len(times); i++ {
@@ -62,7 +72,7 @@ func (b *TupleBuilder) PutTimestampArray(field string, times []time.Time) (wrote
 		// write length
 		b.buffer[b.pos+1] = byte(size)
 
-		wrote += size + 2
+		wrote += size*8 + 2
 	} else if size < math.MaxUint16 {
 
 		// write length
@@ -77,7 +87,7 @@ func (b *TupleBuilder) PutTimestampArray(field string, times []time.Time) (wrote
 		// write type code
 		b.buffer[b.pos] = byte(TimestampArray16Code.OpCode)
 
-		wrote += 3 + size
+		wrote += 3 + size*8
 	} else if size < math.MaxUint32 {
 
 		// write length
@@ -92,7 +102,7 @@ func (b *TupleBuilder) PutTimestampArray(field string, times []time.Time) (wrote
 		// write type code
 		b.buffer[b.pos] = byte(TimestampArray32Code.OpCode)
 
-		wrote += 5 + size
+		wrote += 5 + size*8
 	} else {
 
 		// write length
@@ -107,10 +117,52 @@ func (b *TupleBuilder) PutTimestampArray(field string, times []time.Time) (wrote
 		// write type code
 		b.buffer[b.pos] = byte(TimestampArray64Code.OpCode)
 
-		wrote += 9 + size
+		wrote += 9 + size*8
 	}
 
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
+
+// GetTimestamp returns the `time.Time` value for the given field. The field type must be a `TimestampField`, otherwise an error will be returned. If the field was not written, the default value of the field is returned. If the field does not have a default value, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetTimestamp(field string) (time.Time, error) {
+	pos, err := t.fieldOffset(field, TimestampField)
+	if err == ErrFieldNotPresent {
+		if value, ok := t.fieldDefault(field).(time.Time); ok {
+			return value, nil
+		}
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// verify type code
+	if t.data[pos] != TimestampCode.OpCode {
+		return time.Time{}, ErrInvalidTypeCode
+	}
+
+	value, err := xbinary.LittleEndian.Int64(t.data, pos+1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, value), nil
+}
+
+// GetTimestampArray returns the array of `time.Time` values for the given field. The field type must be a `TimestampArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned.
+func (t *Tuple) GetTimestampArray(field string) ([]time.Time, error) {
+	pos, length, err := t.readArrayHeader(field, TimestampArrayField, TimestampArray8Code, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]time.Time, length)
+	for i := 0; i < length; i++ {
+		nanos, err := xbinary.LittleEndian.Int64(t.data, pos+i*8)
+		if err != nil {
+			return nil, err
+		}
+		value[i] = time.Unix(0, nanos)
+	}
+	return value, nil
+}
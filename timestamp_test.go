@@ -15,8 +15,8 @@ func TestPutTimestampFail(t *testing.T) {
 	// float test type
 	TestType := New("testing", "time")
 	TestType.AddVersion(
-		Field{"timestamp", true, TimestampField},
-		Field{"float64", true, Float64Field},
+		Field{Name: "timestamp", Required: true, Type: TimestampField},
+		Field{Name: "float64", Required: true, Type: Float64Field},
 	)
 
 	// create builder
@@ -40,8 +40,8 @@ func TestPutTimestampPass(t *testing.T) {
 	// float test type
 	TestType := New("testing", "time")
 	TestType.AddVersion(
-		Field{"timestamp", true, TimestampField},
-		Field{"float64", true, Float64Field},
+		Field{Name: "timestamp", Required: true, Type: TimestampField},
+		Field{Name: "float64", Required: true, Type: Float64Field},
 	)
 
 	// create builder
@@ -61,5 +61,27 @@ func TestPutTimestampPass(t *testing.T) {
 	assert.Equal(t, now.UnixNano(), value)
 
 	// validate field offset
-	assert.Equal(t, 0, builder.offsets["timestamp"])
+	assert.Equal(t, 0, builder.offset("timestamp"))
+}
+
+func TestTupleGetTimestamp(t *testing.T) {
+
+	// timestamp test type
+	TestType := New("testing", "time")
+	TestType.AddVersion(
+		Field{Name: "timestamp", Required: true, Type: TimestampField},
+	)
+
+	// create builder
+	buffer := make([]byte, 9)
+	builder := NewBuilder(TestType, buffer)
+
+	now := time.Now()
+	builder.PutTimestamp("timestamp", now)
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	value, err := tuple.GetTimestamp("timestamp")
+	assert.Nil(t, err)
+	assert.Equal(t, now.UnixNano(), value.UnixNano())
 }
@@ -18,6 +18,23 @@ var (
 	// ErrInvalidFieldIndex is returned when the field offset
 	// is greater than the number of fields.
 	ErrInvalidFieldIndex = errors.New("Invalid field index")
+
+	// ErrFieldNotPresent is returned by the Tuple getters when an
+	// optional field was not written or the tuple was written with
+	// a version which does not contain the field.
+	ErrFieldNotPresent = errors.New("Field not present in tuple")
+
+	// ErrIncorrectFieldType is returned by the Tuple getters when the
+	// field is not of the requested type.
+	ErrIncorrectFieldType = errors.New("Incorrect field type")
+
+	// ErrInvalidTypeCode is returned by the Tuple getters when the type
+	// code at the field offset does not match the field type.
+	ErrInvalidTypeCode = errors.New("Invalid type code for field")
+
+	// ErrIncorrectTupleType is returned when an embedded tuple is not
+	// of the expected type.
+	ErrIncorrectTupleType = errors.New("Incorrect tuple type")
 )
 
 // Tuple is the data representation used by the encoder and decoder.
@@ -56,6 +73,133 @@ func (t *Tuple) Payload() []byte {
 	return t.data
 }
 
+// fieldOffset verifies the field type and returns the byte offset of the field in the tuple data. If the field was not written, `ErrFieldNotPresent` is returned.
+func (t *Tuple) fieldOffset(field string, fieldType FieldType) (int, error) {
+	f, index, exists := t.Header.Type.field(field)
+	if !exists {
+		return 0, ErrFieldDoesNotExist
+	}
+
+	// field type should be
+	if f.Type != fieldType {
+		return 0, ErrIncorrectFieldType
+	}
+
+	// Tuple was written with a version which does not contain the field
+	if index >= int(t.Header.FieldCount) || index >= len(t.Header.Offsets) {
+		return 0, ErrFieldNotPresent
+	}
+
+	// Optional fields which were not written have a maximum offset
+	offset := t.Header.Offsets[index]
+	if offset == math.MaxUint64 {
+		return 0, ErrFieldNotPresent
+	} else if offset >= uint64(len(t.data)) {
+		return 0, xbinary.ErrOutOfRange
+	}
+	return int(offset), nil
+}
+
+// fieldDefault returns the default value of the field or nil if the field does not have a default value.
+func (t *Tuple) fieldDefault(field string) interface{} {
+	f, _, _ := t.Header.Type.field(field)
+	return f.Default
+}
+
+// readUint8 reads a single byte at the given position in the tuple data.
+func (t *Tuple) readUint8(pos int) (uint8, error) {
+	if pos < 0 || pos >= len(t.data) {
+		return 0, xbinary.ErrOutOfRange
+	}
+	return t.data[pos], nil
+}
+
+// readLength reads a length value which is stored using 1, 2, 4 or 8 bytes.
+func (t *Tuple) readLength(pos int, size uint8) (length uint64, err error) {
+	switch size {
+	case 1:
+		var value uint8
+		value, err = t.readUint8(pos)
+		length = uint64(value)
+	case 2:
+		var value uint16
+		value, err = xbinary.LittleEndian.Uint16(t.data, pos)
+		length = uint64(value)
+	case 4:
+		var value uint32
+		value, err = xbinary.LittleEndian.Uint32(t.data, pos)
+		length = uint64(value)
+	case 8:
+		length, err = xbinary.LittleEndian.Uint64(t.data, pos)
+	default:
+		err = ErrInvalidLength
+	}
+	return
+}
+
+// readArrayHeader verifies the field type and the type code for a length prefixed value. The type codes for the 8, 16, 32 and 64-bit lengths must be sequential, starting with the given type code. The position of the first element and the number of elements are returned. The width is the number of bytes used by each element and is used to verify the length against the tuple data.
+func (t *Tuple) readArrayHeader(field string, fieldType FieldType, first TypeCode, width int) (pos int, length int, err error) {
+	pos, count, err := t.readArrayLength(field, fieldType, first)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// verify the tuple data contains all the elements
+	if count > uint64(len(t.data)-pos)/uint64(width) {
+		return 0, 0, xbinary.ErrOutOfRange
+	}
+	return pos, int(count), nil
+}
+
+// readArrayLength verifies the field type and the type code for a length prefixed value and returns the position of the first element and the length. The length is not verified against the tuple data.
+func (t *Tuple) readArrayLength(field string, fieldType FieldType, first TypeCode) (pos int, length uint64, err error) {
+	pos, err = t.fieldOffset(field, fieldType)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// verify type code
+	opcode := t.data[pos]
+	if opcode < first.OpCode || opcode > first.OpCode+3 {
+		return 0, 0, ErrInvalidTypeCode
+	}
+
+	// read length
+	size := fieldTypes[opcode].Size
+	length, err = t.readLength(pos+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	return pos + 1 + int(size), length, nil
+}
+
+// readTuple parses the tuple embedded in the given field. The hashes in the embedded header must match the given tuple type, otherwise `ErrIncorrectTupleType` is returned. The returned tuple shares the bytes of the parent tuple.
+func (t *Tuple) readTuple(field string, tupleType TupleType) (Tuple, error) {
+	pos, length, err := t.readArrayHeader(field, TupleField, Tuple8Code, 1)
+	if err != nil {
+		return NIL, err
+	}
+	return parseTuple(t.data[pos:pos+length], tupleType)
+}
+
+// parseTuple parses a tuple header and payload which were written with `Tuple.WriteTo`. The hashes and the type ID in the header must match the given tuple type, otherwise `ErrIncorrectTupleType` is returned.
+func parseTuple(buffer []byte, tupleType TupleType) (Tuple, error) {
+	header, pos, err := parseTupleHeader(buffer)
+	if err != nil {
+		return NIL, err
+	}
+
+	// verify tuple type
+	if header.NamespaceHash != tupleType.NamespaceHash || header.Hash != tupleType.Hash {
+		return NIL, ErrIncorrectTupleType
+	} else if header.TypeID != 0 && header.TypeID != tupleType.ID {
+		return NIL, ErrIncorrectTupleType
+	}
+
+	header.Type = tupleType
+	return Tuple{data: buffer[pos:], Header: header}, nil
+}
+
 // WriteTo sends the binary representation of the Tuple to
 // the given io.Writer.
 func (t Tuple) WriteTo(w io.Writer) (n int, err error) {
@@ -88,7 +232,7 @@ func (b *TupleBuilder) writeTuple(value Tuple, offset, size int) (wrote int, err
 	return
 }
 
-// PutTuple writes a tuple into the given field. The field type must be a TupleField, otherwise an error will be returned. The type code is written first, then the length, then the value. If the tuple length is less than `math.MaxUint8`, a single byte is used to represent the length. If the tuple length is less than `math.MaxUint16`, an unsigned 16-bit integer is used to represent the length and so on as the length increases. If the buffer is not large enough to store the entire tuple an `xbinary.ErrOutOfRange` error is returned. If the write is successful, the number of bytes written is returned as well as a nil error.
+// PutTuple writes a tuple into the given field. The field type must be a TupleField, otherwise an error will be returned. The type code is written first, then the length, then the value. If the tuple length is less than `math.MaxUint8`, a single byte is used to represent the length. If the tuple length is less than `math.MaxUint16`, an unsigned 16-bit integer is used to represent the length and so on as the length increases. If the buffer is not large enough to store the entire tuple an `xbinary.ErrOutOfRange` error is returned. If the write is successful, the number of bytes written is returned as well as a nil error. If the field belongs to a oneof group, `ErrOneOfConflict` is returned when another field of the group was already written and the tuple must be of the type referenced by the field, otherwise `ErrIncorrectTupleType` is returned. The type of the embedded tuple identifies the alternative which was written, see `Tuple.GetOneOf`.
 func (b *TupleBuilder) PutTuple(field string, value Tuple) (wrote int, err error) {
 
 	// field type should be
@@ -96,6 +240,16 @@ func (b *TupleBuilder) PutTuple(field string, value Tuple) (wrote int, err error
 		return 0, err
 	}
 
+	// members of a oneof group are identified by their tuple type
+	if err = b.checkTupleType(field, value); err != nil {
+		return 0, err
+	}
+
+	// grow the buffer of a growable builder
+	if err = b.reserve(tupleSize(value)); err != nil {
+		return 0, err
+	}
+
 	size := value.Size() + value.Header.Size()
 	if size < math.MaxUint8 {
 
@@ -194,12 +348,12 @@ func (b *TupleBuilder) PutTuple(field string, value Tuple) (wrote int, err error
 	}
 
 	// store offset and increment position
-	b.offsets[field] = b.pos
+	b.setOffset(field)
 	b.pos += wrote
 	return
 }
 
-// PutTupleArray writes an array of tuples for the given field. The field type must be `TupleArrayField`, otherwise an error will be returned.
+// PutTupleArray writes an array of tuples for the given field. The field type must be `TupleArrayField`, otherwise an error will be returned. The type code is written first, then the number of tuples, and finally each tuple. Each tuple is written the same way as `PutTuple`, with its own type code and length. If the buffer does not have enough space for the entire array, an `xbinary.ErrOutOfRange` error will be returned. If successful, the number of bytes written will be returned as well as a nil error.
 func (b *TupleBuilder) PutTupleArray(field string, value []Tuple) (wrote int, err error) {
 
 	// field type should be
@@ -208,125 +362,171 @@ func (b *TupleBuilder) PutTupleArray(field string, value []Tuple) (wrote int, er
 	}
 
 	// calculate total size
-	var totalSize int
-	var tupleSize int
-	for i := 0; i < len(value); i++ {
-		tupleSize = value[i].Size()
-
-		// add tuple header
-		if tupleSize < math.MaxUint8 {
-			totalSize += 2
-		} else if tupleSize < math.MaxUint16 {
-			totalSize += 3
-		} else if tupleSize < math.MaxUint32 {
-			totalSize += 5
-		} else {
-			totalSize += 9
-		}
-
-		// add tuple size
-		totalSize += tupleSize
+	var size int
+	for _, tuple := range value {
+		size += tupleSize(tuple)
 	}
 
-	// return error if buffer is too small
-	// 8-bit total size check
-	if totalSize < math.MaxUint8 && b.available() < totalSize+2 {
-		return 0, xbinary.ErrOutOfRange
-
-		// 16-bit total size check
-	} else if totalSize < math.MaxUint16 && b.available() < totalSize+3 {
-		return 0, xbinary.ErrOutOfRange
-
-		// 32-bit total size check
-	} else if totalSize < math.MaxUint32 && b.available() < totalSize+5 {
-		return 0, xbinary.ErrOutOfRange
-
-		// 64-bit size check
-	} else if totalSize > math.MaxUint32 && b.available() < totalSize+9 {
-		return 0, xbinary.ErrOutOfRange
+	// write type code and length
+	wrote, err = b.putArrayHeader(TupleArray8Code, len(value), size)
+	if err != nil {
+		return 0, err
 	}
 
-	// write array values
+	// write tuples
 	for _, tuple := range value {
+		n, err := b.putTuple(b.pos+wrote, tuple)
+		if err != nil {
+			return 0, err
+		}
+		wrote += n
+	}
 
-		size := tuple.Size() + tuple.Header.Size()
-		if size < math.MaxUint8 {
-
-			// check length
-			if b.available() < size+2 {
-				return wrote, xbinary.ErrOutOfRange
-			}
-
-			// write tuple
-			if _, err := b.writeTuple(tuple, b.pos+wrote+2, size); err != nil {
-				return wrote, err
-			}
+	b.setOffset(field)
+	b.pos += wrote
+	return
+}
 
-			// tuple.WriteAt(&b.buffer, int64(b.pos+2+wrote))
+// tupleSize returns the number of bytes needed to write the tuple including the type code, the length and the tuple header.
+func tupleSize(value Tuple) int {
+	size := value.Size() + value.Header.Size()
+	if size < math.MaxUint8 {
+		return size + 2
+	} else if size < math.MaxUint16 {
+		return size + 3
+	} else if size < math.MaxUint32 {
+		return size + 5
+	}
+	return size + 9
+}
 
-			// write type code
-			b.buffer[b.pos+wrote] = byte(TupleArray8Code.OpCode)
+// putTuple writes the type code, the length and the tuple at the given position. The buffer must be large enough to hold the value. The number of bytes written is returned.
+func (b *TupleBuilder) putTuple(pos int, value Tuple) (int, error) {
+	size := value.Size() + value.Header.Size()
+	if size < math.MaxUint8 {
+		b.buffer[pos] = byte(Tuple8Code.OpCode)
+		b.buffer[pos+1] = byte(size)
+	} else if size < math.MaxUint16 {
+		b.buffer[pos] = byte(Tuple16Code.OpCode)
+		xbinary.LittleEndian.PutUint16(b.buffer, pos+1, uint16(size))
+	} else if size < math.MaxUint32 {
+		b.buffer[pos] = byte(Tuple32Code.OpCode)
+		xbinary.LittleEndian.PutUint32(b.buffer, pos+1, uint32(size))
+	} else {
+		b.buffer[pos] = byte(Tuple64Code.OpCode)
+		xbinary.LittleEndian.PutUint64(b.buffer, pos+1, uint64(size))
+	}
 
-			// write length
-			b.buffer[b.pos+1+wrote] = byte(size)
+	header := tupleSize(value) - size
+	if _, err := b.writeTuple(value, pos+header, size); err != nil {
+		return 0, err
+	}
+	return header + size, nil
+}
 
-			wrote += size + 2
-		} else if size < math.MaxUint16 {
-			// check length
-			if b.available() < size+3 {
-				return wrote, xbinary.ErrOutOfRange
-			}
+// GetTuple returns the tuple embedded in the given field. The field type must be a `TupleField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned. The tuple type is resolved from the embedded tuple header using the registry. If the type is not registered, `ErrUnknownTupleType` is returned. The returned tuple shares the bytes of the parent tuple, so no data is copied.
+func (t *Tuple) GetTuple(field string, reg *Registry) (Tuple, error) {
+	pos, length, err := t.readArrayHeader(field, TupleField, Tuple8Code, 1)
+	if err != nil {
+		return NIL, err
+	}
+	return resolveTuple(reg, t.Header.ProtocolVersion, t.data[pos:pos+length:pos+length])
+}
 
-			// write length
-			xbinary.LittleEndian.PutUint16(b.buffer, b.pos+1+wrote, uint16(size))
+// GetTupleArray returns an iterator over the tuples in the given field. The field type must be a `TupleArrayField`, otherwise an error will be returned. If the field was not written, `ErrFieldNotPresent` will be returned. The tuple type of each element is resolved from the embedded tuple header using the registry when the iterator advances.
+func (t *Tuple) GetTupleArray(field string, reg *Registry) (*TupleIterator, error) {
+	protocolVersion := t.Header.ProtocolVersion
+	return t.readTupleArray(field, func(buffer []byte) (Tuple, error) {
+		return resolveTuple(reg, protocolVersion, buffer)
+	})
+}
 
-			// write type code
-			b.buffer[b.pos+wrote] = byte(TimestampArray16Code.OpCode)
+// readTupleArray verifies the field type and the type code of a tuple array and returns an iterator which parses each element using the given function.
+func (t *Tuple) readTupleArray(field string, parse func([]byte) (Tuple, error)) (*TupleIterator, error) {
+	pos, length, err := t.readArrayLength(field, TupleArrayField, TupleArray8Code)
+	if err != nil {
+		return nil, err
+	}
 
-			// write tuple
-			if _, err := b.writeTuple(tuple, b.pos+wrote+3, size); err != nil {
-				return wrote, err
-			}
+	// each tuple uses at least 2 bytes
+	if length > uint64(len(t.data)-pos)/2 {
+		return nil, xbinary.ErrOutOfRange
+	}
+	return &TupleIterator{data: t.data, pos: pos, length: int(length), parse: parse}, nil
+}
 
-			wrote += 3 + size
-		} else if size < math.MaxUint32 {
+// readBytes reads a length prefixed value with its type code at the given position. The type codes for the 8, 16, 32 and 64-bit lengths must be sequential, starting with the given type code. The value and the position after the value are returned. The value shares the bytes of the tuple.
+func (t *Tuple) readBytes(pos int, first TypeCode) (value []byte, next int, err error) {
+	opcode, err := t.readUint8(pos)
+	if err != nil {
+		return nil, 0, err
+	} else if opcode < first.OpCode || opcode > first.OpCode+3 {
+		return nil, 0, ErrInvalidTypeCode
+	}
 
-			// check length
-			if b.available() < size+5 {
-				return wrote, xbinary.ErrOutOfRange
-			}
+	// read length
+	size := fieldTypes[opcode].Size
+	length, err := t.readLength(pos+1, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	pos += 1 + int(size)
 
-			// write tuple
-			if _, err := b.writeTuple(tuple, b.pos+wrote+5, size); err != nil {
-				return wrote, err
-			}
+	// verify the tuple data contains the entire value
+	if length > uint64(len(t.data)-pos) {
+		return nil, 0, xbinary.ErrOutOfRange
+	}
+	next = pos + int(length)
+	return t.data[pos:next:next], next, nil
+}
 
-			// write length
-			xbinary.LittleEndian.PutUint32(b.buffer, b.pos+1+wrote, uint32(size))
+// TupleIterator iterates over the tuples in a `TupleArrayField`. Each tuple is parsed when the iterator advances and shares the bytes of the parent tuple. `Next` returns false at the end of the array or if an error occurred, which is returned by `Err`.
+type TupleIterator struct {
+	data    []byte
+	pos     int
+	length  int
+	index   int
+	current Tuple
+	err     error
+	parse   func([]byte) (Tuple, error)
+}
 
-			// write type code
-			b.buffer[b.pos+wrote] = byte(TimestampArray32Code.OpCode)
+// Len returns the number of tuples in the array.
+func (it *TupleIterator) Len() int {
+	return it.length
+}
 
-			wrote += 5 + size
-		} else {
+// Next parses the next tuple in the array. It returns false at the end of the array or if an error occurred.
+func (it *TupleIterator) Next() bool {
+	if it.err != nil || it.index >= it.length {
+		it.current = NIL
+		return false
+	}
 
-			// write tuple
-			if _, err := b.writeTuple(tuple, b.pos+wrote+9, size); err != nil {
-				return wrote, err
-			}
+	parent := Tuple{data: it.data}
+	buffer, next, err := parent.readBytes(it.pos, Tuple8Code)
+	if err == nil {
+		it.current, err = it.parse(buffer)
+	}
+	if err != nil {
+		it.err = err
+		it.current = NIL
+		return false
+	}
 
-			// write length
-			xbinary.LittleEndian.PutUint64(b.buffer, b.pos+1+wrote, uint64(size))
+	it.pos = next
+	it.index++
+	return true
+}
 
-			// write type code
-			b.buffer[b.pos+wrote] = byte(TimestampArray64Code.OpCode)
-			wrote += 9 + size
-		}
-	}
+// Tuple returns the tuple parsed by the last call to `Next`.
+func (it *TupleIterator) Tuple() Tuple {
+	return it.current
+}
 
-	b.offsets[field] = b.pos
-	b.pos += wrote
-	return
+// Err returns the first error which occurred while iterating over the array.
+func (it *TupleIterator) Err() error {
+	return it.err
 }
 
 // func main() {
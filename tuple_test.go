@@ -1 +1,433 @@
 package namedtuple
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/blacklabeldata/xbinary"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestArrayType() TupleType {
+
+	// array test type
+	TestType := New("testing", "arrays")
+	TestType.AddVersion(
+		Field{Name: "uint8", Required: true, Type: Uint8ArrayField},
+		Field{Name: "int8", Required: true, Type: Int8ArrayField},
+		Field{Name: "uint16", Required: true, Type: Uint16ArrayField},
+		Field{Name: "int16", Required: true, Type: Int16ArrayField},
+		Field{Name: "uint32", Required: true, Type: Uint32ArrayField},
+		Field{Name: "int32", Required: true, Type: Int32ArrayField},
+		Field{Name: "uint64", Required: true, Type: Uint64ArrayField},
+		Field{Name: "int64", Required: true, Type: Int64ArrayField},
+		Field{Name: "float32", Required: true, Type: Float32ArrayField},
+		Field{Name: "float64", Required: true, Type: Float64ArrayField},
+		Field{Name: "timestamp", Required: true, Type: TimestampArrayField},
+	)
+	return TestType
+}
+
+func TestTupleGetFieldDoesNotExist(t *testing.T) {
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutString("username", "username")
+	user, err := builder.Build()
+	assert.Nil(t, err)
+
+	// unknown field
+	_, err = user.GetString("school")
+	assert.Equal(t, ErrFieldDoesNotExist, err)
+}
+
+func TestTupleGetIncorrectFieldType(t *testing.T) {
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutString("username", "username")
+	user, err := builder.Build()
+	assert.Nil(t, err)
+
+	// uuid is a string field
+	_, err = user.GetUint8("uuid")
+	assert.Equal(t, ErrIncorrectFieldType, err)
+}
+
+func TestTupleGetFieldNotPresent(t *testing.T) {
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutString("username", "username")
+	user, err := builder.Build()
+	assert.Nil(t, err)
+
+	// optional field was not written
+	assert.Equal(t, uint64(math.MaxUint64), user.Header.Offsets[2])
+	_, err = user.GetUint8("age")
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	// version 3 fields are optional and included in the header
+	_, err = user.GetFloat32("lat")
+	assert.Equal(t, ErrFieldNotPresent, err)
+}
+
+func TestTupleGetFieldNotInVersion(t *testing.T) {
+
+	// type with a required field in the second version
+	TestType := New("testing", "versions")
+	TestType.AddVersion(Field{Name: "name", Required: true, Type: StringField})
+	TestType.AddVersion(Field{Name: "age", Required: true, Type: Uint8Field})
+
+	builder := NewBuilder(TestType, make([]byte, 64))
+	builder.PutString("name", "namedtuple")
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	// tuple was written with version 1
+	assert.Equal(t, uint8(1), tuple.Header.TupleVersion)
+	assert.Equal(t, uint32(1), tuple.Header.FieldCount)
+	_, err = tuple.GetUint8("age")
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	// field from version 1
+	name, err := tuple.GetString("name")
+	assert.Nil(t, err)
+	assert.Equal(t, "namedtuple", name)
+}
+
+func TestTupleGetInvalidTypeCode(t *testing.T) {
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutString("username", "username")
+	builder.PutUint8("age", 25)
+	user, err := builder.Build()
+	assert.Nil(t, err)
+
+	// corrupt type code
+	user.data[user.Header.Offsets[2]] = StringArray8Code.OpCode
+	_, err = user.GetUint8("age")
+	assert.Equal(t, ErrInvalidTypeCode, err)
+}
+
+func TestTupleGetArrays(t *testing.T) {
+	TestType := createTestArrayType()
+	builder := NewBuilder(TestType, make([]byte, 1024))
+
+	now := time.Unix(0, time.Now().UnixNano())
+	_, err := builder.PutUint8Array("uint8", []uint8{1, 2, 3})
+	assert.Nil(t, err)
+	_, err = builder.PutInt8Array("int8", []int8{-1, 2, -3})
+	assert.Nil(t, err)
+	_, err = builder.PutUint16Array("uint16", []uint16{1, 300, 65000})
+	assert.Nil(t, err)
+	_, err = builder.PutInt16Array("int16", []int16{-1, 300, -32000})
+	assert.Nil(t, err)
+	_, err = builder.PutUint32Array("uint32", []uint32{1, 300, 135000})
+	assert.Nil(t, err)
+	_, err = builder.PutInt32Array("int32", []int32{-1, 300, -135000})
+	assert.Nil(t, err)
+	_, err = builder.PutUint64Array("uint64", []uint64{1, 300, math.MaxUint64})
+	assert.Nil(t, err)
+	_, err = builder.PutInt64Array("int64", []int64{-1, 300, math.MinInt64})
+	assert.Nil(t, err)
+	_, err = builder.PutFloat32Array("float32", []float32{1.5, -2.5})
+	assert.Nil(t, err)
+	_, err = builder.PutFloat64Array("float64", []float64{1.5, -2.5})
+	assert.Nil(t, err)
+	_, err = builder.PutTimestampArray("timestamp", []time.Time{now, now.Add(time.Hour)})
+	assert.Nil(t, err)
+
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	uint8s, err := tuple.GetUint8Array("uint8")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{1, 2, 3}, uint8s)
+
+	int8s, err := tuple.GetInt8Array("int8")
+	assert.Nil(t, err)
+	assert.Equal(t, []int8{-1, 2, -3}, int8s)
+
+	uint16s, err := tuple.GetUint16Array("uint16")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint16{1, 300, 65000}, uint16s)
+
+	int16s, err := tuple.GetInt16Array("int16")
+	assert.Nil(t, err)
+	assert.Equal(t, []int16{-1, 300, -32000}, int16s)
+
+	uint32s, err := tuple.GetUint32Array("uint32")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{1, 300, 135000}, uint32s)
+
+	int32s, err := tuple.GetInt32Array("int32")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{-1, 300, -135000}, int32s)
+
+	uint64s, err := tuple.GetUint64Array("uint64")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 300, math.MaxUint64}, uint64s)
+
+	int64s, err := tuple.GetInt64Array("int64")
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{-1, 300, math.MinInt64}, int64s)
+
+	float32s, err := tuple.GetFloat32Array("float32")
+	assert.Nil(t, err)
+	assert.Equal(t, []float32{1.5, -2.5}, float32s)
+
+	float64s, err := tuple.GetFloat64Array("float64")
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{1.5, -2.5}, float64s)
+
+	times, err := tuple.GetTimestampArray("timestamp")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(times))
+	assert.True(t, now.Equal(times[0]))
+	assert.True(t, now.Add(time.Hour).Equal(times[1]))
+}
+
+func TestTupleGetArrayOutOfRange(t *testing.T) {
+	TestType := createTestArrayType()
+	builder := NewBuilder(TestType, make([]byte, 1024))
+	builder.PutUint32Array("uint32", []uint32{1, 2, 3})
+	tuple := Tuple{data: builder.buffer[:builder.pos], Header: TupleHeader{
+		FieldCount: 5,
+		Offsets:    []uint64{math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64, 0},
+		Type:       TestType,
+	}}
+
+	// truncated array
+	tuple.data = tuple.data[:8]
+	_, err := tuple.GetUint32Array("uint32")
+	assert.NotNil(t, err)
+}
+
+func TestTupleGetDecoded(t *testing.T) {
+	User := createTestTupleType()
+	builder := NewBuilder(User, make([]byte, 1024))
+	builder.PutString("uuid", "0123456789abcdef")
+	builder.PutString("username", "username")
+	builder.PutFloat64("alt", 1024.5)
+	user, err := builder.Build()
+	assert.Nil(t, err)
+
+	// encode tuple
+	var buf bytes.Buffer
+	assert.Nil(t, NewEncoder(&buf).Encode(user))
+
+	// decode tuple
+	reg := NewRegistry()
+	reg.Register(User)
+	decoded, err := NewDecoder(reg, &buf).Decode()
+	assert.Nil(t, err)
+
+	uuid, err := decoded.GetString("uuid")
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789abcdef", uuid)
+
+	alt, err := decoded.GetFloat64("alt")
+	assert.Nil(t, err)
+	assert.Equal(t, 1024.5, alt)
+
+	// missing optional fields are widened to math.MaxUint64
+	_, err = decoded.GetUint8("age")
+	assert.Equal(t, ErrFieldNotPresent, err)
+}
+
+func createTestRouteType() TupleType {
+	Route := New("testing", "route")
+	Route.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "start", Required: false, Type: TupleField},
+		Field{Name: "stops", Required: false, Type: TupleArrayField},
+	)
+	return Route
+}
+
+func buildTestLocation(t *testing.T, lon, lat float32) Tuple {
+	builder := NewBuilder(createTestLocationType(), make([]byte, 256))
+	builder.PutFloat32("lon", lon)
+	builder.PutFloat32("lat", lat)
+	loc, err := builder.Build()
+	assert.Nil(t, err)
+	return loc
+}
+
+func TestTupleGetTuple(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	builder := NewBuilder(createTestRouteType(), make([]byte, 1024))
+	builder.PutString("name", "loop")
+	_, err := builder.PutTuple("start", buildTestLocation(t, 1.5, 2.5))
+	assert.Nil(t, err)
+	route, err := builder.Build()
+	assert.Nil(t, err)
+
+	start, err := route.GetTuple("start", &reg)
+	assert.Nil(t, err)
+	assert.True(t, start.Is(createTestLocationType()))
+
+	lon, err := start.GetFloat32("lon")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1.5), lon)
+
+	// the nested tuple shares the bytes of the parent
+	payload := start.Payload()
+	assert.True(t, &payload[len(payload)-1] == &route.data[len(route.data)-1])
+
+	// not present
+	_, err = route.GetTupleArray("stops", &reg)
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	// incorrect field type
+	_, err = route.GetTuple("name", &reg)
+	assert.Equal(t, ErrIncorrectFieldType, err)
+
+	// unknown tuple type
+	empty := NewRegistry()
+	_, err = route.GetTuple("start", &empty)
+	assert.Equal(t, ErrUnknownTupleType, err)
+}
+
+func TestTupleGetTupleArray(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	builder := NewBuilder(createTestRouteType(), make([]byte, 1024))
+	builder.PutString("name", "loop")
+	_, err := builder.PutTupleArray("stops", []Tuple{
+		buildTestLocation(t, 1, 2),
+		buildTestLocation(t, 3, 4),
+		buildTestLocation(t, 5, 6),
+	})
+	assert.Nil(t, err)
+	route, err := builder.Build()
+	assert.Nil(t, err)
+
+	it, err := route.GetTupleArray("stops", &reg)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, it.Len())
+
+	var lons []float32
+	for it.Next() {
+		stop := it.Tuple()
+		lon, err := stop.GetFloat32("lon")
+		assert.Nil(t, err)
+		lons = append(lons, lon)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []float32{1, 3, 5}, lons)
+	assert.False(t, it.Next())
+
+	// unknown tuple type
+	empty := NewRegistry()
+	it, err = route.GetTupleArray("stops", &empty)
+	assert.Nil(t, err)
+	assert.False(t, it.Next())
+	assert.Equal(t, ErrUnknownTupleType, it.Err())
+
+	// incorrect field type
+	_, err = route.GetTupleArray("start", &reg)
+	assert.Equal(t, ErrIncorrectFieldType, err)
+}
+
+func TestTupleGetTupleArrayOutOfRange(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(createTestLocationType())
+
+	builder := NewBuilder(createTestRouteType(), make([]byte, 1024))
+	builder.PutString("name", "loop")
+	builder.PutTupleArray("stops", []Tuple{buildTestLocation(t, 1, 2)})
+	route, err := builder.Build()
+	assert.Nil(t, err)
+
+	// truncate the last tuple
+	route.data = route.data[:len(route.data)-1]
+	it, err := route.GetTupleArray("stops", &reg)
+	assert.Nil(t, err)
+	assert.False(t, it.Next())
+	assert.Equal(t, xbinary.ErrOutOfRange, it.Err())
+}
+
+func createTestDefaultType() TupleType {
+	created := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	TestType := New("testing", "defaults")
+	TestType.AddVersion(
+		Field{Name: "name", Required: true, Type: StringField},
+		Field{Name: "age", Type: Uint8Field, Default: uint8(18)},
+		Field{Name: "score", Type: Float64Field, Default: float64(0.5)},
+		Field{Name: "nickname", Type: StringField, Default: "anonymous"},
+		Field{Name: "email", Type: StringField},
+	)
+	TestType.AddVersion(
+		Field{Name: "active", Type: BooleanField, Default: true},
+		Field{Name: "created", Type: TimestampField, Default: created},
+		Field{Name: "balance", Type: Int64Field, Default: int64(-1)},
+	)
+	return TestType
+}
+
+func TestTupleGetDefault(t *testing.T) {
+	builder := NewBuilder(createTestDefaultType(), make([]byte, 1024))
+	builder.PutString("name", "namedtuple")
+	builder.PutUint8("age", 30)
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+
+	// written values are returned
+	age, err := tuple.GetUint8("age")
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(30), age)
+
+	// default values are returned for fields which were not written
+	score, err := tuple.GetFloat64("score")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, score)
+
+	nickname, err := tuple.GetString("nickname")
+	assert.Nil(t, err)
+	assert.Equal(t, "anonymous", nickname)
+
+	// fields without a default value are not present
+	_, err = tuple.GetString("email")
+	assert.Equal(t, ErrFieldNotPresent, err)
+
+	// the field type is still checked
+	_, err = tuple.GetUint16("age")
+	assert.Equal(t, ErrIncorrectFieldType, err)
+}
+
+func TestTupleGetDefaultNotInVersion(t *testing.T) {
+	TestType := createTestDefaultType()
+
+	// tuple written with the first version of the type
+	v1 := New("testing", "defaults")
+	v1.AddVersion(TestType.Versions()[0].Fields...)
+	builder := NewBuilder(v1, make([]byte, 1024))
+	builder.PutString("name", "namedtuple")
+	tuple, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(1), tuple.Header.TupleVersion)
+
+	// read with the current version of the type
+	tuple.Header.Type = TestType
+
+	active, err := tuple.GetBool("active")
+	assert.Nil(t, err)
+	assert.True(t, active)
+
+	created, err := tuple.GetTimestamp("created")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), created)
+
+	balance, err := tuple.GetInt64("balance")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), balance)
+}
@@ -1,6 +1,11 @@
 package namedtuple
 
-import "hash/fnv"
+import (
+	"hash"
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
 
 var syncHash SynchronizedHash = NewHasher(fnv.New32a())
 
@@ -12,8 +17,14 @@ type TupleType struct {
 	Name          string // Tuple Name
 	NamespaceHash uint32
 	Hash          uint32
+	ID            uint64 // 64-bit identity of the namespace and name
 	versions      [][]Field
 	fields        map[string]int
+	constrained   bool     // true if any field has constraints
+	enums         bool     // true if any field is an enum
+	oneofs        bool     // true if any field belongs to a oneof group
+	table         []Field  // fields of all versions, indexed by the offsets in fields
+	fingerprints  []uint64 // fingerprint of the first n versions at index n-1
 }
 
 type Version struct {
@@ -25,22 +36,69 @@ type Field struct {
 	Name     string
 	Required bool
 	Type     FieldType
+
+	// TupleNamespace and TupleName reference the nested type of a
+	// TupleField or TupleArrayField. Both are empty if the field
+	// accepts any tuple type.
+	TupleNamespace string
+	TupleName      string
+
+	// Constraints restrict the values of the field. They are checked
+	// by TupleBuilder.Build.
+	Constraints Constraints
+
+	// Default is returned by the Tuple getters if an optional field was
+	// not written. It must have the Go type returned by the getter for
+	// the field type, such as uint8 for a Uint8Field. Only scalar fields
+	// support default values.
+	Default interface{}
+
+	// Enum declares the values of an EnumField.
+	Enum *Enum
+
+	// KeyType and ValueType are the scalar types of the keys and values
+	// of a MapField. See ValidMapTypes for the supported types.
+	KeyType   FieldType
+	ValueType FieldType
+
+	// OneOf is the name of the group of optional fields the field
+	// belongs to. At most one field of a group can be written. It is
+	// empty if the field does not belong to a group.
+	OneOf string
+
+	// Encoding selects the encoding of integer fields. If it is the
+	// DefaultIntEncoding, the encoding of the builder is used.
+	Encoding IntEncoding
 }
 
 // New creates a new TupleType with the given namespace and type name
 func New(namespace string, name string) (t TupleType) {
 	hash := syncHash.Hash([]byte(name))
 	ns_hash := syncHash.Hash([]byte(namespace))
-	t = TupleType{namespace, name, ns_hash, hash, make([][]Field, 0), make(map[string]int)}
+	t = TupleType{namespace, name, ns_hash, hash, TypeID(namespace, name), make([][]Field, 0), make(map[string]int), false, false, false, nil, nil}
 	return
 }
 
+// TypeID returns the 64-bit identity of a tuple type. It is the 64-bit FNV-1a hash of the namespace and the name separated by a zero byte. Unlike the pair of 32-bit hashes, the namespace and name are hashed together so the identity is unlikely to collide even with a large number of types.
+func TypeID(namespace, name string) uint64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(namespace))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(name))
+	return hasher.Sum64()
+}
+
 // AddVersion adds a version to the tuple type
 func (t *TupleType) AddVersion(fields ...Field) {
 	t.versions = append(t.versions, fields)
 	for _, field := range fields {
-		t.fields[field.Name] = len(t.fields)
+		t.fields[field.Name] = len(t.table)
+		t.table = append(t.table, field)
+		t.constrained = t.constrained || !field.Constraints.IsZero()
+		t.enums = t.enums || field.Type == EnumField
+		t.oneofs = t.oneofs || field.OneOf != ""
 	}
+	t.fingerprints = append(t.fingerprints, t.fingerprint(len(t.versions)))
 }
 
 // Contains determines is a field exists in the TupleType
@@ -55,6 +113,93 @@ func (t *TupleType) Offset(field string) (offset int, exists bool) {
 	return
 }
 
+// field returns the field definition and numerical offset for the given field name
+func (t *TupleType) field(name string) (field Field, offset int, exists bool) {
+	if offset, exists = t.fields[name]; !exists {
+		return Field{}, 0, false
+	}
+	return t.table[offset], offset, true
+}
+
+// Fingerprint returns a 64-bit hash of the exact field layout of the first n versions of the tuple type. The namespace, the name and the name, type, required flag, nested tuple type, enum with its values and open flag, map types and oneof group of every field are included, so two tuple types only have the same fingerprint if the versions are identical. The fingerprints are computed once when the versions are added.
+func (t *TupleType) Fingerprint(versions int) uint64 {
+	if versions > len(t.fingerprints) {
+		versions = len(t.fingerprints)
+	}
+	if versions < 1 {
+		return t.fingerprint(0)
+	}
+	return t.fingerprints[versions-1]
+}
+
+// fingerprint hashes the field layout of the first n versions
+func (t *TupleType) fingerprint(versions int) uint64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(t.Namespace))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(t.Name))
+
+	for i := 0; i < versions && i < len(t.versions); i++ {
+
+		// separate the versions so fields cannot move between them
+		hasher.Write([]byte{0xff})
+		for _, field := range t.versions[i] {
+			hasher.Write([]byte{0})
+			hasher.Write([]byte(field.Name))
+			hasher.Write([]byte{0, byte(field.Type), boolByte(field.Required)})
+			hasher.Write([]byte(field.TupleNamespace))
+			hasher.Write([]byte{0})
+			hasher.Write([]byte(field.TupleName))
+
+			// enum values are part of the layout
+			if field.Enum != nil {
+				hasher.Write([]byte{0})
+				hasher.Write([]byte(field.Enum.Namespace))
+				hasher.Write([]byte{0})
+				hasher.Write([]byte(field.Enum.Name))
+				hasher.Write([]byte{0, boolByte(field.Enum.Open)})
+				writeEnumValues(hasher, field.Enum)
+			}
+
+			// map keys and values are part of the layout
+			if field.Type == MapField {
+				hasher.Write([]byte{0, byte(field.KeyType), byte(field.ValueType)})
+			}
+
+			// fields can not move between oneof groups
+			if field.OneOf != "" {
+				hasher.Write([]byte{0})
+				hasher.Write([]byte(field.OneOf))
+			}
+		}
+	}
+	return hasher.Sum64()
+}
+
+// writeEnumValues writes the name and value pairs of the enum sorted by name, so the order of the declaration does not change the fingerprint
+func writeEnumValues(hasher hash.Hash64, e *Enum) {
+	values := append([]EnumValue{}, e.Values()...)
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Name != values[j].Name {
+			return values[i].Name < values[j].Name
+		}
+		return values[i].Value < values[j].Value
+	})
+
+	for _, v := range values {
+		hasher.Write([]byte{0})
+		hasher.Write([]byte(v.Name))
+		hasher.Write([]byte{0, byte(v.Value >> 24), byte(v.Value >> 16), byte(v.Value >> 8), byte(v.Value)})
+	}
+}
+
+func boolByte(value bool) byte {
+	if value {
+		return 1
+	}
+	return 0
+}
+
 // NumVersions returns the number of version in the tuple type
 func (t *TupleType) NumVersions() int {
 	return len(t.versions)
@@ -106,8 +251,51 @@ const (
 	StringArrayField
 	BooleanField
 	BooleanArrayField
+	EnumField
+	MapField
 )
 
+var fieldTypeNames = map[FieldType]string{
+	Uint8Field:          "Uint8Field",
+	Uint8ArrayField:     "Uint8ArrayField",
+	Int8Field:           "Int8Field",
+	Int8ArrayField:      "Int8ArrayField",
+	Uint16Field:         "Uint16Field",
+	Uint16ArrayField:    "Uint16ArrayField",
+	Int16Field:          "Int16Field",
+	Int16ArrayField:     "Int16ArrayField",
+	Uint32Field:         "Uint32Field",
+	Uint32ArrayField:    "Uint32ArrayField",
+	Int32Field:          "Int32Field",
+	Int32ArrayField:     "Int32ArrayField",
+	Uint64Field:         "Uint64Field",
+	Uint64ArrayField:    "Uint64ArrayField",
+	Int64Field:          "Int64Field",
+	Int64ArrayField:     "Int64ArrayField",
+	Float32Field:        "Float32Field",
+	Float32ArrayField:   "Float32ArrayField",
+	Float64Field:        "Float64Field",
+	Float64ArrayField:   "Float64ArrayField",
+	TimestampField:      "TimestampField",
+	TimestampArrayField: "TimestampArrayField",
+	TupleField:          "TupleField",
+	TupleArrayField:     "TupleArrayField",
+	StringField:         "StringField",
+	StringArrayField:    "StringArrayField",
+	BooleanField:        "BooleanField",
+	BooleanArrayField:   "BooleanArrayField",
+	EnumField:           "EnumField",
+	MapField:            "MapField",
+}
+
+// String returns the name of the field type constant
+func (f FieldType) String() string {
+	if name, ok := fieldTypeNames[f]; ok {
+		return name
+	}
+	return "FieldType(" + strconv.Itoa(int(f)) + ")"
+}
+
 // TypeCode represents a field type
 type TypeCode struct {
 	OpCode uint8
@@ -207,99 +395,246 @@ var (
 	TupleArray32Code         = TypeCode{88, 4}
 	TupleArray64Code         = TypeCode{89, 8}
 	// FieldName            = TypeCode{90, 1}
-	// VarInt               = TypeCode{91, 0}
-	// UVarInt              = TypeCode{92, 0}
+	VarIntCode         = TypeCode{91, 0}
+	UVarIntCode        = TypeCode{92, 0}
+	Map8Code           = TypeCode{93, 1}
+	Map16Code          = TypeCode{94, 2}
+	Map32Code          = TypeCode{95, 4}
+	Map64Code          = TypeCode{96, 8}
+	VarIntArray8Code   = TypeCode{97, 1}
+	VarIntArray16Code  = TypeCode{98, 2}
+	VarIntArray32Code  = TypeCode{99, 4}
+	VarIntArray64Code  = TypeCode{100, 8}
+	UVarIntArray8Code  = TypeCode{101, 1}
+	UVarIntArray16Code = TypeCode{102, 2}
+	UVarIntArray32Code = TypeCode{103, 4}
+	UVarIntArray64Code = TypeCode{104, 8}
 )
 
 var fieldTypes = map[byte]TypeCode{
-	0:  NilCode,
-	1:  TrueCode,
-	2:  FalseCode,
-	3:  BooleanArray8Code,
-	4:  BooleanArray16Code,
-	5:  BooleanArray32Code,
-	6:  BooleanArray64Code,
-	7:  ByteCode,
-	8:  ByteArray8Code,
-	9:  ByteArray16Code,
-	10: ByteArray32Code,
-	11: ByteArray64Code,
-	12: UnsignedByteCode,
-	13: UnsignedByteArray8Code,
-	14: UnsignedByteArray16Code,
-	15: UnsignedByteArray32Code,
-	16: UnsignedByteArray64Code,
-	17: Short8Code,
-	18: Short16Code,
-	19: ShortArray8Code,
-	20: ShortArray16Code,
-	21: ShortArray32Code,
-	22: ShortArray64Code,
-	23: UnsignedShort8Code,
-	24: UnsignedShort16Code,
-	25: UnsignedShortArray8Code,
-	26: UnsignedShortArray16Code,
-	27: UnsignedShortArray32Code,
-	28: UnsignedShortArray64Code,
-	29: Int8Code,
-	30: Int16Code,
-	31: Int32Code,
-	32: IntArray8Code,
-	33: IntArray16Code,
-	34: IntArray32Code,
-	35: IntArray64Code,
-	36: UnsignedInt8Code,
-	37: UnsignedInt16Code,
-	38: UnsignedInt32Code,
-	39: UnsignedIntArray8Code,
-	40: UnsignedIntArray16Code,
-	41: UnsignedIntArray32Code,
-	42: UnsignedIntArray64Code,
-	43: Long8Code,
-	44: Long16Code,
-	45: Long32Code,
-	46: Long64Code,
-	47: LongArray8Code,
-	48: LongArray16Code,
-	49: LongArray32Code,
-	50: LongArray64Code,
-	51: UnsignedLong8Code,
-	52: UnsignedLong16Code,
-	53: UnsignedLong32Code,
-	54: UnsignedLong64Code,
-	55: UnsignedLongArray8Code,
-	56: UnsignedLongArray16Code,
-	57: UnsignedLongArray32Code,
-	58: UnsignedLongArray64Code,
-	59: DoubleCode,
-	60: DoubleArray8Code,
-	61: DoubleArray16Code,
-	62: DoubleArray32Code,
-	63: DoubleArray64Code,
-	64: FloatCode,
-	65: FloatArray8Code,
-	66: FloatArray16Code,
-	67: FloatArray32Code,
-	68: FloatArray64Code,
-	69: String8Code,
-	70: String16Code,
-	71: String32Code,
-	72: String64Code,
-	73: StringArray8Code,
-	74: StringArray16Code,
-	75: StringArray32Code,
-	76: StringArray64Code,
-	77: TimestampCode,
-	78: TimestampArray8Code,
-	79: TimestampArray16Code,
-	80: TimestampArray32Code,
-	81: TimestampArray64Code,
-	82: Tuple8Code,
-	83: Tuple16Code,
-	84: Tuple32Code,
-	85: Tuple64Code,
-	86: TupleArray8Code,
-	87: TupleArray16Code,
-	88: TupleArray32Code,
-	89: TupleArray64Code,
+	0:   NilCode,
+	1:   TrueCode,
+	2:   FalseCode,
+	3:   BooleanArray8Code,
+	4:   BooleanArray16Code,
+	5:   BooleanArray32Code,
+	6:   BooleanArray64Code,
+	7:   ByteCode,
+	8:   ByteArray8Code,
+	9:   ByteArray16Code,
+	10:  ByteArray32Code,
+	11:  ByteArray64Code,
+	12:  UnsignedByteCode,
+	13:  UnsignedByteArray8Code,
+	14:  UnsignedByteArray16Code,
+	15:  UnsignedByteArray32Code,
+	16:  UnsignedByteArray64Code,
+	17:  Short8Code,
+	18:  Short16Code,
+	19:  ShortArray8Code,
+	20:  ShortArray16Code,
+	21:  ShortArray32Code,
+	22:  ShortArray64Code,
+	23:  UnsignedShort8Code,
+	24:  UnsignedShort16Code,
+	25:  UnsignedShortArray8Code,
+	26:  UnsignedShortArray16Code,
+	27:  UnsignedShortArray32Code,
+	28:  UnsignedShortArray64Code,
+	29:  Int8Code,
+	30:  Int16Code,
+	31:  Int32Code,
+	32:  IntArray8Code,
+	33:  IntArray16Code,
+	34:  IntArray32Code,
+	35:  IntArray64Code,
+	36:  UnsignedInt8Code,
+	37:  UnsignedInt16Code,
+	38:  UnsignedInt32Code,
+	39:  UnsignedIntArray8Code,
+	40:  UnsignedIntArray16Code,
+	41:  UnsignedIntArray32Code,
+	42:  UnsignedIntArray64Code,
+	43:  Long8Code,
+	44:  Long16Code,
+	45:  Long32Code,
+	46:  Long64Code,
+	47:  LongArray8Code,
+	48:  LongArray16Code,
+	49:  LongArray32Code,
+	50:  LongArray64Code,
+	51:  UnsignedLong8Code,
+	52:  UnsignedLong16Code,
+	53:  UnsignedLong32Code,
+	54:  UnsignedLong64Code,
+	55:  UnsignedLongArray8Code,
+	56:  UnsignedLongArray16Code,
+	57:  UnsignedLongArray32Code,
+	58:  UnsignedLongArray64Code,
+	59:  DoubleCode,
+	60:  DoubleArray8Code,
+	61:  DoubleArray16Code,
+	62:  DoubleArray32Code,
+	63:  DoubleArray64Code,
+	64:  FloatCode,
+	65:  FloatArray8Code,
+	66:  FloatArray16Code,
+	67:  FloatArray32Code,
+	68:  FloatArray64Code,
+	69:  String8Code,
+	70:  String16Code,
+	71:  String32Code,
+	72:  String64Code,
+	73:  StringArray8Code,
+	74:  StringArray16Code,
+	75:  StringArray32Code,
+	76:  StringArray64Code,
+	77:  TimestampCode,
+	78:  TimestampArray8Code,
+	79:  TimestampArray16Code,
+	80:  TimestampArray32Code,
+	81:  TimestampArray64Code,
+	82:  Tuple8Code,
+	83:  Tuple16Code,
+	84:  Tuple32Code,
+	85:  Tuple64Code,
+	86:  TupleArray8Code,
+	87:  TupleArray16Code,
+	88:  TupleArray32Code,
+	89:  TupleArray64Code,
+	91:  VarIntCode,
+	92:  UVarIntCode,
+	93:  Map8Code,
+	94:  Map16Code,
+	95:  Map32Code,
+	96:  Map64Code,
+	97:  VarIntArray8Code,
+	98:  VarIntArray16Code,
+	99:  VarIntArray32Code,
+	100: VarIntArray64Code,
+	101: UVarIntArray8Code,
+	102: UVarIntArray16Code,
+	103: UVarIntArray32Code,
+	104: UVarIntArray64Code,
+}
+
+var typeCodeNames = map[byte]string{
+	0:   "NilCode",
+	1:   "TrueCode",
+	2:   "FalseCode",
+	3:   "BooleanArray8Code",
+	4:   "BooleanArray16Code",
+	5:   "BooleanArray32Code",
+	6:   "BooleanArray64Code",
+	7:   "ByteCode",
+	8:   "ByteArray8Code",
+	9:   "ByteArray16Code",
+	10:  "ByteArray32Code",
+	11:  "ByteArray64Code",
+	12:  "UnsignedByteCode",
+	13:  "UnsignedByteArray8Code",
+	14:  "UnsignedByteArray16Code",
+	15:  "UnsignedByteArray32Code",
+	16:  "UnsignedByteArray64Code",
+	17:  "Short8Code",
+	18:  "Short16Code",
+	19:  "ShortArray8Code",
+	20:  "ShortArray16Code",
+	21:  "ShortArray32Code",
+	22:  "ShortArray64Code",
+	23:  "UnsignedShort8Code",
+	24:  "UnsignedShort16Code",
+	25:  "UnsignedShortArray8Code",
+	26:  "UnsignedShortArray16Code",
+	27:  "UnsignedShortArray32Code",
+	28:  "UnsignedShortArray64Code",
+	29:  "Int8Code",
+	30:  "Int16Code",
+	31:  "Int32Code",
+	32:  "IntArray8Code",
+	33:  "IntArray16Code",
+	34:  "IntArray32Code",
+	35:  "IntArray64Code",
+	36:  "UnsignedInt8Code",
+	37:  "UnsignedInt16Code",
+	38:  "UnsignedInt32Code",
+	39:  "UnsignedIntArray8Code",
+	40:  "UnsignedIntArray16Code",
+	41:  "UnsignedIntArray32Code",
+	42:  "UnsignedIntArray64Code",
+	43:  "Long8Code",
+	44:  "Long16Code",
+	45:  "Long32Code",
+	46:  "Long64Code",
+	47:  "LongArray8Code",
+	48:  "LongArray16Code",
+	49:  "LongArray32Code",
+	50:  "LongArray64Code",
+	51:  "UnsignedLong8Code",
+	52:  "UnsignedLong16Code",
+	53:  "UnsignedLong32Code",
+	54:  "UnsignedLong64Code",
+	55:  "UnsignedLongArray8Code",
+	56:  "UnsignedLongArray16Code",
+	57:  "UnsignedLongArray32Code",
+	58:  "UnsignedLongArray64Code",
+	59:  "DoubleCode",
+	60:  "DoubleArray8Code",
+	61:  "DoubleArray16Code",
+	62:  "DoubleArray32Code",
+	63:  "DoubleArray64Code",
+	64:  "FloatCode",
+	65:  "FloatArray8Code",
+	66:  "FloatArray16Code",
+	67:  "FloatArray32Code",
+	68:  "FloatArray64Code",
+	69:  "String8Code",
+	70:  "String16Code",
+	71:  "String32Code",
+	72:  "String64Code",
+	73:  "StringArray8Code",
+	74:  "StringArray16Code",
+	75:  "StringArray32Code",
+	76:  "StringArray64Code",
+	77:  "TimestampCode",
+	78:  "TimestampArray8Code",
+	79:  "TimestampArray16Code",
+	80:  "TimestampArray32Code",
+	81:  "TimestampArray64Code",
+	82:  "Tuple8Code",
+	83:  "Tuple16Code",
+	84:  "Tuple32Code",
+	85:  "Tuple64Code",
+	86:  "TupleArray8Code",
+	87:  "TupleArray16Code",
+	88:  "TupleArray32Code",
+	89:  "TupleArray64Code",
+	91:  "VarIntCode",
+	92:  "UVarIntCode",
+	93:  "Map8Code",
+	94:  "Map16Code",
+	95:  "Map32Code",
+	96:  "Map64Code",
+	97:  "VarIntArray8Code",
+	98:  "VarIntArray16Code",
+	99:  "VarIntArray32Code",
+	100: "VarIntArray64Code",
+	101: "UVarIntArray8Code",
+	102: "UVarIntArray16Code",
+	103: "UVarIntArray32Code",
+	104: "UVarIntArray64Code",
+}
+
+// String returns the name of the type code constant
+func (c TypeCode) String() string {
+	if name, ok := typeCodeNames[c.OpCode]; ok {
+		return name
+	}
+	return "TypeCode(" + strconv.Itoa(int(c.OpCode)) + ")"
+}
+
+// LookupTypeCode returns the type code for the given opcode. If the opcode is unknown, false is returned.
+func LookupTypeCode(opcode uint8) (TypeCode, bool) {
+	code, ok := fieldTypes[opcode]
+	return code, ok
 }
@@ -0,0 +1,300 @@
+package namedtuple
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constraints restrict the values which can be written into a field. Bounds which are nil are not checked. For array fields, the value constraints are checked for each element and `MinItems` and `MaxItems` restrict the number of elements. For map fields, `MinItems` and `MaxItems` restrict the number of entries.
+type Constraints struct {
+
+	// Min and Max are the inclusive bounds of numeric values.
+	Min *float64
+	Max *float64
+
+	// MinTime and MaxTime are the inclusive bounds of timestamps.
+	MinTime *time.Time
+	MaxTime *time.Time
+
+	// MinLength and MaxLength are the inclusive bounds of the length
+	// of strings in bytes.
+	MinLength *int
+	MaxLength *int
+
+	// Pattern is a regular expression which strings must match.
+	Pattern *regexp.Regexp
+
+	// MinItems and MaxItems are the inclusive bounds of the number of
+	// elements in arrays.
+	MinItems *int
+	MaxItems *int
+}
+
+// IsZero returns true if no constraints are set.
+func (c Constraints) IsZero() bool {
+	return c == Constraints{}
+}
+
+// Violation describes a field value which does not satisfy the field constraints.
+type Violation struct {
+	Field   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// ValidationError is returned from Build if the values of one or more fields violate their constraints. Every violation is included, not just the first.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return "Invalid field values: " + strings.Join(messages, "; ")
+}
+
+// validate checks the values of all the fields with constraints. Fields which were not written are not checked. If any values violate their constraints, a ValidationError is returned.
+func (t *Tuple) validate() error {
+	if !t.Header.Type.constrained {
+		return nil
+	}
+
+	var violations []Violation
+	for _, version := range t.Header.Type.Versions() {
+		if version.Num > t.Header.TupleVersion {
+			break
+		}
+
+		for _, field := range version.Fields {
+			if field.Constraints.IsZero() {
+				continue
+			}
+
+			// default values of fields which were not written are not checked
+			if _, err := t.fieldOffset(field.Name, field.Type); err == ErrFieldNotPresent {
+				continue
+			}
+
+			messages, err := t.validateField(field)
+			if err != nil {
+				return err
+			}
+
+			for _, message := range messages {
+				violations = append(violations, Violation{field.Name, message})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return ValidationError{violations}
+	}
+	return nil
+}
+
+// validateField reads the field value and returns a message for each violated constraint.
+func (t *Tuple) validateField(field Field) (messages []string, err error) {
+	c := field.Constraints
+	name := field.Name
+	switch field.Type {
+	case StringField:
+		var value string
+		if value, err = t.GetString(name); err == nil {
+			messages = c.checkString(value)
+		}
+	case StringArrayField:
+		var values []string
+		if values, err = t.GetStringArray(name); err == nil {
+			messages = c.checkItems(len(values))
+			for i, value := range values {
+				messages = append(messages, elementMessages(i, c.checkString(value))...)
+			}
+		}
+	case TimestampField:
+		var value time.Time
+		if value, err = t.GetTimestamp(name); err == nil {
+			messages = c.checkTime(value)
+		}
+	case TimestampArrayField:
+		var values []time.Time
+		if values, err = t.GetTimestampArray(name); err == nil {
+			messages = c.checkItems(len(values))
+			for i, value := range values {
+				messages = append(messages, elementMessages(i, c.checkTime(value))...)
+			}
+		}
+	case BooleanArrayField:
+		var values []bool
+		if values, err = t.GetBoolArray(name); err == nil {
+			messages = c.checkItems(len(values))
+		}
+	case TupleArrayField:
+		var it *TupleIterator
+		if it, err = t.readTupleArray(name, nil); err == nil {
+			messages = c.checkItems(it.Len())
+		}
+	case MapField:
+		var it *MapIterator
+		if it, err = t.GetMapIterator(name); err == nil {
+			messages = c.checkItems(it.Len())
+		}
+	case BooleanField, TupleField, EnumField:
+	default:
+
+		// numeric values are read as their Go type
+		var v interface{}
+		if v, err = t.jsonValue(field, nil); err == nil {
+			value := reflect.ValueOf(v)
+			if value.Kind() != reflect.Slice {
+				messages = c.checkValue(value)
+				break
+			}
+
+			messages = c.checkItems(value.Len())
+			for i := 0; i < value.Len(); i++ {
+				messages = append(messages, elementMessages(i, c.checkValue(value.Index(i)))...)
+			}
+		}
+	}
+	return
+}
+
+// elementMessages prefixes the messages of an array element with the element index.
+func elementMessages(index int, messages []string) []string {
+	for i, message := range messages {
+		messages[i] = "element " + strconv.Itoa(index) + " " + message
+	}
+	return messages
+}
+
+// checkValue checks the bounds of a numeric value. Integers are compared in integer space, so 64-bit values are not rounded to the nearest float64.
+func (c Constraints) checkValue(value reflect.Value) []string {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return c.checkInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return c.checkUint(value.Uint())
+	}
+	return c.checkNumber(value.Float())
+}
+
+func (c Constraints) checkNumber(value float64) (messages []string) {
+	if c.Min != nil && value < *c.Min {
+		messages = append(messages, fmt.Sprintf("value %s is less than the minimum %s", formatFloat(value), formatFloat(*c.Min)))
+	}
+	if c.Max != nil && value > *c.Max {
+		messages = append(messages, fmt.Sprintf("value %s is greater than the maximum %s", formatFloat(value), formatFloat(*c.Max)))
+	}
+	return
+}
+
+func (c Constraints) checkString(value string) (messages []string) {
+	if c.MinLength != nil && len(value) < *c.MinLength {
+		messages = append(messages, fmt.Sprintf("length %d is less than the minimum length %d", len(value), *c.MinLength))
+	}
+	if c.MaxLength != nil && len(value) > *c.MaxLength {
+		messages = append(messages, fmt.Sprintf("length %d is greater than the maximum length %d", len(value), *c.MaxLength))
+	}
+	if c.Pattern != nil && !c.Pattern.MatchString(value) {
+		messages = append(messages, fmt.Sprintf("value %q does not match the pattern %q", value, c.Pattern.String()))
+	}
+	return
+}
+
+func (c Constraints) checkTime(value time.Time) (messages []string) {
+	if c.MinTime != nil && value.Before(*c.MinTime) {
+		messages = append(messages, fmt.Sprintf("time %s is before the minimum %s", value.Format(time.RFC3339Nano), c.MinTime.Format(time.RFC3339Nano)))
+	}
+	if c.MaxTime != nil && value.After(*c.MaxTime) {
+		messages = append(messages, fmt.Sprintf("time %s is after the maximum %s", value.Format(time.RFC3339Nano), c.MaxTime.Format(time.RFC3339Nano)))
+	}
+	return
+}
+
+func (c Constraints) checkItems(length int) (messages []string) {
+	if c.MinItems != nil && length < *c.MinItems {
+		messages = append(messages, fmt.Sprintf("%d elements is less than the minimum %d", length, *c.MinItems))
+	}
+	if c.MaxItems != nil && length > *c.MaxItems {
+		messages = append(messages, fmt.Sprintf("%d elements is greater than the maximum %d", length, *c.MaxItems))
+	}
+	return
+}
+
+func (c Constraints) checkInt(value int64) (messages []string) {
+	if c.Min != nil && intLess(value, *c.Min) {
+		messages = append(messages, fmt.Sprintf("value %d is less than the minimum %s", value, formatFloat(*c.Min)))
+	}
+	if c.Max != nil && intGreater(value, *c.Max) {
+		messages = append(messages, fmt.Sprintf("value %d is greater than the maximum %s", value, formatFloat(*c.Max)))
+	}
+	return
+}
+
+func (c Constraints) checkUint(value uint64) (messages []string) {
+	if c.Min != nil && uintLess(value, *c.Min) {
+		messages = append(messages, fmt.Sprintf("value %d is less than the minimum %s", value, formatFloat(*c.Min)))
+	}
+	if c.Max != nil && uintGreater(value, *c.Max) {
+		messages = append(messages, fmt.Sprintf("value %d is greater than the maximum %s", value, formatFloat(*c.Max)))
+	}
+	return
+}
+
+// intLess reports whether the integer is less than the bound. An integer is less than a bound if it is less than the bound rounded up, which is compared as an integer unless it is out of the int64 range.
+func intLess(value int64, bound float64) bool {
+	b := math.Ceil(bound)
+	if b >= 1<<63 {
+		return true
+	} else if b < -1<<63 {
+		return false
+	}
+	return value < int64(b)
+}
+
+// intGreater reports whether the integer is greater than the bound rounded down.
+func intGreater(value int64, bound float64) bool {
+	b := math.Floor(bound)
+	if b >= 1<<63 {
+		return false
+	} else if b < -1<<63 {
+		return true
+	}
+	return value > int64(b)
+}
+
+// uintLess reports whether the unsigned integer is less than the bound rounded up.
+func uintLess(value uint64, bound float64) bool {
+	b := math.Ceil(bound)
+	if b <= 0 {
+		return false
+	} else if b >= 1<<64 {
+		return true
+	}
+	return value < uint64(b)
+}
+
+// uintGreater reports whether the unsigned integer is greater than the bound rounded down.
+func uintGreater(value uint64, bound float64) bool {
+	b := math.Floor(bound)
+	if b < 0 {
+		return true
+	} else if b >= 1<<64 {
+		return false
+	}
+	return value > uint64(b)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
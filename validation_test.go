@@ -0,0 +1,161 @@
+package namedtuple
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(value float64) *float64 { return &value }
+func intPtr(value int) *int           { return &value }
+
+func createTestConstrainedType() TupleType {
+	minTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	User := New("testing", "user")
+	User.AddVersion(
+		Field{Name: "username", Required: true, Type: StringField, Constraints: Constraints{
+			MinLength: intPtr(3), MaxLength: intPtr(8), Pattern: regexp.MustCompile("^[a-z]+$"),
+		}},
+		Field{Name: "age", Type: Uint8Field, Constraints: Constraints{Min: floatPtr(13), Max: floatPtr(150)}},
+		Field{Name: "scores", Type: Float64ArrayField, Constraints: Constraints{Min: floatPtr(0), Max: floatPtr(1), MaxItems: intPtr(3)}},
+		Field{Name: "emails", Type: StringArrayField, Constraints: Constraints{MinItems: intPtr(1), Pattern: regexp.MustCompile("@")}},
+		Field{Name: "created", Type: TimestampField, Constraints: Constraints{MinTime: &minTime}},
+	)
+	User.AddVersion(
+		Field{Name: "balance", Type: Int64Field, Constraints: Constraints{Min: floatPtr(0)}},
+	)
+	return User
+}
+
+func TestConstraintsIsZero(t *testing.T) {
+	assert.True(t, Constraints{}.IsZero())
+	assert.False(t, Constraints{MinItems: intPtr(0)}.IsZero())
+
+	// only types with constraints are validated
+	assert.False(t, createTestTupleType().constrained)
+	assert.True(t, createTestConstrainedType().constrained)
+}
+
+func TestValidationPass(t *testing.T) {
+	builder := NewBuilder(createTestConstrainedType(), make([]byte, 1024))
+	builder.PutString("username", "alice")
+	builder.PutUint8("age", 30)
+	builder.PutFloat64Array("scores", []float64{0, 0.5, 1})
+	builder.PutStringArray("emails", []string{"alice@example.com"})
+	builder.PutTimestamp("created", time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC))
+	builder.PutInt64("balance", 100)
+
+	user, err := builder.Build()
+	assert.Nil(t, err)
+	assert.NotEqual(t, NIL, user)
+}
+
+func TestValidationFieldNotPresent(t *testing.T) {
+
+	// optional fields which were not written are not checked
+	builder := NewBuilder(createTestConstrainedType(), make([]byte, 1024))
+	builder.PutString("username", "alice")
+
+	_, err := builder.Build()
+	assert.Nil(t, err)
+}
+
+func TestValidationFail(t *testing.T) {
+	builder := NewBuilder(createTestConstrainedType(), make([]byte, 1024))
+	builder.PutString("username", "Al")
+	builder.PutUint8("age", 7)
+	builder.PutFloat64Array("scores", []float64{0.5, 1.5, -1, 0})
+	builder.PutStringArray("emails", []string{})
+	builder.PutTimestamp("created", time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC))
+	builder.PutInt64("balance", -5)
+
+	user, err := builder.Build()
+	assert.Equal(t, NIL, user)
+
+	// every violated field is reported
+	expected := ValidationError{[]Violation{
+		{"username", "length 2 is less than the minimum length 3"},
+		{"username", `value "Al" does not match the pattern "^[a-z]+$"`},
+		{"age", "value 7 is less than the minimum 13"},
+		{"scores", "4 elements is greater than the maximum 3"},
+		{"scores", "element 1 value 1.5 is greater than the maximum 1"},
+		{"scores", "element 2 value -1 is less than the minimum 0"},
+		{"emails", "0 elements is less than the minimum 1"},
+		{"created", "time 1999-12-31T00:00:00Z is before the minimum 2000-01-01T00:00:00Z"},
+		{"balance", "value -5 is less than the minimum 0"},
+	}}
+	assert.Equal(t, expected, err)
+}
+
+func TestValidationArrayElements(t *testing.T) {
+	builder := NewBuilder(createTestConstrainedType(), make([]byte, 1024))
+	builder.PutString("username", "alice")
+	builder.PutStringArray("emails", []string{"alice@example.com", "alice"})
+
+	_, err := builder.Build()
+	assert.Equal(t, ValidationError{[]Violation{
+		{"emails", `element 1 value "alice" does not match the pattern "@"`},
+	}}, err)
+}
+
+func TestValidationErrorString(t *testing.T) {
+	err := ValidationError{[]Violation{
+		{"username", "length 2 is less than the minimum length 3"},
+		{"age", "value 7 is less than the minimum 13"},
+	}}
+	assert.Equal(t, "Invalid field values: username: length 2 is less than the minimum length 3; age: value 7 is less than the minimum 13", err.Error())
+}
+
+func TestValidationDefault(t *testing.T) {
+	User := New("testing", "user")
+	User.AddVersion(
+		Field{Name: "age", Type: Uint8Field, Default: uint8(0), Constraints: Constraints{Min: floatPtr(13)}},
+	)
+
+	// default values of fields which were not written are not checked
+	builder := NewBuilder(User, make([]byte, 64))
+	_, err := builder.Build()
+	assert.Nil(t, err)
+}
+
+func TestValidationInt64Bounds(t *testing.T) {
+	Counter := New("testing", "counter")
+	Counter.AddVersion(
+		Field{Name: "count", Type: Uint64Field, Constraints: Constraints{Max: floatPtr(1 << 53)}},
+		Field{Name: "offsets", Type: Int64ArrayField, Constraints: Constraints{Min: floatPtr(-1 << 53), Max: floatPtr(0.5)}},
+	)
+
+	// the values round to the bounds as float64
+	builder := NewBuilder(Counter, make([]byte, 128))
+	builder.PutUint64("count", 1<<53+1)
+	builder.PutInt64Array("offsets", []int64{-1<<53 - 1, 0, 1})
+	_, err := builder.Build()
+	assert.Equal(t, ValidationError{[]Violation{
+		{"count", "value 9007199254740993 is greater than the maximum 9.007199254740992e+15"},
+		{"offsets", "element 0 value -9007199254740993 is less than the minimum -9.007199254740992e+15"},
+		{"offsets", "element 2 value 1 is greater than the maximum 0.5"},
+	}}, err)
+
+	builder.Reset()
+	builder.PutUint64("count", 1<<53)
+	builder.PutInt64Array("offsets", []int64{-1 << 53, 0})
+	_, err = builder.Build()
+	assert.Nil(t, err)
+}
+
+func TestValidationMap(t *testing.T) {
+	Inventory := New("testing", "inventory")
+	Inventory.AddVersion(
+		Field{Name: "counts", Type: MapField, KeyType: StringField, ValueType: Uint32Field, Constraints: Constraints{MaxItems: intPtr(1)}},
+	)
+
+	builder := NewBuilder(Inventory, make([]byte, 64))
+	builder.PutMap("counts", map[string]uint32{"a": 1, "b": 2})
+	_, err := builder.Build()
+	assert.Equal(t, ValidationError{[]Violation{
+		{"counts", "2 elements is greater than the maximum 1"},
+	}}, err)
+}
@@ -0,0 +1,147 @@
+package namedtuple
+
+import (
+	"encoding/binary"
+
+	"github.com/blacklabeldata/xbinary"
+)
+
+// IntEncoding selects how the 16, 32 and 64-bit integer fields and arrays are written. 8-bit integers are always written with a single byte. The getters read values written with either encoding.
+type IntEncoding uint8
+
+const (
+	// DefaultIntEncoding uses the encoding of the builder. It is the encoding of fields which do not set one.
+	DefaultIntEncoding IntEncoding = iota
+
+	// FixedIntEncoding writes scalars with the smallest fixed-width type code which fits the value and arrays with the width of the field type for every element. It is the default encoding of a builder.
+	FixedIntEncoding
+
+	// VarIntEncoding writes scalars and every element of an array as LEB128 varints with the `UVarIntCode` and `VarIntCode` type codes. Signed values are zig-zag encoded so small negative values use few bytes as well.
+	VarIntEncoding
+)
+
+// SetIntEncoding sets the encoding of the integer fields which do not set their own encoding. See `IntEncoding`.
+func (b *TupleBuilder) SetIntEncoding(encoding IntEncoding) {
+	b.encoding = encoding
+}
+
+// varInt returns true if the integer field is written as a varint. The encoding of the field takes precedence over the encoding of the builder.
+func (b *TupleBuilder) varInt(field string) bool {
+	if def, _, _ := b.tupleType.field(field); def.Encoding != DefaultIntEncoding {
+		return def.Encoding == VarIntEncoding
+	}
+	return b.encoding == VarIntEncoding
+}
+
+// zigZag maps signed values to unsigned values so that values with a small magnitude have a small encoding: 0, -1, 1, -2 are mapped to 0, 1, 2, 3.
+func zigZag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
+
+// unZigZag reverses zigZag.
+func unZigZag(value uint64) int64 {
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+// uvarintSize returns the number of bytes used by the LEB128 encoding of the value.
+func uvarintSize(value uint64) int {
+	size := 1
+	for value >= 0x80 {
+		value >>= 7
+		size++
+	}
+	return size
+}
+
+// putVarInt writes the type code followed by the varint value. The value of signed fields must already be zig-zag encoded.
+func (b *TupleBuilder) putVarInt(field string, code TypeCode, value uint64) (wrote uint64, err error) {
+	size := uvarintSize(value)
+	if err = b.reserve(size + 1); err != nil {
+		return 0, err
+	}
+	if b.available() < size+1 {
+		return 0, xbinary.ErrOutOfRange
+	}
+
+	// write type code
+	b.buffer[b.pos] = byte(code.OpCode)
+
+	// write value
+	binary.PutUvarint(b.buffer[b.pos+1:], value)
+
+	// set field offset
+	b.setOffset(field)
+
+	// incr pos
+	b.pos += size + 1
+	return uint64(size + 1), nil
+}
+
+// putVarIntArray writes the type code, the number of elements and each element as a varint. The element function returns the value of the element at the given index, zig-zag encoded for signed fields.
+func (b *TupleBuilder) putVarIntArray(field string, first TypeCode, length int, element func(int) uint64) (wrote int, err error) {
+
+	// calculate total size
+	var size int
+	for i := 0; i < length; i++ {
+		size += uvarintSize(element(i))
+	}
+
+	// write type code and length
+	wrote, err = b.putArrayHeader(first, length, size)
+	if err != nil {
+		return 0, err
+	}
+
+	// write elements
+	for i := 0; i < length; i++ {
+		wrote += binary.PutUvarint(b.buffer[b.pos+wrote:], element(i))
+	}
+
+	b.setOffset(field)
+	b.pos += wrote
+	return
+}
+
+// readVarInt reads the varint at the given position and returns the position after the value. If the value is greater than max, `ErrValueOverflow` is returned. The value of signed fields is zig-zag encoded, which maps the range of a signed type onto the range of the unsigned type of the same width.
+func (t *Tuple) readVarInt(pos int, max uint64) (value uint64, next int, err error) {
+	if pos < 0 || pos >= len(t.data) {
+		return 0, 0, xbinary.ErrOutOfRange
+	}
+
+	value, n := binary.Uvarint(t.data[pos:])
+	if n == 0 {
+		return 0, 0, xbinary.ErrOutOfRange
+	} else if n < 0 || value > max {
+		return 0, 0, ErrValueOverflow
+	}
+	return value, pos + n, nil
+}
+
+// readVarIntArray reads an array written with the varint array type codes starting with the given type code. If the field was written with a different type code, false is returned so the caller can read the fixed-width encoding. Errors reading the field offset are left to the caller as well.
+func (t *Tuple) readVarIntArray(field string, fieldType FieldType, first TypeCode, max uint64) (values []uint64, ok bool, err error) {
+	pos, err := t.fieldOffset(field, fieldType)
+	if err != nil || t.data[pos] < first.OpCode || t.data[pos] > first.OpCode+3 {
+		return nil, false, nil
+	}
+
+	// read length
+	size := fieldTypes[t.data[pos]].Size
+	length, err := t.readLength(pos+1, size)
+	if err != nil {
+		return nil, true, err
+	}
+	pos += 1 + int(size)
+
+	// each element uses at least 1 byte
+	if length > uint64(len(t.data)-pos) {
+		return nil, true, xbinary.ErrOutOfRange
+	}
+
+	values = make([]uint64, length)
+	for i := range values {
+		if values[i], pos, err = t.readVarInt(pos, max); err != nil {
+			return nil, true, err
+		}
+	}
+	return values, true, nil
+}
@@ -0,0 +1,219 @@
+package namedtuple
+
+import (
+	"math"
+	"testing"
+
+	"github.com/blacklabeldata/xbinary"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestVarIntType() TupleType {
+	Counters := New("testing", "counters")
+	Counters.AddVersion(
+		Field{Name: "u16", Type: Uint16Field},
+		Field{Name: "i16", Type: Int16Field},
+		Field{Name: "u32", Type: Uint32Field},
+		Field{Name: "i32", Type: Int32Field},
+		Field{Name: "u64", Type: Uint64Field},
+		Field{Name: "i64", Type: Int64Field},
+		Field{Name: "fixed", Type: Uint64Field, Encoding: FixedIntEncoding},
+		Field{Name: "varint", Type: Uint64Field, Encoding: VarIntEncoding},
+	)
+	Counters.AddVersion(
+		Field{Name: "u16s", Type: Uint16ArrayField},
+		Field{Name: "i16s", Type: Int16ArrayField},
+		Field{Name: "u32s", Type: Uint32ArrayField},
+		Field{Name: "i32s", Type: Int32ArrayField},
+		Field{Name: "u64s", Type: Uint64ArrayField},
+		Field{Name: "i64s", Type: Int64ArrayField},
+	)
+	return Counters
+}
+
+func TestZigZag(t *testing.T) {
+	values := []int64{0, -1, 1, -2, 2, math.MaxInt64, math.MinInt64}
+	expected := []uint64{0, 1, 2, 3, 4, math.MaxUint64 - 1, math.MaxUint64}
+	for i, value := range values {
+		assert.Equal(t, expected[i], zigZag(value))
+		assert.Equal(t, value, unZigZag(zigZag(value)))
+	}
+
+	assert.Equal(t, 1, uvarintSize(0))
+	assert.Equal(t, 1, uvarintSize(127))
+	assert.Equal(t, 2, uvarintSize(128))
+	assert.Equal(t, 10, uvarintSize(math.MaxUint64))
+}
+
+func TestBuilderPutVarIntPass(t *testing.T) {
+	builder := NewBuilder(createTestVarIntType(), make([]byte, 1024))
+	builder.SetIntEncoding(VarIntEncoding)
+
+	wrote, err := builder.PutUint32("u32", 300)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), wrote)
+	assert.Equal(t, []byte{UVarIntCode.OpCode, 0xac, 0x02}, builder.buffer[:3])
+
+	wrote, err = builder.PutInt64("i64", -2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), wrote)
+	assert.Equal(t, []byte{VarIntCode.OpCode, 3}, builder.buffer[3:5])
+
+	builder.PutUint16("u16", math.MaxUint16)
+	builder.PutInt16("i16", math.MinInt16)
+	builder.PutInt32("i32", math.MinInt32)
+	builder.PutUint64("u64", math.MaxUint64)
+
+	// the field encoding takes precedence over the builder encoding
+	wrote, err = builder.PutUint64("fixed", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), wrote)
+	assert.Equal(t, UnsignedLong8Code.OpCode, builder.buffer[builder.pos-2])
+
+	counters, err := builder.Build()
+	assert.Nil(t, err)
+
+	u16, err := counters.GetUint16("u16")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(math.MaxUint16), u16)
+
+	i16, err := counters.GetInt16("i16")
+	assert.Nil(t, err)
+	assert.Equal(t, int16(math.MinInt16), i16)
+
+	u32, err := counters.GetUint32("u32")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(300), u32)
+
+	i32, err := counters.GetInt32("i32")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(math.MinInt32), i32)
+
+	u64, err := counters.GetUint64("u64")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), u64)
+
+	i64, err := counters.GetInt64("i64")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-2), i64)
+
+	fixed, err := counters.GetUint64("fixed")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), fixed)
+}
+
+func TestBuilderPutVarIntField(t *testing.T) {
+
+	// fields with the varint encoding do not need the builder encoding
+	builder := NewBuilder(createTestVarIntType(), make([]byte, 1024))
+	wrote, err := builder.PutUint64("varint", 1<<20)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), wrote)
+	assert.Equal(t, UVarIntCode.OpCode, builder.buffer[0])
+
+	wrote, err = builder.PutUint64("u64", 1<<20)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), wrote)
+	assert.Equal(t, UnsignedLong32Code.OpCode, builder.buffer[4])
+}
+
+func TestBuilderPutVarIntFail(t *testing.T) {
+	builder := NewBuilder(createTestVarIntType(), make([]byte, 2))
+	builder.SetIntEncoding(VarIntEncoding)
+
+	// type code and 3 bytes
+	wrote, err := builder.PutUint32("u32", 1<<20)
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+	assert.Equal(t, uint64(0), wrote)
+
+	wrote, err = builder.PutUint32("u32", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), wrote)
+
+	_, err = builder.PutUint32Array("u32s", []uint32{1})
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+}
+
+func TestBuilderPutVarIntArrayPass(t *testing.T) {
+	builder := NewBuilder(createTestVarIntType(), make([]byte, 1024))
+	builder.SetIntEncoding(VarIntEncoding)
+
+	wrote, err := builder.PutUint32Array("u32s", []uint32{1, 300, 0})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, wrote)
+	assert.Equal(t, []byte{UVarIntArray8Code.OpCode, 3, 1, 0xac, 0x02, 0}, builder.buffer[:6])
+
+	builder.PutUint16Array("u16s", []uint16{0, math.MaxUint16})
+	builder.PutInt16Array("i16s", []int16{math.MinInt16, -1, math.MaxInt16})
+	builder.PutInt32Array("i32s", []int32{math.MinInt32, 0, math.MaxInt32})
+	builder.PutUint64Array("u64s", []uint64{math.MaxUint64})
+	builder.PutInt64Array("i64s", []int64{math.MinInt64, -1, 1, math.MaxInt64})
+
+	counters, err := builder.Build()
+	assert.Nil(t, err)
+
+	u16s, err := counters.GetUint16Array("u16s")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint16{0, math.MaxUint16}, u16s)
+
+	i16s, err := counters.GetInt16Array("i16s")
+	assert.Nil(t, err)
+	assert.Equal(t, []int16{math.MinInt16, -1, math.MaxInt16}, i16s)
+
+	u32s, err := counters.GetUint32Array("u32s")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{1, 300, 0}, u32s)
+
+	i32s, err := counters.GetInt32Array("i32s")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{math.MinInt32, 0, math.MaxInt32}, i32s)
+
+	u64s, err := counters.GetUint64Array("u64s")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{math.MaxUint64}, u64s)
+
+	i64s, err := counters.GetInt64Array("i64s")
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{math.MinInt64, -1, 1, math.MaxInt64}, i64s)
+
+	// missing fields are reported by the fixed-width getter
+	_, err = counters.GetUint32Array("missing")
+	assert.Equal(t, ErrFieldDoesNotExist, err)
+}
+
+func TestTupleGetVarIntFail(t *testing.T) {
+	builder := NewBuilder(createTestVarIntType(), make([]byte, 1024))
+	builder.SetIntEncoding(VarIntEncoding)
+	builder.PutUint16("u16", 0)
+	counters, err := builder.Build()
+	assert.Nil(t, err)
+
+	// value does not fit into the field type
+	counters.data[1] = 0xff
+	counters.data = append(counters.data[:2], 0xff, 0x7f)
+	_, err = counters.GetUint16("u16")
+	assert.Equal(t, ErrValueOverflow, err)
+
+	// truncated value
+	counters.data[2] = 0xff
+	counters.data = counters.data[:3]
+	_, err = counters.GetUint16("u16")
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+
+	// array length larger than the data
+	builder = NewBuilder(createTestVarIntType(), make([]byte, 1024))
+	builder.SetIntEncoding(VarIntEncoding)
+	builder.PutUint32Array("u32s", []uint32{300, 1})
+	counters, err = builder.Build()
+	assert.Nil(t, err)
+
+	counters.data[1] = 5
+	_, err = counters.GetUint32Array("u32s")
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+
+	// truncated element
+	counters.data[1] = 2
+	counters.data = counters.data[:4]
+	_, err = counters.GetUint32Array("u32s")
+	assert.Equal(t, xbinary.ErrOutOfRange, err)
+}